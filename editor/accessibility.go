@@ -0,0 +1,99 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// Accessibility exposes the current line and the mode to screen readers as the
+// accessible name and description of the screen widget, which they announce when
+// they change. It only works while an assistive technology is active.
+type Accessibility struct {
+	line  string
+	mode  string
+	timer *core.QTimer
+}
+
+func (w *Workspace) initAccessibility() {
+	w.screen.widget.SetAccessibleName("Neovim")
+	w.accessibility = &Accessibility{
+		timer: core.NewQTimer(nil),
+	}
+	w.accessibility.timer.SetSingleShot(true)
+	// Announce only where the cursor rests, not every line while scrolling
+	w.accessibility.timer.ConnectTimeout(w.announce)
+}
+
+// updateAccessibility is called on flush
+func (w *Workspace) updateAccessibility() {
+	if w.accessibility == nil || !gui.QAccessible_IsActive() {
+		return
+	}
+	w.accessibility.timer.Start(150)
+}
+
+func (w *Workspace) announce() {
+	a := w.accessibility
+	widget := w.screen.widget
+
+	mode := modeLabel(w.mode)
+	if mode != a.mode {
+		a.mode = mode
+		widget.SetAccessibleDescription(mode)
+		gui.QAccessible_UpdateAccessibility(gui.NewQAccessibleEvent(widget, gui.QAccessible__DescriptionChanged))
+	}
+
+	line := w.cursorLineText()
+	if line != a.line {
+		a.line = line
+		widget.SetAccessibleName(line)
+		gui.QAccessible_UpdateAccessibility(gui.NewQAccessibleEvent(widget, gui.QAccessible__NameChanged))
+	}
+}
+
+// cursorLineText is the text of the row of the grid where the cursor is
+func (w *Workspace) cursorLineText() string {
+	win, ok := w.screen.getWindow(w.cursor.gridid)
+	if !ok {
+		return ""
+	}
+	row := w.screen.cursor[0]
+	if row < 0 || row >= len(win.content) {
+		return ""
+	}
+	var b strings.Builder
+	for _, cell := range win.content[row] {
+		if cell == nil {
+			b.WriteString(" ")
+			continue
+		}
+		b.WriteString(cell.char)
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return "blank"
+	}
+
+	return text
+}
+
+func modeLabel(mode string) string {
+	switch {
+	case strings.HasPrefix(mode, "insert"):
+		return "Insert mode"
+	case strings.HasPrefix(mode, "visual"):
+		return "Visual mode"
+	case strings.HasPrefix(mode, "replace"):
+		return "Replace mode"
+	case strings.HasPrefix(mode, "cmdline"):
+		return "Command line mode"
+	case strings.HasPrefix(mode, "terminal"):
+		return "Terminal mode"
+	case mode == "operator":
+		return "Operator pending"
+	default:
+		return "Normal mode"
+	}
+}
@@ -0,0 +1,90 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// The kinds of gonvim_activity, in the order of their priority
+var activityKinds = []string{"terminal", "job", "diagnostics"}
+
+func activityColor(kind string) *RGBA {
+	switch kind {
+	case "diagnostics":
+		return newRGBA(204, 62, 68, 1)
+	case "job":
+		return newRGBA(42, 188, 180, 1)
+	default:
+		return hexToRGBA(editor.config.SideBar.AccentColor)
+	}
+}
+
+func activityPriority(kind string) int {
+	for i, k := range activityKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// setActivity handles gonvim_activity; "terminal" (output of a terminal),
+// "job" (a terminal job or :make has finished) or "diagnostics" (errors have
+// appeared). It is shown as a badge of the workspace until it is shown.
+func (w *Workspace) setActivity(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	kind, _ := args[0].(string)
+	if activityPriority(kind) < 0 || activityPriority(kind) <= activityPriority(w.activity) {
+		return
+	}
+	e := editor
+	index := -1
+	for i, ws := range e.workspaces {
+		if ws == w {
+			index = i
+		}
+	}
+	if index < 0 || index == e.active {
+		return
+	}
+	w.activity = kind
+	e.updateActivity(index)
+}
+
+// updateActivity shows the badge of the workspace in the sidebar and the tabs
+func (e *Editor) updateActivity(index int) {
+	kind := e.workspaces[index].activity
+	if e.wsSide != nil && index < len(e.wsSide.items) {
+		e.wsSide.items[index].setBadge(kind)
+	}
+	if e.wsTabs != nil {
+		e.wsTabs.update()
+	}
+}
+
+func newActivityBadge() *widgets.QLabel {
+	badge := widgets.NewQLabel2("●", nil, 0)
+	badge.SetContentsMargins(0, 0, 0, 0)
+	badge.SetAlignment(core.Qt__AlignVCenter)
+	badge.Hide()
+	return badge
+}
+
+// setBadge shows the badge of the activity of the workspace, "" to hide it
+func (i *WorkspaceSideItem) setBadge(kind string) {
+	if kind == "" {
+		i.badge.Hide()
+		return
+	}
+	i.badge.SetStyleSheet(fmt.Sprintf(" * { background-color: rgba(0, 0, 0, 0); color: %s; }", activityColor(kind).String()))
+	i.badge.SetToolTip(map[string]string{
+		"terminal":    "New output in a terminal",
+		"job":         "A job has finished",
+		"diagnostics": "New errors",
+	}[kind])
+	i.badge.Show()
+}
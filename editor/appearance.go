@@ -0,0 +1,155 @@
+package editor
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+)
+
+// isSystemDarkMode reports whether the OS is currently using a dark appearance
+func isSystemDarkMode() bool {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// AppleInterfaceStyle does not exist in light mode
+		out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+		if err != nil {
+			return false
+		}
+		return strings.Contains(string(out), "Dark")
+	case "windows":
+		cmd = exec.Command(
+			"reg", "query",
+			`HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+			"/v", "AppsUseLightTheme",
+		)
+		util.PrepareRunProc(cmd)
+		out, err := cmd.Output()
+		if err != nil {
+			return true
+		}
+		return strings.Contains(string(out), "0x0")
+	default:
+		out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+		if err == nil && strings.Contains(string(out), "dark") {
+			return true
+		}
+		out, err = exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+		if err != nil {
+			return true
+		}
+		return strings.Contains(strings.ToLower(string(out)), "dark")
+	}
+}
+
+func (e *Editor) initAppearance() {
+	if !e.config.Editor.FollowSystemAppearance {
+		return
+	}
+	e.isDarkAppearance = isSystemDarkMode()
+	e.setBasePalette()
+
+	e.appearanceChanges = make(chan bool, 10)
+	e.signal.ConnectAppearanceSignal(func() {
+		e.isDarkAppearance = <-e.appearanceChanges
+		e.setBasePalette()
+		e.updateGUIColor()
+		for _, ws := range e.workspaces {
+			if ws == nil || ws.nvim == nil {
+				continue
+			}
+			go ws.applyAppearance()
+		}
+	})
+
+	// Watch for the appearance to be changed at runtime. Qt 5 does not tell
+	// it, so it is checked when the application is activated, and seldom by a timer.
+	check := make(chan struct{}, 1)
+	e.app.ConnectApplicationStateChanged(func(state core.Qt__ApplicationState) {
+		if state != core.Qt__ApplicationActive {
+			return
+		}
+		select {
+		case check <- struct{}{}:
+		default:
+		}
+	})
+	isDark := e.isDarkAppearance
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+			case <-check:
+			}
+			if isSystemDarkMode() == isDark {
+				continue
+			}
+			isDark = !isDark
+			e.appearanceChanges <- isDark
+			e.signal.AppearanceSignal()
+		}
+	}()
+}
+
+// setBasePalette sets the GUI foreground/background to the configured base palette of the current appearance
+func (e *Editor) setBasePalette() {
+	fgHex := e.config.Editor.DarkForeground
+	bgHex := e.config.Editor.DarkBackground
+	if !e.isDarkAppearance {
+		fgHex = e.config.Editor.LightForeground
+		bgHex = e.config.Editor.LightBackground
+	}
+	fg := hexToRGBA(fgHex)
+	bg := hexToRGBA(bgHex)
+	if fg == nil || bg == nil {
+		return
+	}
+	e.colors.fg = fg
+	e.colors.bg = bg
+	e.colors.update()
+}
+
+// applyAppearance sets nvim's 'background' and colorscheme to match the system appearance
+func (w *Workspace) applyAppearance() {
+	if !editor.config.Editor.FollowSystemAppearance || !editor.config.Editor.SyncBackground {
+		return
+	}
+	w.setAppearance()
+}
+
+// toggleAppearance switches between the dark and the light appearance by the
+// theme button of the titlebar, until the system appearance is changed
+func (e *Editor) toggleAppearance() {
+	e.isDarkAppearance = !e.isDarkAppearance
+	e.setBasePalette()
+	e.updateGUIColor()
+	for _, ws := range e.workspaces {
+		if ws == nil || ws.nvim == nil {
+			continue
+		}
+		go ws.setAppearance()
+	}
+}
+
+// setAppearance sets nvim's 'background' and the colorscheme of the appearance
+func (w *Workspace) setAppearance() {
+	background := "dark"
+	colorscheme := editor.config.Editor.DarkColorscheme
+	if !editor.isDarkAppearance {
+		background = "light"
+		colorscheme = editor.config.Editor.LightColorscheme
+	}
+	w.nvim.Command(fmt.Sprintf("set background=%s", background))
+	if colorscheme != "" {
+		w.nvim.Command(fmt.Sprintf("silent! colorscheme %s", colorscheme))
+	}
+}
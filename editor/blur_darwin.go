@@ -0,0 +1,27 @@
+// +build darwin
+
+package editor
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+
+typedef int CGSConnectionID;
+extern CGSConnectionID CGSDefaultConnectionForThread(void);
+extern CGError CGSSetWindowBackgroundBlurRadius(CGSConnectionID cid, NSInteger wid, int radius);
+
+static void setWindowBlurRadius(uintptr_t view, int radius) {
+	NSWindow *window = [(NSView *)view window];
+	if (window == nil) {
+		return;
+	}
+	CGSSetWindowBackgroundBlurRadius(CGSDefaultConnectionForThread(), [window windowNumber], radius);
+}
+*/
+import "C"
+
+// setBlurBehind blurs the desktop behind the transparent window by the radius, 0 disables it
+func (e *Editor) setBlurBehind(radius int) {
+	C.setWindowBlurRadius(C.uintptr_t(e.window.WinId()), C.int(radius))
+}
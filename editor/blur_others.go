@@ -0,0 +1,26 @@
+// +build !darwin,!windows
+
+package editor
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// setBlurBehind asks KWin to blur behind the window, 0 disables it.
+// The blur radius is decided by the compositor, and the other compositors
+// and the Wayland sessions are not supported.
+func (e *Editor) setBlurBehind(radius int) {
+	if isWayland() {
+		return
+	}
+	id := fmt.Sprintf("%d", e.window.WinId())
+	var cmd *exec.Cmd
+	if radius > 0 {
+		// An empty region is the whole window
+		cmd = exec.Command("xprop", "-id", id, "-f", "_KDE_NET_WM_BLUR_BEHIND_REGION", "32c", "-set", "_KDE_NET_WM_BLUR_BEHIND_REGION", "0")
+	} else {
+		cmd = exec.Command("xprop", "-id", id, "-remove", "_KDE_NET_WM_BLUR_BEHIND_REGION")
+	}
+	go cmd.Run()
+}
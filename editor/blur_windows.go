@@ -0,0 +1,50 @@
+// +build windows
+
+package editor
+
+import (
+	"unsafe"
+)
+
+const (
+	wcaAccentPolicy = 19
+
+	accentDisabled                = 0
+	accentEnableAcrylicBlurBehind = 4
+)
+
+var procSetWindowCompositionAttribute = user32.NewProc("SetWindowCompositionAttribute")
+
+type accentPolicy struct {
+	accentState   uint32
+	accentFlags   uint32
+	gradientColor uint32
+	animationID   uint32
+}
+
+type windowCompositionAttribData struct {
+	attrib uint32
+	data   unsafe.Pointer
+	size   uintptr
+}
+
+// setBlurBehind enables the acrylic blur behind the transparent window, 0 disables it.
+// The blur radius is decided by the system.
+func (e *Editor) setBlurBehind(radius int) {
+	policy := accentPolicy{
+		accentState: accentDisabled,
+	}
+	if radius > 0 {
+		// The tint of the acrylic is the background color, in AABBGGRR
+		bg := e.colors.bg
+		alpha := uint32(e.config.Editor.Transparent * 255)
+		policy.accentState = accentEnableAcrylicBlurBehind
+		policy.gradientColor = alpha<<24 | uint32(bg.B)<<16 | uint32(bg.G)<<8 | uint32(bg.R)
+	}
+	data := windowCompositionAttribData{
+		attrib: wcaAccentPolicy,
+		data:   unsafe.Pointer(&policy),
+		size:   unsafe.Sizeof(policy),
+	}
+	procSetWindowCompositionAttribute.Call(e.window.WinId(), uintptr(unsafe.Pointer(&data)))
+}
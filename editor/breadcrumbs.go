@@ -0,0 +1,263 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Breadcrumb is a symbol around the cursor, or one of its siblings
+type Breadcrumb struct {
+	name     string
+	kind     string
+	line     int
+	col      int
+	siblings []*Breadcrumb
+}
+
+// Breadcrumbs is the bar above the screen showing the path of the current
+// buffer and the symbols around the cursor; a click on a segment opens the
+// menu of its siblings, the files of the directory or the symbols of the scope
+type Breadcrumbs struct {
+	ws     *Workspace
+	widget *widgets.QWidget
+	layout *widgets.QHBoxLayout
+	items  []*widgets.QWidget
+
+	win     int
+	path    string
+	relpath string
+	symbols []*Breadcrumb
+}
+
+func newBreadcrumbs(ws *Workspace) *Breadcrumbs {
+	b := &Breadcrumbs{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		layout: widgets.NewQHBoxLayout(),
+	}
+	b.layout.SetContentsMargins(8, 1, 8, 1)
+	b.layout.SetSpacing(2)
+	b.layout.AddStretch(1)
+	b.widget.SetLayout(b.layout)
+	b.widget.Hide()
+
+	return b
+}
+
+func (b *Breadcrumbs) setColor() {
+	fg := editor.colors.inactiveFg
+	bg := editor.colors.bg
+	if fg == nil || bg == nil {
+		return
+	}
+	font := b.ws.font
+	b.widget.SetStyleSheet(fmt.Sprintf(`
+	QWidget { color: %s; background-color: %s; font-family: %s; font-size: %dpt; }
+	QPushButton { border: 0px; padding: 0px 2px; }
+	QPushButton:hover { color: %s; background-color: %s; }
+	`, fg.String(), bg.String(), font.fontNew.Family(), int(font.fontNew.PointSizeF()*0.9),
+		editor.colors.fg.String(), editor.colors.selectedBg.String()))
+}
+
+func breadcrumbsFromArgs(arg interface{}) []*Breadcrumb {
+	items, _ := arg.([]interface{})
+	var crumbs []*Breadcrumb
+	for _, i := range items {
+		m, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		crumb := &Breadcrumb{
+			line: util.ReflectToInt(m["line"]),
+			col:  util.ReflectToInt(m["col"]),
+		}
+		crumb.name, _ = m["name"].(string)
+		crumb.kind, _ = m["kind"].(string)
+		if siblings, ok := m["siblings"]; ok {
+			crumb.siblings = breadcrumbsFromArgs(siblings)
+		}
+		crumbs = append(crumbs, crumb)
+	}
+
+	return crumbs
+}
+
+// handle handles gonvim_breadcrumbs; the window, the path, the relative path and the symbols
+func (b *Breadcrumbs) handle(args []interface{}) {
+	if len(args) < 4 {
+		return
+	}
+	win := util.ReflectToInt(args[0])
+	path, _ := args[1].(string)
+	relpath, _ := args[2].(string)
+	symbols := breadcrumbsFromArgs(args[3])
+	if path == "" {
+		b.win = win
+		b.path = ""
+		b.setVisible(false)
+		return
+	}
+	if win == b.win && path == b.path && relpath == b.relpath && sameBreadcrumbs(symbols, b.symbols) {
+		return
+	}
+	b.win = win
+	b.path = path
+	b.relpath = relpath
+	b.symbols = symbols
+	b.update()
+}
+
+func sameBreadcrumbs(a, b []*Breadcrumb) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].name != b[i].name || a[i].line != b[i].line || len(a[i].siblings) != len(b[i].siblings) {
+			return false
+		}
+	}
+	return true
+}
+
+// update rebuilds the segments of the bar
+func (b *Breadcrumbs) update() {
+	for _, item := range b.items {
+		item.DeleteLater()
+	}
+	b.items = nil
+
+	segments := strings.Split(filepath.ToSlash(b.relpath), "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		// The directory of the segment; the file is the last segment
+		dir := b.path
+		for j := i; j < len(segments); j++ {
+			dir = filepath.Dir(dir)
+		}
+		b.addSegment(segment, func(button *widgets.QPushButton) {
+			b.showFiles(button, dir)
+		})
+	}
+	for _, symbol := range b.symbols {
+		crumb := symbol
+		b.addSegment(crumb.name, func(button *widgets.QPushButton) {
+			b.showSymbols(button, crumb)
+		})
+	}
+
+	b.setColor()
+	b.setVisible(true)
+}
+
+// setVisible shows or hides the bar, and resizes the screen to the rest of the height
+func (b *Breadcrumbs) setVisible(visible bool) {
+	if b.widget.IsVisible() == visible {
+		return
+	}
+	b.widget.SetVisible(visible)
+	b.ws.updateSize()
+}
+
+func (b *Breadcrumbs) addSegment(text string, clicked func(*widgets.QPushButton)) {
+	if len(b.items) > 0 {
+		separator := widgets.NewQLabel2("›", nil, 0)
+		b.layout.InsertWidget(len(b.items), separator, 0, 0)
+		b.items = append(b.items, separator.QWidget_PTR())
+	}
+	button := widgets.NewQPushButton2(text, nil)
+	button.SetFocusPolicy(core.Qt__NoFocus)
+	button.SetCursor(gui.NewQCursor2(core.Qt__PointingHandCursor))
+	button.ConnectClicked(func(bool) {
+		clicked(button)
+	})
+	b.layout.InsertWidget(len(b.items), button, 0, 0)
+	b.items = append(b.items, button.QWidget_PTR())
+}
+
+func (b *Breadcrumbs) popup(menu *widgets.QMenu, button *widgets.QPushButton) {
+	menu.Popup(button.MapToGlobal(core.NewQPoint2(0, button.Height())), nil)
+}
+
+// showFiles shows the menu of the files in the directory; the directories are submenus
+func (b *Breadcrumbs) showFiles(button *widgets.QPushButton, dir string) {
+	menu := widgets.NewQMenu(b.widget)
+	b.fillFiles(menu, dir)
+	b.popup(menu, button)
+}
+
+func (b *Breadcrumbs) fillFiles(menu *widgets.QMenu, dir string) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if entry.IsDir() {
+			sub := menu.AddMenu2(name + "/")
+			filled := false
+			sub.ConnectAboutToShow(func() {
+				if filled {
+					return
+				}
+				filled = true
+				b.fillFiles(sub, path)
+			})
+			continue
+		}
+		menu.AddAction(name).ConnectTriggered(func(bool) {
+			go b.ws.nvim.Command(fmt.Sprintf("execute 'edit ' . fnameescape('%s')", escapeVimString(path)))
+			editor.wsWidget.SetFocus2()
+		})
+	}
+}
+
+// showSymbols shows the menu of the symbols in the scope of the symbol
+func (b *Breadcrumbs) showSymbols(button *widgets.QPushButton, crumb *Breadcrumb) {
+	menu := widgets.NewQMenu(b.widget)
+	siblings := crumb.siblings
+	if len(siblings) == 0 {
+		siblings = []*Breadcrumb{crumb}
+	}
+	win := b.win
+	for _, sibling := range siblings {
+		s := sibling
+		text := s.name
+		if s.kind != "" {
+			text = fmt.Sprintf("%s\t%s", s.name, s.kind)
+		}
+		action := menu.AddAction(text)
+		if s.name == crumb.name && s.line == crumb.line {
+			action.SetCheckable(true)
+			action.SetChecked(true)
+		}
+		action.ConnectTriggered(func(bool) {
+			go func() {
+				b.ws.nvim.SetWindowCursor(nvim.Window(win), [2]int{s.line, s.col})
+				b.ws.nvim.Command("normal! zvzz")
+			}()
+			editor.wsWidget.SetFocus2()
+		})
+	}
+	b.popup(menu, button)
+}
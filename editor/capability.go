@@ -0,0 +1,124 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// Capability is the set of UI features supported by the attached nvim
+type Capability struct {
+	apiLevel  int
+	uiEvents  map[string]bool
+	uiOptions map[string]bool
+}
+
+// uiFeature is an externalized UI feature and the ui option which enables it
+type uiFeature struct {
+	name     string
+	option   string
+	apiLevel int
+}
+
+// The api levels are the ones of the nvim release which introduced the option,
+// used when the nvim does not report "ui_options"
+var uiFeatures = []uiFeature{
+	{name: "multigrid", option: "ext_multigrid", apiLevel: 6},
+	{name: "hlstate", option: "ext_hlstate", apiLevel: 5},
+	{name: "cmdline", option: "ext_cmdline", apiLevel: 4},
+	{name: "messages", option: "ext_messages", apiLevel: 6},
+	{name: "popupmenu", option: "ext_popupmenu", apiLevel: 4},
+	{name: "tabline", option: "ext_tabline", apiLevel: 4},
+}
+
+// getCapability reads the api level, ui events and ui options from nvim_get_api_info
+func (w *Workspace) getCapability() *Capability {
+	c := &Capability{
+		uiEvents:  make(map[string]bool),
+		uiOptions: make(map[string]bool),
+	}
+	apiInfo, err := w.nvim.APIInfo()
+	if err != nil || len(apiInfo) < 2 {
+		return c
+	}
+	metadata, ok := apiInfo[1].(map[string]interface{})
+	if !ok {
+		return c
+	}
+	if version, ok := metadata["version"].(map[string]interface{}); ok {
+		c.apiLevel = util.ReflectToInt(version["api_level"])
+	}
+	if events, ok := metadata["ui_events"].([]interface{}); ok {
+		for _, event := range events {
+			function, ok := event.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, ok := function["name"].(string)
+			if !ok {
+				continue
+			}
+			c.uiEvents[name] = true
+		}
+	}
+	if options, ok := metadata["ui_options"].([]interface{}); ok {
+		for _, option := range options {
+			name, ok := option.(string)
+			if !ok {
+				continue
+			}
+			c.uiOptions[name] = true
+		}
+	}
+
+	return c
+}
+
+func (c *Capability) hasUIOption(option string) bool {
+	if len(c.uiOptions) > 0 {
+		return c.uiOptions[option]
+	}
+	for _, f := range uiFeatures {
+		if f.option == option {
+			return c.apiLevel >= f.apiLevel
+		}
+	}
+
+	return false
+}
+
+func (c *Capability) hasUIEvent(event string) bool {
+	return c.uiEvents[event]
+}
+
+// hasFloat reports whether nvim sends the positions of the floating windows
+func (c *Capability) hasFloat() bool {
+	return c.hasUIEvent("win_float_pos")
+}
+
+// notifyUnsupported tells which of the configured features are disabled because nvim is too old
+func (w *Workspace) notifyUnsupported(requested map[string]bool) {
+	var unsupported []string
+	for _, f := range uiFeatures {
+		if !requested[f.option] || w.capability.hasUIOption(f.option) {
+			continue
+		}
+		unsupported = append(unsupported, f.name)
+	}
+	if requested["ext_multigrid"] && !w.capability.hasFloat() {
+		unsupported = append(unsupported, "float")
+	}
+	if len(unsupported) == 0 {
+		return
+	}
+	editor.pushNotification(
+		NotifyWarn,
+		-1,
+		fmt.Sprintf(
+			"[Gonvim] The Neovim (API level %d) is too old for: %s. These features are disabled.",
+			w.capability.apiLevel,
+			strings.Join(unsupported, ", "),
+		),
+	)
+}
@@ -193,9 +193,7 @@ func (c *Cmdline) hide(args []interface{}) {
 	// }
 	win, ok := c.ws.screen.getWindow(c.ws.cursor.gridid)
 	if ok {
-		c.ws.cursor.widget.SetParent(win.widget)
-		c.ws.cursor.widget.Hide()
-		c.ws.cursor.widget.Show()
+		c.ws.cursor.setParentWindow(win)
 	}
 
 	c.shown = false
@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+var colorLiteralRegexp = regexp.MustCompile(`#[0-9a-fA-F]{6}\b|#[0-9a-fA-F]{3}\b|rgb\(\s*\d{1,3}\s*,\s*\d{1,3}\s*,\s*\d{1,3}\s*\)`)
+
+// ColorSwatches shows a swatch of each color literal shown in the grids at the
+// end of its line; a click on it opens the color dialog to replace the literal
+type ColorSwatches struct {
+	ws       *Workspace
+	swatches []*colorSwatch
+}
+
+type colorSwatch struct {
+	widget  *widgets.QWidget
+	win     nvim.Window
+	row     int
+	col     int
+	literal string
+	color   *RGBA
+}
+
+// colorLiteral is a color literal found in a row of a grid
+type colorLiteral struct {
+	col     int
+	literal string
+	color   *RGBA
+}
+
+func newColorSwatches(ws *Workspace) *ColorSwatches {
+	return &ColorSwatches{
+		ws: ws,
+	}
+}
+
+func (c *ColorSwatches) newSwatch() *colorSwatch {
+	s := &colorSwatch{
+		widget: widgets.NewQWidget(c.ws.screen.widget, 0),
+	}
+	s.widget.SetCursor(gui.NewQCursor2(core.Qt__PointingHandCursor))
+	s.widget.ConnectMousePressEvent(func(event *gui.QMouseEvent) {
+		c.pick(s)
+	})
+
+	return s
+}
+
+// update is called on flush
+func (c *ColorSwatches) update() {
+	if !editor.config.Editor.ColorSwatches {
+		return
+	}
+	n := 0
+	c.ws.screen.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil || win.grid == 1 || win.isMsgGrid || win.isFloatWin || !win.isShown() {
+			return true
+		}
+		font := win.getFont()
+		size := font.lineHeight * 6 / 10
+		for y, line := range win.content {
+			literals, end := findColorLiterals(line)
+			for i, lit := range literals {
+				if n >= len(c.swatches) {
+					c.swatches = append(c.swatches, c.newSwatch())
+				}
+				s := c.swatches[n]
+				n++
+				s.win = win.id
+				s.row = y
+				s.col = lit.col
+				s.literal = lit.literal
+				if s.color == nil || !s.color.equals(lit.color) {
+					s.widget.SetStyleSheet(fmt.Sprintf(" * { background: %s; border: 1px solid %s; border-radius: 2px; }", lit.color.String(), editor.colors.inactiveFg.String()))
+				}
+				s.color = lit.color
+				s.widget.SetFixedSize2(size, size)
+				x := int(float64(win.pos[0]+end+1)*font.truewidth) + i*(size+4)
+				top := (win.pos[1] + y) * font.lineHeight
+				s.widget.Move2(x, top+(font.lineHeight-size)/2)
+				s.widget.Show()
+				s.widget.Raise()
+			}
+		}
+		return true
+	})
+	for i := n; i < len(c.swatches); i++ {
+		c.swatches[i].widget.Hide()
+	}
+}
+
+func (c *ColorSwatches) pick(s *colorSwatch) {
+	picked := widgets.QColorDialog_GetColor(s.color.QColor(), editor.window, "Pick a color", 0)
+	if !picked.IsValid() {
+		return
+	}
+	r, g, b := picked.Red(), picked.Green(), picked.Blue()
+	value := fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	if strings.HasPrefix(s.literal, "rgb") {
+		value = fmt.Sprintf("rgb(%d, %d, %d)", r, g, b)
+	}
+	go c.ws.execLua("require('gonvim').replace_color(...)", s.win, s.row, s.col, s.literal, value)
+}
+
+// findColorLiterals returns the color literals of the row of a grid, and the column after its text
+func findColorLiterals(line []*Cell) ([]*colorLiteral, int) {
+	var text strings.Builder
+	// The column of each byte of the text
+	var cols []int
+	end := 0
+	for x, cell := range line {
+		if cell == nil || cell.char == "" {
+			continue
+		}
+		text.WriteString(cell.char)
+		for i := 0; i < len(cell.char); i++ {
+			cols = append(cols, x)
+		}
+		if cell.char != " " {
+			end = x + 1
+		}
+	}
+	var literals []*colorLiteral
+	for _, loc := range colorLiteralRegexp.FindAllStringIndex(text.String(), -1) {
+		literal := text.String()[loc[0]:loc[1]]
+		color := parseColorLiteral(literal)
+		if color == nil {
+			continue
+		}
+		literals = append(literals, &colorLiteral{
+			col:     cols[loc[0]],
+			literal: literal,
+			color:   color,
+		})
+	}
+
+	return literals, end
+}
+
+func parseColorLiteral(literal string) *RGBA {
+	if strings.HasPrefix(literal, "#") {
+		return hexToRGBA(literal)
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(literal, "rgb("), ")"), ",")
+	if len(parts) != 3 {
+		return nil
+	}
+	var rgb [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v > 255 {
+			return nil
+		}
+		rgb[i] = v
+	}
+
+	return newRGBA(rgb[0], rgb[1], rgb[2], 1)
+}
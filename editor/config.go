@@ -3,6 +3,7 @@ package editor
 import (
 	"fmt"
 	"path/filepath"
+	"runtime"
 
 	"github.com/BurntSushi/toml"
 )
@@ -19,11 +20,84 @@ import (
 // clipboard = true
 // cursorBlink = true
 // indentGuide = true
+// # The character of the indent guides, a thin line if empty
+// indentGuideChar = "│"
+// # Highlight the guide of the treesitter scope around the cursor, updated when the cursor rests
+// indentGuideScope = true
+// # Draw the fold column of nvim ('foldcolumn') as chevrons, toggle the folds by a click on them,
+// # and preview the closed folds on hover
+// foldColumn = true
 // cachedDrawing = false
 // disableIMEinNormal = true
 // startFullScreen = true
+// # Restore the window size, position and state of the previous session
+// restoreWindowGeometry = true
+// # Draw the titlebar and the frame of the window by goneovim on macOS and Windows;
+// # false uses the decorations of the OS, e.g. for the tiling window managers.
+// # The decorations of the OS are always used on linux
+// borderlessWindow = true
+// # The buttons of the titlebar of the borderless window: "minimize", "maximize", "close",
+// # "workspace" (a new workspace), "settings" (this file), "theme" (dark / light),
+// # and their side, "left" / "right" ("": left on macOS). The titlebar of the borderless
+// # window is left as it is if no buttons are set, e.g.
+// # titlebarButtons = ["minimize", "maximize", "close"]
+// titlebarButtons = []
+// titlebarButtonsSide = ""
+// # Snap the borderless window to the halves and the quarters of the monitor when it is
+// # dragged by the titlebar to the edges and the corners, and maximize it at the top edge
+// # (false by default on macOS)
+// edgeSnap = true
+// # Snap it also by Ctrl+Alt+arrows (halves), Ctrl+Alt+U/I/J/K (quarters), Ctrl+Alt+Enter
+// # (maximize) and Ctrl+Alt+Backspace (restore), or :GonvimSnap {area}
+// snapKeys = false
+// # {filename}, {filepath}, {modified}, {cwd}, {workspace} are replaced
+// titleFormat = "{filename}{modified} - {cwd}"
+// # Reduce animations and redraw rate, and pause minimap on battery power
+// # "auto" / "on" / "off"
+// powerSaving = "auto"
+// powerSavingFps = 30
 // transparent = 0.5
+// # Check GitHub for a newer release at startup
+// checkUpdate = false
+// # Raise the contrast of the derived GUI text colors to this WCAG ratio, e.g. 4.5 (AA), 7 (AAA)
+// minimumContrast = 4.5
+// # Blur the desktop behind the transparent window, 0 to disable.
+// # The radius is used on macOS; Windows (acrylic) and KDE decide it by themselves
+// blurRadius = 20
 // desktopNotifications = true
+// # Show notifications in the OS notification center
+// nativeNotifications = true
+// # Do not show notifications in the window as well
+// nativeNotificationsOnly = false
+// # Font size multiplier in :GonvimPresentation
+// presentationFontScale = 1.5
+// # Zoom the font of the workspace by Ctrl+= / Ctrl+- / Ctrl+0 and Ctrl+wheel (Cmd on macOS)
+// zoomKeys = true
+// # Animate the zoom around the cursor, or the pointer for Ctrl+wheel; turn it off for
+// # the lowest latency
+// smoothZoom = true
+// # :GonvimZen centers the text in a column of this width, dims the background around it by
+// # zenDim (0.0 - 1.0), and hides the tabline, the statusline, the sidebar and the minimap
+// zenWidth = 100
+// zenDim = 0.3
+// # Turn on the typewriter scrolling (see typewriter) in :GonvimZen
+// zenTypewriter = false
+// # Toggle :GonvimZen by Ctrl+Shift+Z (Cmd on macOS), which is not sent to nvim then
+// zenKey = false
+// # Keep the cursor line at the center of the window in the new workspaces;
+// # toggled in a workspace by :GonvimTypewriter
+// typewriter = false
+// # Scroll to the center by a line per frame
+// typewriterAnimation = true
+// # Show the progress of the language servers (LspProgress) as notifications
+// lspProgress = true
+// # Show the debug panel for nvim-dap, and draw its signs as shapes
+// dapPanel = true
+// # Show the git blame of the line on CursorHold, as well as by :GonvimGitBlame
+// gitBlameOnHover = false
+// # Set 'cmdheight' to 0, and show the messages, 'showmode', 'showcmd' and 'ruler' in the GUI.
+// # extMessages and extCmdline are turned on
+// cmdheightZero = false
 // // -- diffpattern enum --
 // // SolidPattern             1
 // // Dense1Pattern            2
@@ -46,7 +120,109 @@ import (
 // diffdeletepattern = 12
 // diffchangepattern = 12
 // diffaddpattern = 1
+// # In diff mode, connect the changed regions of the windows side by side
+// diffConnectors = true
+// # In diff mode, show the changes of the whole buffer in a gutter beside the scrollbar
+// diffGutter = false
+// # Show the buttons to resolve the merge conflicts on their markers
+// conflictButtons = true
+// # Show a swatch beside the #rrggbb / #rgb / rgb() literals, click it to pick a color
+// colorSwatches = true
+// # Draw the window separators as lines of the windowSeparator color instead of the grid of nvim.
+// # Always on if transparent < 1.0
+// drawBorder = false
+// # Resize the splits by dragging the window separators with the mouse
+// separatorDrag = true
+// # Show the start screen with the recent files and workspaces in an empty workspace (:GonvimDashboard)
+// dashboard = true
+// # The shell of the terminal below the editor (:GonvimTerminal), $SHELL if empty.
+// # It runs in a pty apart from nvim, and follows the cwd of the workspace
+// terminalShell = "/bin/zsh"
+// terminalScrollback = 10000
+// # Show the quickfix list in a panel grouped by file after :make, :grep, :vimgrep etc.,
+// # as well as by :GonvimQuickfix
+// quickfixPanel = false
+// # Show the path of the buffer and the symbols around the cursor above the editor;
+// # the symbols of the language server, or of treesitter without it
+// breadcrumbs = false
+// # Show the search pattern and the number of its matches above the statusline
+// # while 'hlsearch' highlights them
+// searchBar = false
+// # Highlight the line and the column of the cursor by the GUI over the background,
+// # with the background of CursorLine; it is seen on the transparent window as well.
+// # Turn off 'cursorline' / 'cursorcolumn' of nvim to use only these
+// cursorLineOverlay = false
+// cursorColumnOverlay = false
+// # The opacity of the overlay color (0.0 - 1.0)
+// cursorLineBlend = 0.15
+// # Fade the cursorline out to the right
+// cursorLineGradient = false
+// # Draw the cursor on a layer above all windows, so that it moves and blinks without
+// # repainting the windows under it
+// cursorLayer = false
+// # Show 'showcmd' (the count and the pending operator) next to the cursor instead of
+// # at the bottom right of the screen; requires extMessages
+// showcmdNearCursor = false
+// # Restore the cursor and the scroll position of the buffers when they are opened again,
+// # also after a restart, and of the windows when the workspace is shown again
+// restoreViewport = true
+// # The fonts of the windows of the filetypes, e.g. { markdown = "Noto Sans:h16" };
+// # also set by require("gonvim").font.set_filetype(filetype, font)
+// filetypeFonts = {}
+// # Open the find bar (:GonvimFind) by Ctrl+F and with the replacement (:GonvimReplace)
+// # by Ctrl+H (Cmd on macOS), instead of sending them to nvim
+// findKeys = false
+// # Open the dialog of :GonvimGotoLine by Ctrl+G (Cmd on macOS)
+// gotoLineKey = false
+// # Show a badge on the workspaces not shown when there is output in a terminal, a job
+// # or :make has finished, or new errors are diagnosed
+// activityBadges = true
+// # Mark the rows continuing the wrapped lines in the gutter, and count a wrapped line
+// # once in the scrollbar and the minimap
+// wrapIndicators = true
+// # Color the brackets by their depth in the treesitter tree, in the colors derived from
+// # the palette
+// rainbowDelimiters = false
+// # Outline the pair of the brackets around the cursor and draw a guide between their rows
+// pairGuides = false
+// # Underline the spell errors with dots instead of the undercurl of nvim
+// spellDots = true
+// # Show the suggestions of a spell error by a right click on it, or by :GonvimSpellSuggest
+// spellSuggestions = true
 // SkipGlobalId = true
+// # Show/Hide the window from anywhere, e.g. "Ctrl+Alt+Space" (X11, not Wayland, on Linux)
+// globalHotkey = "Ctrl+Alt+Space"
+// # Slide the window down from the top of the primary monitor
+// quakeMode = true
+// quakeHeightRatio = 0.4
+// # Switch the base palette with the OS dark/light appearance
+// followSystemAppearance = true
+// darkForeground = "#b4b9be"
+// darkBackground = "#090d11"
+// lightForeground = "#383a42"
+// lightBackground = "#fafafa"
+// # Also issue `:set background=` and `:colorscheme` to nvim
+// syncBackground = true
+// darkColorscheme = "gruvbox"
+// lightColorscheme = "morning"
+// # Load the GUI colors from ~/.goneovim/themes/<theme>.toml
+// # "high-contrast" is built in
+// theme = "nord"
+// # Rebuild the GUI colors from the highlight groups of the colorscheme when it is changed
+// syncPalette = true
+//
+// [notification]
+// # Do not pop up the notifications below this level: "info" / "warn"
+// # They are still kept in the history (:GonvimNotifications)
+// level = "info"
+// # Do not pop up any notification except the ones asking for an action
+// doNotDisturb = false
+// # Show vim.notify() messages as notifications instead of in the message area
+// vimNotify = false
+// # "bottom-right" / "top-right" / "bottom-left" / "top-left"
+// position = "bottom-right"
+// # The older ones are folded into "+N more"
+// maxVisible = 5
 //
 // [palette]
 // AreaRatio = 0.8
@@ -63,7 +239,7 @@ import (
 // visualModeColor = "#123456"
 // termnalModeColor = "#123456"
 // left = [ "mode", "filepath", "filename" ]
-// right = [ "message", "git", "filetype", "fileformat", "fileencoding", "curpos", "lint" ]
+// right = [ "message", "git", "filetype", "fileformat", "fileencoding", "curpos", "lint", "power" ]
 //
 // [tabline]
 // visible = true
@@ -101,24 +277,98 @@ import (
 //
 // # restore the previous sessions if there are exists.
 // restoreSession = false
+// # Show the workspaces as tabs in the titlebar, instead of the list of the sidebar
+// tabs = false
+//
+// [input]
+// # The lines to scroll by a step of a mouse wheel
+// wheelScrollLines = 2
+// # Scroll horizontally by the wheel and the trackpad
+// horizontalScroll = true
+// # Invert the direction of the scroll
+// invertScroll = false
+// # How to scroll by the wheel events
+// #   auto: smooth by the pixels for the trackpads, by the steps for the mouse wheels
+// #   smooth: always by the pixels
+// #   step: always by the steps of wheelScrollLines
+// wheelMode = "auto"
 //
 // [dein]
 // tomlFile
+//
+// [icons]
+// # Draw the icons by the glyphs of a Nerd Font
+// nerdFont = true
+// # The font of the glyphs, the font of the GUI by default
+// fontFamily = "Hack Nerd Font"
+// # SVG files in ~/.goneovim/icons override or add the icons of the same name,
+// # e.g. go.svg, cross.svg. "currentColor" in them is replaced with the icon color.
+// # Add or replace the glyphs by the icon name or the file extension
+// [icons.glyphs]
+// zig = "\ue6a9"
+// lsp_snippet = "\ueb66"
+//
+// [colors]
+// # Override the GUI colors, on top of the theme and the derived colors.
+// # The keys are the same as the ones of the theme files
+// sideBarBg = "#1e2127"
+// widgetBg = "#21252b"
+// scrollBarFg = "#3e4451"
+// indentGuide = "#2c313a"
+// windowSeparator = "#181a1f"
+//
+// [env]
+// # The environment variables of the nvim of the workspaces, of its terminals
+// # and jobs, and of the tasks. .gonvim.toml in the root of a project adds or
+// # overrides them by the same [env] table while the cwd of the workspace is the
+// # project. $NAME in the values is expanded by the environment of goneovim.
+// # .gonvim.toml is read by vim.secure.read() of nvim 0.9 or later, which asks
+// # whether to trust the file as 'exrc' does, and it is not read with --clean.
+// PATH = "$HOME/sdk/go1.17/bin:$PATH"
+// GOFLAGS = "-mod=vendor"
+//
+// [tasks]
+// # The shell commands run in the cwd of the workspace by :GonvimTask [name].
+// # .gonvim/tasks.toml of the project adds or overrides them by the same [tasks] table,
+// # if the user trusts it as .gonvim.toml above; it is not read with --clean.
+// # Double click a file:line in the output to jump to it
+// build = "go build ./..."
+// test = "go test ./..."
+//
+// [virtualText.diagnostic]
+// # Style the virtual text of nvim by its source, which is the prefix of the highlight groups of the text
+// highlight = "DiagnosticVirtualText"
+// # Put the text at the right edge of the window
+// align = "right"
+// # Draw the text on a pill with rounded corners
+// pill = true
+// # Show "…" at the end of the text cut by the window
+// ellipsis = true
+// [virtualText.inlayHint]
+// highlight = "LspInlayHint"
+// pill = true
 type gonvimConfig struct {
-	Editor      editorConfig
-	Palette     paletteConfig
-	Message     messageConfig
-	Statusline  statusLineConfig
-	Tabline     tabLineConfig
-	Lint        lintConfig
-	Popupmenu   popupMenuConfig
-	ScrollBar   scrollBarConfig
-	ActivityBar activityBarConfig
-	MiniMap     miniMapConfig
-	SideBar     sideBarConfig
-	Workspace   workspaceConfig
-	FileExplore fileExploreConfig
-	Dein        deinConfig
+	Editor       editorConfig
+	Palette      paletteConfig
+	Message      messageConfig
+	Notification notificationConfig
+	Statusline   statusLineConfig
+	Tabline      tabLineConfig
+	Lint         lintConfig
+	Popupmenu    popupMenuConfig
+	ScrollBar    scrollBarConfig
+	ActivityBar  activityBarConfig
+	MiniMap      miniMapConfig
+	SideBar      sideBarConfig
+	Workspace    workspaceConfig
+	Input        inputConfig
+	FileExplore  fileExploreConfig
+	Dein         deinConfig
+	Icons        iconsConfig
+	Colors       map[string]string
+	Tasks        map[string]string
+	Env          map[string]string
+	VirtualText  map[string]virtualTextConfig
 }
 
 type editorConfig struct {
@@ -145,10 +395,114 @@ type editorConfig struct {
 	DrawBorder           bool
 	SkipGlobalId         bool
 	IndentGuide          bool
+	IndentGuideChar      string
+	IndentGuideScope     bool
+	FoldColumn           bool
 	DesktopNotifications bool
 	DiffAddPattern       int
 	DiffDeletePattern    int
 	DiffChangePattern    int
+	GlobalHotkey         string
+	QuakeMode            bool
+	QuakeHeightRatio     float64
+
+	FollowSystemAppearance bool
+	DarkForeground         string
+	DarkBackground         string
+	LightForeground        string
+	LightBackground        string
+	SyncBackground         bool
+	DarkColorscheme        string
+	LightColorscheme       string
+
+	NativeNotifications     bool
+	NativeNotificationsOnly bool
+
+	PresentationFontScale float64
+
+	RestoreWindowGeometry bool
+	TitleFormat           string
+	BorderlessWindow      bool
+	TitlebarButtons       []string
+	TitlebarButtonsSide   string
+	EdgeSnap              bool
+	SnapKeys              bool
+
+	PowerSaving    string
+	PowerSavingFps int
+
+	LspProgress bool
+
+	Theme       string
+	SyncPalette bool
+
+	BlurRadius int
+
+	MinimumContrast float64
+
+	CheckUpdate bool
+
+	ZoomKeys   bool
+	SmoothZoom bool
+
+	ZenWidth      int
+	ZenDim        float64
+	ZenTypewriter bool
+	ZenKey        bool
+
+	Typewriter          bool
+	TypewriterAnimation bool
+
+	DapPanel bool
+
+	GitBlameOnHover bool
+
+	DiffConnectors bool
+	DiffGutter     bool
+
+	ConflictButtons bool
+
+	ColorSwatches bool
+
+	// Implies ExtMessages and ExtCmdline
+	CmdheightZero bool
+
+	SeparatorDrag bool
+
+	Dashboard bool
+
+	TerminalShell      string
+	TerminalScrollback int
+
+	QuickfixPanel bool
+	Breadcrumbs   bool
+	SearchBar     bool
+
+	CursorLineOverlay   bool
+	CursorColumnOverlay bool
+	CursorLineBlend     float64
+	CursorLineGradient  bool
+	CursorLayer         bool
+
+	// Requires ExtMessages
+	ShowcmdNearCursor bool
+
+	RestoreViewport bool
+
+	WrapIndicators bool
+
+	RainbowDelimiters bool
+	PairGuides        bool
+
+	ActivityBadges bool
+
+	FindKeys    bool
+	GotoLineKey bool
+
+	FiletypeFonts map[string]string
+
+	SpellDots        bool
+	SpellSuggestions bool
 }
 
 type paletteConfig struct {
@@ -161,6 +515,14 @@ type messageConfig struct {
 	Transparent float64
 }
 
+type notificationConfig struct {
+	Level        string
+	DoNotDisturb bool
+	VimNotify    bool
+	Position     string
+	MaxVisible   int
+}
+
 type statusLineConfig struct {
 	Visible           bool
 	ModeIndicatorType string
@@ -213,6 +575,14 @@ type sideBarConfig struct {
 type workspaceConfig struct {
 	RestoreSession bool
 	PathStyle      string
+	Tabs           bool
+}
+
+type inputConfig struct {
+	WheelScrollLines int
+	HorizontalScroll bool
+	InvertScroll     bool
+	WheelMode        string
 }
 
 type fileExploreConfig struct {
@@ -220,19 +590,35 @@ type fileExploreConfig struct {
 	MaxDisplayItems int
 }
 
+type iconsConfig struct {
+	NerdFont   bool
+	FontFamily string
+	Glyphs     map[string]string
+}
+
+type virtualTextConfig struct {
+	Highlight string
+	Align     string
+	Pill      bool
+	Ellipsis  bool
+}
+
 type deinConfig struct {
 	TomlFile string
 }
 
-func newGonvimConfig(home string) gonvimConfig {
+// newGonvimConfig reads setting.toml over the defaults, unless clean (--clean)
+func newGonvimConfig(home string, clean bool) gonvimConfig {
 	var config gonvimConfig
 
 	config.init()
 
 	// Read toml
-	_, err := toml.DecodeFile(filepath.Join(home, ".goneovim", "setting.toml"), &config)
-	if err != nil {
-		fmt.Println(err)
+	if !clean {
+		_, err := toml.DecodeFile(filepath.Join(home, ".goneovim", "setting.toml"), &config)
+		if err != nil {
+			fmt.Println(err)
+		}
 	}
 
 	if config.Editor.Transparent < 1.0 {
@@ -249,6 +635,11 @@ func newGonvimConfig(home string) gonvimConfig {
 		config.Editor.DiffChangePattern = 1
 	}
 
+	if config.Editor.CmdheightZero {
+		config.Editor.ExtMessages = true
+		config.Editor.ExtCmdline = true
+	}
+
 	if config.Editor.Width <= 400 {
 		config.Editor.Width = 400
 	}
@@ -258,6 +649,27 @@ func newGonvimConfig(home string) gonvimConfig {
 	if config.Editor.Transparent <= 0.1 {
 		config.Editor.Transparent = 1.0
 	}
+	if config.Editor.QuakeHeightRatio <= 0.1 || config.Editor.QuakeHeightRatio > 1.0 {
+		config.Editor.QuakeHeightRatio = 0.4
+	}
+	if config.Editor.PowerSavingFps <= 0 {
+		config.Editor.PowerSavingFps = 30
+	}
+	if config.Editor.CursorLineBlend <= 0 || config.Editor.CursorLineBlend > 1.0 {
+		config.Editor.CursorLineBlend = 0.15
+	}
+	if config.Editor.TerminalScrollback <= 0 {
+		config.Editor.TerminalScrollback = 10000
+	}
+	if config.Editor.PresentationFontScale < 1.0 {
+		config.Editor.PresentationFontScale = 1.5
+	}
+	if config.Editor.ZenWidth <= 0 {
+		config.Editor.ZenWidth = 100
+	}
+	if config.Editor.ZenDim < 0 || config.Editor.ZenDim > 1 {
+		config.Editor.ZenDim = 0.3
+	}
 	if config.Statusline.ModeIndicatorType == "" {
 		config.Statusline.ModeIndicatorType = "textLabel"
 	}
@@ -300,6 +712,15 @@ func newGonvimConfig(home string) gonvimConfig {
 		config.Workspace.PathStyle = "minimum"
 	}
 
+	if config.Input.WheelScrollLines < 1 {
+		config.Input.WheelScrollLines = 2
+	}
+	switch config.Input.WheelMode {
+	case "auto", "smooth", "step":
+	default:
+		config.Input.WheelMode = "auto"
+	}
+
 	return config
 }
 
@@ -321,11 +742,57 @@ func (c *gonvimConfig) init() {
 
 	// Indent guide
 	c.Editor.IndentGuide = true
+	c.Editor.IndentGuideScope = true
+	c.Editor.FoldColumn = true
 
 	// replace diff color drawing pattern
 	c.Editor.DiffAddPattern = 12
 	c.Editor.DiffDeletePattern = 12
 
+	// quake mode
+	c.Editor.QuakeHeightRatio = 0.4
+
+	// system appearance
+	c.Editor.DarkForeground = "#b4b9be"
+	c.Editor.DarkBackground = "#090d11"
+	c.Editor.LightForeground = "#383a42"
+	c.Editor.LightBackground = "#fafafa"
+	c.Editor.SyncBackground = true
+
+	c.Editor.PresentationFontScale = 1.5
+
+	c.Editor.RestoreWindowGeometry = true
+	c.Editor.BorderlessWindow = true
+	// macOS has the snapping of its own, which the drags of the window would conflict with
+	c.Editor.EdgeSnap = runtime.GOOS != "darwin"
+
+	c.Editor.PowerSaving = "auto"
+	c.Editor.PowerSavingFps = 30
+
+	c.Editor.LspProgress = true
+
+	c.Editor.SyncPalette = true
+
+	c.Editor.BlurRadius = 20
+	c.Editor.ZoomKeys = true
+	c.Editor.SmoothZoom = true
+	c.Editor.ZenWidth = 100
+	c.Editor.ZenDim = 0.3
+	c.Editor.TypewriterAnimation = true
+	c.Editor.DapPanel = true
+	c.Editor.DiffConnectors = true
+	c.Editor.ConflictButtons = true
+	c.Editor.ColorSwatches = true
+	c.Editor.SeparatorDrag = true
+	c.Editor.Dashboard = true
+	c.Editor.TerminalScrollback = 10000
+	c.Editor.CursorLineBlend = 0.15
+	c.Editor.RestoreViewport = true
+	c.Editor.WrapIndicators = true
+	c.Editor.ActivityBadges = true
+	c.Editor.SpellDots = true
+	c.Editor.SpellSuggestions = true
+
 	// palette size
 	c.Palette.AreaRatio = 0.5
 	c.Palette.MaxNumberOfResultItems = 30
@@ -333,6 +800,10 @@ func (c *gonvimConfig) init() {
 
 	c.Message.Transparent = 1.0
 
+	c.Notification.Level = "info"
+	c.Notification.Position = "bottom-right"
+	c.Notification.MaxVisible = 5
+
 	c.Statusline.Visible = false
 	c.Statusline.ModeIndicatorType = "textLabel"
 	c.Statusline.Left = []string{"mode", "filename"}
@@ -358,4 +829,8 @@ func (c *gonvimConfig) init() {
 	c.FileExplore.MaxDisplayItems = 30
 
 	c.Workspace.PathStyle = "minimum"
+
+	c.Input.WheelScrollLines = 2
+	c.Input.HorizontalScroll = true
+	c.Input.WheelMode = "auto"
 }
@@ -0,0 +1,142 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// conflictStartMarker starts a conflict block of git
+const conflictStartMarker = "<<<<<<<"
+
+// Conflicts overlays the buttons to resolve the merge conflicts on the start
+// markers shown in the grids; require("gonvim").resolve_conflict edits the buffer
+type Conflicts struct {
+	ws   *Workspace
+	bars []*conflictBar
+}
+
+type conflictBar struct {
+	widget *widgets.QWidget
+	win    nvim.Window
+	row    int
+}
+
+// The buttons, and the choice passed to resolve_conflict
+var conflictChoices = []struct {
+	text   string
+	choice string
+}{
+	{"Accept Current", "current"},
+	{"Accept Incoming", "incoming"},
+	{"Accept Both", "both"},
+}
+
+func newConflicts(ws *Workspace) *Conflicts {
+	return &Conflicts{
+		ws: ws,
+	}
+}
+
+func (c *Conflicts) newBar() *conflictBar {
+	bar := &conflictBar{
+		widget: widgets.NewQWidget(c.ws.screen.widget, 0),
+	}
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(8)
+	bar.widget.SetLayout(layout)
+	for _, ch := range conflictChoices {
+		choice := ch.choice
+		button := widgets.NewQPushButton2(ch.text, nil)
+		button.SetFlat(true)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.SetCursor(gui.NewQCursor2(core.Qt__PointingHandCursor))
+		button.ConnectClicked(func(bool) {
+			bar.widget.Hide()
+			go c.ws.execLua("require('gonvim').resolve_conflict(...)", bar.win, bar.row, choice)
+		})
+		layout.AddWidget(button, 0, 0)
+	}
+	c.setColor(bar)
+
+	return bar
+}
+
+func (c *Conflicts) setColor(bar *conflictBar) {
+	font := c.ws.font
+	bar.widget.SetStyleSheet(fmt.Sprintf(
+		" QPushButton { color: %s; background: transparent; border: 0px; padding: 0px; font-family: %s; font-size: %dpt; } QPushButton:hover { text-decoration: underline; }",
+		editor.colors.comment.String(),
+		font.fontNew.Family(),
+		int(font.fontNew.PointSizeF()*0.9),
+	))
+}
+
+// update is called on flush, it finds the start markers in the shown grids
+func (c *Conflicts) update() {
+	if !editor.config.Editor.ConflictButtons {
+		return
+	}
+	n := 0
+	c.ws.screen.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil || win.grid == 1 || win.isMsgGrid || win.isFloatWin || !win.isShown() {
+			return true
+		}
+		font := win.getFont()
+		for y, line := range win.content {
+			end, ok := conflictMarkerEnd(line)
+			if !ok {
+				continue
+			}
+			if n >= len(c.bars) {
+				c.bars = append(c.bars, c.newBar())
+			}
+			bar := c.bars[n]
+			n++
+			bar.win = win.id
+			bar.row = y
+			bar.widget.AdjustSize()
+			x := int(float64(win.pos[0]+end+2) * font.truewidth)
+			top := (win.pos[1] + y) * font.lineHeight
+			bar.widget.Move2(x, top+(font.lineHeight-bar.widget.Height())/2)
+			bar.widget.Show()
+			bar.widget.Raise()
+		}
+		return true
+	})
+	for i := n; i < len(c.bars); i++ {
+		c.bars[i].widget.Hide()
+	}
+}
+
+// conflictMarkerEnd reports whether the row starts with the start marker after
+// the sign and number columns, and the column after its text
+func conflictMarkerEnd(line []*Cell) (int, bool) {
+	start := -1
+	end := 0
+	text := ""
+	for x, cell := range line {
+		if cell == nil {
+			continue
+		}
+		if start < 0 {
+			if cell.isSignColumn() {
+				continue
+			}
+			start = x
+		}
+		if len(text) < len(conflictStartMarker) {
+			text += cell.char
+		}
+		if cell.char != " " && cell.char != "" {
+			end = x + 1
+		}
+	}
+
+	return end, start >= 0 && text == conflictStartMarker
+}
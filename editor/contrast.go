@@ -0,0 +1,24 @@
+package editor
+
+// enforceContrast raises the contrast of the derived text colors against their backgrounds
+// to Editor.MinimumContrast. The colors set by the theme and [colors] are left as they are.
+func (c *ColorPalette) enforceContrast() {
+	ratio := c.e.config.Editor.MinimumContrast
+	if ratio <= 1 {
+		return
+	}
+	c.inactiveFg = ensureContrast(c.inactiveFg, c.bg, ratio)
+	c.comment = ensureContrast(c.comment, c.bg, ratio)
+	c.matchFg = ensureContrast(c.matchFg, c.bg, ratio)
+	c.sideBarFg = ensureContrast(c.sideBarFg, c.sideBarBg, ratio)
+	c.sideBarFg = ensureContrast(c.sideBarFg, c.sideBarSelectedItemBg, ratio)
+	c.widgetFg = ensureContrast(c.widgetFg, c.widgetBg, ratio)
+	c.widgetFg = ensureContrast(c.widgetFg, c.widgetInputArea, ratio)
+	// The non-text elements only need to be distinguishable, 3:1 in WCAG
+	nonText := ratio
+	if nonText > 3 {
+		nonText = 3
+	}
+	c.scrollBarFg = ensureContrast(c.scrollBarFg, c.scrollBarBg, nonText)
+	c.windowSeparator = ensureContrast(c.windowSeparator, c.bg, nonText)
+}
@@ -0,0 +1,156 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (e *Editor) crashDir() string {
+	crash := filepath.Join(e.homeDir, ".goneovim", "crash")
+	if e.opts.Window > 0 {
+		crash = filepath.Join(crash, fmt.Sprintf("window%d", e.opts.Window))
+	}
+	return crash
+}
+
+// handleCrash writes the crash report and saves the sessions of the workspaces on a panic,
+// which are offered to be restored at the next launch. It is deferred at the top of the
+// goroutines; the panics of the Qt callbacks reach the one of InitEditor.
+func (e *Editor) handleCrash() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, stack)
+
+	dir := e.crashDir()
+	if err := os.MkdirAll(dir, 0755); err == nil {
+		report := filepath.Join(dir, "crash-"+time.Now().Format("20060102-150405")+".log")
+		ioutil.WriteFile(report, []byte(e.crashReport(r, stack)), 0644)
+		e.saveEmergencySessions(dir, report)
+	}
+
+	os.Exit(2)
+}
+
+func (e *Editor) crashReport(r interface{}, stack []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "goneovim %s (%s/%s, %s)\n", e.version, runtime.GOOS, runtime.GOARCH, runtime.Version())
+	fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "panic: %v\n\n", r)
+
+	for i, ws := range e.workspaces {
+		if ws == nil || ws.capability == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "workspace %d: nvim api level %d\n", i+1, ws.capability.apiLevel)
+	}
+
+	c := e.config.Editor
+	fmt.Fprintf(&b, "config: extCmdline=%t extPopupmenu=%t extTabline=%t extMessages=%t ", c.ExtCmdline, c.ExtPopupmenu, c.ExtTabline, c.ExtMessages)
+	fmt.Fprintf(&b, "cachedDrawing=%t transparent=%.2f powerSaving=%s theme=%q ", c.CachedDrawing, c.Transparent, c.PowerSaving, c.Theme)
+	fmt.Fprintf(&b, "sideBar=%t miniMap=%t scrollBar=%t\n\n", e.config.SideBar.Visible, e.config.MiniMap.Visible, e.config.ScrollBar.Visible)
+
+	b.Write(stack)
+
+	return b.String()
+}
+
+// saveEmergencySessions saves the sessions of the workspaces. The nvim processes are
+// still alive since only the GUI has crashed.
+func (e *Editor) saveEmergencySessions(dir, report string) {
+	var lines []string
+	lines = append(lines, report)
+	for i, ws := range e.workspaces {
+		if ws == nil || ws.nvim == nil || ws.uiRemoteAttached {
+			continue
+		}
+		path := filepath.Join(dir, strconv.Itoa(i)+".vim")
+		done := make(chan error, 1)
+		go func(ws *Workspace) {
+			done <- ws.nvim.Command(fmt.Sprintf("execute 'mksession!' fnameescape('%s')", escapeVimString(path)))
+		}(ws)
+		select {
+		case err := <-done:
+			if err != nil {
+				continue
+			}
+		case <-time.After(2 * time.Second):
+			continue
+		}
+		lines = append(lines, path)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "sessions"), []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// offerCrashRecovery asks whether to restore the sessions saved by the crash of the last run
+func (e *Editor) offerCrashRecovery() {
+	dir := e.crashDir()
+	list := filepath.Join(dir, "sessions")
+	data, err := ioutil.ReadFile(list)
+	if err != nil {
+		return
+	}
+	os.Remove(list)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	report := lines[0]
+	sessions := lines[1:]
+	if len(sessions) == 0 {
+		e.pushNotification(NotifyWarn, 0, "[Gonvim] Goneovim crashed last time. The report is saved in "+report)
+		return
+	}
+
+	buttons := []*NotifyButton{}
+	buttons = append(buttons, &NotifyButton{
+		action: func() {
+			e.restoreCrashSessions(sessions)
+		},
+		text: "Restore previous session",
+	})
+	buttons = append(buttons, &NotifyButton{
+		action: func() {
+			for _, session := range sessions {
+				os.Remove(session)
+			}
+		},
+		text: "Discard",
+	})
+	e.pushNotification(NotifyWarn, 0, "[Gonvim] Goneovim crashed last time. The report is saved in "+report, notifyOptionArg(buttons))
+}
+
+// restoreCrashSessions sources the first session in the current workspace,
+// and opens the rest in new workspaces
+func (e *Editor) restoreCrashSessions(sessions []string) {
+	for i, session := range sessions {
+		if _, err := os.Stat(session); err != nil {
+			continue
+		}
+		if i == 0 {
+			ws := e.workspaces[e.active]
+			go func(path string) {
+				ws.nvim.Command(fmt.Sprintf("execute 'silent! source' fnameescape('%s')", escapeVimString(path)))
+				os.Remove(path)
+			}(session)
+			continue
+		}
+		if len(e.workspaces) >= WorkspaceLen {
+			break
+		}
+		editor.isSetGuiColor = false
+		ws, err := newWorkspace(session)
+		if err != nil {
+			continue
+		}
+		e.workspaces = append(e.workspaces, ws)
+		e.active = len(e.workspaces) - 1
+		e.workspaceUpdate()
+	}
+}
@@ -44,6 +44,11 @@ func initCursorNew() *Cursor {
 	widget := widgets.NewQWidget(nil, 0)
 	// widget := widgets.NewQLabel(nil, 0)
 	widget.SetContentsMargins(0, 0, 0, 0)
+	if editor.config.Editor.CursorLayer {
+		// The cursor fills all of its rect, so that its blinking does not
+		// repaint the window under it
+		widget.SetAttribute(core.Qt__WA_OpaquePaintEvent, true)
+	}
 	c := &Cursor{
 		widget:               widget,
 		timer:                core.NewQTimer(nil),
@@ -117,14 +122,51 @@ func (c *Cursor) setBlink() {
 }
 
 func (c *Cursor) move() {
+	x, y := c.x, c.y
+	if c.isLayered() {
+		// c.x and c.y are in the window
+		if win, ok := c.ws.screen.getWindow(c.gridid); ok && win.widget != nil {
+			x += win.widget.X()
+			y += win.widget.Y()
+		}
+	}
 	c.widget.Move(
 		core.NewQPoint2(
-			c.x,
-			c.y,
+			x,
+			y,
 		),
 	)
 
 	c.ws.loc.updatePos()
+	if c.ws.msgline != nil {
+		c.ws.msgline.updateCmdPos()
+	}
+}
+
+// setParentWindow puts the cursor in the window, or on the layer above all
+// windows of the screen if cursorLayer is set, where it is moved and repainted
+// without repainting the windows
+func (c *Cursor) setParentWindow(win *Window) {
+	parent := win.widget
+	if editor.config.Editor.CursorLayer {
+		parent = c.ws.screen.widget
+	}
+	if current := c.widget.ParentWidget(); current != nil && current.Pointer() == parent.Pointer() {
+		c.widget.Raise()
+		return
+	}
+	c.widget.SetParent(parent)
+	c.widget.Hide()
+	c.widget.Show()
+}
+
+// isLayered reports whether the cursor is on the layer above the windows
+func (c *Cursor) isLayered() bool {
+	if !editor.config.Editor.CursorLayer {
+		return false
+	}
+	parent := c.widget.ParentWidget()
+	return parent != nil && parent.Pointer() == c.ws.screen.widget.Pointer()
 }
 
 func (c *Cursor) updateFont(font *Font) {
@@ -273,5 +315,9 @@ func (c *Cursor) update() {
 	y := row*font.lineHeight + c.shift
 	c.x = x
 	c.y = y
+	if c.isLayered() {
+		// A float window may have been raised over the layer
+		c.widget.Raise()
+	}
 	c.move()
 }
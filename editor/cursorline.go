@@ -0,0 +1,84 @@
+package editor
+
+import (
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// CursorLine is the row and the column of the cursor highlighted by the GUI,
+// over the background of the cells, so that it is seen on the transparent
+// background as well as with 'cursorline' off
+type CursorLine struct {
+	grid int
+	row  int
+	col  int
+}
+
+// cursorLineColor is the background of CursorLine of the colorscheme, or the selection color
+func (s *Screen) cursorLineColor() *RGBA {
+	if id, ok := s.highlightGroup["CursorLine"]; ok {
+		if hl, ok := s.highAttrDef[id]; ok && hl != nil && hl.background != nil && !hl.background.equals(editor.colors.bg) {
+			return hl.background
+		}
+	}
+	return editor.colors.selectedBg
+}
+
+// updateCursorLine repaints the rows (and the columns) of the previous and the new cursor position
+func (s *Screen) updateCursorLine(grid, row, col int) {
+	c := &s.cursorLine
+	if c.grid == grid && c.row == row && c.col == col {
+		return
+	}
+	prev := *c
+	c.grid, c.row, c.col = grid, row, col
+	for _, pos := range []CursorLine{prev, *c} {
+		win, ok := s.getWindow(pos.grid)
+		if !ok || win == nil || win.widget == nil {
+			continue
+		}
+		if editor.config.Editor.CursorColumnOverlay {
+			win.widget.Update()
+			continue
+		}
+		font := win.getFont()
+		win.widget.Update2(0, pos.row*font.lineHeight, win.widget.Width(), font.lineHeight)
+	}
+}
+
+// drawCursorLine blends the color of the cursorline over the row, and of the
+// cursorcolumn over the cell of the column of the cursor
+func (w *Window) drawCursorLine(p *gui.QPainter, y int) {
+	c := w.s.cursorLine
+	if c.grid != w.grid || w.isMsgGrid || w.isFloatWin || !w.isShown() {
+		return
+	}
+	font := w.getFont()
+	blend := editor.config.Editor.CursorLineBlend
+	color := w.s.cursorLineColor()
+	top := float64(y * font.lineHeight)
+	lineHeight := float64(font.lineHeight)
+
+	if editor.config.Editor.CursorLineOverlay && y == c.row {
+		width := float64(w.widget.Width())
+		if editor.config.Editor.CursorLineGradient {
+			gradient := gui.NewQLinearGradient3(0, 0, width, 0)
+			start := color.QColor()
+			start.SetAlphaF(blend)
+			end := color.QColor()
+			end.SetAlphaF(blend / 4)
+			gradient.SetColorAt(0, start)
+			gradient.SetColorAt(1, end)
+			p.FillRect(core.NewQRectF4(0, top, width, lineHeight), gui.NewQBrush10(gradient))
+		} else {
+			qcolor := color.QColor()
+			qcolor.SetAlphaF(blend)
+			p.FillRect4(core.NewQRectF4(0, top, width, lineHeight), qcolor)
+		}
+	}
+	if editor.config.Editor.CursorColumnOverlay && y != c.row {
+		qcolor := color.QColor()
+		qcolor.SetAlphaF(blend)
+		p.FillRect4(core.NewQRectF4(float64(c.col)*font.truewidth, top, font.truewidth, lineHeight), qcolor)
+	}
+}
@@ -0,0 +1,344 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// DebugPanel shows the state of the nvim-dap session of the workspace, which
+// require("gonvim").setup_dap() sends by gonvim_dap
+type DebugPanel struct {
+	ws        *Workspace
+	widget    *widgets.QWidget
+	status    *widgets.QLabel
+	variables *widgets.QTreeWidget
+	watches   *widgets.QTreeWidgetItem
+	stack     *widgets.QListWidget
+
+	// The tree items by the variablesReference of DAP
+	refs   map[int]*widgets.QTreeWidgetItem
+	frames []int
+}
+
+// The toolbar buttons, and the functions of nvim-dap they call
+var debugActions = []struct {
+	text   string
+	action string
+}{
+	{"Continue", "continue"},
+	{"Step Over", "step_over"},
+	{"Step Into", "step_into"},
+	{"Step Out", "step_out"},
+	{"Stop", "terminate"},
+}
+
+func newDebugPanel(ws *Workspace) *DebugPanel {
+	d := &DebugPanel{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		refs:   make(map[int]*widgets.QTreeWidgetItem),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 4, 6, 4)
+	layout.SetSpacing(4)
+	d.widget.SetLayout(layout)
+
+	toolbar := widgets.NewQHBoxLayout()
+	toolbar.SetSpacing(4)
+	d.status = widgets.NewQLabel(nil, 0)
+	toolbar.AddWidget(d.status, 1, 0)
+	for _, a := range debugActions {
+		action := a.action
+		button := widgets.NewQPushButton2(a.text, nil)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.ConnectClicked(func(bool) {
+			d.call("action", action)
+		})
+		toolbar.AddWidget(button, 0, 0)
+	}
+	layout.AddLayout(toolbar, 0)
+
+	splitter := widgets.NewQSplitter2(core.Qt__Horizontal, nil)
+	d.variables = widgets.NewQTreeWidget(nil)
+	d.variables.SetColumnCount(3)
+	d.variables.SetHeaderLabels([]string{"Name", "Value", "Type"})
+	d.variables.SetFocusPolicy(core.Qt__NoFocus)
+	d.variables.ConnectItemExpanded(d.expand)
+	d.stack = widgets.NewQListWidget(nil)
+	d.stack.SetFocusPolicy(core.Qt__NoFocus)
+	d.stack.ConnectItemClicked(func(item *widgets.QListWidgetItem) {
+		row := d.stack.Row(item)
+		if row >= 0 && row < len(d.frames) {
+			d.call("select_frame", d.frames[row])
+		}
+	})
+	splitter.AddWidget(d.variables)
+	splitter.AddWidget(d.stack)
+	splitter.SetStretchFactor(0, 2)
+	splitter.SetStretchFactor(1, 1)
+	layout.AddWidget(splitter, 1, 0)
+
+	d.widget.SetFixedHeight(editor.height / 3)
+	d.widget.Hide()
+
+	return d
+}
+
+// call calls require("gonvim").dap[name](args...)
+func (d *DebugPanel) call(name string, args ...interface{}) {
+	go d.ws.execLua(fmt.Sprintf("require('gonvim').dap.%s(...)", name), args...)
+}
+
+func (d *DebugPanel) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	d.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QTreeWidget, QListWidget { border: 0px; background-color: %s; }
+	QHeaderView::section { color: %s; background-color: %s; border: 0px; }
+	QPushButton { border: 1px solid %s; padding: 2px 8px; }
+	QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.bg.String(), editor.colors.inactiveFg.String(), bg.String(), editor.colors.inactiveFg.String(), editor.colors.selectedBg.String()))
+}
+
+// handle handles gonvim_dap; "start", "running", "end", "stack" {frames},
+// "scopes" {scopes}, "variables" ref {variables} and "watch" expr value ref
+func (d *DebugPanel) handle(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	event, _ := args[0].(string)
+	switch event {
+	case "start":
+		d.reset()
+		d.status.SetText("Running")
+		d.setColor()
+		d.widget.Show()
+	case "running":
+		d.status.SetText("Running")
+		d.stack.Clear()
+		d.frames = nil
+	case "end":
+		d.reset()
+		d.widget.Hide()
+	case "stack":
+		d.widget.Show()
+		d.status.SetText("Paused")
+		// The scopes and the watches of the frame follow
+		d.reset()
+		if len(args) > 1 {
+			d.setStack(args[1])
+		}
+	case "scopes":
+		if len(args) > 1 {
+			d.setScopes(args[1])
+		}
+	case "variables":
+		if len(args) > 2 {
+			d.setVariables(util.ReflectToInt(args[1]), args[2])
+		}
+	case "watch":
+		if len(args) > 3 {
+			expr, _ := args[1].(string)
+			d.setWatch(expr, fmt.Sprint(args[2]), util.ReflectToInt(args[3]))
+		}
+	}
+}
+
+func (d *DebugPanel) reset() {
+	d.variables.Clear()
+	d.watches = nil
+	d.refs = make(map[int]*widgets.QTreeWidgetItem)
+	d.stack.Clear()
+	d.frames = nil
+}
+
+func (d *DebugPanel) setStack(arg interface{}) {
+	frames, _ := arg.([]interface{})
+	for _, f := range frames {
+		frame, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := frame["name"].(string)
+		path, _ := frame["path"].(string)
+		text := name
+		if path != "" {
+			text = fmt.Sprintf("%s  %s:%d", name, filepath.Base(path), util.ReflectToInt(frame["line"]))
+		}
+		item := widgets.NewQListWidgetItem2(text, d.stack, 0)
+		item.SetToolTip(path)
+		if util.IsTrue(frame["current"]) {
+			d.stack.SetCurrentItem(item)
+		}
+		d.frames = append(d.frames, util.ReflectToInt(frame["id"]))
+	}
+}
+
+func (d *DebugPanel) setScopes(arg interface{}) {
+	for _, s := range d.decodeVariables(arg) {
+		item := widgets.NewQTreeWidgetItem4(d.variables, []string{s.name}, 0)
+		d.setReference(item, s.ref)
+		item.SetExpanded(true)
+	}
+}
+
+func (d *DebugPanel) setVariables(ref int, arg interface{}) {
+	parent, ok := d.refs[ref]
+	if !ok {
+		return
+	}
+	for _, child := range parent.TakeChildren() {
+		child.DestroyQTreeWidgetItem()
+	}
+	for _, v := range d.decodeVariables(arg) {
+		item := widgets.NewQTreeWidgetItem7(parent, []string{v.name, v.value, v.typ}, 0)
+		item.SetToolTip(1, v.value)
+		d.setReference(item, v.ref)
+	}
+}
+
+func (d *DebugPanel) setWatch(expr, value string, ref int) {
+	if d.watches == nil {
+		d.watches = widgets.NewQTreeWidgetItem2([]string{"Watch"}, 0)
+		d.variables.InsertTopLevelItem(0, d.watches)
+		d.watches.SetExpanded(true)
+	}
+	var item *widgets.QTreeWidgetItem
+	for i := 0; i < d.watches.ChildCount(); i++ {
+		if d.watches.Child(i).Text(0) == expr {
+			item = d.watches.Child(i)
+			break
+		}
+	}
+	if item == nil {
+		item = widgets.NewQTreeWidgetItem7(d.watches, []string{expr}, 0)
+	}
+	item.SetText(1, value)
+	item.SetToolTip(1, value)
+	d.setReference(item, ref)
+}
+
+// setReference makes the item expandable if it has children, which are fetched when it is expanded
+func (d *DebugPanel) setReference(item *widgets.QTreeWidgetItem, ref int) {
+	if ref <= 0 {
+		return
+	}
+	d.refs[ref] = item
+	item.SetChildIndicatorPolicy(widgets.QTreeWidgetItem__ShowIndicator)
+}
+
+func (d *DebugPanel) expand(item *widgets.QTreeWidgetItem) {
+	if item.ChildCount() > 0 {
+		return
+	}
+	for ref, i := range d.refs {
+		if i.Pointer() == item.Pointer() {
+			d.call("expand", ref)
+			return
+		}
+	}
+}
+
+type debugVariable struct {
+	name  string
+	value string
+	typ   string
+	ref   int
+}
+
+func (d *DebugPanel) decodeVariables(arg interface{}) []*debugVariable {
+	items, _ := arg.([]interface{})
+	var vars []*debugVariable
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		v := &debugVariable{}
+		v.name, _ = item["name"].(string)
+		v.value, _ = item["value"].(string)
+		v.typ, _ = item["type"].(string)
+		v.ref = util.ReflectToInt(item["ref"])
+		vars = append(vars, v)
+	}
+
+	return vars
+}
+
+// isDebugSign reports whether the cell is a sign of nvim-dap, which is drawn as a shape
+func (c *Cell) isDebugSign() bool {
+	switch c.highlight.hlName {
+	case "DapBreakpoint", "DapBreakpointCondition", "DapBreakpointRejected", "DapLogPoint", "DapStopped":
+		return true
+	}
+	return false
+}
+
+// drawDebugSigns draws the breakpoints as circles and the stopped line as an arrow
+func (w *Window) drawDebugSigns(p *gui.QPainter, y int, col int, cols int) {
+	if y >= len(w.content) {
+		return
+	}
+	line := w.content[y]
+	font := w.getFont()
+	for x := col; x <= col+cols && x < len(line); x++ {
+		cell := line[x]
+		if cell == nil || !cell.isDebugSign() || cell.char == " " {
+			continue
+		}
+		// The sign is two cells wide
+		width := font.truewidth * 2
+		left := float64(x) * font.truewidth
+		top := float64(y * font.lineHeight)
+		bg := w.background
+		if cell.highlight.background != nil {
+			bg = cell.highlight.background
+		}
+		if bg == nil {
+			bg = editor.colors.bg
+		}
+		p.FillRect4(core.NewQRectF4(left, top, width, float64(font.lineHeight)), bg.QColor())
+
+		fg := cell.highlight.foreground
+		if fg == nil {
+			fg = editor.colors.fg
+		}
+		size := float64(font.height) * 0.6
+		cx := left + width/2
+		cy := top + float64(font.lineHeight)/2
+		p.Save()
+		p.SetRenderHint(gui.QPainter__Antialiasing, true)
+		p.SetPen3(core.Qt__NoPen)
+		p.SetBrush(gui.NewQBrush3(fg.QColor(), core.Qt__SolidPattern))
+		switch cell.highlight.hlName {
+		case "DapStopped":
+			path := gui.NewQPainterPath()
+			path.MoveTo2(cx-size/2, cy-size/2)
+			path.LineTo2(cx+size/2, cy)
+			path.LineTo2(cx-size/2, cy+size/2)
+			path.CloseSubpath()
+			p.DrawPath(path)
+		case "DapLogPoint":
+			path := gui.NewQPainterPath()
+			path.MoveTo2(cx, cy-size/2)
+			path.LineTo2(cx+size/2, cy)
+			path.LineTo2(cx, cy+size/2)
+			path.LineTo2(cx-size/2, cy)
+			path.CloseSubpath()
+			p.DrawPath(path)
+		default:
+			p.DrawEllipse(core.NewQRectF4(cx-size/2, cy-size/2, size, size))
+		}
+		p.Restore()
+		x++
+	}
+}
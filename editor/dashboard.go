@@ -0,0 +1,197 @@
+package editor
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// dashboardEmptyExpr is true if the workspace has only the empty buffer of the startup
+const dashboardEmptyExpr = `bufname("%") ==# "" && &buftype ==# "" && !&modified && line("$") == 1 && getline(1) ==# "" && len(getbufinfo({"buflisted": 1})) <= 1`
+
+// Dashboard is the start screen of an empty workspace; it is dismissed when a
+// buffer is opened, and shown again only by :GonvimDashboard
+type Dashboard struct {
+	ws        *Workspace
+	widget    *widgets.QWidget
+	title     *widgets.QLabel
+	sections  *widgets.QLabel
+	dismissed bool
+}
+
+func newDashboard(ws *Workspace) *Dashboard {
+	d := &Dashboard{
+		ws:       ws,
+		widget:   widgets.NewQWidget(ws.screen.widget, 0),
+		title:    widgets.NewQLabel(nil, 0),
+		sections: widgets.NewQLabel(nil, 0),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetSpacing(24)
+	layout.AddStretch(1)
+	layout.AddWidget(d.title, 0, core.Qt__AlignHCenter)
+	layout.AddWidget(d.sections, 0, core.Qt__AlignHCenter)
+	layout.AddStretch(1)
+	d.widget.SetLayout(layout)
+	d.widget.SetAutoFillBackground(true)
+
+	d.title.SetText("Goneovim")
+	d.sections.SetTextFormat(core.Qt__RichText)
+	d.sections.SetFocusPolicy(core.Qt__NoFocus)
+	d.sections.ConnectLinkActivated(d.activate)
+	d.widget.Hide()
+
+	return d
+}
+
+// handle handles gonvim_dashboard; whether the workspace is empty, and whether to show it anyway
+func (d *Dashboard) handle(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	if len(args) > 1 && util.IsTrue(args[1]) {
+		d.dismissed = false
+		d.show()
+		return
+	}
+	if d.dismissed {
+		return
+	}
+	if util.IsTrue(args[0]) {
+		d.show()
+	} else {
+		d.hide()
+	}
+}
+
+func (d *Dashboard) show() {
+	d.setColor()
+	d.sections.SetText(d.html())
+	d.resize()
+	d.widget.Show()
+	d.widget.Raise()
+}
+
+func (d *Dashboard) hide() {
+	d.dismissed = true
+	d.widget.Hide()
+}
+
+func (d *Dashboard) resize() {
+	if d.ws.screen == nil {
+		return
+	}
+	d.widget.SetGeometry2(0, 0, d.ws.screen.widget.Width(), d.ws.screen.widget.Height())
+}
+
+func (d *Dashboard) setColor() {
+	font := d.ws.font.fontNew
+	d.widget.SetStyleSheet(fmt.Sprintf(
+		" * { background: %s; color: %s; font-family: %s; font-size: %dpt; }",
+		editor.colors.bg.String(),
+		editor.colors.fg.String(),
+		font.Family(),
+		int(font.PointSizeF()),
+	))
+	d.title.SetStyleSheet(fmt.Sprintf(" * { color: %s; font-size: %dpt; }", editor.colors.comment.String(), int(font.PointSizeF()*2.5)))
+}
+
+// html lists the sections; the links are handled by activate
+func (d *Dashboard) html() string {
+	var b strings.Builder
+	link := editor.colors.fg.String()
+	comment := editor.colors.comment.String()
+	section := func(title string) {
+		fmt.Fprintf(&b, "<tr><td colspan='2' style='padding-top: 16px; color: %s;'>%s</td></tr>", comment, title)
+	}
+	// action is an optional link after the note
+	item := func(href, text, note, action, actionHref string) {
+		fmt.Fprintf(
+			&b,
+			"<tr><td><a href='%s' style='color: %s; text-decoration: none;'>%s</a></td><td style='padding-left: 24px; color: %s;'>%s",
+			html.EscapeString(href), link, html.EscapeString(text), comment, html.EscapeString(note),
+		)
+		if action != "" {
+			fmt.Fprintf(&b, "&nbsp;&nbsp;<a href='%s' style='color: %s;'>%s</a>", html.EscapeString(actionHref), comment, action)
+		}
+		b.WriteString("</td></tr>")
+	}
+
+	b.WriteString("<table>")
+	section("Start")
+	item("action:open-file", "Open File...", "", "", "")
+	item("action:open-folder", "Open Folder...", "", "", "")
+	item("action:new-workspace", "New Workspace", "", "", "")
+	if editor.recent != nil && len(editor.recent.files) > 0 {
+		section("Recent Files")
+		for _, path := range editor.recent.files {
+			item("file:"+path, filepath.Base(path), shortenHomePath(filepath.Dir(path)), "", "")
+		}
+	}
+	if editor.recent != nil && len(editor.recent.workspaces) > 0 {
+		section("Recent Workspaces")
+		for _, ws := range editor.recent.workspaces {
+			name := filepath.Base(ws.path)
+			if ws.pinned {
+				name = "\u2605 " + name
+			}
+			note := shortenHomePath(ws.path)
+			if !ws.opened.IsZero() {
+				note += "  " + ws.opened.Format("2006-01-02 15:04")
+			}
+			if ws.pinned {
+				item("folder:"+ws.path, name, note, "unpin", "unpin:"+ws.path)
+			} else {
+				item("folder:"+ws.path, name, note, "pin", "pin:"+ws.path)
+			}
+		}
+	}
+	section("Configure")
+	item("action:settings", "Goneovim Settings", "~/.goneovim/setting.toml", "", "")
+	item("action:vimrc", "Neovim Config", "$MYVIMRC", "", "")
+	b.WriteString("</table>")
+
+	return b.String()
+}
+
+func (d *Dashboard) activate(href string) {
+	parts := strings.SplitN(href, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+	switch parts[0] {
+	case "file":
+		editor.openPath(parts[1], 0, 0)
+	case "folder":
+		editor.openFolder(parts[1])
+	case "pin", "unpin":
+		editor.pinRecentWorkspace(parts[1], parts[0] == "pin")
+		d.sections.SetText(d.html())
+	case "action":
+		switch parts[1] {
+		case "open-file":
+			d.ws.openFileDialog()
+		case "open-folder":
+			d.ws.openFolderDialog()
+		case "new-workspace":
+			editor.workspaceNew()
+		case "settings":
+			editor.openPath(filepath.Join(editor.homeDir, ".goneovim", "setting.toml"), 0, 0)
+		case "vimrc":
+			go d.ws.nvim.Command("execute 'edit ' . fnameescape($MYVIMRC)")
+		}
+	}
+}
+
+// shortenHomePath replaces the home directory of the path with ~
+func shortenHomePath(path string) string {
+	if editor.homeDir != "" && strings.HasPrefix(path, editor.homeDir) {
+		return "~" + strings.TrimPrefix(path, editor.homeDir)
+	}
+	return path
+}
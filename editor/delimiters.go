@@ -0,0 +1,227 @@
+package editor
+
+import (
+	"math"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+const delimiterChars = "()[]{}"
+
+// Delimiters is the brackets shown in a window with their depth in the
+// treesitter tree, and the guide between the pair of the brackets around the
+// cursor, sent by require("gonvim").delimiters()
+type Delimiters struct {
+	rows map[int][]*Delimiter
+
+	hasGuide   bool
+	guideCol   int
+	guideFirst int
+	guideLast  int
+	guideDepth int
+}
+
+// Delimiter is a bracket in a cell of a window
+type Delimiter struct {
+	col   int
+	depth int
+	pair  bool
+}
+
+// rainbowColors derives the colors of the depths of the brackets from the
+// accent color of the palette, rotating its hue and keeping them readable on bg
+func rainbowColors(accent, bg *RGBA) []*RGBA {
+	if accent == nil || bg == nil {
+		return nil
+	}
+	hsv := accent.HSV()
+	s := math.Max(hsv.S, 0.45)
+	v := hsv.V
+	if bg.HSV().V < 0.5 {
+		v = math.Max(v, 0.75)
+	} else {
+		v = math.Min(v, 0.6)
+	}
+	colors := make([]*RGBA, 6)
+	for i := range colors {
+		h := math.Mod(hsv.H+float64(i)/float64(len(colors)), 1)
+		colors[i] = (&HSV{h, s, v}).RGB()
+	}
+
+	return colors
+}
+
+func delimiterColor(depth int) *RGBA {
+	colors := editor.colors.delimiters
+	if !editor.config.Editor.RainbowDelimiters || len(colors) == 0 {
+		return editor.colors.matchFg
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	return colors[depth%len(colors)]
+}
+
+// setDelimiters handles gonvim_delimiters
+func (s *Screen) setDelimiters(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	items, _ := args[0].([]interface{})
+	delimiters := make(map[int]*Delimiters)
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		d := &Delimiters{
+			rows: make(map[int][]*Delimiter),
+		}
+		cells, _ := item["delimiters"].([]interface{})
+		for _, c := range cells {
+			cell, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			row := util.ReflectToInt(cell["row"])
+			d.rows[row] = append(d.rows[row], &Delimiter{
+				col:   util.ReflectToInt(cell["col"]),
+				depth: util.ReflectToInt(cell["depth"]),
+				pair:  util.IsTrue(cell["pair"]),
+			})
+		}
+		if guide, ok := item["guide"].(map[string]interface{}); ok {
+			d.hasGuide = true
+			d.guideCol = util.ReflectToInt(guide["col"])
+			d.guideFirst = util.ReflectToInt(guide["first"])
+			d.guideLast = util.ReflectToInt(guide["last"])
+			d.guideDepth = util.ReflectToInt(guide["depth"])
+		}
+		delimiters[util.ReflectToInt(item["win"])] = d
+	}
+
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		d, ok := delimiters[int(win.id)]
+		if !ok {
+			return true
+		}
+		win.delimiters = d
+		if win.widget != nil {
+			win.widget.Update()
+		}
+		return true
+	})
+}
+
+// drawDelimiters draws the brackets of the row again in the colors of their
+// depth, and outlines the pair around the cursor
+func (w *Window) drawDelimiters(p *gui.QPainter, y int) {
+	if w.delimiters == nil || y >= len(w.content) {
+		return
+	}
+	font := w.getFont()
+	for _, d := range w.delimiters.rows[y] {
+		if d.col < 0 || d.col >= len(w.content[y]) {
+			continue
+		}
+		// The cell may have been redrawn since the brackets were sent
+		cell := w.content[y][d.col]
+		if cell == nil || cell.highlight == nil || len(cell.char) != 1 || !strings.Contains(delimiterChars, cell.char) {
+			continue
+		}
+		color := delimiterColor(d.depth)
+		if color == nil {
+			continue
+		}
+		rect := core.NewQRectF4(
+			float64(d.col)*font.truewidth,
+			float64(y*font.lineHeight),
+			font.truewidth,
+			float64(font.lineHeight),
+		)
+
+		if editor.config.Editor.RainbowDelimiters {
+			p.Save()
+			p.SetClipRect(rect, core.Qt__ReplaceClip)
+			p.SetCompositionMode(gui.QPainter__CompositionMode_Source)
+			bg := cell.highlight.bg().QColor()
+			if !w.isFloatWin && cell.highlight.bg().equals(editor.colors.bg) {
+				bg.SetAlphaF(editor.config.Editor.Transparent)
+			}
+			p.FillRect4(rect, bg)
+			p.SetCompositionMode(gui.QPainter__CompositionMode_SourceOver)
+			if editor.config.Editor.CursorLineOverlay || editor.config.Editor.CursorColumnOverlay {
+				w.drawCursorLine(p, y)
+			}
+			qfont := p.Font()
+			qfont.SetBold(cell.highlight.bold)
+			qfont.SetItalic(cell.highlight.italic)
+			p.SetPen2(color.QColor())
+			p.DrawText(core.NewQPointF3(rect.X(), float64(y*font.lineHeight+font.shift)), cell.char)
+			p.Restore()
+		}
+
+		if d.pair {
+			qcolor := color.QColor()
+			qcolor.SetAlphaF(0.8)
+			pen := gui.NewQPen3(qcolor)
+			pen.SetWidthF(1)
+			p.Save()
+			p.SetRenderHint(gui.QPainter__Antialiasing, true)
+			p.SetPen(pen)
+			p.SetBrush(gui.NewQBrush2(core.Qt__NoBrush))
+			p.DrawRoundedRect(core.NewQRectF4(
+				float64(d.col)*font.truewidth+0.5,
+				float64(y*font.lineHeight)+0.5,
+				font.truewidth-1,
+				float64(font.lineHeight)-1,
+			), 2, 2, core.Qt__AbsoluteSize)
+			p.Restore()
+		}
+	}
+}
+
+// drawPairGuide draws the line between the rows of the pair of the brackets
+// around the cursor, at the indent of the line of the opening one
+func (w *Window) drawPairGuide(p *gui.QPainter, row, rows int) {
+	d := w.delimiters
+	if d == nil || !d.hasGuide || !w.isShown() {
+		return
+	}
+	color := delimiterColor(d.guideDepth)
+	if color == nil {
+		return
+	}
+	qcolor := color.QColor()
+	qcolor.SetAlphaF(0.7)
+	font := w.getFont()
+	for y := row; y < row+rows && y < len(w.content); y++ {
+		if y < d.guideFirst || y > d.guideLast {
+			continue
+		}
+		x := d.guideCol
+		if x < 0 || x >= len(w.content[y]) {
+			continue
+		}
+		// Do not draw over the text of a wrapped line
+		if cell := w.content[y][x]; cell != nil && cell.char != " " && cell.char != "" {
+			continue
+		}
+		p.FillRect4(
+			core.NewQRectF4(
+				float64(x)*font.truewidth,
+				float64(y*font.lineHeight),
+				1,
+				float64(font.lineHeight),
+			),
+			qcolor,
+		)
+	}
+}
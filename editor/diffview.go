@@ -0,0 +1,231 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// diffMarksExpr evaluates to [[lnum, group], ...] of the changed lines of the current window
+const diffMarksExpr = `map(filter(range(1, line('$')), 'diff_hlID(v:val, 1) > 0 || diff_filler(v:val) > 0'), '[v:val, diff_filler(v:val) > 0 ? "DiffDelete" : synIDattr(diff_hlID(v:val, 1), "name")]')`
+
+// DiffView draws the connectors between the changed regions of the windows in
+// diff mode over the screen, and the change marks of the whole buffer in a gutter
+type DiffView struct {
+	ws      *Workspace
+	active  bool
+	overlay *widgets.QWidget
+	gutter  *widgets.QWidget
+
+	marks    []diffMark
+	maxLines int
+}
+
+type diffMark struct {
+	line  int
+	group string
+}
+
+// diffRegion is a range of rows of a window whose lines are changed
+type diffRegion struct {
+	top    int
+	bottom int
+	group  string
+}
+
+func newDiffView(ws *Workspace) *DiffView {
+	d := &DiffView{
+		ws:      ws,
+		overlay: widgets.NewQWidget(ws.screen.widget, 0),
+		gutter:  widgets.NewQWidget(nil, 0),
+	}
+	d.overlay.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+	d.overlay.ConnectPaintEvent(d.paintConnectors)
+	d.overlay.Hide()
+
+	d.gutter.SetFixedWidth(8)
+	d.gutter.ConnectPaintEvent(d.paintGutter)
+	d.gutter.Hide()
+
+	return d
+}
+
+// handle handles gonvim_diff; &diff, the marks of diffMarksExpr and line("$")
+func (d *DiffView) handle(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	d.active = util.IsTrue(args[0])
+	d.maxLines = util.ReflectToInt(args[2])
+	d.marks = nil
+	items, _ := args[1].([]interface{})
+	for _, i := range items {
+		item, ok := i.([]interface{})
+		if !ok || len(item) < 2 {
+			continue
+		}
+		group, _ := item[1].(string)
+		d.marks = append(d.marks, diffMark{
+			line:  util.ReflectToInt(item[0]),
+			group: group,
+		})
+	}
+
+	if d.active && editor.config.Editor.DiffConnectors {
+		d.overlay.Show()
+	} else {
+		d.overlay.Hide()
+	}
+	if d.active && editor.config.Editor.DiffGutter {
+		d.gutter.Show()
+		d.gutter.Update()
+	} else {
+		d.gutter.Hide()
+	}
+	d.update()
+}
+
+// update is called on flush, since the regions follow the contents of the grids
+func (d *DiffView) update() {
+	if !d.active || !d.overlay.IsVisible() {
+		return
+	}
+	d.overlay.Resize2(d.ws.screen.widget.Width(), d.ws.screen.widget.Height())
+	d.overlay.Raise()
+	d.overlay.Update()
+}
+
+func diffColor(s *Screen, group string) *RGBA {
+	switch group {
+	case "DiffAdd":
+		return s.groupColor(group, newRGBA(80, 160, 80, 1))
+	case "DiffDelete":
+		return s.groupColor(group, newRGBA(200, 80, 80, 1))
+	default:
+		return s.groupColor("DiffChange", newRGBA(80, 120, 200, 1))
+	}
+}
+
+// diffRegions groups the rows of the window whose first text cell is highlighted by a Diff group
+func (w *Window) diffRegions() []diffRegion {
+	var regions []diffRegion
+	for y, line := range w.content {
+		group := ""
+		for _, cell := range line {
+			if cell == nil || cell.isDiffGutter() {
+				continue
+			}
+			switch cell.highlight.hlName {
+			case "DiffAdd", "DiffChange", "DiffText", "DiffDelete":
+				group = cell.highlight.hlName
+				if group == "DiffText" {
+					group = "DiffChange"
+				}
+			}
+			break
+		}
+		if group == "" {
+			continue
+		}
+		n := len(regions)
+		if n > 0 && regions[n-1].bottom == y-1 && regions[n-1].group == group {
+			regions[n-1].bottom = y
+			continue
+		}
+		regions = append(regions, diffRegion{top: y, bottom: y, group: group})
+	}
+
+	return regions
+}
+
+// isDiffGutter reports whether the cell is of the columns left of the text,
+// which are shown with the foldcolumn of diff mode as well as the signs
+func (c *Cell) isDiffGutter() bool {
+	switch c.highlight.hlName {
+	case "FoldColumn",
+		"CursorLineFold",
+		"CursorLineNr",
+		"CursorLineSign",
+		"LineNrAbove",
+		"LineNrBelow":
+		return true
+	}
+	return c.isSignColumn()
+}
+
+// paintConnectors connects the n-th region of a window to the n-th region of
+// the window on its right, across the separator between them
+func (d *DiffView) paintConnectors(event *gui.QPaintEvent) {
+	var wins []*Window
+	d.ws.screen.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if ok && win != nil && win.grid != 1 && !win.isMsgGrid && !win.isFloatWin && win.isShown() {
+			wins = append(wins, win)
+		}
+		return true
+	})
+	if len(wins) < 2 {
+		return
+	}
+
+	p := gui.NewQPainter2(d.overlay)
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+	p.SetPen3(core.Qt__NoPen)
+	for _, left := range wins {
+		for _, right := range wins {
+			if right.pos[0] != left.pos[0]+left.cols+1 {
+				continue
+			}
+			d.connect(p, left, right)
+		}
+	}
+	p.DestroyQPainter()
+}
+
+func (d *DiffView) connect(p *gui.QPainter, left, right *Window) {
+	lregions := left.diffRegions()
+	rregions := right.diffRegions()
+	font := left.getFont()
+	lineHeight := float64(font.lineHeight)
+	x0 := float64(left.pos[0]+left.cols) * font.truewidth
+	x1 := float64(right.pos[0]) * font.truewidth
+	for i := 0; i < len(lregions) && i < len(rregions); i++ {
+		l := lregions[i]
+		r := rregions[i]
+		color := diffColor(d.ws.screen, l.group)
+		if l.group == "DiffChange" || r.group == "DiffChange" {
+			color = diffColor(d.ws.screen, "DiffChange")
+		}
+		qcolor := color.QColor()
+		qcolor.SetAlphaF(0.6)
+		p.SetBrush(gui.NewQBrush3(qcolor, core.Qt__SolidPattern))
+
+		path := gui.NewQPainterPath()
+		path.MoveTo2(x0, float64(left.pos[1]+l.top)*lineHeight)
+		path.LineTo2(x1, float64(right.pos[1]+r.top)*lineHeight)
+		path.LineTo2(x1, float64(right.pos[1]+r.bottom+1)*lineHeight)
+		path.LineTo2(x0, float64(left.pos[1]+l.bottom+1)*lineHeight)
+		path.CloseSubpath()
+		p.DrawPath(path)
+	}
+}
+
+// paintGutter draws the changes of the whole buffer, like the overview ruler of merge tools
+func (d *DiffView) paintGutter(event *gui.QPaintEvent) {
+	if d.maxLines <= 0 {
+		return
+	}
+	p := gui.NewQPainter2(d.gutter)
+	height := float64(d.gutter.Height())
+	width := d.gutter.Width()
+	markHeight := int(height/float64(d.maxLines)) + 1
+	if markHeight < 2 {
+		markHeight = 2
+	}
+	for _, m := range d.marks {
+		y := int(float64(m.line-1) / float64(d.maxLines) * height)
+		p.FillRect5(1, y, width-2, markHeight, diffColor(d.ws.screen, m.group).QColor())
+	}
+	p.DestroyQPainter()
+}
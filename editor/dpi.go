@@ -0,0 +1,81 @@
+package editor
+
+import (
+	"github.com/therecipe/qt/gui"
+)
+
+// watchScreenChange updates the workspaces when the window is moved to a
+// monitor of another scale factor, or the scale factor of the monitor is changed
+func (e *Editor) watchScreenChange() {
+	handle := e.window.WindowHandle()
+	if handle == nil || handle.Pointer() == nil {
+		return
+	}
+	e.devicePixelRatio = e.window.DevicePixelRatioF()
+
+	watch := func(screen *gui.QScreen) {
+		if screen == nil || screen.Pointer() == nil {
+			return
+		}
+		screen.ConnectLogicalDotsPerInchChanged(func(float64) {
+			e.updateDevicePixelRatio()
+		})
+	}
+	for _, screen := range gui.QGuiApplication_Screens() {
+		watch(screen)
+	}
+	e.app.ConnectScreenAdded(watch)
+	handle.ConnectScreenChanged(func(*gui.QScreen) {
+		e.updateDevicePixelRatio()
+	})
+}
+
+func (e *Editor) updateDevicePixelRatio() {
+	ratio := e.window.DevicePixelRatioF()
+	if ratio == e.devicePixelRatio {
+		return
+	}
+	e.devicePixelRatio = ratio
+	for _, ws := range e.workspaces {
+		ws.updateDevicePixelRatio()
+	}
+}
+
+// updateDevicePixelRatio recomputes the font metrics, which may be rounded
+// differently in the new scale, redraws the text cache in the new resolution
+// and resizes the grids to the new metrics
+func (w *Workspace) updateDevicePixelRatio() {
+	if w.font == nil || w.screen == nil {
+		return
+	}
+	w.font.change(w.font.fontNew.Family(), w.font.fontNew.PointSizeF())
+	w.screen.font = w.font
+	w.screen.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		win.devicePixelRatio = 0
+		if win.font != nil {
+			oldWidth := float64(win.cols) * win.font.truewidth
+			oldHeight := win.rows * win.font.lineHeight
+			win.font = initFontNew(win.font.fontNew.Family(), win.font.fontNew.PointSizeF(), win.font.lineSpace, false)
+			if win.textCache != nil {
+				win.textCache.Purge()
+			}
+			if w.nvim != nil {
+				go w.nvim.TryResizeUIGrid(win.grid, int(oldWidth/win.font.truewidth), oldHeight/win.font.lineHeight)
+			}
+		}
+		if win.widget != nil {
+			win.widget.Update()
+		}
+		return true
+	})
+	w.cursor.updateFont(w.font)
+	w.cursor.isNeedUpdateModeInfo = true
+	w.cursor.update()
+
+	// Resizes the grids if the number of the cells has changed
+	w.updateSize()
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	frameless "github.com/akiyosi/goqtframelesswindow"
 	clipb "github.com/atotto/clipboard"
@@ -50,12 +51,32 @@ type ColorPalette struct {
 	minimapCurrentRegion  *RGBA
 	windowSeparator       *RGBA
 	indentGuide           *RGBA
+	indentGuideScope      *RGBA
+
+	// The colors of the depths of the rainbow delimiters, derived from matchFg
+	delimiters []*RGBA
+
+	// Only set by a theme; the highlights of nvim are used otherwise
+	tablineFg       *RGBA
+	tablineActiveFg *RGBA
+	tablineAccent   *RGBA
+	statuslineFg    *RGBA
+	statuslineBg    *RGBA
+
+	// The colors of paletteHighlights of the current colorscheme
+	highlights map[string]*HighlightColor
 }
 
 // NotifyButton is
 type NotifyButton struct {
 	action func()
 	text   string
+
+	// Set for the buttons created by gonvim_notify; function is a VimL function name,
+	// and callbackID is the id of a Lua function registered by require("gonvim")
+	function   string
+	callbackID int
+	args       []interface{}
 }
 
 // Notify is
@@ -64,6 +85,7 @@ type Notify struct {
 	period  int
 	message string
 	buttons []*NotifyButton
+	options []NotifyOptionArg
 }
 
 type Option struct {
@@ -73,6 +95,26 @@ type Option struct {
 
 	Server string `long:"server" description:"Remote session address"`
 	Nvim   string `long:"nvim" description:"Excutable nvim path to attach"`
+
+	RegisterURLScheme bool   `long:"register-url-scheme" description:"Associate gonvim:// links with this executable"`
+	Profile           string `long:"profile" description:"Profile name to save and restore the window geometry and the sessions"`
+
+	RegisterShellIntegration bool `long:"register-shell-integration" description:"Register file associations and \"Open with Gonvim\" context menu entries"`
+	Reuse                    bool `long:"reuse" description:"Open the files in the running goneovim if exists"`
+	Window                   int  `long:"window" description:"Index of the top-level window, used to save its session"`
+
+	StartupTime string `long:"startuptime" description:"Write the startup timing messages to the file"`
+
+	Headless       bool   `long:"headless" description:"Attach to nvim, render the first frame offscreen and exit, for smoke testing"`
+	HeadlessOutput string `long:"headless-output" description:"Save the frame rendered by --headless to the PNG file"`
+	HeadlessScript string `long:"headless-script" description:"Run the script of keys and expectations on the screen in --headless, see harness.go"`
+
+	RecordRedraw string `long:"record-redraw" description:"Record the redraw events of nvim to the file"`
+	ReplayRedraw string `long:"replay-redraw" description:"Replay the redraw events recorded by --record-redraw at max speed, print the timing and exit"`
+
+	Folder string `long:"folder" description:"Open the directory as a workspace"`
+
+	Clean bool `long:"clean" description:"Start nvim with -u NONE -i NONE and the GUI with the default config, without setting.toml, the extensions, the sessions, the saved window geometry and the viewports"`
 }
 
 // Editor is the editor
@@ -85,10 +127,14 @@ type Editor struct {
 	args    []string
 	opts    Option
 
+	// sessionReadOnly is true when another goneovim holds the lock of the sessions
+	sessionReadOnly bool
+
 	notifyStartPos    *core.QPoint
 	notificationWidth int
 	notify            chan *Notify
 	guiInit           chan bool
+	openURLs          chan string
 	doneGuiInit       bool
 
 	workspaces []*Workspace
@@ -98,8 +144,13 @@ type Editor struct {
 	split      *widgets.QSplitter
 	wsWidget   *widgets.QWidget
 	wsSide     *WorkspaceSide
+	wsTabs     *WorkspaceTabs
+	extensions *Extensions
 	sysTray    *widgets.QSystemTrayIcon
 
+	viewports      map[string]*Viewport
+	shownWorkspace *Workspace
+
 	statuslineHeight int
 	width            int
 	height           int
@@ -123,18 +174,55 @@ type Editor struct {
 	config                 gonvimConfig
 	notifications          []*Notification
 	isDisplayNotifications bool
-
-	isSetGuiColor bool
-	colors        *ColorPalette
-	svgs          map[string]*SvgXML
+	progressNotifications  map[progressKey]*ProgressNotification
+	notificationHistory    []*NotificationHistoryItem
+	doNotDisturbAction     *widgets.QAction
+	notificationMore       *widgets.QLabel
+
+	recent       *Recent
+	presentation *Presentation
+	zen          *ZenMode
+
+	alwaysOnTop       bool
+	alwaysOnTopAction *widgets.QAction
+	opacity           float64
+	powerSaving       bool
+	perf              *PerfHUD
+	latency           *LatencyMeter
+	devicePixelRatio  float64
+	titlebarButtons   *TitlebarButtons
+	snap              *WindowSnap
+
+	isSetGuiColor    bool
+	isDarkAppearance bool
+	colors           *ColorPalette
+	themeColors      map[string]*RGBA
+	colorOverrides   map[string]*RGBA
+	colorsErr        error
+	svgs             map[string]*SvgXML
+	svgsOnce         sync.Once
+
+	// The appearance of the OS, sent to the Qt thread by appearanceSignal
+	appearanceChanges chan bool
 
 	extFontFamily string
 	extFontSize   int
+
+	startupTime    *StartupTime
+	redrawRecords  int
+	headlessTimer  *time.Timer
+	doneFirstPaint bool
+	deferredOnce   sync.Once
 }
 
 type editorSignal struct {
 	core.QObject
 	_ func() `signal:"notifySignal"`
+	_ func() `signal:"toggleWindowSignal"`
+	_ func() `signal:"appearanceSignal"`
+	_ func() `signal:"openURLSignal"`
+	_ func() `signal:"powerSavingSignal"`
+	_ func() `signal:"extensionSignal"`
 }
 
 func (hl *Highlight) copy() Highlight {
@@ -173,30 +261,79 @@ func InitEditor() {
 		home = "~"
 	}
 
+	if opts.RegisterURLScheme {
+		err := registerURLScheme()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if opts.RegisterShellIntegration {
+		err := registerShellIntegration(home)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if opts.Reuse && sendPathsToRunningInstance(home, args) {
+		os.Exit(0)
+	}
+
+	// Open gonvim:// links in the running instance if exists
+	urlArg, args := splitURLArg(args)
+	if urlArg != "" {
+		if sendURLToRunningInstance(home, urlArg) {
+			os.Exit(0)
+		}
+		g, err := parseGonvimURL(urlArg)
+		if err == nil {
+			args = g.nvimArgs(args)
+		}
+	}
+
+	// Safe mode, to tell whether a problem comes from the user config
+	if opts.Clean {
+		args = append([]string{"-u", "NONE", "-i", "NONE"}, args...)
+	}
+
 	editor = &Editor{
-		version: GONEOVIMVERSION,
-		signal:  NewEditorSignal(nil),
-		notify:  make(chan *Notify, 10),
-		stop:    make(chan struct{}),
-		guiInit: make(chan bool, 1),
-		config:  newGonvimConfig(home),
-		homeDir: home,
-		args:    args,
-		opts:    opts,
+		version:  GONEOVIMVERSION,
+		signal:   NewEditorSignal(nil),
+		notify:   make(chan *Notify, 100),
+		stop:     make(chan struct{}),
+		guiInit:  make(chan bool, 1),
+		openURLs: make(chan string, 10),
+		opacity:  1.0,
+		config:   newGonvimConfig(home, opts.Clean),
+		homeDir:  home,
+		args:     args,
+		opts:     opts,
 	}
 	e := editor
+	defer e.handleCrash()
+	if opts.StartupTime != "" {
+		e.startupTime = newStartupTime()
+	}
 
+	e.prepareHeadless()
 	e.app = widgets.NewQApplication(len(os.Args), os.Args)
 	e.app.ConnectAboutToQuit(func() {
 		e.cleanup()
 	})
 	e.app.SetAttribute(core.Qt__AA_EnableHighDpiScaling, true)
+	e.markStartup("Qt init")
 
 	e.initFont()
-	e.initSVGS()
+	e.iconSize = e.extFontSize * 11 / 9
+	e.markStartup("font init")
+	// SVGs are initialized when they are used first,
+	// and notifications and the system tray after the first frame (see deferredInit)
 	e.initColorPalette()
-	e.initNotifications()
-	e.initSysTray()
+	e.initAppearance()
 
 	e.window = frameless.CreateQFramelessWindow(e.config.Editor.Transparent)
 	e.setWindowSize()
@@ -207,14 +344,26 @@ func InitEditor() {
 	l.SetSpacing(0)
 
 	e.window.SetupContent(l)
+	e.initTitlebarButtons()
 
 	e.wsWidget = widgets.NewQWidget(nil, 0)
 	e.wsSide = newWorkspaceSide()
+	// The items of the hidden sidebar are added after the first frame
+	if e.config.SideBar.Visible {
+		e.wsSide.addItems()
+	}
 	e.wsSide.newScrollArea()
 	e.wsSide.scrollarea.Hide()
 	e.newSplitter()
-	l.AddWidget(e.split, 1, 0)
+	if e.config.Workspace.Tabs {
+		e.wsTabs = newWorkspaceTabs()
+		e.wsTabs.attach(l)
+	} else {
+		l.AddWidget(e.split, 1, 0)
+	}
+	e.markStartup("window init")
 
+	e.lockSession()
 	e.initWorkspaces()
 
 	e.wsWidget.ConnectResizeEvent(func(event *gui.QResizeEvent) {
@@ -224,6 +373,18 @@ func InitEditor() {
 	})
 
 	e.loadFileInDarwin()
+	e.initCloseEvent()
+
+	e.initGlobalHotkey()
+	e.initURLServer()
+	e.initExtensions()
+	e.initRecent()
+	e.loadViewports()
+	e.initPowerSaving()
+	e.initWindowSnap()
+	e.claimWindow()
+	// In case nvim does not draw anything
+	core.QTimer_SingleShot(3000, e.deferredInit)
 
 	go func() {
 		<-e.stop
@@ -234,6 +395,7 @@ func InitEditor() {
 	}()
 
 	e.window.Show()
+	e.watchScreenChange()
 	e.wsWidget.SetFocus2()
 	widgets.QApplication_Exec()
 }
@@ -256,9 +418,9 @@ func (e *Editor) newSplitter() {
 func (e *Editor) initWorkspaces() {
 	e.workspaces = []*Workspace{}
 	sessionExists := false
-	if e.config.Workspace.RestoreSession {
+	if e.config.Workspace.RestoreSession && !e.opts.Clean {
 		for i := 0; i <= WorkspaceLen; i++ {
-			path := filepath.Join(e.homeDir, ".goneovim", "sessions", strconv.Itoa(i)+".vim")
+			path := filepath.Join(e.sessionDir(), strconv.Itoa(i)+".vim")
 			_, err := os.Stat(path)
 			if err != nil {
 				break
@@ -278,6 +440,9 @@ func (e *Editor) initWorkspaces() {
 		}
 		e.workspaces = append(e.workspaces, ws)
 	}
+	if e.opts.Folder != "" {
+		e.startFolder(sessionExists)
+	}
 
 	e.workspaceUpdate()
 
@@ -299,11 +464,13 @@ func (e *Editor) loadFileInDarwin() {
 				return false
 			}
 			fileOpenEvent := gui.NewQFileOpenEventFromPointer(event.Pointer())
+			if fileOpenEvent.Url().Scheme() == urlScheme {
+				e.openURL(fileOpenEvent.Url().ToString(core.QUrl__None))
+				return true
+			}
 			macosArg = fileOpenEvent.File()
 			goneovim := e.workspaces[e.active].nvim
-			isModified := ""
-			isModified, _ = goneovim.CommandOutput("echo &modified")
-			if isModified == "1" {
+			if e.workspaces[e.active].modified {
 				goneovim.Command(fmt.Sprintf(":tabe %s", macosArg))
 			} else {
 				goneovim.Command(fmt.Sprintf(":e %s", macosArg))
@@ -311,10 +478,6 @@ func (e *Editor) loadFileInDarwin() {
 		}
 		return true
 	})
-	e.window.ConnectCloseEvent(func(event *gui.QCloseEvent) {
-		e.app.DisconnectEvent()
-		event.Accept()
-	})
 }
 
 func (e *Editor) initNotifications() {
@@ -322,22 +485,42 @@ func (e *Editor) initNotifications() {
 	e.notificationWidth = e.config.Editor.Width * 2 / 3
 	e.notifyStartPos = core.NewQPoint2(e.width-e.notificationWidth-10, e.height-30)
 	e.signal.ConnectNotifySignal(func() {
-		notify := <-e.notify
+		var notify *Notify
+		select {
+		case notify = <-e.notify:
+		default:
+			return
+		}
 		if notify.message == "" {
 			return
 		}
-		if notify.buttons == nil {
-			e.popupNotification(notify.level, notify.period, notify.message)
-		} else {
-			e.popupNotification(notify.level, notify.period, notify.message, notifyOptionArg(notify.buttons))
+		e.addNotificationHistory(notify.level, notify.message)
+		if e.isNotificationSuppressed(notify) {
+			return
 		}
+		if e.config.Editor.NativeNotifications {
+			e.nativeNotification(notify.level, notify.message)
+			// Notifications with buttons still need the in-window popup
+			if e.config.Editor.NativeNotificationsOnly && notify.buttons == nil {
+				return
+			}
+		}
+		e.popupNotification(notify.level, notify.period, notify.message, notify.options...)
 	})
+	// The notifications pushed before the signal is connected
+	for i := len(e.notify); i > 0; i-- {
+		e.signal.NotifySignal()
+	}
 }
 
 func (e *Editor) initSysTray() {
 	if !e.config.Editor.DesktopNotifications {
 		return
 	}
+	e.initSysTrayIcon()
+}
+
+func (e *Editor) initSysTrayIcon() {
 	pixmap := gui.NewQPixmap()
 	color := ""
 	size := 0.95
@@ -355,6 +538,14 @@ func (e *Editor) initSysTray() {
 		trayIcon = gui.NewQIcon5(image)
 	}
 	e.sysTray = widgets.NewQSystemTrayIcon2(trayIcon, e.app)
+	trayMenu := widgets.NewQMenu(nil)
+	trayMenu.AddAction("Open Folder...").ConnectTriggered(func(bool) {
+		e.workspaces[e.active].openFolderDialog()
+	})
+	trayMenu.AddSeparator()
+	e.addWindowStateActions(trayMenu)
+	e.addNotificationActions(trayMenu)
+	e.sysTray.SetContextMenu(trayMenu)
 	e.sysTray.Show()
 }
 
@@ -365,6 +556,7 @@ func putEnv() {
 		_ = os.Setenv("LD_LIBRARY_PATH", dir+"lib")
 		_ = os.Setenv("QT_PLUGIN_PATH", dir+"plugins")
 		_ = os.Setenv("RESOURCE_NAME", "goneovim")
+		putWaylandEnv()
 	}
 	if runtime.GOOS == "darwin" {
 		shell := os.Getenv("SHELL")
@@ -424,6 +616,7 @@ func (e *Editor) pushNotification(level NotifyLevel, p int, message string, opt
 		period:  p,
 		message: message,
 		buttons: opts.buttons,
+		options: opt,
 	}
 	e.notify <- n
 	e.signal.NotifySignal()
@@ -431,14 +624,7 @@ func (e *Editor) pushNotification(level NotifyLevel, p int, message string, opt
 
 func (e *Editor) popupNotification(level NotifyLevel, p int, message string, opt ...NotifyOptionArg) {
 	notification := newNotification(level, p, message, opt...)
-	notification.widget.SetParent(e.window)
-	notification.widget.AdjustSize()
-	x := e.notifyStartPos.X()
-	y := e.notifyStartPos.Y() - notification.widget.Height() - 4
-	notification.widget.Move2(x, y)
-	e.notifyStartPos = core.NewQPoint2(x, y)
-	e.notifications = append(e.notifications, notification)
-	notification.show()
+	e.addNotification(notification)
 }
 
 func (e *Editor) initColorPalette() {
@@ -453,11 +639,28 @@ func (e *Editor) initColorPalette() {
 		matchFg:    rgbAccent,
 	}
 
+	if e.config.Editor.Theme != "" {
+		colors, err := e.loadTheme(e.config.Editor.Theme)
+		if err != nil {
+			e.colorsErr = fmt.Errorf("the theme %s: %s", e.config.Editor.Theme, err)
+		}
+		e.themeColors = colors
+	}
+	overrides, err := parseColors(e.config.Colors)
+	if err != nil {
+		e.colorsErr = fmt.Errorf("[colors] of settings.toml: %s", err)
+	}
+	e.colorOverrides = overrides
+
 	e.colors = c
 	e.colors.update()
 }
 
 func (c *ColorPalette) update() {
+	// The base colors of the theme and the config are used for the derivations,
+	// and the rest of them override the derived colors
+	c.applyColors(c.e.themeColors)
+	c.applyColors(c.e.colorOverrides)
 	fg := c.fg
 	bg := c.bg
 	rgbAccent := hexToRGBA(c.e.config.SideBar.AccentColor)
@@ -476,13 +679,25 @@ func (c *ColorPalette) update() {
 	c.minimapCurrentRegion = warpColor(bg, 20)
 	c.windowSeparator = warpColor(bg, -40)
 	c.indentGuide = warpColor(bg, -30)
+	c.indentGuideScope = warpColor(bg, -70)
+	c.applyHighlights()
+	c.enforceContrast()
+	c.applyColors(c.e.themeColors)
+	c.applyColors(c.e.colorOverrides)
+	c.delimiters = rainbowColors(c.matchFg, c.bg)
+}
+
+// isFrameless reports whether the titlebar and the frame of the window are drawn
+// by goneovim. Frameless drawing is not used on linux; on Wayland, the
+// decorations are drawn on the client side by the QtWayland decoration plugin.
+func (e *Editor) isFrameless() bool {
+	return runtime.GOOS != "linux" && e.config.Editor.BorderlessWindow
 }
 
 func (e *Editor) updateGUIColor() {
 	e.workspaces[e.active].updateWorkspaceColor()
 
-	// Do not use frameless drawing on linux
-	if runtime.GOOS == "linux" {
+	if !e.isFrameless() {
 		// e.window.Widget.SetStyleSheet(fmt.Sprintf(" * { background-color: rgba(%d, %d, %d, %f); }", e.colors.bg.R, e.colors.bg.G, e.colors.bg.B, e.config.Editor.Transparent))
 		e.window.TitleBar.Hide()
 		e.window.WindowWidget.SetStyleSheet(fmt.Sprintf(" #QFramelessWidget { background-color: rgba(%d, %d, %d, %f); border-radius: 0px;}", e.colors.bg.R, e.colors.bg.G, e.colors.bg.B, e.config.Editor.Transparent))
@@ -492,29 +707,57 @@ func (e *Editor) updateGUIColor() {
 	} else {
 		e.window.SetupWidgetColor((uint16)(e.colors.bg.R), (uint16)(e.colors.bg.G), (uint16)(e.colors.bg.B))
 		e.window.SetupTitleColor((uint16)(e.colors.fg.R), (uint16)(e.colors.fg.G), (uint16)(e.colors.fg.B))
+		e.titlebarButtons.setColor()
 	}
 
-	e.window.SetWindowOpacity(1.0)
+	e.window.SetWindowOpacity(e.opacity)
+	e.updateBlurBehind()
+}
+
+// updateBlurBehind blurs the desktop behind the window to keep a transparent window readable
+func (e *Editor) updateBlurBehind() {
+	if e.config.Editor.Transparent >= 1.0 || e.config.Editor.BlurRadius <= 0 {
+		return
+	}
+	e.setBlurBehind(e.config.Editor.BlurRadius)
 }
 
+// hexToRGBA parses #RGB, #RGBA, #RRGGBB, #RRGGBBAA and the CSS basic color names
 func hexToRGBA(hex string) *RGBA {
-	format := "#%02x%02x%02x"
-	if len(hex) == 4 {
-		format = "#%1x%1x%1x"
+	if named, ok := namedColors[strings.ToLower(hex)]; ok {
+		hex = named
+	}
+	if !strings.HasPrefix(hex, "#") {
+		return nil
+	}
+	digits := hex[1:]
+	// The short forms repeat each digit
+	if len(digits) == 3 || len(digits) == 4 {
+		long := ""
+		for _, d := range digits {
+			long += string(d) + string(d)
+		}
+		digits = long
+	}
+	if len(digits) == 6 {
+		digits += "ff"
 	}
-	var r, g, b uint8
-	n, err := fmt.Sscanf(hex, format, &r, &g, &b)
+	if len(digits) != 8 {
+		return nil
+	}
+	var r, g, b, a uint8
+	n, err := fmt.Sscanf(digits, "%02x%02x%02x%02x", &r, &g, &b, &a)
 	if err != nil {
 		return nil
 	}
-	if n != 3 {
+	if n != 4 {
 		return nil
 	}
 	rgba := &RGBA{
 		R: (int)(r),
 		G: (int)(g),
 		B: (int)(b),
-		A: 1,
+		A: float64(a) / 255.0,
 	}
 
 	return rgba
@@ -546,11 +789,16 @@ func (e *Editor) setWindowOptions() {
 	e.window.SetupWidgetColor(0, 0, 0)
 	e.width = e.config.Editor.Width
 	e.height = e.config.Editor.Height
-	e.window.SetMinimumSize2(e.width, e.height)
+	e.window.SetMinimumSize2(400, 300)
+	e.window.Resize2(e.width, e.height)
 	e.window.SetWindowOpacity(0.0)
 	e.initSpecialKeys()
 	e.window.ConnectKeyPressEvent(e.keyPress)
 	e.window.SetAcceptDrops(true)
+	// Explicitly specified geometry takes precedence over the saved one
+	if e.opts.Geometry == "" {
+		e.restoreWindowGeometry()
+	}
 	if e.config.Editor.StartFullscreen || e.opts.Fullscreen {
 		e.window.ShowFullScreen()
 	} else if e.config.Editor.StartMaximizedWindow || e.opts.Maximized {
@@ -566,9 +814,10 @@ func isFileExist(filename string) bool {
 func (e *Editor) copyClipBoard() {
 	go func() {
 		var yankedText string
-		yankedText, _ = e.workspaces[e.active].nvim.CommandOutput("echo getreg()")
+		e.workspaces[e.active].nvim.Call("getreg", &yankedText)
 		if yankedText != "" {
 			clipb.WriteAll(yankedText)
+			setPrimarySelection(yankedText)
 		}
 	}()
 
@@ -619,6 +868,7 @@ func (e *Editor) workspaceUpdate() {
 	}
 	for i, ws := range e.workspaces {
 		if i == e.active {
+			ws.activity = ""
 			ws.hide()
 			ws.show()
 		} else {
@@ -629,15 +879,47 @@ func (e *Editor) workspaceUpdate() {
 		e.wsSide.items[i].setSideItemLabel(i)
 		e.wsSide.items[i].setText(e.workspaces[i].cwdlabel)
 		e.wsSide.items[i].show()
+		e.wsSide.items[i].setBadge(e.workspaces[i].activity)
 	}
 	for i := len(e.workspaces); i < len(e.wsSide.items); i++ {
 		e.wsSide.items[i].hide()
 	}
+	if e.wsTabs != nil {
+		e.wsTabs.update()
+	}
+	e.switchViewports()
+	e.updateTitle()
 }
 
 func (e *Editor) keyPress(event *gui.QKeyEvent) {
+	if isFullscreenKey(event) {
+		e.toggleFullscreen()
+		return
+	}
+	if isZenKey(event) {
+		e.toggleZen()
+		return
+	}
+	if area := isSnapKey(event); area != "" {
+		e.snapWindow(area)
+		return
+	}
+	if isGotoLineKey(event) {
+		e.workspaces[e.active].gotoLine()
+		return
+	}
+	if find := isFindKey(event); find != "" {
+		e.workspaces[e.active].findBar.open(find == "replace")
+		return
+	}
+	e.workspaces[e.active].git.hide()
+	if zoom := isZoomKey(event); zoom != "" {
+		e.workspaces[e.active].zoom(zoom)
+		return
+	}
 	input := e.convertKey(event.Text(), event.Key(), event.Modifiers())
 	if input != "" {
+		e.latency.keyPressed()
 		e.workspaces[e.active].nvim.Input(input)
 	}
 }
@@ -889,12 +1171,26 @@ func (e *Editor) close() {
 }
 
 func (e *Editor) cleanup() {
-	home, err := homedir.Dir()
-	if err != nil {
+	for _, ws := range e.workspaces {
+		ws.redrawRecorder.close()
+		ws.tasks.kill()
+	}
+	e.saveWindowGeometry()
+	e.saveViewports()
+	defer e.shutdownWorkspaces()
+
+	// The sessions belong to another goneovim, or are not used by --clean
+	if e.sessionReadOnly || e.opts.Clean {
 		return
 	}
-	sessions := filepath.Join(home, ".goneovim", "sessions")
-	os.RemoveAll(sessions)
+	defer e.releaseSessionLock()
+
+	sessions := e.sessionDir()
+	// Keep the sessions of the other windows
+	oldSessions, _ := filepath.Glob(filepath.Join(sessions, "*.vim"))
+	for _, oldSession := range oldSessions {
+		os.Remove(oldSession)
+	}
 	os.MkdirAll(sessions, 0755)
 
 	select {
@@ -907,6 +1203,7 @@ func (e *Editor) cleanup() {
 		sessionPath := filepath.Join(sessions, strconv.Itoa(i)+".vim")
 		fmt.Println(sessionPath)
 		fmt.Println(ws.nvim.Command("mksession " + sessionPath))
+		appendZoomToSession(sessionPath, ws.zoomLevel)
 		fmt.Println("mksession finished")
 	}
 }
@@ -0,0 +1,33 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHexToRGBA(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want *RGBA
+	}{
+		{"#0a0b0c", &RGBA{R: 10, G: 11, B: 12, A: 1}},
+		{"#FFA500", &RGBA{R: 255, G: 165, B: 0, A: 1}},
+		{"#0a0b0c00", &RGBA{R: 10, G: 11, B: 12, A: 0}},
+		{"#11223380", &RGBA{R: 0x11, G: 0x22, B: 0x33, A: 128.0 / 255.0}},
+		{"#abc", &RGBA{R: 0xaa, G: 0xbb, B: 0xcc, A: 1}},
+		{"#abc0", &RGBA{R: 0xaa, G: 0xbb, B: 0xcc, A: 0}},
+		{"red", &RGBA{R: 255, G: 0, B: 0, A: 1}},
+		{"White", &RGBA{R: 255, G: 255, B: 255, A: 1}},
+		{"", nil},
+		{"0a0b0c", nil},
+		{"#0a0b0", nil},
+		{"#0a0b0c0d0e", nil},
+		{"#gggggg", nil},
+		{"notacolor", nil},
+	}
+	for _, tt := range tests {
+		if got := hexToRGBA(tt.hex); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("hexToRGBA(%q) = %+v, want %+v", tt.hex, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// projectConfigFile is the config of the project in its root directory
+const projectConfigFile = ".gonvim.toml"
+
+// projectTasksFile is the tasks of the project in its root directory
+var projectTasksFile = filepath.Join(".gonvim", "tasks.toml")
+
+// loadProjectEnv returns [env] of the config and of the contents of .gonvim.toml.
+// $NAME and ${NAME} in the values are expanded by the environment of goneovim.
+func loadProjectEnv(contents string) map[string]string {
+	values := make(map[string]string)
+	for name, value := range editor.config.Env {
+		values[name] = value
+	}
+	if contents != "" {
+		var project struct {
+			Env map[string]string
+		}
+		if _, err := toml.Decode(contents, &project); err == nil {
+			for name, value := range project.Env {
+				values[name] = value
+			}
+		}
+	}
+	env := make(map[string]string)
+	for name, value := range values {
+		env[name] = os.Expand(value, os.Getenv)
+	}
+
+	return env
+}
+
+// updateEnv sets the environment variables of the project in the cwd to the
+// nvim of the workspace, so that its terminals and jobs run with them, and
+// restores the ones of the previous project. .gonvim.toml and the tasks of the
+// project are read by nvim, which asks whether the user trusts them, and are
+// sent back by gonvim_project_files.
+func (w *Workspace) updateEnv(cwd string) {
+	if cwd == w.envDir || w.nvim == nil {
+		return
+	}
+	w.envDir = cwd
+	w.projectTasks = nil
+	paths := []string{filepath.Join(cwd, projectConfigFile), filepath.Join(cwd, projectTasksFile)}
+	exists := false
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			exists = true
+		}
+	}
+	if !exists || cwd == "" || editor.opts.Clean {
+		w.applyEnv(loadProjectEnv(""))
+		return
+	}
+	go w.execLua("require('gonvim').read_project_files(...)", cwd, paths)
+}
+
+// setProjectFiles handles gonvim_project_files; the directory of the project
+// and the contents of its .gonvim.toml and .gonvim/tasks.toml, empty if the
+// user does not trust them
+func (w *Workspace) setProjectFiles(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	dir, _ := args[0].(string)
+	files, _ := args[1].([]interface{})
+	// The cwd has changed while the user was asked
+	if dir != w.envDir || len(files) < 2 {
+		return
+	}
+	config, _ := files[0].(string)
+	w.applyEnv(loadProjectEnv(config))
+
+	tasks, _ := files[1].(string)
+	var project struct {
+		Tasks map[string]string
+	}
+	if _, err := toml.Decode(tasks, &project); err == nil {
+		w.projectTasks = project.Tasks
+	}
+}
+
+func (w *Workspace) applyEnv(env map[string]string) {
+	if len(env) == 0 && len(w.env) == 0 {
+		return
+	}
+	w.env = env
+	go w.execLua("require('gonvim').set_env(...)", env)
+}
+
+// environ returns the environment of the processes started by the GUI for the
+// workspace, the tasks and the terminal panel
+func (w *Workspace) environ() []string {
+	environ := os.Environ()
+	names := make([]string, 0, len(w.env))
+	for name := range w.env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// The last value of the duplicate names is used by exec
+	for _, name := range names {
+		environ = append(environ, name+"="+w.env[name])
+	}
+
+	return environ
+}
@@ -0,0 +1,427 @@
+package editor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Extensions are the processes in ~/.goneovim/extensions/<name>/, started with
+// the command of the extension.toml of the directory:
+//
+//	name = "todo"
+//	command = ["python3", "todo.py"]
+//
+// The name consists of letters, digits and underscores, and defaults to the
+// name of the directory.
+// The command runs in the directory of the extension, and talks with the GUI by
+// a JSON object per line on its stdin and stdout. The extension sends:
+//
+//	{"method": "register_panel", "id": "list", "title": "TODO"}
+//	{"method": "set_panel", "id": "list", "html": "<a href=\"1\">item</a>"}
+//	{"method": "set_segment", "id": "count", "text": "3 TODO", "tooltip": "..."}
+//	{"method": "register_command", "name": "TodoAdd"}
+//	{"method": "nvim_command", "command": "echo 'done'"}
+//	{"method": "notify", "level": "warn", "text": "..."}
+//
+// and receives:
+//
+//	{"event": "command", "name": "TodoAdd", "args": "..."}
+//	{"event": "link", "panel": "list", "url": "1"}
+//
+// The panels are the tabs of the extension panel at the bottom of the
+// workspaces, toggled by :GonvimExtensionPanel, and the segments are added to
+// the right of the statusline.
+type Extensions struct {
+	mu   sync.Mutex
+	list []*Extension
+
+	messages chan *extensionMessage
+}
+
+// Extension is a running extension
+type Extension struct {
+	name  string
+	dir   string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	mu    sync.Mutex
+
+	commands []string
+	panels   []*extensionPanel
+	segments []*extensionSegment
+
+	// The messages received from the extension, for the RPC status panel
+	stats *RPCStats
+}
+
+type extensionManifest struct {
+	Name    string
+	Command []string
+}
+
+type extensionMessage struct {
+	ext *Extension
+
+	Method  string `json:"method"`
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+	Tooltip string `json:"tooltip"`
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Level   string `json:"level"`
+}
+
+type extensionEvent struct {
+	Event string `json:"event"`
+	Name  string `json:"name,omitempty"`
+	Args  string `json:"args,omitempty"`
+	Panel string `json:"panel,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type extensionPanel struct {
+	id    string
+	title string
+	html  string
+}
+
+type extensionSegment struct {
+	id      string
+	text    string
+	tooltip string
+}
+
+var extensionCommandName = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// The name of an extension is put in the commands defined in nvim
+var extensionName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+func (e *Editor) initExtensions() {
+	e.extensions = &Extensions{
+		messages: make(chan *extensionMessage, 100),
+	}
+	e.signal.ConnectExtensionSignal(func() {
+		e.extensions.handle(<-e.extensions.messages)
+	})
+	// The extensions are not started by --clean
+	if e.opts.Clean {
+		return
+	}
+
+	dir := filepath.Join(e.homeDir, ".goneovim", "extensions")
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		var manifest extensionManifest
+		if _, err := toml.DecodeFile(filepath.Join(path, "extension.toml"), &manifest); err != nil {
+			continue
+		}
+		if manifest.Name == "" {
+			manifest.Name = entry.Name()
+		}
+		if !extensionName.MatchString(manifest.Name) {
+			e.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] The extension in %s has an invalid name: %s", path, manifest.Name))
+			continue
+		}
+		if len(manifest.Command) == 0 {
+			e.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] The extension %s has no command", manifest.Name))
+			continue
+		}
+		ext := &Extension{
+			name:  manifest.Name,
+			dir:   path,
+			stats: newRPCStats(),
+		}
+		if err := ext.start(manifest.Command); err != nil {
+			e.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] Failed to start the extension %s: %s", ext.name, err))
+			continue
+		}
+		e.extensions.mu.Lock()
+		e.extensions.list = append(e.extensions.list, ext)
+		e.extensions.mu.Unlock()
+	}
+
+	go func() {
+		<-e.stop
+		e.extensions.mu.Lock()
+		defer e.extensions.mu.Unlock()
+		for _, ext := range e.extensions.list {
+			ext.stdin.Close()
+			if ext.cmd.Process != nil {
+				ext.cmd.Process.Kill()
+			}
+		}
+	}()
+}
+
+func (ext *Extension) start(command []string) error {
+	ext.cmd = exec.Command(command[0], command[1:]...)
+	ext.cmd.Dir = ext.dir
+	stdin, err := ext.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := ext.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := ext.cmd.Start(); err != nil {
+		return err
+	}
+	ext.stdin = stdin
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			msg := &extensionMessage{}
+			if err := json.Unmarshal(scanner.Bytes(), msg); err != nil {
+				ext.stats.setError("invalid message: " + err.Error())
+				continue
+			}
+			ext.stats.count(msg.Method, 1)
+			msg.ext = ext
+			editor.extensions.messages <- msg
+			editor.signal.ExtensionSignal()
+		}
+		if err := ext.cmd.Wait(); err != nil {
+			ext.stats.setError("exited: " + err.Error())
+			editor.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] The extension %s exited: %s", ext.name, err))
+		}
+	}()
+	return nil
+}
+
+// send writes the event to the stdin of the extension
+func (ext *Extension) send(event *extensionEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	ext.stdin.Write(append(data, '\n'))
+}
+
+// handle runs a message of an extension in the GUI thread
+func (x *Extensions) handle(msg *extensionMessage) {
+	if msg == nil {
+		return
+	}
+	ext := msg.ext
+	switch msg.Method {
+	case "register_panel", "set_panel":
+		var panel *extensionPanel
+		for _, p := range ext.panels {
+			if p.id == msg.ID {
+				panel = p
+			}
+		}
+		if panel == nil {
+			panel = &extensionPanel{id: msg.ID, title: msg.ID}
+			ext.panels = append(ext.panels, panel)
+		}
+		if msg.Title != "" {
+			panel.title = msg.Title
+		}
+		if msg.Method == "set_panel" {
+			panel.html = msg.HTML
+		}
+		for _, ws := range editor.workspaces {
+			ws.extensions.setPanel(ext, panel)
+		}
+	case "set_segment":
+		var segment *extensionSegment
+		for _, s := range ext.segments {
+			if s.id == msg.ID {
+				segment = s
+			}
+		}
+		if segment == nil {
+			segment = &extensionSegment{id: msg.ID}
+			ext.segments = append(ext.segments, segment)
+		}
+		segment.text = msg.Text
+		segment.tooltip = msg.Tooltip
+		for _, ws := range editor.workspaces {
+			ws.statusline.setExtension(ext.name+"/"+segment.id, segment.text, segment.tooltip)
+		}
+	case "register_command":
+		if !extensionCommandName.MatchString(msg.Name) {
+			editor.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] The extension %s registered an invalid command name: %s", ext.name, msg.Name))
+			return
+		}
+		x.mu.Lock()
+		ext.commands = append(ext.commands, msg.Name)
+		x.mu.Unlock()
+		for _, ws := range editor.workspaces {
+			go ws.nvim.Command(ext.commandDefinition(msg.Name))
+		}
+	case "nvim_command":
+		if editor.active >= len(editor.workspaces) {
+			return
+		}
+		ws := editor.workspaces[editor.active]
+		go ws.nvim.Command(msg.Command)
+	case "notify":
+		level := NotifyInfo
+		if msg.Level == "warn" || msg.Level == "error" {
+			level = NotifyWarn
+		}
+		editor.pushNotification(level, -1, fmt.Sprintf("[%s] %s", ext.name, msg.Text))
+	}
+}
+
+func (ext *Extension) commandDefinition(name string) string {
+	return fmt.Sprintf(`command! -nargs=* %s call rpcnotify(0, "Gui", "gonvim_extension_command", "%s", "%s", <q-args>)`, name, ext.name, name)
+}
+
+// all returns the running extensions
+func (x *Extensions) all() []*Extension {
+	if x == nil {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return append([]*Extension(nil), x.list...)
+}
+
+// attach adds the panels and the segments of the extensions to a new workspace
+func (x *Extensions) attach(w *Workspace) {
+	for _, ext := range x.all() {
+		for _, panel := range ext.panels {
+			w.extensions.setPanel(ext, panel)
+		}
+		for _, segment := range ext.segments {
+			w.statusline.setExtension(ext.name+"/"+segment.id, segment.text, segment.tooltip)
+		}
+	}
+}
+
+// registerCommands defines the commands of the extensions in the nvim of the workspace
+func (x *Extensions) registerCommands(w *Workspace) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, ext := range x.list {
+		for _, name := range ext.commands {
+			w.nvim.Command(ext.commandDefinition(name))
+		}
+	}
+}
+
+// command handles gonvim_extension_command; the extension, the command and its arguments
+func (x *Extensions) command(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	name, _ := args[0].(string)
+	command, _ := args[1].(string)
+	cmdArgs, _ := args[2].(string)
+	for _, ext := range x.all() {
+		if ext.name == name {
+			go ext.send(&extensionEvent{Event: "command", Name: command, Args: cmdArgs})
+		}
+	}
+}
+
+// ExtensionPanel is the panel of a workspace which shows the panels of the
+// extensions as tabs
+type ExtensionPanel struct {
+	ws     *Workspace
+	widget *widgets.QWidget
+	tabs   *widgets.QTabWidget
+
+	// The pages by the extension and the id of the panel
+	pages map[string]*widgets.QTextBrowser
+}
+
+func newExtensionPanel(ws *Workspace) *ExtensionPanel {
+	x := &ExtensionPanel{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		tabs:   widgets.NewQTabWidget(nil),
+		pages:  make(map[string]*widgets.QTextBrowser),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 4, 6, 4)
+	layout.SetSpacing(4)
+	x.widget.SetLayout(layout)
+
+	x.tabs.SetDocumentMode(true)
+	x.tabs.SetFocusPolicy(core.Qt__NoFocus)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	closeButton.SetFocusPolicy(core.Qt__NoFocus)
+	closeButton.ConnectClicked(func(bool) {
+		x.widget.Hide()
+	})
+	x.tabs.SetCornerWidget(closeButton, core.Qt__TopRightCorner)
+	layout.AddWidget(x.tabs, 1, 0)
+
+	x.widget.SetFixedHeight(editor.height / 4)
+	x.widget.Hide()
+
+	return x
+}
+
+func (x *ExtensionPanel) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	x.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QTextBrowser { border: 0px; background-color: %s; }
+	QTabBar::tab { padding: 4px 10px; border: 0px; }
+	QTabBar::tab:selected { background-color: %s; }
+	QPushButton { border: 1px solid %s; padding: 2px 8px; }
+	QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.bg.String(), editor.colors.selectedBg.String(), editor.colors.inactiveFg.String(), editor.colors.selectedBg.String()))
+}
+
+// toggle shows or hides the panel; it is not shown until an extension registers a panel
+func (x *ExtensionPanel) toggle() {
+	if x.widget.IsVisible() || len(x.pages) == 0 {
+		x.widget.Hide()
+		return
+	}
+	x.widget.Show()
+}
+
+func (x *ExtensionPanel) setPanel(ext *Extension, panel *extensionPanel) {
+	key := ext.name + "/" + panel.id
+	page, ok := x.pages[key]
+	if !ok {
+		page = widgets.NewQTextBrowser(nil)
+		page.SetOpenLinks(false)
+		page.SetFocusPolicy(core.Qt__NoFocus)
+		id := panel.id
+		page.ConnectAnchorClicked(func(url *core.QUrl) {
+			go ext.send(&extensionEvent{Event: "link", Panel: id, URL: url.ToString(core.QUrl__None)})
+		})
+		x.pages[key] = page
+		x.tabs.AddTab(page, panel.title)
+	}
+	x.tabs.SetTabText(x.tabs.IndexOf(page), panel.title)
+	page.SetHtml(panel.html)
+}
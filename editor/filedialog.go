@@ -0,0 +1,46 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/therecipe/qt/widgets"
+)
+
+// openFileDialog shows the native file dialog and edits the chosen file, by
+// :GonvimOpen. :browse is not hooked; nvim is built without the browse feature
+// and does not let a UI provide the dialog of :browse {command}.
+func (w *Workspace) openFileDialog() {
+	path := widgets.QFileDialog_GetOpenFileName(editor.window, "Open", w.cwd, "", "", 0)
+	if path == "" {
+		return
+	}
+	go w.nvim.Command(fmt.Sprintf("execute 'edit ' . fnameescape('%s')", escapeVimString(path)))
+}
+
+// openFolderDialog shows the native directory dialog and opens the chosen one as a workspace
+func (w *Workspace) openFolderDialog() {
+	path := widgets.QFileDialog_GetExistingDirectory(editor.window, "Open Folder", w.cwd, widgets.QFileDialog__ShowDirsOnly)
+	if path == "" {
+		return
+	}
+	editor.openFolder(path)
+}
+
+// saveAsFileDialog shows the native save dialog and saves the current buffer as the chosen path
+func (w *Workspace) saveAsFileDialog() {
+	dir := w.cwd
+	if w.filepath != "" {
+		dir = w.filepath
+	}
+	path := widgets.QFileDialog_GetSaveFileName(editor.window, "Save As", dir, "", "", 0)
+	if path == "" {
+		return
+	}
+	go w.nvim.Command(fmt.Sprintf("execute 'saveas! ' . fnameescape('%s')", escapeVimString(path)))
+}
+
+// escapeVimString escapes the string to be put in a single-quoted vim string
+func escapeVimString(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
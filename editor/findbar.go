@@ -0,0 +1,256 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// FindBar is the bar of find and replace, opened by :GonvimFind and
+// :GonvimReplace, or Ctrl+F / Ctrl+H with findKeys. It drives the search of
+// nvim, highlighted by 'hlsearch', and :substitute, previewed by 'inccommand'
+// while the replacement is typed.
+type FindBar struct {
+	ws         *Workspace
+	widget     *widgets.QWidget
+	find       *widgets.QLineEdit
+	replace    *widgets.QLineEdit
+	regex      *widgets.QToolButton
+	matchCase  *widgets.QToolButton
+	word       *widgets.QToolButton
+	count      *widgets.QLabel
+	replaceRow *widgets.QWidget
+
+	// The substitution is shown in the cmdline of nvim for 'inccommand'
+	previewing bool
+}
+
+func newFindBar(ws *Workspace) *FindBar {
+	b := &FindBar{
+		ws:         ws,
+		widget:     widgets.NewQWidget(nil, 0),
+		find:       widgets.NewQLineEdit(nil),
+		replace:    widgets.NewQLineEdit(nil),
+		regex:      widgets.NewQToolButton(nil),
+		matchCase:  widgets.NewQToolButton(nil),
+		word:       widgets.NewQToolButton(nil),
+		count:      widgets.NewQLabel(nil, 0),
+		replaceRow: widgets.NewQWidget(nil, 0),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(8, 4, 8, 4)
+	layout.SetSpacing(4)
+	b.widget.SetLayout(layout)
+
+	button := func(text, tooltip string, clicked func()) *widgets.QPushButton {
+		button := widgets.NewQPushButton2(text, nil)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.SetToolTip(tooltip)
+		button.ConnectClicked(func(bool) {
+			clicked()
+		})
+		return button
+	}
+
+	findRow := widgets.NewQHBoxLayout()
+	findRow.SetSpacing(4)
+	b.find.SetPlaceholderText("Find")
+	b.find.ConnectTextChanged(func(string) {
+		b.search("c")
+	})
+	b.find.ConnectKeyPressEvent(b.keyPress(b.find))
+	findRow.AddWidget(b.find, 1, 0)
+	for _, option := range []struct {
+		button  *widgets.QToolButton
+		text    string
+		tooltip string
+	}{
+		{b.regex, ".*", "Regular expression (Vim)"},
+		{b.matchCase, "Aa", "Match case"},
+		{b.word, "ab", "Match whole word"},
+	} {
+		option.button.SetText(option.text)
+		option.button.SetToolTip(option.tooltip)
+		option.button.SetCheckable(true)
+		option.button.SetFocusPolicy(core.Qt__NoFocus)
+		option.button.ConnectToggled(func(bool) {
+			b.search("c")
+		})
+		findRow.AddWidget(option.button, 0, 0)
+	}
+	findRow.AddWidget(b.count, 0, 0)
+	findRow.AddWidget(button("↑", "Previous match (Shift+Enter)", func() { b.search("N") }), 0, 0)
+	findRow.AddWidget(button("↓", "Next match (Enter)", func() { b.search("n") }), 0, 0)
+	findRow.AddWidget(button("×", "Close (Esc)", b.close), 0, 0)
+	layout.AddLayout(findRow, 0)
+
+	replaceRow := widgets.NewQHBoxLayout()
+	replaceRow.SetContentsMargins(0, 0, 0, 0)
+	replaceRow.SetSpacing(4)
+	b.replaceRow.SetLayout(replaceRow)
+	b.replace.SetPlaceholderText("Replace")
+	b.replace.ConnectTextChanged(func(string) {
+		b.preview()
+	})
+	b.replace.ConnectKeyPressEvent(b.keyPress(b.replace))
+	replaceRow.AddWidget(b.replace, 1, 0)
+	replaceRow.AddWidget(button("Replace", "Replace the match (Enter)", func() { b.doReplace(false) }), 0, 0)
+	replaceRow.AddWidget(button("Replace All", "Replace all the matches (Ctrl+Enter)", func() { b.doReplace(true) }), 0, 0)
+	layout.AddWidget(b.replaceRow, 0, 0)
+
+	b.widget.Hide()
+
+	return b
+}
+
+func (b *FindBar) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	b.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QLineEdit { border: 1px solid %s; background-color: %s; padding: 1px 4px; }
+	QToolButton, QPushButton { border: 0px; padding: 2px 6px; }
+	QToolButton:checked { color: %s; background-color: %s; }
+	QToolButton:hover, QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.inactiveFg.String(), editor.colors.widgetInputArea.String(),
+		editor.colors.fg.String(), editor.colors.selectedBg.String(), editor.colors.selectedBg.String()))
+}
+
+// keyPress handles Enter (next / replace), Shift+Enter (previous), Ctrl+Enter
+// (replace all) and Esc in the line edits
+func (b *FindBar) keyPress(edit *widgets.QLineEdit) func(*gui.QKeyEvent) {
+	return func(event *gui.QKeyEvent) {
+		mod := event.Modifiers()
+		switch core.Qt__Key(event.Key()) {
+		case core.Qt__Key_Escape:
+			b.close()
+		case core.Qt__Key_Return, core.Qt__Key_Enter:
+			switch {
+			case edit == b.replace && mod&core.Qt__ControlModifier > 0:
+				b.doReplace(true)
+			case edit == b.replace:
+				b.doReplace(false)
+			case mod&core.Qt__ShiftModifier > 0:
+				b.search("N")
+			default:
+				b.search("n")
+			}
+		default:
+			edit.KeyPressEventDefault(event)
+		}
+	}
+}
+
+// open shows the bar, with the row of the replacement for :GonvimReplace
+func (b *FindBar) open(replace bool) {
+	b.replaceRow.SetVisible(replace)
+	if !b.widget.IsVisible() {
+		b.widget.Show()
+		b.ws.updateSize()
+	}
+	b.find.SetFocus2()
+	b.find.SelectAll()
+	if b.find.Text() != "" {
+		b.search("c")
+	}
+}
+
+func (b *FindBar) close() {
+	b.endPreview()
+	b.widget.Hide()
+	b.ws.updateSize()
+	editor.wsWidget.SetFocus2()
+}
+
+// search searches the text; move is "c" to match at the cursor as well, "n"
+// for the next match, or "N" for the previous one
+func (b *FindBar) search(move string) {
+	b.endPreview()
+	text := b.find.Text()
+	regex, matchCase, word := b.regex.IsChecked(), b.matchCase.IsChecked(), b.word.IsChecked()
+	go b.ws.execLua("require('gonvim').find(...)", text, regex, matchCase, word, move)
+}
+
+// preview shows the substitution in the cmdline of nvim, which 'inccommand' previews
+func (b *FindBar) preview() {
+	text := b.find.Text()
+	if text == "" {
+		return
+	}
+	b.previewing = true
+	replace := b.replace.Text()
+	regex, matchCase, word := b.regex.IsChecked(), b.matchCase.IsChecked(), b.word.IsChecked()
+	go b.ws.execLua("require('gonvim').find_preview(...)", text, replace, regex, matchCase, word)
+}
+
+func (b *FindBar) endPreview() {
+	if !b.previewing {
+		return
+	}
+	b.previewing = false
+	go b.ws.execLua("require('gonvim').find_preview_end()")
+}
+
+// doReplace replaces the match at the cursor and goes to the next one, or all the matches
+func (b *FindBar) doReplace(all bool) {
+	b.previewing = false
+	text := b.find.Text()
+	if text == "" {
+		return
+	}
+	replace := b.replace.Text()
+	regex, matchCase, word := b.regex.IsChecked(), b.matchCase.IsChecked(), b.word.IsChecked()
+	go b.ws.execLua("require('gonvim').find_replace(...)", text, replace, regex, matchCase, word, all)
+}
+
+// handle handles gonvim_find; the index of the match at the cursor, the number
+// of the matches, and whether the pattern is invalid
+func (b *FindBar) handle(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	current := util.ReflectToInt(args[0])
+	total := util.ReflectToInt(args[1])
+	invalid := util.IsTrue(args[2])
+
+	if invalid {
+		b.find.SetStyleSheet(fmt.Sprintf(" QLineEdit { border: 1px solid %s; }", newRGBA(204, 62, 68, 1).String()))
+	} else {
+		b.find.SetStyleSheet("")
+	}
+	switch {
+	case invalid:
+		b.count.SetText("Invalid pattern")
+	case b.find.Text() == "":
+		b.count.SetText("")
+	case total == 0:
+		b.count.SetText("No results")
+	default:
+		b.count.SetText(fmt.Sprintf("%d of %d", current, total))
+	}
+}
+
+// isFindKey returns "find" for Ctrl+F, "replace" for Ctrl+H (Cmd on macOS),
+// or "" for the other keys
+func isFindKey(event *gui.QKeyEvent) string {
+	if !editor.config.Editor.FindKeys {
+		return ""
+	}
+	mod := event.Modifiers() &^ core.Qt__KeypadModifier
+	if mod != core.Qt__ControlModifier {
+		return ""
+	}
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_F:
+		return "find"
+	case core.Qt__Key_H:
+		return "replace"
+	}
+	return ""
+}
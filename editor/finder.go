@@ -29,9 +29,7 @@ func (f *Finder) hide() {
 	// }
 	win, ok := f.ws.screen.getWindow(f.ws.cursor.gridid)
 	if ok {
-		f.ws.cursor.widget.SetParent(win.widget)
-		f.ws.cursor.widget.Hide()
-		f.ws.cursor.widget.Show()
+		f.ws.cursor.setParentWindow(win)
 	}
 }
 
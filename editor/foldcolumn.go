@@ -0,0 +1,299 @@
+package editor
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// The folds starting on a row, sent by require("gonvim").folds()
+const (
+	foldNone = iota
+	foldOpen
+	foldClosed
+)
+
+// FoldPreview shows the contents of a closed fold when the mouse rests on it
+type FoldPreview struct {
+	ws     *Workspace
+	widget *widgets.QLabel
+	timer  *core.QTimer
+
+	win *Window
+	row int
+}
+
+func newFoldPreview(ws *Workspace) *FoldPreview {
+	f := &FoldPreview{
+		ws:     ws,
+		widget: widgets.NewQLabel(ws.screen.widget, 0),
+		timer:  core.NewQTimer(nil),
+		row:    -1,
+	}
+	f.widget.SetTextFormat(core.Qt__RichText)
+	f.widget.SetContentsMargins(8, 6, 8, 6)
+	f.widget.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+	f.widget.SetGraphicsEffect(util.DropShadow(-2, 6, 40, 200))
+	f.widget.Hide()
+	if editor.config.Editor.SeparatorDrag {
+		// For the mouse cursor on hover
+		ws.screen.widget.SetMouseTracking(true)
+	}
+	f.timer.SetSingleShot(true)
+	f.timer.ConnectTimeout(func() {
+		if f.win == nil {
+			return
+		}
+		go f.ws.execLua("require('gonvim').fold_preview(...)", f.win.id, f.row)
+	})
+
+	return f
+}
+
+// setFolds handles gonvim_folds
+func (s *Screen) setFolds(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	items, _ := args[0].([]interface{})
+	folds := make(map[int][]int)
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rows, _ := item["rows"].([]interface{})
+		kinds := make([]int, len(rows))
+		for j, r := range rows {
+			kinds[j] = util.ReflectToInt(r)
+		}
+		folds[util.ReflectToInt(item["win"])] = kinds
+	}
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		if kinds, ok := folds[int(win.id)]; ok {
+			win.folds = kinds
+			if win.widget != nil {
+				win.widget.Update()
+			}
+		}
+		return true
+	})
+	s.updateMouseTracking()
+}
+
+// updateMouseTracking tracks the mouse for the preview of the closed folds
+// only while a window shows the fold column
+func (s *Screen) updateMouseTracking() {
+	if editor.config.Editor.SeparatorDrag {
+		return
+	}
+	tracking := false
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if ok && win != nil && len(win.folds) > 0 && win.isShown() {
+			tracking = true
+			return false
+		}
+		return true
+	})
+	if s.widget.HasMouseTracking() == tracking {
+		return
+	}
+	s.widget.SetMouseTracking(tracking)
+	if !tracking {
+		s.ws.foldPreview.hide()
+	}
+}
+
+func (w *Window) foldAt(row int) int {
+	if row < 0 || row >= len(w.folds) {
+		return foldNone
+	}
+	return w.folds[row]
+}
+
+// foldColumnAt reports whether the cell is the first cell of the fold column of nvim
+func (w *Window) foldColumnAt(x, y int) bool {
+	if y < 0 || y >= len(w.content) || x < 0 || x >= len(w.content[y]) {
+		return false
+	}
+	cell := w.content[y][x]
+	if cell == nil || cell.highlight.hlName != "FoldColumn" {
+		return false
+	}
+	if x > 0 {
+		prev := w.content[y][x-1]
+		if prev != nil && prev.highlight.hlName == "FoldColumn" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// drawFoldColumn draws the open and closed folds in the fold column as chevrons
+func (w *Window) drawFoldColumn(p *gui.QPainter, y int, col int, cols int) {
+	fold := w.foldAt(y)
+	if fold == foldNone || y >= len(w.content) {
+		return
+	}
+	font := w.getFont()
+	for x := col; x <= col+cols && x < len(w.content[y]); x++ {
+		if !w.foldColumnAt(x, y) {
+			continue
+		}
+		cell := w.content[y][x]
+		left := float64(x) * font.truewidth
+		top := float64(y * font.lineHeight)
+		bg := cell.highlight.background
+		if bg == nil {
+			bg = w.background
+		}
+		if bg == nil {
+			bg = editor.colors.bg
+		}
+		p.FillRect4(core.NewQRectF4(left, top, font.truewidth, float64(font.lineHeight)), bg.QColor())
+		fg := cell.highlight.foreground
+		if fg == nil {
+			fg = editor.colors.fg
+		}
+
+		size := font.truewidth * 0.6
+		cx := left + font.truewidth/2
+		cy := top + float64(font.lineHeight)/2
+		pen := gui.NewQPen3(fg.QColor())
+		pen.SetWidthF(1.5)
+		p.Save()
+		p.SetRenderHint(gui.QPainter__Antialiasing, true)
+		p.SetPen(pen)
+		path := gui.NewQPainterPath()
+		if fold == foldClosed {
+			path.MoveTo2(cx-size/4, cy-size/2)
+			path.LineTo2(cx+size/4, cy)
+			path.LineTo2(cx-size/4, cy+size/2)
+		} else {
+			path.MoveTo2(cx-size/2, cy-size/4)
+			path.LineTo2(cx, cy+size/4)
+			path.LineTo2(cx+size/2, cy-size/4)
+		}
+		p.DrawPath(path)
+		p.Restore()
+		return
+	}
+}
+
+// windowAt returns the window and the cell at the position of the screen widget
+func (s *Screen) windowAt(x, y int) (*Window, int, int) {
+	var found *Window
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil || win.grid == 1 || win.isMsgGrid || !win.isShown() || win.widget == nil {
+			return true
+		}
+		if win.widget.Geometry().Contains3(x, y) {
+			found = win
+			if win.isFloatWin {
+				return false
+			}
+		}
+		return true
+	})
+	if found == nil {
+		return nil, 0, 0
+	}
+	font := found.getFont()
+	col := int(float64(x-found.widget.X()) / font.truewidth)
+	row := (y - found.widget.Y()) / font.lineHeight
+
+	return found, col, row
+}
+
+// foldMouseEvent toggles the fold on a click on the fold column, and shows the
+// preview of a closed fold on hover. It reports whether the event is consumed.
+func (s *Screen) foldMouseEvent(event *gui.QMouseEvent) bool {
+	if !editor.config.Editor.FoldColumn {
+		return false
+	}
+	win, col, row := s.windowAt(event.X(), event.Y())
+	preview := s.ws.foldPreview
+	switch event.Type() {
+	case core.QEvent__MouseMove:
+		if event.Buttons() != core.Qt__NoButton {
+			return false
+		}
+		if win == nil || win.foldAt(row) != foldClosed {
+			preview.hide()
+			return false
+		}
+		if preview.win != win || preview.row != row {
+			preview.hide()
+			preview.win = win
+			preview.row = row
+			preview.timer.Start(500)
+		}
+	case core.QEvent__MouseButtonPress:
+		preview.hide()
+		if event.Button() != core.Qt__LeftButton || win == nil || win.foldAt(row) == foldNone || !win.foldColumnAt(col, row) {
+			return false
+		}
+		s.foldClicked = true
+		go s.ws.execLua("require('gonvim').toggle_fold(...)", win.id, row)
+		return true
+	case core.QEvent__MouseButtonRelease:
+		if s.foldClicked {
+			s.foldClicked = false
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *FoldPreview) hide() {
+	f.timer.Stop()
+	f.win = nil
+	f.row = -1
+	f.widget.Hide()
+}
+
+// show handles gonvim_fold_preview; window, row, lines and the number of the folded lines
+func (f *FoldPreview) show(args []interface{}) {
+	if len(args) < 4 || f.win == nil {
+		return
+	}
+	if util.ReflectToInt(args[0]) != int(f.win.id) || util.ReflectToInt(args[1]) != f.row {
+		return
+	}
+	items, _ := args[2].([]interface{})
+	var lines []string
+	for _, i := range items {
+		line, _ := i.(string)
+		lines = append(lines, html.EscapeString(line))
+	}
+	total := util.ReflectToInt(args[3])
+	if total > len(lines) {
+		lines = append(lines, fmt.Sprintf("<i>... %d more lines</i>", total-len(lines)))
+	}
+
+	font := f.win.getFont()
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	f.widget.SetStyleSheet(fmt.Sprintf(" * { color: %s; background-color: %s; border: 1px solid %s; }", fg.String(), bg.String(), editor.colors.inactiveFg.String()))
+	f.widget.SetFont(font.fontNew)
+	f.widget.SetText("<pre>" + strings.Join(lines, "\n") + "</pre>")
+	f.widget.AdjustSize()
+	x := f.win.widget.X() + int(font.truewidth*2)
+	y := f.win.widget.Y() + (f.row+1)*font.lineHeight
+	f.widget.Move2(x, y)
+	f.widget.Show()
+	f.widget.Raise()
+}
@@ -0,0 +1,99 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// openFolder opens the directory as a new workspace, or switches to the
+// workspace whose cwd is already the directory, and shows the file explorer
+// of the sidebar rooted at it
+func (e *Editor) openFolder(path string) {
+	dir, ok := folderPath(path)
+	if !ok {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] Not a directory: "+path)
+		return
+	}
+
+	for i, ws := range e.workspaces {
+		if ws.cwd == dir {
+			e.workspaceSwitch(i + 1)
+			e.showFolder()
+			go ws.nvim.Call("rpcnotify", nil, 0, "GonvimFiler", "redraw")
+			return
+		}
+	}
+
+	ws := e.workspaces[e.active]
+	if ws.uiRemoteAttached || len(e.workspaces) >= WorkspaceLen {
+		// Workspaces can not be added, open it in the current one instead
+		go ws.nvim.Command(fmt.Sprintf("execute 'tchdir ' . fnameescape('%s')", escapeVimString(dir)))
+		e.showFolder()
+		return
+	}
+	e.workspaceNew()
+	e.workspaces[e.active].folder = dir
+	e.addRecentWorkspace(dir)
+}
+
+// startFolder opens the directory of --folder in the workspace of the startup,
+// or in a new one after the restored workspaces
+func (e *Editor) startFolder(restored bool) {
+	dir, ok := folderPath(e.opts.Folder)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Not a directory: "+e.opts.Folder)
+		return
+	}
+	if restored && len(e.workspaces) < WorkspaceLen {
+		ws, err := newWorkspace("")
+		if err != nil {
+			return
+		}
+		e.workspaces = append(e.workspaces, ws)
+		e.active = len(e.workspaces) - 1
+	}
+	e.workspaces[e.active].folder = dir
+}
+
+// changeToFolder changes the directory of nvim to the one of openFolder when it has started
+func (w *Workspace) changeToFolder() {
+	dir := w.folder
+	w.folder = ""
+	go func() {
+		w.nvim.Command(fmt.Sprintf("execute 'cd ' . fnameescape('%s')", escapeVimString(dir)))
+		w.nvim.Call("rpcnotify", nil, 0, "GonvimFiler", "redraw")
+	}()
+	if w == editor.workspaces[editor.active] {
+		editor.showFolder()
+	}
+}
+
+// showFolder shows the file explorer of the active workspace in the sidebar
+func (e *Editor) showFolder() {
+	if e.wsSide == nil {
+		return
+	}
+	e.wsSide.show()
+	e.workspaces[e.active].sideItem().openContent()
+}
+
+// folderPath returns the absolute path of the directory
+func folderPath(path string) (string, bool) {
+	dir, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// expandHome expands ~ at the head of the path
+func expandHome(path string) string {
+	if path == "~" || len(path) > 1 && path[:2] == "~/" {
+		return filepath.Join(editor.homeDir, path[1:])
+	}
+	return path
+}
@@ -0,0 +1,54 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/therecipe/qt/core"
+)
+
+func (e *Editor) windowGeometryPath() string {
+	name := "windowgeometry"
+	if e.opts.Profile != "" {
+		name = name + "-" + e.opts.Profile
+	}
+	if e.opts.Window > 0 {
+		name = fmt.Sprintf("%s-window%d", name, e.opts.Window)
+	}
+	return filepath.Join(e.homeDir, ".goneovim", name)
+}
+
+// saveWindowGeometry saves the window size, position, screen and maximized/fullscreen state
+func (e *Editor) saveWindowGeometry() {
+	if !e.config.Editor.RestoreWindowGeometry || e.opts.Clean {
+		return
+	}
+	// Save the state before entering presentation mode
+	if e.presentation != nil && !e.presentation.fullscreen {
+		e.window.ShowNormal()
+	}
+	geometry := e.window.SaveGeometry()
+	path := e.windowGeometryPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	ioutil.WriteFile(path, []byte(geometry.ConstData()), 0644)
+}
+
+// restoreWindowGeometry restores the window geometry saved in the previous session
+func (e *Editor) restoreWindowGeometry() bool {
+	if !e.config.Editor.RestoreWindowGeometry || e.opts.Clean {
+		return false
+	}
+	data, err := ioutil.ReadFile(e.windowGeometryPath())
+	if err != nil || len(data) == 0 {
+		return false
+	}
+	if !e.window.RestoreGeometry(core.NewQByteArray2(string(data), len(data))) {
+		return false
+	}
+	e.width = e.window.Width()
+	e.height = e.window.Height()
+
+	return true
+}
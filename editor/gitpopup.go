@@ -0,0 +1,370 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// GitWorker runs the git commands of the workspace one by one, off the Qt thread
+type GitWorker struct {
+	jobs     chan func()
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newGitWorker() *GitWorker {
+	g := &GitWorker{
+		jobs: make(chan func(), 16),
+		done: make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case job := <-g.jobs:
+				job()
+			case <-g.done:
+				return
+			}
+		}
+	}()
+
+	return g
+}
+
+// post queues the job, or drops it if git is too busy or the worker is stopped
+func (g *GitWorker) post(job func()) {
+	select {
+	case <-g.done:
+		return
+	default:
+	}
+	select {
+	case g.jobs <- job:
+	default:
+	}
+}
+
+// stop ends the goroutine when the workspace is closed
+func (g *GitWorker) stop() {
+	g.stopOnce.Do(func() {
+		close(g.done)
+	})
+}
+
+func runGit(dir string, stdin []byte, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	util.PrepareRunProc(cmd)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		return "", fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+	}
+
+	return string(out), err
+}
+
+// gitHunk is a hunk of `git diff -U0` with the header of the file
+type gitHunk struct {
+	root   string
+	header string
+	lines  []string
+}
+
+func (h *gitHunk) patch() []byte {
+	return []byte(h.header + strings.Join(h.lines, "\n") + "\n")
+}
+
+// richText colors the lines by DiffAdd and DiffDelete of the colorscheme
+func (h *gitHunk) richText(s *Screen) string {
+	var text []string
+	for _, l := range h.lines[1:] {
+		color := editor.colors.widgetFg
+		if strings.HasPrefix(l, "+") {
+			color = s.groupColor("DiffAdd", newRGBA(80, 160, 80, 1))
+		} else if strings.HasPrefix(l, "-") {
+			color = s.groupColor("DiffDelete", newRGBA(200, 80, 80, 1))
+		}
+		text = append(text, fmt.Sprintf("<span style=\"color: %s;\">%s</span>", color.String(), html.EscapeString(l)))
+	}
+
+	return "<pre>" + strings.Join(text, "\n") + "</pre>"
+}
+
+// GitPopup shows the blame of a line, or a hunk with the buttons to stage or undo it
+type GitPopup struct {
+	ws      *Workspace
+	worker  *GitWorker
+	widget  *widgets.QWidget
+	label   *widgets.QLabel
+	buttons *widgets.QWidget
+	hunk    *gitHunk
+}
+
+func newGitPopup(ws *Workspace) *GitPopup {
+	g := &GitPopup{
+		ws:     ws,
+		worker: newGitWorker(),
+		widget: widgets.NewQWidget(nil, 0),
+	}
+	g.widget.SetContentsMargins(8, 6, 8, 6)
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(6)
+	g.widget.SetLayout(layout)
+
+	g.label = widgets.NewQLabel(nil, 0)
+	g.label.SetTextFormat(core.Qt__RichText)
+	layout.AddWidget(g.label, 0, 0)
+
+	g.buttons = widgets.NewQWidget(nil, 0)
+	buttonLayout := widgets.NewQHBoxLayout()
+	buttonLayout.SetContentsMargins(0, 0, 0, 0)
+	buttonLayout.AddStretch(1)
+	g.buttons.SetLayout(buttonLayout)
+	stage := widgets.NewQPushButton2("Stage", nil)
+	stage.SetFocusPolicy(core.Qt__NoFocus)
+	stage.ConnectClicked(func(bool) {
+		g.applyHunk(false)
+	})
+	undo := widgets.NewQPushButton2("Undo", nil)
+	undo.SetFocusPolicy(core.Qt__NoFocus)
+	undo.ConnectClicked(func(bool) {
+		g.applyHunk(true)
+	})
+	buttonLayout.AddWidget(stage, 0, 0)
+	buttonLayout.AddWidget(undo, 0, 0)
+	layout.AddWidget(g.buttons, 0, 0)
+
+	g.widget.SetParent(editor.wsWidget)
+	g.widget.SetGraphicsEffect(util.DropShadow(-2, 6, 40, 200))
+	g.widget.Hide()
+
+	return g
+}
+
+func (g *GitPopup) setColor() {
+	fg := editor.colors.widgetFg.String()
+	bg := editor.colors.widgetBg.String()
+	g.widget.SetStyleSheet(fmt.Sprintf(".QWidget { border: 1px solid %s; } QWidget { background-color: %s; } * { color: %s; }", bg, bg, fg))
+}
+
+// handle handles gonvim_git_blame and gonvim_git_hunk; file, line and modified
+func (g *GitPopup) handle(event string, args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	file, _ := args[0].(string)
+	line := util.ReflectToInt(args[1])
+	modified := len(args) > 2 && util.IsTrue(args[2])
+	if file == "" || strings.HasPrefix(file, "term://") {
+		return
+	}
+	// The lines of the file do not match the ones of the buffer
+	if event == "gonvim_git_blame" && modified {
+		g.show([]interface{}{"<i>Save the buffer to show the blame</i>", nil, true})
+		return
+	}
+	g.worker.post(func() {
+		var text string
+		var hunk *gitHunk
+		if event == "gonvim_git_blame" {
+			text = gitBlame(file, line)
+		} else {
+			hunk = gitHunkAt(file, line)
+		}
+		if text == "" && hunk == nil {
+			return
+		}
+		// Shown on the Qt thread
+		g.ws.guiUpdates <- []interface{}{"gonvim_git_popup", text, hunk, modified}
+		g.ws.signal.GuiSignal()
+	})
+}
+
+// show handles gonvim_git_popup posted by the worker
+func (g *GitPopup) show(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	text, _ := args[0].(string)
+	g.hunk, _ = args[1].(*gitHunk)
+	modified, _ := args[2].(bool)
+	if g.hunk != nil {
+		text = g.hunk.richText(g.ws.screen)
+		if modified {
+			text += "<i>Save the buffer to stage or undo the hunk</i>"
+		}
+	}
+	g.setColor()
+	g.label.SetText(text)
+	g.buttons.SetVisible(g.hunk != nil && !modified)
+	g.widget.AdjustSize()
+
+	x, y := g.ws.getPointInWidget(g.ws.screen.cursor[1], g.ws.screen.cursor[0]+1, g.ws.cursor.gridid)
+	g.widget.Move2(x, y)
+	g.widget.Show()
+	g.widget.Raise()
+}
+
+func (g *GitPopup) hide() {
+	if g == nil || !g.widget.IsVisible() {
+		return
+	}
+	g.widget.Hide()
+}
+
+func (g *GitPopup) applyHunk(undo bool) {
+	hunk := g.hunk
+	if hunk == nil {
+		return
+	}
+	g.hide()
+	g.worker.post(func() {
+		var err error
+		if undo {
+			_, err = runGit(hunk.root, hunk.patch(), "apply", "-R", "--unidiff-zero", "-")
+		} else {
+			_, err = runGit(hunk.root, hunk.patch(), "apply", "--cached", "--unidiff-zero", "-")
+		}
+		if err != nil {
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] git apply: "+err.Error())
+			return
+		}
+		if undo {
+			g.ws.nvim.Command("checktime")
+		}
+	})
+}
+
+// gitBlame formats `git blame --porcelain` of the line
+func gitBlame(file string, line int) string {
+	out, err := runGit(filepath.Dir(file), nil, "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filepath.Base(file))
+	if err != nil || out == "" {
+		return ""
+	}
+	var sha, author, summary string
+	var when time.Time
+	for i, l := range strings.Split(out, "\n") {
+		if i == 0 {
+			sha = strings.SplitN(l, " ", 2)[0]
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "author "):
+			author = strings.TrimPrefix(l, "author ")
+		case strings.HasPrefix(l, "author-time "):
+			t, _ := strconv.ParseInt(strings.TrimPrefix(l, "author-time "), 10, 64)
+			when = time.Unix(t, 0)
+		case strings.HasPrefix(l, "summary "):
+			summary = strings.TrimPrefix(l, "summary ")
+		}
+	}
+	if strings.Trim(sha, "0") == "" {
+		return "Not committed yet"
+	}
+	if len(sha) > 8 {
+		sha = sha[:8]
+	}
+
+	return fmt.Sprintf(
+		"<b>%s</b>  %s<br>%s  %s",
+		html.EscapeString(author),
+		when.Format("2006-01-02 15:04"),
+		sha,
+		html.EscapeString(summary),
+	)
+}
+
+// gitHunkAt finds the unstaged hunk of `git diff -U0` containing the line
+func gitHunkAt(file string, line int) *gitHunk {
+	dir := filepath.Dir(file)
+	root, err := runGit(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	out, err := runGit(dir, nil, "diff", "-U0", "--no-color", "--no-ext-diff", "--", filepath.Base(file))
+	if err != nil || out == "" {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	header := ""
+	i := 0
+	for ; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+		header += lines[i] + "\n"
+	}
+	for i < len(lines) {
+		start, count := parseHunkRange(lines[i])
+		hunk := []string{lines[i]}
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+			hunk = append(hunk, lines[i])
+		}
+		// A deletion has no line of its own, it is after the start
+		contains := line >= start && line < start+count
+		if count == 0 {
+			contains = line == start || line == start+1
+		}
+		if !contains {
+			continue
+		}
+
+		return &gitHunk{
+			root:   strings.TrimSpace(root),
+			header: header,
+			lines:  hunk,
+		}
+	}
+
+	return nil
+}
+
+// parseHunkRange returns the range of the new file of "@@ -a,b +c,d @@"
+func parseHunkRange(header string) (int, int) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+	parts := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)
+	start, _ := strconv.Atoi(parts[0])
+	count := 1
+	if len(parts) == 2 {
+		count, _ = strconv.Atoi(parts[1])
+	}
+
+	return start, count
+}
+
+// groupColor is the color of the highlight group of the UI, its background if it has
+func (s *Screen) groupColor(name string, fallback *RGBA) *RGBA {
+	id, ok := s.highlightGroup[name]
+	if !ok {
+		return fallback
+	}
+	hl, ok := s.highAttrDef[id]
+	if !ok || hl == nil {
+		return fallback
+	}
+	if hl.background != nil {
+		return hl.background
+	}
+	if hl.foreground != nil {
+		return hl.foreground
+	}
+
+	return fallback
+}
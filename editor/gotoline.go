@@ -0,0 +1,109 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+var (
+	// file:line[:col], also the line:col of the current buffer
+	jumpLocationPattern = regexp.MustCompile(`^(.*?):(\d+)(?:[:.](\d+))?:?$`)
+	// file(line[,col]), e.g. C# and MSBuild
+	jumpParenPattern = regexp.MustCompile(`^(.+?)\((\d+)(?:,\s*(\d+))?\)$`)
+	// File "file", line N, Python
+	jumpPythonPattern = regexp.MustCompile(`^File "(.+)", line (\d+)`)
+)
+
+// parseJumpLocation parses "line", "line:col", "file:line:col" and the
+// locations of the common stack traces; file is "" for the current buffer
+func parseJumpLocation(text string) (file string, line, col int, ok bool) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "at ")
+	if m := jumpPythonPattern.FindStringSubmatch(text); m != nil {
+		line, _ = strconv.Atoi(m[2])
+		return m[1], line, 0, true
+	}
+	m := jumpParenPattern.FindStringSubmatch(text)
+	if m == nil {
+		// "function (file:line:col)", JavaScript
+		if i := strings.LastIndex(text, "("); i >= 0 && strings.HasSuffix(text, ")") {
+			text = text[i+1 : len(text)-1]
+		}
+		text = strings.Trim(text, "[]<>'\"` ")
+		if text == "" {
+			return "", 0, 0, false
+		}
+		if n, err := strconv.Atoi(text); err == nil {
+			return "", n, 0, true
+		}
+		m = jumpLocationPattern.FindStringSubmatch(text)
+	}
+	if m == nil {
+		// A file without a line
+		return text, 0, 0, true
+	}
+	file = m[1]
+	line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		col, _ = strconv.Atoi(m[3])
+	}
+	// "12:3" is the line and the column of the current buffer
+	if n, err := strconv.Atoi(file); err == nil && m[3] == "" {
+		return "", n, line, true
+	}
+	return file, line, col, true
+}
+
+// isJumpFile reports whether the file of a location may be opened; the file
+// names with | or a newline are rather commands pasted by mistake
+func isJumpFile(file string) bool {
+	return !strings.ContainsAny(file, "|\r\n")
+}
+
+// gotoLine shows the dialog of :GonvimGotoLine, and jumps to the location
+func (w *Workspace) gotoLine() {
+	dialog := widgets.NewQInputDialog(editor.window, 0)
+	dialog.SetWindowTitle("Goneovim")
+	dialog.SetInputMode(widgets.QInputDialog__TextInput)
+	dialog.SetLabelText("Go to line[:col], or file:line:col")
+	// Fill in the location copied from a stack trace, which openPath escapes
+	if text := gui.QGuiApplication_Clipboard().Text(gui.QClipboard__Clipboard); !strings.Contains(text, "\n") {
+		if file, _, _, ok := parseJumpLocation(text); ok && file != "" && isJumpFile(file) && len(text) < 512 {
+			dialog.SetTextValue(strings.TrimSpace(text))
+		}
+	}
+	if dialog.Exec() != int(widgets.QDialog__Accepted) {
+		return
+	}
+	file, line, col, ok := parseJumpLocation(dialog.TextValue())
+	if !ok {
+		return
+	}
+	if file != "" {
+		if !isJumpFile(file) {
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] Not a file name: "+file)
+			return
+		}
+		editor.openPath(file, line, col)
+		return
+	}
+	if col < 1 {
+		col = 1
+	}
+	// Set the previous context mark to come back by ''
+	go w.nvim.Command(fmt.Sprintf(`call setpos("''", getpos(".")) | call cursor(%d, %d)`, line, col))
+}
+
+// isGotoLineKey reports whether the key event is Ctrl+G (Cmd on macOS)
+func isGotoLineKey(event *gui.QKeyEvent) bool {
+	if !editor.config.Editor.GotoLineKey {
+		return false
+	}
+	mod := event.Modifiers() &^ core.Qt__KeypadModifier
+	return core.Qt__Key(event.Key()) == core.Qt__Key_G && mod == core.Qt__ControlModifier
+}
@@ -0,0 +1,1918 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// The GUI scripting API
+//
+// Plugins drive the GUI with rpcnotify(0, "Gui", event, args...), or with the
+// Lua module returned by require("gonvim"), which wraps the same events.
+// g:gonvim_api_version is set to guiAPIVersion; events are only added in
+// a compatible way while the version stays the same.
+//
+//	gonvim_notify              message, level ("info" / "warn"), timeout (seconds, 0: sticky),
+//	                           buttons ([{"text": ..., "function": VimL function name, "args": [...]}]),
+//	                           options ({"sticky": bool, "countdown": bool, "pause_on_hover": bool})
+//	gonvim_notifications       shows the notification history
+//	finder                     rpcnotify(0, "GonvimFuzzy", "run", {options})
+//	gonvim_markdown_toggle     toggles the markdown preview
+//	gonvim_markdown_scroll_*   down, up, top, bottom, pagedown, pageup, halfpagedown, halfpageup
+//	side_open, side_close, side_toggle
+//	gonvim_workspace_new, gonvim_workspace_next, gonvim_workspace_previous
+//	gonvim_workspace_switch    index (1-based)
+//	gonvim_open_folder         directory to open as a workspace ("": choose by the dialog)
+//	Font                       guifont string, e.g. "Fira Code:h14"
+//	Linespace                  line space in pixels
+//	gonvim_grid_font           guifont string for the current window
+//	gonvim_window_font         window (0: the current one), guifont string ("": the font of the workspace)
+//	gonvim_zoom                "in", "out", "reset" or the zoom level of the workspace (10% per level)
+//	gonvim_theme               name of ~/.goneovim/themes/<name>.toml ("": the colors derived from nvim)
+//	gonvim_copy_clipboard      copies the unnamed register to the system clipboard
+//	gonvim_minimap_toggle, gonvim_fullscreen, gonvim_presentation, gonvim_zen, gonvim_always_on_top,
+//	gonvim_perf_hud, gonvim_typewriter, gonvim_latency
+//	gonvim_screenshot          grid only (bool), path ("": ~/Pictures/goneovim-<time>.png)
+//	gonvim_record              grid only (bool), seconds, path (.gif, or .webm with ffmpeg)
+//	gonvim_opacity             opacity (0.1 - 1.0)
+//	gonvim_snap                "left", "right", "top", "bottom", "top-left", "top-right", "bottom-left",
+//	                           "bottom-right", "maximize" or "restore"; snaps the window on its monitor
+//	gonvim_progress            progress of the taskbar / dock icon (0 - 100, negative to hide)
+//	gonvim_badge               text of the dock icon badge
+//	gonvim_ui_select           callback id, prompt, labels; used by require("gonvim").ui.select
+//	gonvim_ui_input            callback id, prompt, default; used by require("gonvim").ui.input
+//	gonvim_git_blame           file, line, modified; shows the git blame of the line of the saved file
+//	gonvim_git_hunk            file, line, modified; shows the hunk with the buttons to stage or undo it
+//	gonvim_terminal            toggles the terminal below the editor
+//	gonvim_task                name of the task to run ("": choose it from the menu)
+//	gonvim_quickfix_toggle     toggles the quickfix panel
+//	gonvim_quickfix            title, [{idx, filename, lnum, col, text, type, valid}, ...]; sent by require("gonvim").quickfix
+//	gonvim_breadcrumbs         win, path, path relative to the cwd, [{name, kind, line, col, siblings: [{name, kind, line, col}, ...]}, ...]
+//	gonvim_recording           the register of the macro being recorded ("": stopped)
+//	gonvim_snippet             win (0: none), current index, the number of the placeholders,
+//	                           [{index, row, col, width}, ...]; sent by require("gonvim").snippet
+//	gonvim_cursors             [{win, regions: [{row, col, width (0: a cursor), primary}, ...]}, ...]; the other windows
+//	                           have none; sent by require("gonvim").cursors
+//	gonvim_peek                win, path relative to the cwd, line; sent by require("gonvim").peek_definition
+//	gonvim_peek_close          win
+//	gonvim_spell               win, row, col, buf, line, byte column, the misspelled word, [suggestion, ...];
+//	                           sent by require("gonvim").spell_suggest
+//	gonvim_search              pattern ("": no highlighted search), current, total, incomplete
+//	                           (0, 1: timed out, 2: over maxcount) of searchcount(); sent by require("gonvim").search
+//	gonvim_viewport            path, {lnum, col, topline, leftcol}; the view of the buffer left
+//	gonvim_viewport_request    path, win; asks for the view of the buffer entered in the window
+//	gonvim_dashboard           whether the workspace is empty, whether to show the start screen anyway
+//	gonvim_indent_guides       [{win, leftcol, textoff, sw, rows: [indent, ...], scope: {col, first, last}}, ...]
+//	gonvim_folds               [{win, rows: [0 (none) / 1 (open) / 2 (closed), ...]}, ...]
+//	gonvim_goto_line           shows the dialog of line[:col] or file:line:col to jump to
+//	gonvim_find_bar            whether to show the replacement; opens the find bar
+//	gonvim_find                the index of the match at the cursor, the number of the matches, whether the pattern is invalid
+//	gonvim_activity            "terminal", "job" or "diagnostics"; the badge of the workspace while it is not shown
+//	gonvim_wraps               [{win, topline, botline, lines, textoff, rows: [rows continuing wrapped lines]}, ...]
+//	gonvim_delimiters          [{win, delimiters: [{row, col, depth, pair}, ...], guide: {col, first, last, depth}}, ...]
+//	gonvim_fold_preview        win, row, lines, the number of the folded lines
+//	gonvim_diff                &diff, [[lnum, group], ...] of the changed lines, line("$")
+//	gonvim_dap                 "start", "running", "end", "stack" {frames}, "scopes" {scopes},
+//	                           "variables" ref {variables}, "watch" expr value ref; used by require("gonvim").setup_dap
+//	gonvim_lsp_progress        token, kind ("begin" / "report" / "end"), title, message, percentage (-1: unknown)
+//	gonvim_startup_errors      the messages of the startup if there are errors in them, v:false (nvim has not exited)
+//	gonvim_rpc_channels        [{id, name, type, stream}, ...] of the RPC channels, the id of the channel of the GUI
+//	gonvim_project_files       the directory of the project, [contents of .gonvim.toml, of .gonvim/tasks.toml] ("": not trusted)
+const guiAPIVersion = 1
+
+const gonvimLuaModule = `
+local M = {}
+
+M.api_version = %d
+
+local function gui(event, ...)
+  return vim.api.nvim_call_function('rpcnotify', {0, 'Gui', event, ...})
+end
+
+-- The results of the events last sent by gui_changed
+local last_sent = {}
+
+-- Send the event only if the result differs from the one sent last time
+local function gui_changed(event, result)
+  local encoded = vim.inspect(result)
+  if last_sent[event] == encoded then
+    return
+  end
+  last_sent[event] = encoded
+  gui(event, result)
+end
+
+local later_timers = {}
+
+-- Call M[name] once after the autocmds fired in a row, e.g. by the keys typed
+-- in insert mode, have settled
+function M.later(name)
+  local timer = later_timers[name]
+  if not timer then
+    timer = vim.loop.new_timer()
+    later_timers[name] = timer
+  end
+  timer:stop()
+  timer:start(50, 0, vim.schedule_wrap(M[name]))
+end
+
+local callbacks = {}
+local callback_id = 0
+
+local function register(callback)
+  callback_id = callback_id + 1
+  callbacks[callback_id] = callback
+  return callback_id
+end
+
+function M._ui_callback(id, ...)
+  local callback = callbacks[id]
+  callbacks[id] = nil
+  if callback then
+    callback(...)
+  end
+end
+
+-- buttons: { { text = 'Update', callback = function() ... end }, ... }
+-- callback may also be the name of a VimL function, called with args
+-- opts: { sticky = bool, countdown = bool, pause_on_hover = bool }
+function M.notify(message, level, timeout, buttons, opts)
+  local items = {}
+  for i, button in ipairs(buttons or {}) do
+    local item = { text = button.text, args = button.args or {} }
+    if type(button.callback) == 'function' then
+      item.id = register(button.callback)
+    else
+      item['function'] = button.callback
+    end
+    items[i] = item
+  end
+  gui('gonvim_notify', message, level or 'info', timeout or -1, items, opts or vim.empty_dict())
+end
+
+function M.notification_history()
+  gui('gonvim_notifications')
+end
+
+-- Forward vim.notify() to the notifications of the GUI
+function M.setup_notify()
+  vim.notify = function(message, level, opts)
+    local levels = vim.log.levels
+    level = level or levels.INFO
+    if level == levels.OFF then
+      return
+    end
+    local name = 'info'
+    if level >= levels.ERROR then
+      name = 'error'
+    elseif level >= levels.WARN then
+      name = 'warn'
+    end
+    if type(opts) == 'table' and opts.title then
+      message = '[' .. opts.title .. '] ' .. message
+    end
+    M.notify(message, name, -1)
+  end
+end
+
+M.finder = {
+  run = function(options)
+    vim.api.nvim_call_function('rpcnotify', {0, 'GonvimFuzzy', 'run', options})
+  end,
+}
+
+M.markdown = {
+  toggle = function() gui('gonvim_markdown_toggle') end,
+  scroll_down = function() gui('gonvim_markdown_scroll_down') end,
+  scroll_up = function() gui('gonvim_markdown_scroll_up') end,
+  scroll_top = function() gui('gonvim_markdown_scroll_top') end,
+  scroll_bottom = function() gui('gonvim_markdown_scroll_bottom') end,
+  scroll_page_down = function() gui('gonvim_markdown_scroll_pagedown') end,
+  scroll_page_up = function() gui('gonvim_markdown_scroll_pageup') end,
+}
+
+M.sidebar = {
+  show = function() gui('side_open') end,
+  hide = function() gui('side_close') end,
+  toggle = function() gui('side_toggle') end,
+}
+
+M.workspace = {
+  new = function() gui('gonvim_workspace_new') end,
+  next = function() gui('gonvim_workspace_next') end,
+  previous = function() gui('gonvim_workspace_previous') end,
+  switch = function(index) gui('gonvim_workspace_switch', index) end,
+}
+
+M.font = {
+  set = function(font) gui('Font', font) end,
+  linespace = function(space) gui('Linespace', space) end,
+  set_window = function(font) gui('gonvim_grid_font', font) end,
+  set_win = function(win, font) gui('gonvim_window_font', win or 0, font or '') end,
+  set_filetype = function(filetype, font) M.set_filetype_font(filetype, font) end,
+}
+
+M.clipboard = {
+  copy = function() gui('gonvim_copy_clipboard') end,
+}
+
+M.window = {
+  fullscreen = function() gui('gonvim_fullscreen') end,
+  presentation = function() gui('gonvim_presentation') end,
+  zen = function() gui('gonvim_zen') end,
+  always_on_top = function() gui('gonvim_always_on_top') end,
+  opacity = function(opacity) gui('gonvim_opacity', opacity) end,
+  progress = function(progress) gui('gonvim_progress', progress) end,
+  badge = function(text) gui('gonvim_badge', text or '') end,
+  zoom = function(zoom) gui('gonvim_zoom', tostring(zoom or 'in')) end,
+  snap = function(area) gui('gonvim_snap', area) end,
+}
+
+M.theme = {
+  set = function(name) gui('gonvim_theme', name or '') end,
+}
+
+M.minimap = {
+  toggle = function() gui('gonvim_minimap_toggle') end,
+}
+
+M.ui = {
+  select = function(items, opts, on_choice)
+    opts = opts or {}
+    local format_item = opts.format_item or tostring
+    local labels = {}
+    for i, item in ipairs(items) do
+      labels[i] = format_item(item)
+    end
+    local id = register(function(index)
+      if index == nil or items[index] == nil then
+        on_choice(nil, nil)
+      else
+        on_choice(items[index], index)
+      end
+    end)
+    gui('gonvim_ui_select', id, opts.prompt or 'Select one of:', labels)
+  end,
+  input = function(opts, on_confirm)
+    opts = opts or {}
+    local id = register(on_confirm)
+    gui('gonvim_ui_input', id, opts.prompt or '', opts.default or '')
+  end,
+}
+
+-- Use the GUI dialogs for vim.ui.select and vim.ui.input
+function M.setup_ui()
+  vim.ui.select = M.ui.select
+  vim.ui.input = M.ui.input
+end
+
+-- Forward the progress of the language servers to the notifications
+function M.setup_lsp_progress()
+  if vim.api.nvim_create_autocmd == nil then
+    return
+  end
+  local group = vim.api.nvim_create_augroup('GonvimAuLspProgress', { clear = true })
+  local ok = pcall(vim.api.nvim_create_autocmd, 'LspProgress', {
+    group = group,
+    callback = function(ev)
+      local params = ev.data and ev.data.params
+      if not params or type(params.value) ~= 'table' then
+        return
+      end
+      local value = params.value
+      gui('gonvim_lsp_progress', tostring(ev.data.client_id) .. ':' .. tostring(params.token),
+        value.kind or 'report', value.title or '', value.message or '', value.percentage or -1)
+    end,
+  })
+  if ok then
+    return
+  end
+  -- nvim 0.9 and earlier
+  vim.api.nvim_create_autocmd('User', {
+    group = group,
+    pattern = 'LspProgressUpdate',
+    callback = function()
+      for _, m in ipairs(vim.lsp.util.get_progress_messages()) do
+        if m.progress then
+          gui('gonvim_lsp_progress', tostring(m.name) .. ':' .. tostring(m.token),
+            m.done and 'end' or 'report', m.title or '', m.message or '', m.percentage or -1)
+        end
+      end
+    end,
+  })
+end
+
+-- nvim-dap integration; the debug panel of the GUI shows the state of the session
+local function dap_variables(session, ref)
+  session:request('variables', { variablesReference = ref }, function(err, resp)
+    local vars = {}
+    if not err and resp then
+      for _, v in ipairs(resp.variables or {}) do
+        table.insert(vars, { name = v.name, value = v.value or '', type = v.type or '', ref = v.variablesReference or 0 })
+      end
+    end
+    gui('gonvim_dap', 'variables', ref, vars)
+  end)
+end
+
+local function dap_refresh(session)
+  local frame = session.current_frame
+  local thread = session.threads and session.threads[session.stopped_thread_id]
+  local frames = {}
+  for i, f in ipairs((thread and thread.frames) or {}) do
+    frames[i] = {
+      id = f.id,
+      name = f.name or '',
+      path = (f.source and f.source.path) or '',
+      line = f.line or 0,
+      current = frame ~= nil and f.id == frame.id,
+    }
+  end
+  gui('gonvim_dap', 'stack', frames)
+  if not frame then
+    return
+  end
+  session:request('scopes', { frameId = frame.id }, function(err, resp)
+    if err or not resp then
+      return
+    end
+    local scopes = {}
+    for _, s in ipairs(resp.scopes or {}) do
+      table.insert(scopes, { name = s.name, ref = s.variablesReference or 0 })
+    end
+    gui('gonvim_dap', 'scopes', scopes)
+    for _, s in ipairs(scopes) do
+      if s.ref > 0 then
+        dap_variables(session, s.ref)
+      end
+    end
+  end)
+  for _, expr in ipairs(M.dap.watches) do
+    session:request('evaluate', { expression = expr, frameId = frame.id, context = 'watch' }, function(err, resp)
+      if err or not resp then
+        gui('gonvim_dap', 'watch', expr, (err and err.message) or 'error', 0)
+      else
+        gui('gonvim_dap', 'watch', expr, resp.result or '', resp.variablesReference or 0)
+      end
+    end)
+  end
+end
+
+M.dap = {
+  watches = {},
+  action = function(name)
+    require('dap')[name]()
+  end,
+  expand = function(ref)
+    local session = require('dap').session()
+    if session then
+      dap_variables(session, ref)
+    end
+  end,
+  select_frame = function(id)
+    local session = require('dap').session()
+    if not session then
+      return
+    end
+    local thread = session.threads[session.stopped_thread_id]
+    for _, f in ipairs((thread and thread.frames) or {}) do
+      if f.id == id then
+        if session._frame_set then
+          session:_frame_set(f)
+        end
+        dap_refresh(session)
+        return
+      end
+    end
+  end,
+  watch = function(expr)
+    table.insert(M.dap.watches, expr)
+    local ok, dap = pcall(require, 'dap')
+    if ok and dap.session() then
+      dap_refresh(dap.session())
+    end
+  end,
+  unwatch = function(expr)
+    for i, e in ipairs(M.dap.watches) do
+      if e == expr then
+        table.remove(M.dap.watches, i)
+        return
+      end
+    end
+  end,
+}
+
+-- Show the debug panel for the nvim-dap sessions, and draw the signs of nvim-dap as shapes
+function M.setup_dap()
+  local ok, dap = pcall(require, 'dap')
+  if not ok then
+    return
+  end
+  local signs = {
+    DapBreakpoint = { text = 'B', color = '#e51400' },
+    DapBreakpointCondition = { text = 'C', color = '#e51400' },
+    DapBreakpointRejected = { text = 'R', color = '#848484' },
+    DapLogPoint = { text = 'L', color = '#e51400' },
+    DapStopped = { text = '>', color = '#ffcc00' },
+  }
+  for name, sign in pairs(signs) do
+    -- The cells are drawn by the GUI only with their own highlight, not a linked one
+    vim.cmd('highlight default ' .. name .. ' guifg=' .. sign.color)
+    local defined = vim.fn.sign_getdefined(name)[1]
+    if not defined then
+      vim.fn.sign_define(name, { text = sign.text, texthl = name })
+    elseif (defined.texthl or '') == '' then
+      -- nvim-dap defines its signs without texthl
+      vim.fn.sign_define(name, { texthl = name })
+    end
+  end
+
+  dap.listeners.after.event_initialized.gonvim = function()
+    gui('gonvim_dap', 'start')
+  end
+  dap.listeners.after.event_continued.gonvim = function()
+    gui('gonvim_dap', 'running')
+  end
+  dap.listeners.after.stackTrace.gonvim = function(session)
+    vim.schedule(function()
+      dap_refresh(session)
+    end)
+  end
+  for _, event in ipairs({ 'event_terminated', 'event_exited', 'disconnect' }) do
+    dap.listeners.after[event].gonvim = function()
+      gui('gonvim_dap', 'end')
+    end
+  end
+end
+
+-- The line shown on the row of the window which matches the pattern
+local function row_line(win, row, lines, pattern, plain)
+  local top = vim.fn.line('w0', win)
+  local winrow = vim.fn.win_screenpos(win)[1]
+  -- Wrapped or folded lines above make the line of the row smaller than top + row
+  local found
+  for l = math.min(top + row, #lines), top, -1 do
+    if lines[l]:find(pattern, 1, plain) then
+      found = found or l
+      if vim.fn.screenpos(win, l, 1).row - winrow == row then
+        return l
+      end
+    end
+  end
+  return found
+end
+
+-- Resolve the merge conflict whose start marker is shown on the row of the window;
+-- choice is "current", "incoming" or "both"
+function M.resolve_conflict(win, row, choice)
+  if not vim.api.nvim_win_is_valid(win) then
+    return
+  end
+  local buf = vim.api.nvim_win_get_buf(win)
+  local lines = vim.api.nvim_buf_get_lines(buf, 0, -1, false)
+  local start = row_line(win, row, lines, '^<<<<<<<')
+  if not start then
+    return
+  end
+  local base, mid, finish
+  for l = start + 1, #lines do
+    if not base and not mid and lines[l]:match('^|||||||') then
+      base = l
+    elseif not mid and lines[l]:match('^=======') then
+      mid = l
+    elseif mid and lines[l]:match('^>>>>>>>') then
+      finish = l
+      break
+    end
+  end
+  if not mid or not finish then
+    return
+  end
+  local current = vim.list_slice(lines, start + 1, (base or mid) - 1)
+  local incoming = vim.list_slice(lines, mid + 1, finish - 1)
+  local result = current
+  if choice == 'incoming' then
+    result = incoming
+  elseif choice == 'both' then
+    result = vim.list_extend(vim.list_slice(current, 1, #current), incoming)
+  end
+  vim.api.nvim_buf_set_lines(buf, start - 1, finish, false, result)
+end
+
+-- Replace the color literal shown at the row and the column of the grid of the window
+function M.replace_color(win, row, col, old, new)
+  if not vim.api.nvim_win_is_valid(win) then
+    return
+  end
+  local buf = vim.api.nvim_win_get_buf(win)
+  local lines = vim.api.nvim_buf_get_lines(buf, 0, -1, false)
+  local l = row_line(win, row, lines, old, true)
+  if not l then
+    return
+  end
+  local line = lines[l]
+  local info = vim.fn.getwininfo(win)[1]
+  local leftcol = vim.api.nvim_win_call(win, vim.fn.winsaveview).leftcol
+  -- The occurrence nearest to the column
+  local best, distance
+  local s, e = line:find(old, 1, true)
+  while s do
+    local d = math.abs(vim.fn.strdisplaywidth(line:sub(1, s - 1)) + (info.textoff or 0) - leftcol - col)
+    if not distance or d < distance then
+      best, distance = s, d
+    end
+    s, e = line:find(old, e + 1, true)
+  end
+  if not best then
+    return
+  end
+  vim.api.nvim_buf_set_text(buf, l - 1, best - 1, l - 1, best - 1 + #old, { new })
+end
+
+-- The node types containing these words are the scopes of the indent guides
+local scope_types = { 'block', 'function', 'method', 'class', 'if', 'for', 'while', 'switch', 'case', 'table', 'object', 'array', 'body', 'struct', 'impl', 'module' }
+
+-- The lines of the innermost treesitter scope around the cursor of the window
+local function treesitter_scope(win)
+  if not vim.treesitter or not vim.treesitter.get_node then
+    return nil
+  end
+  local cursor = vim.api.nvim_win_get_cursor(win)
+  local ok, node = pcall(vim.treesitter.get_node, { bufnr = vim.api.nvim_win_get_buf(win), pos = { cursor[1] - 1, cursor[2] } })
+  if not ok then
+    return nil
+  end
+  while node do
+    local srow, _, erow = node:range()
+    if erow > srow then
+      local t = node:type()
+      for _, s in ipairs(scope_types) do
+        if t:find(s, 1, true) then
+          return srow + 1, erow + 1
+        end
+      end
+    end
+    node = node:parent()
+  end
+  return nil
+end
+
+-- The lines shown on the rows of the window; a closed fold takes a row, and a
+-- wrapped line takes its height. Each item is {line, closed, first row (0-based)}.
+local function visible_lines(win)
+  local info = vim.fn.getwininfo(win)[1]
+  local wrap = vim.wo[win].wrap and vim.api.nvim_win_text_height ~= nil
+  local items = {}
+  local row = 0
+  local l = info.topline
+  while l <= info.botline and row < info.height do
+    local closed = vim.api.nvim_win_call(win, function() return vim.fn.foldclosedend(l) end)
+    local height = 1
+    if closed == -1 and wrap then
+      height = vim.api.nvim_win_text_height(win, { start_row = l - 1, end_row = l - 1 }).all
+    end
+    table.insert(items, { line = l, closed = closed ~= -1, row = row, height = height })
+    row = row + height
+    if closed ~= -1 then
+      l = closed + 1
+    else
+      l = l + 1
+    end
+  end
+  return items, info
+end
+
+-- The line shown on the row of the window
+local function line_of_row(win, row)
+  for _, item in ipairs((visible_lines(win))) do
+    if row >= item.row and row < item.row + item.height then
+      return item.line, item.closed
+    end
+  end
+end
+
+local function normal_windows()
+  local wins = {}
+  for _, win in ipairs(vim.api.nvim_tabpage_list_wins(0)) do
+    if vim.api.nvim_win_get_config(win).relative == '' then
+      table.insert(wins, win)
+    end
+  end
+  return wins
+end
+
+-- Send the indent of each row of the windows of the current tabpage for the indent guides.
+-- The closed folds and the wrapped rows have no guides, and the blank lines
+-- take the smaller indent of the lines around them.
+function M.indent_guides()
+  local result = {}
+  for _, win in ipairs(normal_windows()) do
+    local buf = vim.api.nvim_win_get_buf(win)
+    -- nvim_win_call returns only one value
+    local view = vim.api.nvim_win_call(win, function()
+      local v = vim.fn.winsaveview()
+      v.shiftwidth = vim.fn.shiftwidth()
+      return v
+    end)
+    local s, e = treesitter_scope(win)
+    local scope
+    local rows = {}
+    local items, info = visible_lines(win)
+    for _, item in ipairs(items) do
+      local l = item.line
+      if item.closed then
+        table.insert(rows, -1)
+      else
+        local text = vim.api.nvim_buf_get_lines(buf, l - 1, l, false)[1] or ''
+        local indent = -2
+        if text:find('%%S') then
+          indent = vim.api.nvim_win_call(win, function() return vim.fn.indent(l) end)
+        end
+        if s and l > s and l < e then
+          local col = vim.api.nvim_win_call(win, function() return vim.fn.indent(s) end)
+          scope = scope or { col = col, first = #rows }
+          scope.last = #rows
+        end
+        table.insert(rows, indent)
+        for _ = 2, item.height do
+          table.insert(rows, -1)
+        end
+      end
+    end
+    for i, indent in ipairs(rows) do
+      if indent == -2 then
+        local prev, next = 0, 0
+        for j = i - 1, 1, -1 do
+          if rows[j] >= 0 then prev = rows[j] break end
+        end
+        for j = i + 1, #rows do
+          if rows[j] >= 0 then next = rows[j] break end
+        end
+        rows[i] = math.min(prev, next)
+      end
+    end
+    table.insert(result, {
+      win = win,
+      leftcol = view.leftcol,
+      textoff = info.textoff or 0,
+      sw = view.shiftwidth,
+      rows = rows,
+      scope = scope,
+    })
+  end
+  gui_changed('gonvim_indent_guides', result)
+end
+
+-- Send the folds starting on each row of the windows of the current tabpage;
+-- 0: none, 1: an open fold, 2: a closed fold. The windows without the fold
+-- column have no rows.
+function M.folds()
+  local result = {}
+  for _, win in ipairs(normal_windows()) do
+    local rows = {}
+    for _, item in ipairs(vim.wo[win].foldcolumn == '0' and {} or (visible_lines(win))) do
+      local kind = 0
+      if item.closed then
+        kind = 2
+      else
+        local l = item.line
+        local starts = vim.api.nvim_win_call(win, function()
+          return vim.fn.foldlevel(l) > (l > 1 and vim.fn.foldlevel(l - 1) or 0)
+        end)
+        if starts then
+          kind = 1
+        end
+      end
+      table.insert(rows, kind)
+      for _ = 2, item.height do
+        table.insert(rows, 0)
+      end
+    end
+    table.insert(result, { win = win, rows = rows })
+  end
+  gui_changed('gonvim_folds', result)
+end
+
+-- Send the rows of the windows of the current tabpage continuing the wrapped
+-- lines, and the lines shown, so that a wrapped line counts once in the
+-- scrollbar and the minimap
+function M.wraps()
+  local result = {}
+  for _, win in ipairs(normal_windows()) do
+    local items, info = visible_lines(win)
+    local rows = {}
+    if vim.wo[win].wrap then
+      for _, item in ipairs(items) do
+        for r = 1, item.height - 1 do
+          table.insert(rows, item.row + r)
+        end
+      end
+    end
+    table.insert(result, {
+      win = win,
+      topline = info.topline,
+      botline = info.botline,
+      lines = vim.api.nvim_buf_line_count(vim.api.nvim_win_get_buf(win)),
+      textoff = info.textoff or 0,
+      rows = rows,
+    })
+  end
+  gui_changed('gonvim_wraps', result)
+end
+
+local delimiter_open = { ['('] = ')', ['['] = ']', ['{'] = '}' }
+local delimiter_close = { [')'] = '(', [']'] = '[', ['}'] = '{' }
+
+-- The brackets of the treesitter tree of the buffer in the nodes over the lines
+-- (1-based, inclusive); each is {line, col (0-based byte), depth, pair (the index
+-- of the other one)}. The brackets of the nodes over the lines are walked
+-- though they are not shown, so that the depth and the pairs are right.
+local function treesitter_delimiters(buf, first, last)
+  if not vim.treesitter or not vim.treesitter.get_parser then
+    return nil
+  end
+  local ok, parser = pcall(vim.treesitter.get_parser, buf)
+  if not ok or not parser then
+    return nil
+  end
+  local trees = parser:parse()
+  if not trees or not trees[1] then
+    return nil
+  end
+  local delims = {}
+  local function walk(node, depth)
+    local stack = {}
+    for child in node:iter_children() do
+      local srow, scol, erow = child:range()
+      local t = child:type()
+      if not child:named() and delimiter_open[t] then
+        table.insert(delims, { line = srow + 1, col = scol, depth = depth + #stack })
+        table.insert(stack, { char = t, index = #delims })
+      elseif not child:named() and delimiter_close[t] then
+        local open = stack[#stack]
+        if open and open.char == delimiter_close[t] then
+          table.remove(stack)
+          table.insert(delims, { line = srow + 1, col = scol, depth = depth + #stack, pair = open.index })
+          delims[open.index].pair = #delims
+        else
+          table.insert(delims, { line = srow + 1, col = scol, depth = depth + #stack })
+        end
+      elseif erow + 1 >= first and srow + 1 <= last and child:child_count() > 0 then
+        walk(child, depth + #stack)
+      end
+    end
+  end
+  walk(trees[1]:root(), 0)
+  return delims
+end
+
+local delimiter_options = { rainbow = false, guide = false }
+
+-- Set what M.delimiters sends: the brackets for the rainbow delimiters, the
+-- pair around the cursor for the pair guide
+function M.setup_delimiters(rainbow, guide)
+  delimiter_options = { rainbow = rainbow, guide = guide }
+end
+
+-- Send the brackets shown in the windows of the current tabpage with their
+-- depth for the rainbow delimiters, and the pair of the brackets around the
+-- cursor with the rows between them for the pair guide
+function M.delimiters()
+  local rainbow, guide = delimiter_options.rainbow, delimiter_options.guide
+  local result = {}
+  local cur = vim.api.nvim_get_current_win()
+  for _, win in ipairs(normal_windows()) do
+    local buf = vim.api.nvim_win_get_buf(win)
+    local _, info = visible_lines(win)
+    local delims = treesitter_delimiters(buf, info.topline, info.botline) or {}
+    local wpos = vim.fn.win_screenpos(win)
+    local function screen_row(d)
+      local spos = vim.fn.screenpos(win, d.line, d.col + 1)
+      if spos.row > 0 then
+        return spos.row - wpos[1]
+      elseif d.line < info.topline then
+        return -1
+      elseif d.line > info.botline then
+        return info.height
+      end
+    end
+
+    local pair
+    if guide and win == cur then
+      local cursor = vim.api.nvim_win_get_cursor(win)
+      local function before(a, l, c)
+        return a.line < l or (a.line == l and a.col <= c)
+      end
+      -- The pair is searched in the nodes over the cursor line, not the shown
+      -- lines, since its brackets can be scrolled out of the window
+      local around = treesitter_delimiters(buf, cursor[1], cursor[1]) or {}
+      for i, d in ipairs(around) do
+        local close = d.pair and d.pair > i and around[d.pair]
+        if close and before(d, cursor[1], cursor[2]) and not before(close, cursor[1], cursor[2] - 1) then
+          -- The later opening one is the inner one
+          if not pair or before(pair[1], d.line, d.col) then
+            pair = { d, close }
+          end
+        end
+      end
+    end
+
+    local function in_pair(d)
+      return pair ~= nil and ((d.line == pair[1].line and d.col == pair[1].col) or (d.line == pair[2].line and d.col == pair[2].col))
+    end
+
+    local items = {}
+    for _, d in ipairs(delims) do
+      local shown = (rainbow or in_pair(d)) and d.line >= info.topline and d.line <= info.botline
+      if shown and #items < 2000 then
+        local spos = vim.fn.screenpos(win, d.line, d.col + 1)
+        if spos.row > 0 then
+          table.insert(items, {
+            row = spos.row - wpos[1],
+            col = spos.col - wpos[2],
+            depth = d.depth,
+            pair = in_pair(d),
+          })
+        end
+      end
+    end
+
+    local item = { win = win, delimiters = items }
+    if pair and pair[1].line ~= pair[2].line then
+      local orow = screen_row(pair[1])
+      local crow = screen_row(pair[2])
+      if orow and crow then
+        local col = vim.api.nvim_win_call(win, function()
+          return vim.fn.indent(pair[1].line) - vim.fn.winsaveview().leftcol
+        end)
+        if col >= 0 then
+          item.guide = {
+            col = (info.textoff or 0) + col,
+            first = math.max(orow + 1, 0),
+            last = math.min(crow - 1, info.height - 1),
+            depth = pair[1].depth,
+          }
+        end
+      end
+    end
+    table.insert(result, item)
+  end
+  gui('gonvim_delimiters', result)
+end
+
+local filetype_fonts = {}
+
+-- Set the font of the filetype of the buffer of the window, or the font of the
+-- workspace to the window which had the font of a filetype
+function M.filetype_font(win)
+  win = win or vim.api.nvim_get_current_win()
+  if not vim.api.nvim_win_is_valid(win) or vim.api.nvim_win_get_config(win).relative ~= '' then
+    return
+  end
+  local filetype = vim.bo[vim.api.nvim_win_get_buf(win)].filetype
+  local font = filetype_fonts[filetype] or ''
+  local ok, current = pcall(vim.api.nvim_win_get_var, win, 'gonvim_font')
+  if (ok and current or '') == font then
+    return
+  end
+  vim.api.nvim_win_set_var(win, 'gonvim_font', font)
+  gui('gonvim_window_font', win, font)
+end
+
+-- Set the font of the windows of the buffers of the filetype ("": the font of the workspace)
+function M.set_filetype_font(filetype, font)
+  filetype_fonts[filetype] = (font and font ~= '') and font or nil
+  vim.cmd('augroup GonvimFiletypeFont | autocmd! | augroup END')
+  if next(filetype_fonts) then
+    vim.cmd('autocmd GonvimFiletypeFont BufWinEnter,FileType,WinNew * lua require("gonvim").filetype_font()')
+  end
+  for _, win in ipairs(normal_windows()) do
+    M.filetype_font(win)
+  end
+end
+
+-- Open or close the fold on the row of the window
+function M.toggle_fold(win, row)
+  local l, closed = line_of_row(win, row)
+  if not l then
+    return
+  end
+  vim.api.nvim_win_call(win, function()
+    vim.api.nvim_win_set_cursor(win, { l, 0 })
+    vim.cmd(closed and 'normal! zo' or 'normal! zc')
+  end)
+  M.folds()
+end
+
+-- Send the contents of the closed fold on the row of the window
+function M.fold_preview(win, row)
+  local l, closed = line_of_row(win, row)
+  if not l or not closed then
+    return
+  end
+  local last = vim.api.nvim_win_call(win, function() return vim.fn.foldclosedend(l) end)
+  local buf = vim.api.nvim_win_get_buf(win)
+  local lines = vim.api.nvim_buf_get_lines(buf, l - 1, math.min(last, l + 29), false)
+  gui('gonvim_fold_preview', win, row, lines, last - l + 1)
+end
+
+-- The pattern of the find bar; the text is a regex, or a literal without it
+local function find_pattern(text, regex, case, word)
+  local pattern = regex and text or ('\\V' .. vim.fn.escape(text, '\\'))
+  if word then
+    pattern = '\\<\\%%(' .. pattern .. '\\)\\>'
+  end
+  return (case and '\\C' or '\\c') .. pattern
+end
+
+-- The :substitute of the find bar, with a separator which is in neither the
+-- pattern nor the replacement
+local function find_substitute(text, replace, regex, case, word, range, flags)
+  local pattern = find_pattern(text, regex, case, word)
+  if range == '' then
+    -- The match at the cursor
+    pattern = '\\%%#' .. pattern
+  end
+  if not regex then
+    replace = vim.fn.escape(replace, '\\&~')
+  end
+  for _, sep in ipairs({ '/', '#', ';', ',', '@', '!', '=', ':' }) do
+    if not pattern:find(sep, 1, true) and not replace:find(sep, 1, true) then
+      return range .. 's' .. sep .. pattern .. sep .. replace .. sep .. flags
+    end
+  end
+  return nil
+end
+
+-- Search the text of the find bar from the cursor; move is 'c' to match at the
+-- cursor as well, 'n' for the next match, or 'N' for the previous one
+function M.find(text, regex, case, word, move)
+  if text == '' then
+    vim.v.hlsearch = 0
+    gui('gonvim_find', 0, 0, false)
+    return
+  end
+  local pattern = find_pattern(text, regex, case, word)
+  local flags = move == 'N' and 'bw' or (move == 'c' and 'cw' or 'w')
+  if not pcall(vim.fn.search, pattern, flags) then
+    gui('gonvim_find', 0, 0, true)
+    return
+  end
+  vim.fn.setreg('/', pattern)
+  vim.o.hlsearch = true
+  vim.v.hlsearch = 1
+  local ok, count = pcall(vim.fn.searchcount, { pattern = pattern, maxcount = 9999, timeout = 200 })
+  if not ok then
+    count = {}
+  end
+  gui('gonvim_find', count.current or 0, count.total or 0, false)
+end
+
+-- Type the substitution in the cmdline, which 'inccommand' previews
+function M.find_preview(text, replace, regex, case, word)
+  if vim.o.inccommand == '' then
+    return
+  end
+  local cmd = find_substitute(text, replace, regex, case, word, '%%', 'g')
+  if not cmd then
+    return
+  end
+  local keys = vim.fn.mode() == 'c' and '<C-u>' or '<C-\\><C-n>:<C-u>'
+  vim.api.nvim_input(keys .. (cmd:gsub('<', '<lt>')))
+end
+
+-- Leave the cmdline of the preview
+function M.find_preview_end()
+  if vim.fn.mode() == 'c' then
+    vim.api.nvim_feedkeys(vim.api.nvim_replace_termcodes('<C-c>', true, false, true), 'nx', false)
+  end
+end
+
+-- Replace the match at the cursor and go to the next one, or all the matches
+function M.find_replace(text, replace, regex, case, word, all)
+  M.find_preview_end()
+  if not all then
+    M.find(text, regex, case, word, 'c')
+  end
+  local cmd = find_substitute(text, replace, regex, case, word, all and '%%' or '', all and 'ge' or 'e')
+  if not cmd then
+    vim.notify('[Gonvim] No separator of :substitute is available for the pattern', vim.log.levels.ERROR)
+    return
+  end
+  local view = vim.fn.winsaveview()
+  local ok, err = pcall(vim.cmd, 'keeppatterns ' .. cmd)
+  if not ok then
+    vim.notify(err, vim.log.levels.ERROR)
+  end
+  vim.fn.winrestview(view)
+  M.find(text, regex, case, word, all and 'c' or 'n')
+end
+
+local activity_sent = {}
+local activity_errors = 0
+
+-- Notify the GUI of the activity, which is shown as the badge of the workspace
+-- while it is not shown; at most once a second for each kind
+local function activity(kind)
+  local now = vim.loop.now()
+  if activity_sent[kind] and now - activity_sent[kind] < 1000 then
+    return
+  end
+  activity_sent[kind] = now
+  gui('gonvim_activity', kind)
+end
+
+-- Watch the output of the terminal buffer
+function M.activity_terminal(buf)
+  vim.api.nvim_buf_attach(buf, false, {
+    on_lines = function()
+      vim.schedule(function()
+        activity('terminal')
+      end)
+    end,
+  })
+end
+
+function M.activity_job()
+  activity('job')
+end
+
+-- Notify when the number of the errors has increased
+function M.activity_diagnostics()
+  local errors = #vim.diagnostic.get(nil, { severity = vim.diagnostic.severity.ERROR })
+  if errors > activity_errors then
+    activity('diagnostics')
+  end
+  activity_errors = errors
+end
+
+-- Send the quickfix list with the file names of its buffers
+function M.quickfix()
+  local qf = vim.fn.getqflist({ title = 1, items = 1 })
+  local items = {}
+  for i, item in ipairs(qf.items) do
+    table.insert(items, {
+      idx = i,
+      filename = item.bufnr > 0 and vim.fn.fnamemodify(vim.fn.bufname(item.bufnr), ':~:.') or '',
+      lnum = item.lnum,
+      col = item.col,
+      text = item.text,
+      type = item.type,
+      valid = item.valid,
+    })
+  end
+  gui('gonvim_quickfix', qf.title, items)
+end
+
+local symbol_kinds = {
+  'File', 'Module', 'Namespace', 'Package', 'Class', 'Method', 'Property', 'Field', 'Constructor',
+  'Enum', 'Interface', 'Function', 'Variable', 'Constant', 'String', 'Number', 'Boolean', 'Array',
+  'Object', 'Key', 'Null', 'EnumMember', 'Struct', 'Event', 'Operator', 'TypeParameter',
+}
+
+local function range_contains(range, line, col)
+  local s, e = range.start, range['end']
+  if line < s.line or line > e.line then
+    return false
+  end
+  if line == s.line and col < s.character then
+    return false
+  end
+  if line == e.line and col > e.character then
+    return false
+  end
+  return true
+end
+
+local function symbol_item(name, kind, range)
+  return { name = name, kind = kind, line = range.start.line + 1, col = range.start.character }
+end
+
+-- The chain of the symbols around the position (0-based), from the outermost.
+-- DocumentSymbol has the children; SymbolInformation is a flat list, whose
+-- symbols around the position are nested in the order of their ranges.
+local function lsp_symbol_chain(symbols, line, col)
+  local chain = {}
+  while symbols and #symbols > 0 do
+    local siblings = {}
+    local found
+    for _, s in ipairs(symbols) do
+      local range = s.range or (s.location and s.location.range)
+      if range then
+        if not found and range_contains(range, line, col) then
+          found = s
+          if not s.range then
+            -- SymbolInformation: the siblings are the symbols in the same container
+            siblings = {}
+            for _, o in ipairs(symbols) do
+              if o.containerName == s.containerName then
+                table.insert(siblings, symbol_item(o.name, symbol_kinds[o.kind] or '', o.location.range))
+              end
+            end
+          end
+        end
+        if s.range then
+          table.insert(siblings, symbol_item(s.name, symbol_kinds[s.kind] or '', range))
+        end
+      end
+    end
+    if not found then
+      break
+    end
+    local crumb = symbol_item(found.name, symbol_kinds[found.kind] or '', found.range or found.location.range)
+    crumb.siblings = siblings
+    table.insert(chain, crumb)
+    if found.range then
+      symbols = found.children
+    else
+      local inner = {}
+      for _, s in ipairs(symbols) do
+        if s ~= found and s.location and range_contains(found.location.range, s.location.range.start.line, s.location.range.start.character) then
+          table.insert(inner, s)
+        end
+      end
+      symbols = inner
+    end
+  end
+  return chain
+end
+
+-- The chain of the treesitter nodes with a name around the cursor, from the outermost
+local function treesitter_symbol_chain(buf, line, col)
+  if not vim.treesitter or not vim.treesitter.get_node then
+    return {}
+  end
+  local ok, node = pcall(vim.treesitter.get_node, { bufnr = buf, pos = { line, col } })
+  if not ok then
+    return {}
+  end
+  local function item(n)
+    local names = n:field('name')
+    if not names or not names[1] then
+      return nil
+    end
+    local srow, scol = n:start()
+    return { name = vim.treesitter.get_node_text(names[1], buf), kind = n:type(), line = srow + 1, col = scol }
+  end
+  local chain = {}
+  while node do
+    local crumb = item(node)
+    if crumb then
+      crumb.siblings = {}
+      local parent = node:parent()
+      if parent then
+        for child in parent:iter_children() do
+          local sibling = child:named() and item(child)
+          if sibling then
+            table.insert(crumb.siblings, sibling)
+          end
+        end
+      end
+      table.insert(chain, 1, crumb)
+    end
+    node = node:parent()
+  end
+  return chain
+end
+
+-- Send the path of the current buffer and the symbols around the cursor for the
+-- breadcrumbs; the symbols of a language server, or of treesitter without it
+function M.breadcrumbs()
+  local win = vim.api.nvim_get_current_win()
+  local buf = vim.api.nvim_get_current_buf()
+  if vim.api.nvim_win_get_config(win).relative ~= '' then
+    return
+  end
+  local path = vim.api.nvim_buf_get_name(buf)
+  if vim.bo[buf].buftype ~= '' or path == '' then
+    gui('gonvim_breadcrumbs', win, '', '', {})
+    return
+  end
+  local relpath = vim.fn.fnamemodify(path, ':~:.')
+  local cursor = vim.api.nvim_win_get_cursor(win)
+  local line, col = cursor[1] - 1, cursor[2]
+
+  local get_clients = vim.lsp.get_clients or vim.lsp.get_active_clients
+  local supported = false
+  for _, client in ipairs(get_clients({ bufnr = buf })) do
+    if client.server_capabilities and client.server_capabilities.documentSymbolProvider then
+      supported = true
+    end
+  end
+  if not supported then
+    gui('gonvim_breadcrumbs', win, path, relpath, treesitter_symbol_chain(buf, line, col))
+    return
+  end
+  local params = { textDocument = vim.lsp.util.make_text_document_params(buf) }
+  vim.lsp.buf_request_all(buf, 'textDocument/documentSymbol', params, function(responses)
+    if vim.api.nvim_get_current_buf() ~= buf then
+      return
+    end
+    for _, response in pairs(responses) do
+      if response.result and #response.result > 0 then
+        gui('gonvim_breadcrumbs', win, path, relpath, lsp_symbol_chain(response.result, line, col))
+        return
+      end
+    end
+    gui('gonvim_breadcrumbs', win, path, relpath, treesitter_symbol_chain(buf, line, col))
+  end)
+end
+
+local snippet_ns = vim.api.nvim_create_namespace('gonvim_snippet')
+local snippet
+
+-- Send the regions of the placeholders of the snippet on the rows of its window
+local function send_snippet()
+  if not snippet or not vim.api.nvim_buf_is_valid(snippet.buf) then
+    gui('gonvim_snippet', 0, 0, 0, {})
+    return
+  end
+  local win = vim.fn.bufwinid(snippet.buf)
+  if win == -1 then
+    gui('gonvim_snippet', 0, 0, 0, {})
+    return
+  end
+  local wpos = vim.fn.win_screenpos(win)
+  local regions = {}
+  for i, id in ipairs(snippet.marks) do
+    local mark = vim.api.nvim_buf_get_extmark_by_id(snippet.buf, snippet_ns, id, { details = true })
+    if mark[1] then
+      local srow, scol = mark[1], mark[2]
+      local erow, ecol = mark[3].end_row or srow, mark[3].end_col or scol
+      for l = srow, erow do
+        local text = vim.api.nvim_buf_get_lines(snippet.buf, l, l + 1, false)[1] or ''
+        local s = l == srow and scol or 0
+        local e = l == erow and ecol or #text
+        local spos = vim.fn.screenpos(win, l + 1, s + 1)
+        if spos.row > 0 then
+          local width = 1
+          if e > s then
+            local epos = vim.fn.screenpos(win, l + 1, e)
+            if epos.row == spos.row then
+              width = epos.endcol - spos.col + 1
+            end
+          end
+          table.insert(regions, { index = i, row = spos.row - wpos[1], col = spos.col - wpos[2], width = width })
+        end
+      end
+    end
+  end
+  gui('gonvim_snippet', win, snippet.current, #snippet.marks, regions)
+end
+
+-- Show the placeholders of the active snippet of the buffer (0: the current one)
+-- for the snippet engines. Each placeholder is {row, col, end_row, end_col},
+-- 0-based and the end exclusive, in the order of the jumps; current is the index
+-- of the placeholder being edited. They follow the edits until the snippet is
+-- cleared by an empty list.
+function M.snippet(placeholders, current, buf)
+  if snippet then
+    pcall(vim.api.nvim_buf_clear_namespace, snippet.buf, snippet_ns, 0, -1)
+  end
+  vim.cmd('augroup GonvimSnippet | autocmd! | augroup END')
+  if not placeholders or #placeholders == 0 then
+    snippet = nil
+    send_snippet()
+    return
+  end
+  buf = (buf == nil or buf == 0) and vim.api.nvim_get_current_buf() or buf
+  local marks = {}
+  for _, p in ipairs(placeholders) do
+    table.insert(marks, vim.api.nvim_buf_set_extmark(buf, snippet_ns, p[1], p[2], {
+      end_row = p[3],
+      end_col = p[4],
+      right_gravity = false,
+      end_right_gravity = true,
+    }))
+  end
+  snippet = { buf = buf, marks = marks, current = current or 1 }
+  vim.cmd('autocmd GonvimSnippet TextChanged,TextChangedI,TextChangedP,WinScrolled,BufWinEnter,BufWinLeave * lua require("gonvim").snippet_update()')
+  send_snippet()
+end
+
+-- Change the placeholder being edited of the active snippet
+function M.snippet_jump(current)
+  if snippet then
+    snippet.current = current
+    send_snippet()
+  end
+end
+
+function M.snippet_update()
+  send_snippet()
+end
+
+local cursors_ns = vim.api.nvim_create_namespace('gonvim_cursors')
+local cursors
+
+-- Send the cells of the cursors and the selections on the rows of the windows of the buffer
+local function send_cursors()
+  local items = {}
+  if cursors and vim.api.nvim_buf_is_valid(cursors.buf) then
+    for _, win in ipairs(normal_windows()) do
+      if vim.api.nvim_win_get_buf(win) == cursors.buf then
+        local wpos = vim.fn.win_screenpos(win)
+        local regions = {}
+        for id, primary in pairs(cursors.marks) do
+          local mark = vim.api.nvim_buf_get_extmark_by_id(cursors.buf, cursors_ns, id, { details = true })
+          if mark[1] then
+            local srow, scol = mark[1], mark[2]
+            local erow, ecol = mark[3].end_row or srow, mark[3].end_col or scol
+            for l = srow, erow do
+              local text = vim.api.nvim_buf_get_lines(cursors.buf, l, l + 1, false)[1] or ''
+              local s = l == srow and scol or 0
+              local e = l == erow and ecol or #text
+              local spos = vim.fn.screenpos(win, l + 1, math.min(s, math.max(#text - 1, 0)) + 1)
+              if spos.row > 0 then
+                local width = 0
+                if e > s then
+                  local epos = vim.fn.screenpos(win, l + 1, e)
+                  width = epos.row == spos.row and epos.endcol - spos.col + 1 or 1
+                end
+                table.insert(regions, { row = spos.row - wpos[1], col = spos.col - wpos[2], width = width, primary = primary })
+              end
+            end
+          end
+        end
+        table.insert(items, { win = win, regions = regions })
+      end
+    end
+  end
+  gui('gonvim_cursors', items)
+end
+
+-- Show the cursors and the selections of a plugin of multiple cursors on the
+-- buffer (0: the current one), drawn by the GUI instead of the highlights. Each
+-- is {line = , col = , end_line = , end_col = , primary = }, the line 1-based,
+-- the column 0-based in bytes, and the end exclusive; without the end it is a
+-- cursor. They follow the edits until they are cleared by an empty list.
+function M.cursors(list, buf)
+  if cursors then
+    pcall(vim.api.nvim_buf_clear_namespace, cursors.buf, cursors_ns, 0, -1)
+  end
+  vim.cmd('augroup GonvimCursors | autocmd! | augroup END')
+  if not list or #list == 0 then
+    cursors = nil
+    send_cursors()
+    return
+  end
+  buf = (buf == nil or buf == 0) and vim.api.nvim_get_current_buf() or buf
+  local marks = {}
+  for _, c in ipairs(list) do
+    local id = vim.api.nvim_buf_set_extmark(buf, cursors_ns, c.line - 1, c.col, {
+      end_row = (c.end_line or c.line) - 1,
+      end_col = c.end_col or c.col,
+      right_gravity = false,
+      end_right_gravity = true,
+      strict = false,
+    })
+    marks[id] = c.primary == true
+  end
+  cursors = { buf = buf, marks = marks }
+  vim.cmd('autocmd GonvimCursors TextChanged,TextChangedI,WinScrolled,BufWinEnter,BufWinLeave * lua require("gonvim").cursors_update()')
+  send_cursors()
+end
+
+function M.cursors_update()
+  send_cursors()
+end
+
+local peek
+
+-- Close the window of peek_definition
+function M.peek_close()
+  if not peek then
+    return
+  end
+  if vim.api.nvim_win_is_valid(peek.win) then
+    vim.api.nvim_win_close(peek.win, true)
+  end
+  gui('gonvim_peek_close', peek.win)
+  peek = nil
+end
+
+-- Open the location of the window of peek_definition in the current window
+function M.peek_open()
+  if not peek then
+    return
+  end
+  local p = peek
+  M.peek_close()
+  vim.cmd("normal! m'")
+  vim.api.nvim_win_set_buf(0, p.buf)
+  pcall(vim.api.nvim_win_set_cursor, 0, { p.line, p.col })
+  vim.cmd('normal! zvzz')
+end
+
+local function show_peek(uri, line, col)
+  M.peek_close()
+  local origin = vim.api.nvim_get_current_buf()
+  local buf = vim.uri_to_bufnr(uri)
+  vim.fn.bufload(buf)
+  local width = math.max(20, math.min(vim.api.nvim_win_get_width(0) - 4, 100))
+  local height = math.max(3, math.min(15, vim.api.nvim_win_get_height(0) - vim.fn.winline() - 3))
+  -- The window is not entered; it is scrolled by the mouse wheel, and closed by moving the cursor
+  local win = vim.api.nvim_open_win(buf, false, {
+    relative = 'cursor', row = 1, col = 0, width = width, height = height,
+    border = 'single', focusable = true,
+  })
+  vim.wo[win].cursorline = true
+  vim.wo[win].winhighlight = 'Normal:NormalFloat'
+  pcall(vim.api.nvim_win_set_cursor, win, { line + 1, col })
+  vim.api.nvim_win_call(win, function() vim.cmd('normal! zt') end)
+  peek = { win = win, buf = buf, line = line + 1, col = col }
+  if vim.api.nvim_create_autocmd then
+    vim.api.nvim_create_autocmd({ 'CursorMoved', 'InsertEnter', 'BufLeave' }, {
+      buffer = origin,
+      once = true,
+      callback = function() M.peek_close() end,
+    })
+  end
+  gui('gonvim_peek', win, vim.fn.fnamemodify(vim.api.nvim_buf_get_name(buf), ':~:.'), line + 1)
+end
+
+-- Show the definition of the symbol under the cursor in a floating window below
+-- the cursor, without leaving the current buffer. The location ({uri, line, col},
+-- 0-based as LSP) can be given instead of asking the language server.
+function M.peek_definition(location)
+  if location then
+    show_peek(location.uri, location.line, location.col)
+    return
+  end
+  local get_clients = vim.lsp.get_clients or vim.lsp.get_active_clients
+  local client = get_clients({ bufnr = 0 })[1]
+  if not client then
+    vim.notify('[Gonvim] No language server is attached', vim.log.levels.WARN)
+    return
+  end
+  local params = vim.lsp.util.make_position_params(0, client.offset_encoding)
+  vim.lsp.buf_request_all(0, 'textDocument/definition', params, function(responses)
+    for _, response in pairs(responses) do
+      local result = response.result
+      if result and (result.uri or result.targetUri) then
+        result = { result }
+      end
+      if result and #result > 0 then
+        local loc = result[1]
+        local range = loc.targetSelectionRange or loc.range
+        show_peek(loc.uri or loc.targetUri, range.start.line, range.start.character)
+        return
+      end
+    end
+    vim.notify('[Gonvim] No definition found', vim.log.levels.INFO)
+  end)
+end
+
+-- The byte column (0-based) of the spell error at the cursor of the window,
+-- and the error, or nil if there is no error
+local function spell_error_at_cursor(win)
+  local cursor = vim.api.nvim_win_get_cursor(win)
+  local lnum, col = cursor[1], cursor[2]
+  return vim.api.nvim_win_call(win, function()
+    local view = vim.fn.winsaveview()
+    vim.api.nvim_win_set_cursor(win, {lnum, 0})
+    local found, start, word
+    -- spellbadword() moves the cursor to the error under or after it
+    for _ = 1, 1000 do
+      local result = vim.fn.spellbadword()
+      local pos = vim.api.nvim_win_get_cursor(win)
+      if result[1] == '' or pos[1] ~= lnum or pos[2] > col then
+        break
+      end
+      if col < pos[2] + #result[1] then
+        found, start, word = true, pos[2], result[1]
+        break
+      end
+      vim.api.nvim_win_set_cursor(win, {lnum, pos[2] + #result[1]})
+    end
+    vim.fn.winrestview(view)
+    if found then
+      return lnum, start, word
+    end
+  end)
+end
+
+-- Send the suggestions of spellsuggest() for the spell error at the row and the
+-- column of the window, or at the cursor of the current window
+function M.spell_suggest(win, row, col)
+  local lnum, byte
+  if not win then
+    win = vim.api.nvim_get_current_win()
+    row = vim.fn.winline() - 1
+    col = vim.fn.wincol() - 1
+    local cursor = vim.api.nvim_win_get_cursor(win)
+    lnum, byte = cursor[1], cursor[2]
+  else
+    if not vim.api.nvim_win_is_valid(win) or vim.fn.exists('*virtcol2col') == 0 then
+      return
+    end
+    local items, info = visible_lines(win)
+    local leftcol = vim.api.nvim_win_call(win, vim.fn.winsaveview).leftcol
+    for _, item in ipairs(items) do
+      if not item.closed and row >= item.row and row < item.row + item.height then
+        lnum = item.line
+        local vcol = (row - item.row) * (info.width - info.textoff) + col - info.textoff + leftcol + 1
+        byte = vim.fn.virtcol2col(win, lnum, vcol) - 1
+      end
+    end
+    if not lnum or byte < 0 then
+      return
+    end
+  end
+  local buf = vim.api.nvim_win_get_buf(win)
+  local cursor = vim.api.nvim_win_get_cursor(win)
+  vim.api.nvim_win_set_cursor(win, {lnum, byte})
+  local line, start, word = spell_error_at_cursor(win)
+  vim.api.nvim_win_set_cursor(win, cursor)
+  if not line then
+    return
+  end
+  gui('gonvim_spell', win, row, col, buf, line, start, word, vim.fn.spellsuggest(word, 10))
+end
+
+-- Replace the misspelled word by the suggestion, if the word is still there
+function M.spell_apply(buf, lnum, start, word, suggestion)
+  if not vim.api.nvim_buf_is_valid(buf) then
+    return
+  end
+  local line = vim.api.nvim_buf_get_lines(buf, lnum - 1, lnum, false)[1]
+  if not line or line:sub(start + 1, start + #word) ~= word then
+    return
+  end
+  vim.api.nvim_buf_set_text(buf, lnum - 1, start, lnum - 1, start + #word, {suggestion})
+end
+
+-- Add the word to the spell file, or to the internal word list to ignore it in this session
+function M.spell_good(word, internal)
+  vim.cmd('silent! spellgood' .. (internal and '! ' or ' ') .. word)
+end
+
+-- Send the last search pattern and the count of its matches while 'hlsearch' highlights them
+local last_search = nil
+
+function M.search()
+  local pattern = vim.fn.getreg('/')
+  if vim.v.hlsearch == 0 or not vim.o.hlsearch or pattern == '' then
+    if last_search ~= '' then
+      last_search = ''
+      gui('gonvim_search', '', 0, 0, 0)
+    end
+    return
+  end
+  local ok, count = pcall(vim.fn.searchcount, {maxcount = 999, timeout = 100})
+  if not ok or vim.tbl_isempty(count) then
+    return
+  end
+  local state = table.concat({pattern, count.current, count.total, count.incomplete}, '\n')
+  if state == last_search then
+    return
+  end
+  last_search = state
+  gui('gonvim_search', pattern, count.current, count.total, count.incomplete)
+end
+
+-- Send the files of the project which the user trusts, as 'exrc' is; '' for
+-- the ones not trusted or not found
+function M.read_project_files(dir, paths)
+  vim.schedule(function()
+    local contents = {}
+    for i, path in ipairs(paths) do
+      contents[i] = ''
+      if vim.secure and vim.fn.filereadable(path) == 1 then
+        contents[i] = vim.secure.read(path) or ''
+      end
+    end
+    gui('gonvim_project_files', dir, contents)
+  end)
+end
+
+-- The values of the variables before the ones of the project were set,
+-- false for the unset ones
+local saved_env = {}
+
+-- Set the environment variables of .gonvim.toml of the project, after
+-- restoring the ones changed for the previous project
+function M.set_env(env)
+  for name, value in pairs(saved_env) do
+    vim.env[name] = value or nil
+  end
+  saved_env = {}
+  for name, value in pairs(env) do
+    saved_env[name] = vim.env[name] or false
+    vim.env[name] = value
+  end
+end
+
+-- The views of the windows saved when the workspace is hidden
+local saved_views = {}
+
+-- The buffers of the files have the views restored; the terminals, the help
+-- etc. are only restored by the window when the workspace is shown again
+local function viewport_key(buf)
+  local name = vim.api.nvim_buf_get_name(buf)
+  if name == '' or vim.bo[buf].buftype ~= '' then
+    return nil
+  end
+  return name
+end
+
+local function send_viewport(win, view)
+  local key = viewport_key(vim.api.nvim_win_get_buf(win))
+  if key then
+    gui('gonvim_viewport', key, {lnum = view.lnum, col = view.col, topline = view.topline, leftcol = view.leftcol})
+  end
+end
+
+-- Send the view of the buffer which the current window leaves
+function M.viewport_save()
+  local win = vim.api.nvim_get_current_win()
+  send_viewport(win, vim.fn.winsaveview())
+end
+
+-- Save the views of the windows of the current tabpage when the workspace is
+-- hidden, and send the ones of the buffers
+function M.viewport_save_all()
+  saved_views = {}
+  for _, win in ipairs(normal_windows()) do
+    local view = vim.api.nvim_win_call(win, vim.fn.winsaveview)
+    saved_views[win] = view
+    send_viewport(win, view)
+  end
+end
+
+-- Restore the views saved by viewport_save_all after the grid is resized
+function M.viewport_restore_all()
+  local views = saved_views
+  saved_views = {}
+  vim.defer_fn(function()
+    for win, view in pairs(views) do
+      if vim.api.nvim_win_is_valid(win) then
+        vim.api.nvim_win_call(win, function()
+          vim.fn.winrestview(view)
+        end)
+      end
+    end
+  end, 50)
+end
+
+-- Ask the GUI for the view of the buffer entered in the current window
+function M.viewport_request()
+  local win = vim.api.nvim_get_current_win()
+  local key = viewport_key(vim.api.nvim_win_get_buf(win))
+  if key then
+    gui('gonvim_viewport_request', key, win)
+  end
+end
+
+-- Restore the view of the buffer in the window, unless the cursor has been
+-- moved since it was entered, e.g. by a jump or the last position of shada
+function M.viewport_restore(win, key, view)
+  if not vim.api.nvim_win_is_valid(win) then
+    return
+  end
+  local buf = vim.api.nvim_win_get_buf(win)
+  if viewport_key(buf) ~= key then
+    return
+  end
+  local cursor = vim.api.nvim_win_get_cursor(win)
+  if cursor[1] ~= 1 or cursor[2] ~= 0 then
+    return
+  end
+  local last = vim.api.nvim_buf_line_count(buf)
+  if view.lnum > last then
+    return
+  end
+  vim.api.nvim_win_call(win, function()
+    vim.fn.winrestview({lnum = view.lnum, col = view.col, topline = math.min(view.topline, last), leftcol = view.leftcol})
+  end)
+end
+
+local typewriter = { animate = false }
+
+-- Scroll the window by a line toward the center; returns whether it has scrolled
+local function typewriter_step(win)
+  return vim.api.nvim_win_call(win, function()
+    local center = math.ceil(vim.api.nvim_win_get_height(win) / 2)
+    local row = vim.fn.winline()
+    if row == center then
+      return false
+    end
+    local key = row > center and '<C-e>' or '<C-y>'
+    vim.cmd('normal! ' .. vim.api.nvim_replace_termcodes(key, true, false, true))
+    return vim.fn.winline() ~= row
+  end)
+end
+
+-- Scroll the window by the rows (negative: up)
+function M.scroll_window(win, rows)
+  if not vim.api.nvim_win_is_valid(win) or rows == 0 then
+    return
+  end
+  local key = rows > 0 and '<C-e>' or '<C-y>'
+  vim.api.nvim_win_call(win, function()
+    vim.cmd('normal! ' .. math.abs(rows) .. vim.api.nvim_replace_termcodes(key, true, false, true))
+  end)
+end
+
+-- Keep the cursor line at the center of the current window, scrolling a line
+-- per frame when it is animated
+function M.typewriter_center()
+  local win = vim.api.nvim_get_current_win()
+  if vim.api.nvim_win_get_config(win).relative ~= '' or vim.bo.buftype == 'terminal' then
+    return
+  end
+  if typewriter.timer then
+    typewriter.timer:stop()
+    typewriter.timer:close()
+    typewriter.timer = nil
+  end
+  if not typewriter.animate then
+    vim.api.nvim_win_call(win, function()
+      vim.cmd('normal! zz')
+    end)
+    return
+  end
+  local timer = vim.loop.new_timer()
+  typewriter.timer = timer
+  timer:start(0, 16, vim.schedule_wrap(function()
+    if typewriter.timer ~= timer then
+      return
+    end
+    if not vim.api.nvim_win_is_valid(win) or not typewriter_step(win) then
+      timer:stop()
+      timer:close()
+      typewriter.timer = nil
+    end
+  end))
+end
+
+-- Turn the typewriter scrolling on or off
+function M.typewriter(enable, animate)
+  typewriter.animate = animate
+  vim.cmd('augroup GonvimTypewriter | autocmd! | augroup END')
+  if enable then
+    vim.cmd('autocmd GonvimTypewriter CursorMoved,CursorMovedI,WinEnter * lua require("gonvim").typewriter_center()')
+    M.typewriter_center()
+  end
+end
+
+-- Send the messages of the startup if there are errors in them, e.g. of a
+-- broken init.vim
+function M.startup_errors()
+  local messages = vim.trim(vim.fn.execute('messages'))
+  if messages:find('Error detected while processing', 1, true) then
+    gui('gonvim_startup_errors', messages, false)
+  end
+end
+
+-- Send the RPC channels with the client info set by nvim_set_client_info(),
+-- and the channel of the GUI calling this
+function M.rpc_channels()
+  local channels = {}
+  for _, chan in ipairs(vim.api.nvim_list_chans()) do
+    if chan.mode == 'rpc' then
+      local client = chan.client or {}
+      table.insert(channels, {id = chan.id, name = client.name or '', type = client.type or '', stream = chan.stream or ''})
+    end
+  end
+  gui('gonvim_rpc_channels', channels, vim.api.nvim_get_api_info()[1])
+end
+
+return M
+`
+
+// loadLuaModule makes require("gonvim") available in nvim, and reports whether it is
+func (w *Workspace) loadLuaModule() bool {
+	w.nvim.SetVar("gonvim_api_version", guiAPIVersion)
+	// nvim_execute_lua is available since API level 5
+	if w.capability == nil || w.capability.apiLevel < 5 {
+		return false
+	}
+	code := "package.preload['gonvim'] = function() " + fmt.Sprintf(gonvimLuaModule, guiAPIVersion) + " end"
+	if w.execLua(code) != nil {
+		return false
+	}
+
+	if editor.config.Notification.VimNotify {
+		w.execLua("require('gonvim').setup_notify()")
+	}
+	if editor.config.Editor.LspProgress {
+		w.execLua("require('gonvim').setup_lsp_progress()")
+	}
+	if editor.config.Editor.DapPanel {
+		w.execLua("require('gonvim').setup_dap()")
+	}
+
+	return true
+}
+
+func (w *Workspace) execLua(code string, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	err := w.nvim.Call("nvim_execute_lua", nil, code, args)
+	if err != nil {
+		w.guiStats.setError(err.Error())
+	}
+	return err
+}
+
+// guiNotify shows the notification requested by gonvim_notify
+func (w *Workspace) guiNotify(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	message, ok := args[0].(string)
+	if !ok || message == "" {
+		return
+	}
+	level := NotifyInfo
+	if len(args) >= 2 {
+		switch args[1] {
+		case "warn", "warning", "error":
+			level = NotifyWarn
+		}
+	}
+	period := -1
+	if len(args) >= 3 {
+		period = util.ReflectToInt(args[2])
+	}
+	var opts []NotifyOptionArg
+	if len(args) >= 4 {
+		if buttons := w.notifyButtons(args[3]); len(buttons) > 0 {
+			opts = append(opts, notifyOptionArg(buttons))
+		}
+	}
+	if len(args) >= 5 {
+		opts = append(opts, notifyOptions(args[4])...)
+	}
+	editor.pushNotification(level, period, message, opts...)
+}
+
+// notifyOptions converts {"sticky": bool, "countdown": bool, "pause_on_hover": bool}
+func notifyOptions(arg interface{}) []NotifyOptionArg {
+	options, ok := arg.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var opts []NotifyOptionArg
+	if util.IsTrue(options["sticky"]) {
+		opts = append(opts, notifySticky())
+	}
+	if util.IsTrue(options["countdown"]) {
+		opts = append(opts, notifyCountdown())
+	}
+	if util.IsTrue(options["pause_on_hover"]) {
+		opts = append(opts, notifyPauseOnHover())
+	}
+
+	return opts
+}
+
+func (w *Workspace) notifyButtons(arg interface{}) []*NotifyButton {
+	items, ok := arg.([]interface{})
+	if !ok {
+		return nil
+	}
+	var buttons []*NotifyButton
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		b := &NotifyButton{}
+		b.text, _ = item["text"].(string)
+		b.function, _ = item["function"].(string)
+		if id, ok := item["id"]; ok {
+			b.callbackID = util.ReflectToInt(id)
+		}
+		b.args, _ = item["args"].([]interface{})
+		if b.text == "" || (b.function == "" && b.callbackID == 0) {
+			continue
+		}
+		b.action = func() {
+			w.callNotifyButton(b)
+		}
+		buttons = append(buttons, b)
+	}
+
+	return buttons
+}
+
+// callNotifyButton calls the function of the button in nvim
+func (w *Workspace) callNotifyButton(b *NotifyButton) {
+	var err error
+	if b.callbackID != 0 {
+		err = w.nvim.Call("nvim_call_function", nil, "luaeval", []interface{}{"require('gonvim')._ui_callback(_A)", b.callbackID})
+	} else {
+		args := b.args
+		if args == nil {
+			args = []interface{}{}
+		}
+		err = w.nvim.Call("nvim_call_function", nil, b.function, args)
+	}
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+	}
+}
@@ -0,0 +1,254 @@
+package editor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Harness runs the script of --headless-script against the windows of the
+// screen, as the GUI has drawn them from the redraw events. Each line of the
+// script is a step:
+//
+//	# a comment
+//	keys ihello<Esc>
+//	command set number
+//	wait 100
+//	timeout 5000
+//	expect-text 0 hello
+//	expect-contains 0 hell
+//	expect-hl 0 0 Normal
+//	snapshot hello.txt
+//
+// The rows and the columns are of the screen, 0-based. The expectations are
+// retried until the timeout (2 seconds by default), since nvim redraws
+// asynchronously. The text of a row is compared without the trailing spaces.
+type Harness struct {
+	ws *Workspace
+}
+
+// harnessCell is a cell of the screen; the text and the highlight group
+type harnessCell struct {
+	text string
+	hl   string
+}
+
+// harnessScreen is the cells of the windows composed as they are shown
+type harnessScreen [][]harnessCell
+
+// snapshot composes the windows of the screen; it is called on the Qt thread
+func (s *Screen) snapshot() harnessScreen {
+	var wins []*Window
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if ok && win != nil && (win.grid == 1 || win.isShown()) {
+			wins = append(wins, win)
+		}
+		return true
+	})
+
+	return composeWindows(s.ws.cols, s.ws.rows, wins)
+}
+
+// composeWindows puts the windows on the screen; the global grid, the windows,
+// and then the floating windows and the messages over them
+func composeWindows(cols, rows int, wins []*Window) harnessScreen {
+	layer := func(win *Window) int {
+		switch {
+		case win.grid == 1:
+			return 0
+		case win.isFloatWin || win.isMsgGrid:
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(wins, func(i, j int) bool {
+		if layer(wins[i]) != layer(wins[j]) {
+			return layer(wins[i]) < layer(wins[j])
+		}
+		return wins[i].grid < wins[j].grid
+	})
+
+	screen := make(harnessScreen, rows)
+	for y := range screen {
+		screen[y] = make([]harnessCell, cols)
+		for x := range screen[y] {
+			screen[y][x] = harnessCell{text: " ", hl: "Normal"}
+		}
+	}
+	for _, win := range wins {
+		for y, line := range win.content {
+			for x, cell := range line {
+				row, col := win.pos[1]+y, win.pos[0]+x
+				if win.grid == 1 {
+					row, col = y, x
+				}
+				if cell == nil || row < 0 || row >= rows || col < 0 || col >= cols {
+					continue
+				}
+				hl := "Normal"
+				if cell.highlight != nil && cell.highlight.hlName != "" {
+					hl = cell.highlight.hlName
+				}
+				screen[row][col] = harnessCell{text: cell.char, hl: hl}
+			}
+		}
+	}
+
+	return screen
+}
+
+// text returns the text of the row
+func (s harnessScreen) text(row int) string {
+	if row < 0 || row >= len(s) {
+		return ""
+	}
+	var b strings.Builder
+	for _, cell := range s[row] {
+		b.WriteString(cell.text)
+	}
+	return b.String()
+}
+
+// String returns the text of the rows, separated by newlines
+func (s harnessScreen) String() string {
+	lines := make([]string, len(s))
+	for y := range lines {
+		lines[y] = s.text(y)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// screen asks the Qt thread for the snapshot of the screen
+func (h *Harness) screen() harnessScreen {
+	reply := make(chan harnessScreen, 1)
+	h.ws.guiUpdates <- []interface{}{"gonvim_harness_snapshot", reply}
+	h.ws.signal.GuiSignal()
+
+	return <-reply
+}
+
+// run runs the script and exits with 1 if a step failed
+func (h *Harness) run(path string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "headless:", err)
+		os.Exit(1)
+	}
+	timeout := 2 * time.Second
+	failed := 0
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	lnum := 0
+	for scanner.Scan() {
+		lnum++
+		step, arg, ok := parseHarnessStep(scanner.Text())
+		if !ok {
+			continue
+		}
+		var err error
+		switch step {
+		case "keys":
+			_, err = h.ws.nvim.Input(arg)
+		case "command":
+			err = h.ws.nvim.Command(arg)
+		case "wait":
+			var ms int
+			ms, err = strconv.Atoi(arg)
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+		case "timeout":
+			var ms int
+			ms, err = strconv.Atoi(arg)
+			timeout = time.Duration(ms) * time.Millisecond
+		case "expect-text", "expect-contains", "expect-hl":
+			err = h.expect(step, arg, timeout)
+		case "snapshot":
+			err = ioutil.WriteFile(arg, []byte(h.screen().String()+"\n"), 0644)
+		default:
+			err = fmt.Errorf("unknown step %q", step)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "headless: %s:%d: %s\n", path, lnum, err)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "headless: %d steps failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("headless: all steps passed")
+	os.Exit(0)
+}
+
+// parseHarnessStep splits a line of the script into the step and its
+// argument; it reports false for the blank lines and the comments
+func parseHarnessStep(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	if i := strings.Index(line, " "); i >= 0 {
+		return line[:i], strings.TrimSpace(line[i+1:]), true
+	}
+	return line, "", true
+}
+
+// expect checks an expectation until it holds or the timeout passes
+func (h *Harness) expect(step, arg string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkExpectation(h.screen(), step, arg)
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// checkExpectation checks an expectation of the script on the screen
+func checkExpectation(screen harnessScreen, step, arg string) error {
+	fields := strings.SplitN(arg, " ", 3)
+	if len(fields) < 2 {
+		return fmt.Errorf("%s: too few arguments", step)
+	}
+	row, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return err
+	}
+	var got, want string
+	var ok bool
+	switch step {
+	case "expect-text", "expect-contains":
+		want = strings.Join(fields[1:], " ")
+		got = strings.TrimRight(screen.text(row), " ")
+		if step == "expect-text" {
+			ok = got == want
+		} else {
+			ok = strings.Contains(got, want)
+		}
+	case "expect-hl":
+		if len(fields) < 3 {
+			return fmt.Errorf("%s: too few arguments", step)
+		}
+		col, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return err
+		}
+		want = fields[2]
+		if row >= 0 && row < len(screen) && col >= 0 && col < len(screen[row]) {
+			got = screen[row][col].hl
+		}
+		ok = got == want
+	default:
+		return fmt.Errorf("unknown step %q", step)
+	}
+	if !ok {
+		return fmt.Errorf("%s: want %q, got %q", step, want, got)
+	}
+
+	return nil
+}
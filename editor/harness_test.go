@@ -0,0 +1,136 @@
+package editor
+
+import (
+	"testing"
+)
+
+// testWindow returns a shown window with the lines, the cells of the text highlighted by hl
+func testWindow(grid gridId, col, row int, hl string, lines ...string) *Window {
+	win := &Window{
+		grid:  grid,
+		pos:   [2]int{col, row},
+		rows:  len(lines),
+		shown: true,
+	}
+	highlight := &Highlight{hlName: hl}
+	for _, l := range lines {
+		var line []*Cell
+		for _, c := range l {
+			line = append(line, &Cell{char: string(c), highlight: highlight})
+		}
+		if len(line) > win.cols {
+			win.cols = len(line)
+		}
+		win.content = append(win.content, line)
+	}
+
+	return win
+}
+
+func TestComposeWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		wins []*Window
+		want string
+	}{
+		{
+			name: "global grid",
+			wins: []*Window{testWindow(1, 0, 0, "", "abcd", "efgh")},
+			want: "abcd\nefgh",
+		},
+		{
+			name: "window at its position",
+			wins: []*Window{
+				testWindow(1, 0, 0, "", "....", "...."),
+				testWindow(2, 1, 1, "", "xy"),
+			},
+			want: "....\n.xy.",
+		},
+		{
+			name: "float over the window regardless of the order",
+			wins: func() []*Window {
+				float := testWindow(3, 1, 0, "", "f")
+				float.isFloatWin = true
+				return []*Window{float, testWindow(2, 0, 0, "", "wwww")}
+			}(),
+			want: "wfww\n    ",
+		},
+		{
+			name: "clipped to the screen",
+			wins: []*Window{testWindow(2, 3, 1, "", "xyz")},
+			want: "    \n   x",
+		},
+		{
+			name: "cells not drawn yet",
+			wins: func() []*Window {
+				win := testWindow(2, 0, 0, "", "ab")
+				win.content[0][0] = nil
+				return []*Window{win}
+			}(),
+			want: " b  \n    ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := composeWindows(4, 2, tt.wins).String()
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckExpectation(t *testing.T) {
+	screen := composeWindows(8, 2, []*Window{
+		testWindow(1, 0, 0, "", "hello", "world"),
+		testWindow(2, 0, 1, "Search", "wor"),
+	})
+	tests := []struct {
+		step string
+		arg  string
+		ok   bool
+	}{
+		{"expect-text", "0 hello", true},
+		{"expect-text", "0 hell", false},
+		{"expect-text", "1 world", true},
+		{"expect-text", "2 hello", false},
+		{"expect-contains", "0 ell", true},
+		{"expect-contains", "1 xyz", false},
+		{"expect-hl", "0 0 Normal", true},
+		{"expect-hl", "1 2 Search", true},
+		{"expect-hl", "1 3 Search", false},
+		{"expect-hl", "1 9 Normal", false},
+		{"expect-hl", "1 0", false},
+		{"expect-text", "x hello", false},
+		{"expect-text", "0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.step+" "+tt.arg, func(t *testing.T) {
+			err := checkExpectation(screen, tt.step, tt.arg)
+			if (err == nil) != tt.ok {
+				t.Errorf("got %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseHarnessStep(t *testing.T) {
+	tests := []struct {
+		line string
+		step string
+		arg  string
+		ok   bool
+	}{
+		{"", "", "", false},
+		{"  # comment", "", "", false},
+		{"keys ihello<Esc>", "keys", "ihello<Esc>", true},
+		{"  command   set number ", "command", "set number", true},
+		{"snapshot", "snapshot", "", true},
+	}
+	for _, tt := range tests {
+		step, arg, ok := parseHarnessStep(tt.line)
+		if step != tt.step || arg != tt.arg || ok != tt.ok {
+			t.Errorf("parseHarnessStep(%q) = %q, %q, %v, want %q, %q, %v", tt.line, step, arg, ok, tt.step, tt.arg, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/therecipe/qt/core"
+)
+
+// --headless is a smoke test of the whole GUI: it attaches to nvim, renders the
+// first frame with the offscreen platform plugin of Qt and exits with 0 if it
+// has been drawn. It is not a way to unit test the parts of the GUI. Of them,
+// only the model of the grids is free of Qt, in the grid package; the key
+// conversion, the palette, the sessions and the RPC handling use the Qt types
+// directly, and are tested by go test only where they are plain functions.
+
+// headlessTimeout is the time to wait for the first frame in --headless
+const headlessTimeout = 30 * time.Second
+
+// prepareHeadless makes Qt render to an offscreen surface, it must be called
+// before the QApplication is created
+func (e *Editor) prepareHeadless() {
+	if e.opts.HeadlessScript != "" {
+		e.opts.Headless = true
+	}
+	if !e.opts.Headless {
+		return
+	}
+	os.Setenv("QT_QPA_PLATFORM", "offscreen")
+	e.headlessTimer = time.AfterFunc(headlessTimeout, func() {
+		fmt.Fprintln(os.Stderr, "headless: timed out waiting for the first frame")
+		os.Exit(1)
+	})
+}
+
+// finishHeadless is called instead of deferredInit in --headless. It saves the
+// rendered grid if --headless-output is given, reports it and exits, or runs
+// the script of --headless-script.
+func (e *Editor) finishHeadless() {
+	e.headlessTimer.Stop()
+	ws := e.workspaces[e.active]
+	if e.opts.HeadlessScript != "" {
+		harness := &Harness{ws: ws}
+		go harness.run(e.opts.HeadlessScript)
+		return
+	}
+	grids := 0
+	ws.screen.windows.Range(func(_, _ interface{}) bool {
+		grids++
+		return true
+	})
+	pixmap := ws.screen.widget.Grab(core.NewQRect4(0, 0, -1, -1))
+	if pixmap.IsNull() || grids == 0 || ws.rows == 0 || ws.cols == 0 {
+		fmt.Fprintln(os.Stderr, "headless: failed to render the grid")
+		os.Exit(1)
+	}
+	if e.opts.HeadlessOutput != "" && !pixmap.Save(e.opts.HeadlessOutput, "PNG", -1) {
+		fmt.Fprintln(os.Stderr, "headless: failed to save "+e.opts.HeadlessOutput)
+		os.Exit(1)
+	}
+	fmt.Printf("headless: rendered %dx%d, %d grids, %dx%d cells\n", pixmap.Width(), pixmap.Height(), grids, ws.cols, ws.rows)
+	// Neither the session nor the window geometry are saved
+	os.Exit(0)
+}
@@ -0,0 +1,145 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// Hotkey is a key combination registered to the OS as a global shortcut
+type Hotkey struct {
+	ctrl  bool
+	alt   bool
+	shift bool
+	super bool
+	key   string
+}
+
+// parseHotkey parses a key combination such as "Ctrl+Alt+Space", "Super+F12"
+// or "Ctrl++". The key is taken from the right, so that it can be "+".
+func parseHotkey(s string) (*Hotkey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("hotkey is empty")
+	}
+	h := &Hotkey{}
+	var modifiers string
+	if strings.HasSuffix(s, "++") || s == "+" {
+		h.key = "+"
+		modifiers = strings.TrimSuffix(s[:len(s)-1], "+")
+	} else {
+		i := strings.LastIndex(s, "+")
+		h.key = strings.ToUpper(strings.TrimSpace(s[i+1:]))
+		if i > 0 {
+			modifiers = s[:i]
+		}
+	}
+	if modifiers == "" {
+		if h.key == "" {
+			return nil, fmt.Errorf("no key in hotkey: %s", s)
+		}
+		return h, nil
+	}
+	for _, p := range strings.Split(modifiers, "+") {
+		p = strings.TrimSpace(p)
+		switch strings.ToLower(p) {
+		case "ctrl", "control":
+			h.ctrl = true
+		case "alt", "option":
+			h.alt = true
+		case "shift":
+			h.shift = true
+		case "super", "win", "cmd", "meta":
+			h.super = true
+		default:
+			return nil, fmt.Errorf("unknown modifier: %s", p)
+		}
+	}
+	if h.key == "" {
+		return nil, fmt.Errorf("no key in hotkey: %s", s)
+	}
+
+	return h, nil
+}
+
+func (e *Editor) initGlobalHotkey() {
+	if e.config.Editor.GlobalHotkey == "" {
+		return
+	}
+	hotkey, err := parseHotkey(e.config.Editor.GlobalHotkey)
+	if err != nil {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+	e.signal.ConnectToggleWindowSignal(func() {
+		e.toggleWindow()
+	})
+	go func() {
+		err := registerGlobalHotkey(hotkey, func() {
+			e.signal.ToggleWindowSignal()
+		})
+		if err != nil {
+			e.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to register the global hotkey: "+err.Error())
+		}
+	}()
+}
+
+// toggleWindow hides the window if it has focus, otherwise shows and focuses it
+func (e *Editor) toggleWindow() {
+	if e.window.IsVisible() && e.window.IsActiveWindow() {
+		if e.config.Editor.QuakeMode {
+			e.slideQuakeWindow(false)
+		} else {
+			e.window.Hide()
+		}
+		return
+	}
+
+	if e.config.Editor.QuakeMode {
+		e.slideQuakeWindow(true)
+	} else {
+		e.window.Show()
+	}
+	e.window.Raise()
+	e.window.ActivateWindow()
+	e.wsWidget.SetFocus2()
+}
+
+// slideQuakeWindow slides the window down from (or up to) the top edge of the primary monitor
+func (e *Editor) slideQuakeWindow(show bool) {
+	screen := gui.QGuiApplication_PrimaryScreen()
+	if screen == nil {
+		return
+	}
+	geo := screen.AvailableGeometry()
+	// The window is shorter than its minimum size while it slides
+	minimumSize := e.window.MinimumSize()
+	height := int(float64(geo.Height()) * e.config.Editor.QuakeHeightRatio)
+	shown := core.NewQRect4(geo.X(), geo.Y(), geo.Width(), height)
+	hidden := core.NewQRect4(geo.X(), geo.Y()-height, geo.Width(), height)
+
+	e.window.SetMinimumSize2(0, 0)
+	animation := core.NewQPropertyAnimation2(e.window, core.NewQByteArray2("geometry", len("geometry")), e.window)
+	animation.SetDuration(e.animationDuration(180))
+	animation.SetEasingCurve(core.NewQEasingCurve(core.QEasingCurve__OutCubic))
+	if show {
+		e.window.SetGeometry(hidden)
+		e.window.Show()
+		animation.SetStartValue(core.NewQVariant31(hidden))
+		animation.SetEndValue(core.NewQVariant31(shown))
+		animation.ConnectFinished(func() {
+			e.window.SetMinimumSize(minimumSize)
+		})
+	} else {
+		animation.SetStartValue(core.NewQVariant31(shown))
+		animation.SetEndValue(core.NewQVariant31(hidden))
+		animation.ConnectFinished(func() {
+			e.window.Hide()
+			e.window.SetMinimumSize(minimumSize)
+		})
+	}
+	animation.Start(core.QAbstractAnimation__DeleteWhenStopped)
+}
@@ -0,0 +1,112 @@
+// +build darwin
+
+package editor
+
+/*
+#cgo LDFLAGS: -framework Carbon
+#include <Carbon/Carbon.h>
+#include <dispatch/dispatch.h>
+
+extern void globalHotkeyPressed(void);
+
+static OSStatus hotkeyHandler(EventHandlerCallRef next, EventRef event, void *data) {
+	globalHotkeyPressed();
+	return noErr;
+}
+
+typedef struct {
+	UInt32 keyCode;
+	UInt32 modifiers;
+	OSStatus status;
+} hotkeyRegistration;
+
+static void registerHotkeyOnMain(void *ctx) {
+	hotkeyRegistration *r = ctx;
+	EventTypeSpec spec = { kEventClassKeyboard, kEventHotKeyPressed };
+	r->status = InstallApplicationEventHandler(NewEventHandlerUPP(hotkeyHandler), 1, &spec, NULL, NULL);
+	if (r->status != noErr) {
+		return;
+	}
+	// The signature is 'gnvm'
+	EventHotKeyID id = { 0x676E766D, 1 };
+	EventHotKeyRef ref;
+	r->status = RegisterEventHotKey(r->keyCode, r->modifiers, id, GetApplicationEventTarget(), 0, &ref);
+}
+
+// registerHotkey registers the hotkey on the main thread, whose run loop dispatches its events
+static OSStatus registerHotkey(UInt32 keyCode, UInt32 modifiers) {
+	hotkeyRegistration r = { keyCode, modifiers, noErr };
+	dispatch_sync_f(dispatch_get_main_queue(), &r, registerHotkeyOnMain);
+	return r.status;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+)
+
+// Carbon modifier masks of Events.h
+const (
+	carbonCmdKey     = 0x0100
+	carbonShiftKey   = 0x0200
+	carbonOptionKey  = 0x0800
+	carbonControlKey = 0x1000
+)
+
+// carbonKeyCodes are the virtual key codes of the ANSI keyboard (kVK_*)
+var carbonKeyCodes = map[string]uint32{
+	"A": 0x00, "S": 0x01, "D": 0x02, "F": 0x03, "H": 0x04, "G": 0x05, "Z": 0x06,
+	"X": 0x07, "C": 0x08, "V": 0x09, "B": 0x0B, "Q": 0x0C, "W": 0x0D, "E": 0x0E,
+	"R": 0x0F, "Y": 0x10, "T": 0x11, "O": 0x1F, "U": 0x20, "I": 0x22, "P": 0x23,
+	"L": 0x25, "J": 0x26, "K": 0x28, "N": 0x2D, "M": 0x2E,
+	"1": 0x12, "2": 0x13, "3": 0x14, "4": 0x15, "6": 0x16, "5": 0x17, "9": 0x19,
+	"7": 0x1A, "8": 0x1C, "0": 0x1D,
+	"+": 0x18, "=": 0x18, "-": 0x1B,
+	"ENTER": 0x24, "RETURN": 0x24, "TAB": 0x30, "SPACE": 0x31, "ESC": 0x35, "ESCAPE": 0x35,
+	"F1": 0x7A, "F2": 0x78, "F3": 0x63, "F4": 0x76, "F5": 0x60, "F6": 0x61,
+	"F7": 0x62, "F8": 0x64, "F9": 0x65, "F10": 0x6D, "F11": 0x67, "F12": 0x6F,
+	"F13": 0x69, "F14": 0x6B, "F15": 0x71, "F16": 0x6A, "F17": 0x40, "F18": 0x4F,
+	"F19": 0x50, "F20": 0x5A,
+}
+
+// globalHotkeyFn is called on the main thread when the hotkey is pressed
+var globalHotkeyFn func()
+
+//export globalHotkeyPressed
+func globalHotkeyPressed() {
+	if globalHotkeyFn != nil {
+		globalHotkeyFn()
+	}
+}
+
+// registerGlobalHotkey registers the hotkey with RegisterEventHotKey of
+// Carbon. Its events are dispatched to fn on the main thread by the run loop
+// of Qt, so it returns once the hotkey is registered.
+func registerGlobalHotkey(hotkey *Hotkey, fn func()) error {
+	keyCode, ok := carbonKeyCodes[hotkey.key]
+	if !ok {
+		return fmt.Errorf("unsupported key: %s", hotkey.key)
+	}
+	var mod uint32
+	if hotkey.alt {
+		mod |= carbonOptionKey
+	}
+	if hotkey.ctrl {
+		mod |= carbonControlKey
+	}
+	if hotkey.shift {
+		mod |= carbonShiftKey
+	}
+	if hotkey.super {
+		mod |= carbonCmdKey
+	}
+
+	globalHotkeyFn = fn
+	if status := C.registerHotkey(C.UInt32(keyCode), C.UInt32(mod)); status != C.noErr {
+		globalHotkeyFn = nil
+		return fmt.Errorf("RegisterEventHotKey failed: %d", int(status))
+	}
+
+	return nil
+}
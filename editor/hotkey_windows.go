@@ -0,0 +1,111 @@
+// +build windows
+
+package editor
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modShift    = 0x0004
+	modWin      = 0x0008
+	modNoRepeat = 0x4000
+
+	wmHotkey = 0x0312
+)
+
+var (
+	user32             = syscall.NewLazyDLL("user32.dll")
+	procRegisterHotKey = user32.NewProc("RegisterHotKey")
+	procGetMessage     = user32.NewProc("GetMessageW")
+)
+
+type winMsg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	x, y    int32
+}
+
+// virtualKeyCode converts a key name into a Windows virtual-key code
+func virtualKeyCode(key string) (uintptr, error) {
+	if len(key) == 1 {
+		c := key[0]
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return uintptr(c), nil
+		}
+	}
+	if len(key) >= 2 && key[0] == 'F' {
+		var n int
+		if _, err := fmt.Sscanf(key[1:], "%d", &n); err == nil && n >= 1 && n <= 24 {
+			return uintptr(0x70 + n - 1), nil
+		}
+	}
+	switch key {
+	case "SPACE":
+		return 0x20, nil
+	case "ENTER", "RETURN":
+		return 0x0D, nil
+	case "TAB":
+		return 0x09, nil
+	case "ESC", "ESCAPE":
+		return 0x1B, nil
+	case "+", "=":
+		// VK_OEM_PLUS
+		return 0xBB, nil
+	case "-":
+		// VK_OEM_MINUS
+		return 0xBD, nil
+	}
+
+	return 0, fmt.Errorf("unsupported key: %s", key)
+}
+
+// registerGlobalHotkey registers the hotkey and blocks while dispatching its events to fn
+func registerGlobalHotkey(hotkey *Hotkey, fn func()) error {
+	vk, err := virtualKeyCode(hotkey.key)
+	if err != nil {
+		return err
+	}
+	mod := uintptr(modNoRepeat)
+	if hotkey.alt {
+		mod |= modAlt
+	}
+	if hotkey.ctrl {
+		mod |= modControl
+	}
+	if hotkey.shift {
+		mod |= modShift
+	}
+	if hotkey.super {
+		mod |= modWin
+	}
+
+	// The hotkey message is posted to the thread that registered it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	id := uintptr(1)
+	ret, _, err := procRegisterHotKey.Call(0, id, mod, vk)
+	if ret == 0 {
+		return err
+	}
+
+	var msg winMsg
+	for {
+		ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return nil
+		}
+		if msg.message == wmHotkey && msg.wParam == id {
+			fn()
+		}
+	}
+}
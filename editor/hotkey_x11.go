@@ -0,0 +1,112 @@
+// +build !windows,!darwin
+
+package editor
+
+/*
+#cgo LDFLAGS: -lX11
+#include <stdlib.h>
+#include <X11/Xlib.h>
+
+static int grabError;
+
+static int handleGrabError(Display *dpy, XErrorEvent *ev) {
+	grabError = ev->error_code;
+	return 0;
+}
+
+// grabKey grabs the key on the root window with the modifiers, also with
+// CapsLock and NumLock on, and returns the X error of the grab
+static int grabKey(Display *dpy, KeyCode keycode, unsigned int modifiers) {
+	unsigned int locks[] = { 0, LockMask, Mod2Mask, LockMask | Mod2Mask };
+	XErrorHandler old = XSetErrorHandler(handleGrabError);
+	grabError = 0;
+	for (int i = 0; i < 4; i++) {
+		XGrabKey(dpy, keycode, modifiers | locks[i], DefaultRootWindow(dpy), True, GrabModeAsync, GrabModeAsync);
+	}
+	XSync(dpy, False);
+	XSetErrorHandler(old);
+	return grabError;
+}
+
+static int nextKeyPress(Display *dpy) {
+	XEvent ev;
+	XNextEvent(dpy, &ev);
+	return ev.type == KeyPress;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// keysymName converts a key name into the name of its X keysym
+func keysymName(key string) string {
+	switch key {
+	case "SPACE":
+		return "space"
+	case "ENTER", "RETURN":
+		return "Return"
+	case "TAB":
+		return "Tab"
+	case "ESC", "ESCAPE":
+		return "Escape"
+	case "+", "=":
+		return "equal"
+	case "-":
+		return "minus"
+	}
+	if len(key) == 1 {
+		return strings.ToLower(key)
+	}
+
+	return key
+}
+
+// registerGlobalHotkey grabs the hotkey with XGrabKey on a connection of its
+// own to the X server, and blocks while dispatching its events to fn
+func registerGlobalHotkey(hotkey *Hotkey, fn func()) error {
+	dpy := C.XOpenDisplay(nil)
+	if dpy == nil {
+		return errors.New("cannot open the X display, a global hotkey needs X11")
+	}
+	defer C.XCloseDisplay(dpy)
+
+	name := C.CString(keysymName(hotkey.key))
+	defer C.free(unsafe.Pointer(name))
+	keysym := C.XStringToKeysym(name)
+	if keysym == C.NoSymbol {
+		return fmt.Errorf("unsupported key: %s", hotkey.key)
+	}
+	keycode := C.XKeysymToKeycode(dpy, keysym)
+	if keycode == 0 {
+		return fmt.Errorf("no key of the keyboard for %s", hotkey.key)
+	}
+
+	var mod C.uint
+	if hotkey.alt {
+		mod |= C.Mod1Mask
+	}
+	if hotkey.ctrl {
+		mod |= C.ControlMask
+	}
+	if hotkey.shift {
+		mod |= C.ShiftMask
+	}
+	if hotkey.super {
+		mod |= C.Mod4Mask
+	}
+	if code := C.grabKey(dpy, keycode, mod); code != 0 {
+		// BadAccess if another client has grabbed the key
+		return fmt.Errorf("XGrabKey failed: error code %d", int(code))
+	}
+
+	for {
+		if C.nextKeyPress(dpy) != 0 {
+			fn()
+		}
+	}
+}
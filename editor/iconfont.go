@@ -0,0 +1,76 @@
+package editor
+
+import (
+	"fmt"
+	"html"
+)
+
+// nerdFontGlyphs are the Nerd Font glyphs used instead of the SVG icons of the same name
+// when [icons] nerdFont is enabled. The icons not in the table, nor in [icons.glyphs]
+// of the config, are drawn by the SVG icons as before.
+var nerdFontGlyphs = map[string]string{
+	"default":    "\uf15b", // nf-fa-file
+	"directory":  "\uf07b", // nf-fa-folder
+	"folder":     "\uf07b",
+	"go":         "\ue627", // nf-seti-go
+	"py":         "\ue606", // nf-seti-python
+	"pyc":        "\ue606",
+	"js":         "\ue74e", // nf-dev-javascript
+	"ts":         "\ue628", // nf-seti-typescript
+	"json":       "\ue60b", // nf-seti-json
+	"markdown":   "\ue609", // nf-seti-markdown
+	"sh":         "\ue795", // nf-dev-terminal
+	"c":          "\ue61e", // nf-custom-c
+	"h":          "\ue61e",
+	"cpp":        "\ue61d", // nf-custom-cpp
+	"rs":         "\ue7a8", // nf-dev-rust
+	"lua":        "\ue620", // nf-seti-lua
+	"vim":        "\ue62b", // nf-custom-vim
+	"html":       "\ue736", // nf-dev-html5
+	"css":        "\ue749", // nf-dev-css3
+	"java":       "\ue738", // nf-dev-java
+	"rb":         "\ue739", // nf-dev-ruby
+	"php":        "\ue73d", // nf-dev-php
+	"dockerfile": "\ue7b0", // nf-dev-docker
+
+	"lsp_function": "\uea8c", // nf-cod-symbol_method
+	"lsp_method":   "\uea8c",
+	"lsp_variable": "\uea88", // nf-cod-symbol_variable
+	"lsp_class":    "\ueb5b", // nf-cod-symbol_class
+	"lsp_struct":   "\uea91", // nf-cod-symbol_structure
+	"lsp_const":    "\ueb5d", // nf-cod-symbol_constant
+	"lsp_constant": "\ueb5d",
+	"lsp_module":   "\uea8b", // nf-cod-symbol_namespace
+	"lsp_keyword":  "\ueb62", // nf-cod-symbol_keyword
+	"lsp_package":  "\ueb29", // nf-cod-package
+}
+
+const glyphSvgTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24">` +
+	`<text x="12" y="19" font-family="%s" font-size="20" text-anchor="middle" fill="%s">%s</text></svg>`
+
+// nerdFontGlyph returns the glyph of the icon, the ones of the config first
+func (e *Editor) nerdFontGlyph(name string) (string, bool) {
+	if !e.config.Icons.NerdFont {
+		return "", false
+	}
+	if glyph, ok := e.config.Icons.Glyphs[name]; ok {
+		return glyph, glyph != ""
+	}
+	// The icon packs are preferred to the built-in glyphs
+	if svg := e.svgs[name]; svg != nil && svg.isUser {
+		return "", false
+	}
+	glyph, ok := nerdFontGlyphs[name]
+
+	return glyph, ok
+}
+
+// glyphSvg draws the glyph as an SVG, so that it is used in the same way as the SVG icons
+func (e *Editor) glyphSvg(glyph string, color *RGBA) string {
+	family := e.config.Icons.FontFamily
+	if family == "" {
+		family = e.extFontFamily
+	}
+
+	return fmt.Sprintf(glyphSvgTemplate, html.EscapeString(family), color.Hex(), html.EscapeString(glyph))
+}
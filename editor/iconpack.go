@@ -0,0 +1,42 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// loadIconPack loads the SVG files in ~/.goneovim/icons, which override or add the
+// icons of the same name as the file name without the extension, e.g. "go.svg" for
+// the Go files, "cross.svg" for the close buttons.
+// "currentColor" in the files is replaced with the color the icon is drawn in.
+func (e *Editor) loadIconPack() {
+	dir := filepath.Join(e.homeDir, ".goneovim", "icons")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".svg" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), ".svg")
+		xml := strings.Replace(string(data), "%", "%%", -1)
+		// Every placeholder refers to the one color
+		xml = strings.Replace(xml, "currentColor", "%[1]s", -1)
+		if !strings.Contains(xml, "%[1]s") {
+			// Drawn in the colors of the file, the color is ignored
+			xml += "<!-- %[1]s -->"
+		}
+		e.svgs[name] = &SvgXML{
+			xml:    xml,
+			isUser: true,
+		}
+	}
+}
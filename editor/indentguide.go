@@ -0,0 +1,127 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// IndentGuides is the indent of each row of a window, sent by
+// require("gonvim").indent_guides(), which takes the horizontal scroll, the
+// folds and the wrapped lines into account
+type IndentGuides struct {
+	rows       []int
+	leftcol    int
+	textoff    int
+	shiftwidth int
+
+	// The guide of the treesitter scope around the cursor, if any
+	hasScope   bool
+	scopeCol   int
+	scopeFirst int
+	scopeLast  int
+}
+
+// setIndentGuides handles gonvim_indent_guides
+func (s *Screen) setIndentGuides(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	items, _ := args[0].([]interface{})
+	guides := make(map[int]*IndentGuides)
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		g := &IndentGuides{
+			leftcol:    util.ReflectToInt(item["leftcol"]),
+			textoff:    util.ReflectToInt(item["textoff"]),
+			shiftwidth: util.ReflectToInt(item["sw"]),
+		}
+		rows, _ := item["rows"].([]interface{})
+		for _, r := range rows {
+			g.rows = append(g.rows, util.ReflectToInt(r))
+		}
+		if scope, ok := item["scope"].(map[string]interface{}); ok {
+			g.hasScope = true
+			g.scopeCol = util.ReflectToInt(scope["col"])
+			g.scopeFirst = util.ReflectToInt(scope["first"])
+			g.scopeLast = util.ReflectToInt(scope["last"])
+		}
+		guides[util.ReflectToInt(item["win"])] = g
+	}
+
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		g, ok := guides[int(win.id)]
+		if !ok {
+			return true
+		}
+		win.indentGuides = g
+		if win.widget != nil {
+			win.widget.Update()
+		}
+		return true
+	})
+}
+
+// drawIndentGuides draws the guides at every shiftwidth of the indent of the rows
+func (w *Window) drawIndentGuides(p *gui.QPainter, row, rows int) {
+	if w == nil || w.isMsgGrid || w.isFloatWin || !w.isShown() {
+		return
+	}
+	g := w.indentGuides
+	if g == nil || g.shiftwidth <= 0 {
+		return
+	}
+	for y := row; y < row+rows && y < len(g.rows) && y < len(w.content); y++ {
+		indent := g.rows[y]
+		for col := 0; col < indent; col += g.shiftwidth {
+			x := g.textoff + col - g.leftcol
+			if x < g.textoff {
+				continue
+			}
+			if x >= len(w.content[y]) {
+				break
+			}
+			// Do not draw over the text of a wrapped line
+			if cell := w.content[y][x]; cell != nil && cell.char != " " && cell.char != "" {
+				continue
+			}
+			color := editor.colors.indentGuide
+			if g.hasScope && col == g.scopeCol && y >= g.scopeFirst && y <= g.scopeLast {
+				color = editor.colors.indentGuideScope
+			}
+			w.drawIndentline(p, x, y, color)
+		}
+	}
+}
+
+func (w *Window) drawIndentline(p *gui.QPainter, x int, y int, color *RGBA) {
+	font := w.getFont()
+	X := float64(x) * font.truewidth
+	Y := float64(y * font.lineHeight)
+	if char := editor.config.Editor.IndentGuideChar; char != "" {
+		p.SetFont(font.fontNew)
+		p.SetPen2(color.QColor())
+		p.DrawText(core.NewQPointF3(X, Y+float64(font.shift)), char)
+	} else {
+		p.FillRect4(
+			core.NewQRectF4(
+				X,
+				Y,
+				1,
+				float64(font.lineHeight),
+			),
+			color.QColor(),
+		)
+	}
+
+	if w.lenContent[y] < x {
+		w.lenContent[y] = x
+	}
+}
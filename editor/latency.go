@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// latencySamplesLen is the number of the latest latencies kept for the percentiles
+const latencySamplesLen = 10000
+
+// LatencyMeter measures the time from a key press to the end of the paint of
+// its result, toggled by :GonvimLatency. The key is counted as painted by the
+// first paint after a flush of nvim. All of it is done on the Qt thread; it is
+// not measured while editor.latency is nil.
+type LatencyMeter struct {
+	start   time.Time
+	pending []time.Time
+	flushed bool
+	samples []time.Duration
+}
+
+// toggleLatency starts the measurement, or stops it and reports the result
+func (e *Editor) toggleLatency() {
+	if e.latency == nil {
+		e.latency = &LatencyMeter{start: time.Now()}
+		e.pushNotification(NotifyInfo, 3, "[Gonvim] Measuring the input latency; run :GonvimLatency again to see the result.")
+		return
+	}
+	l := e.latency
+	e.latency = nil
+	if len(l.samples) == 0 {
+		e.pushNotification(NotifyWarn, 3, "[Gonvim] No key presses were measured.")
+		return
+	}
+	report := l.report()
+	e.pushNotification(NotifyInfo, 0, "[Gonvim] Input latency: "+report)
+	l.log(report)
+}
+
+// keyPressed is called before the key is sent to nvim
+func (l *LatencyMeter) keyPressed() {
+	if l == nil {
+		return
+	}
+	l.pending = append(l.pending, time.Now())
+}
+
+// flush is called when nvim has flushed a redraw batch
+func (l *LatencyMeter) flush() {
+	if l == nil || len(l.pending) == 0 {
+		return
+	}
+	l.flushed = true
+}
+
+// painted is called at the end of a paint of the screen
+func (l *LatencyMeter) painted() {
+	if l == nil || !l.flushed {
+		return
+	}
+	now := time.Now()
+	for _, t := range l.pending {
+		l.samples = append(l.samples, now.Sub(t))
+	}
+	if len(l.samples) > latencySamplesLen {
+		l.samples = l.samples[len(l.samples)-latencySamplesLen:]
+	}
+	l.pending = l.pending[:0]
+	l.flushed = false
+}
+
+// percentiles returns p50, p95, p99 and the max of the latencies
+func (l *LatencyMeter) percentiles() (p50, p95, p99, max time.Duration) {
+	if len(l.samples) == 0 {
+		return
+	}
+	sorted := append([]time.Duration{}, l.samples...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	percentile := func(p float64) time.Duration {
+		return sorted[int(float64(len(sorted)-1)*p)]
+	}
+	return percentile(0.5), percentile(0.95), percentile(0.99), sorted[len(sorted)-1]
+}
+
+func (l *LatencyMeter) report() string {
+	p50, p95, p99, max := l.percentiles()
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return fmt.Sprintf("%d keys, p50 %.1fms, p95 %.1fms, p99 %.1fms, max %.1fms", len(l.samples), ms(p50), ms(p95), ms(p99), ms(max))
+}
+
+// log appends the result to ~/.goneovim/latency.log with the settings which
+// affect it, to compare the configurations
+func (l *LatencyMeter) log(report string) {
+	e := editor
+	path := filepath.Join(e.homeDir, ".goneovim", "latency.log")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	font := e.workspaces[e.active].font.fontNew
+	settings := fmt.Sprintf("font %q %.1fpt, cachedDrawing %v, powerSaving %v", font.Family(), font.PointSizeF(), e.config.Editor.CachedDrawing, e.powerSaving)
+	fmt.Fprintf(file, "%s\t%s\t%s\t%s\n", l.start.Format(time.RFC3339), time.Since(l.start).Round(time.Second), report, settings)
+}
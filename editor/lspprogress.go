@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressKey identifies a progress; the tokens are unique only in a nvim
+type progressKey struct {
+	ws    *Workspace
+	token string
+}
+
+// ProgressNotification is a notification of the progress of a language server,
+// updated in place for the same token
+type ProgressNotification struct {
+	key          progressKey
+	notification *Notification
+	bar          *widgets.QProgressBar
+	timer        *core.QTimer
+	frame        int
+	title        string
+	message      string
+	percentage   int
+	done         bool
+}
+
+// lspProgress handles gonvim_lsp_progress
+func (w *Workspace) lspProgress(args []interface{}) {
+	e := editor
+	if len(args) < 5 || e.notifyStartPos == nil {
+		return
+	}
+	token, _ := args[0].(string)
+	kind, _ := args[1].(string)
+	title, _ := args[2].(string)
+	message, _ := args[3].(string)
+	percentage := util.ReflectToInt(args[4])
+
+	if e.progressNotifications == nil {
+		e.progressNotifications = make(map[progressKey]*ProgressNotification)
+	}
+	key := progressKey{ws: w, token: token}
+	p, ok := e.progressNotifications[key]
+	if !ok {
+		if kind == "end" {
+			return
+		}
+		p = e.newProgressNotification(key)
+		e.progressNotifications[key] = p
+	}
+	if title != "" {
+		p.title = title
+	}
+	// Only the latest state is drawn at the next frame of the spinner
+	p.message = message
+	if percentage >= 0 {
+		p.percentage = percentage
+	}
+	if kind == "end" {
+		p.finish()
+	}
+}
+
+// stopLspProgress closes the progress notifications of the workspace
+func (w *Workspace) stopLspProgress() {
+	for key, p := range editor.progressNotifications {
+		if key.ws != w {
+			continue
+		}
+		p.stop()
+		if p.notification.widget.Pointer() != nil {
+			p.notification.closeNotification()
+		}
+	}
+}
+
+func (e *Editor) newProgressNotification(key progressKey) *ProgressNotification {
+	notification := newNotification(NotifyInfo, 0, spinnerFrames[0])
+	bar := widgets.NewQProgressBar(nil)
+	bar.SetRange(0, 100)
+	bar.SetTextVisible(false)
+	bar.SetFixedHeight(4)
+	bar.SetValue(0)
+	notification.widget.Layout().AddWidget(bar)
+
+	p := &ProgressNotification{
+		key:          key,
+		notification: notification,
+		bar:          bar,
+		percentage:   -1,
+	}
+
+	e.addNotification(notification)
+
+	p.timer = core.NewQTimer(nil)
+	p.timer.ConnectTimeout(p.update)
+	// The spinner is slowed down in power saving mode
+	interval := 100
+	if e.powerSaving {
+		interval = 500
+	}
+	p.timer.Start(interval)
+
+	return p
+}
+
+func (p *ProgressNotification) update() {
+	// Closed by the user; the next report of the token shows a new notification
+	if p.notification.widget.Pointer() == nil {
+		p.stop()
+		return
+	}
+	p.frame = (p.frame + 1) % len(spinnerFrames)
+	text := spinnerFrames[p.frame] + " " + p.title
+	if p.done {
+		text = "✓ " + p.title
+	}
+	if p.message != "" {
+		text += ": " + p.message
+	}
+	if p.percentage >= 0 {
+		text += fmt.Sprintf(" (%d%%)", p.percentage)
+		p.bar.SetRange(0, 100)
+		p.bar.SetValue(p.percentage)
+	} else if !p.done {
+		// Busy indicator
+		p.bar.SetRange(0, 0)
+	}
+	p.notification.label.SetText(text)
+}
+
+// finish shows the completion and closes the notification after a while
+func (p *ProgressNotification) finish() {
+	p.done = true
+	p.percentage = 100
+	p.stop()
+	p.update()
+	core.QTimer_SingleShot(1500, func() {
+		if p.notification.widget.Pointer() == nil {
+			return
+		}
+		p.notification.closeNotification()
+	})
+}
+
+// stop releases the timer and forgets the token
+func (p *ProgressNotification) stop() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer.DeleteLater()
+		p.timer = nil
+	}
+	if editor.progressNotifications[p.key] == p {
+		delete(editor.progressNotifications, p.key)
+	}
+}
@@ -319,7 +319,7 @@ func (m *Message) msgShow(args []interface{}) {
 		}
 
 		// If window is minimize, then message notified as a desktop notifications
-		if !isActiveState && notifyText != "" {
+		if !isActiveState && notifyText != "" && editor.sysTray != nil {
 			editor.sysTray.ShowMessage("GoNeovim", notifyText, widgets.QSystemTrayIcon__NoIcon, 2000)
 			return
 		}
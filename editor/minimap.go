@@ -248,7 +248,11 @@ func (m *MiniMap) setColorscheme() {
 	if m.isSetColorscheme {
 		return
 	}
-	colo, _ := m.ws.nvim.CommandOutput("colo")
+	colo := ""
+	m.ws.nvim.Var("colors_name", &colo)
+	if colo == "" {
+		colo = "default"
+	}
 
 	if !m.isSetRuntimepath {
 		sep := "/"
@@ -303,16 +307,21 @@ func (m *MiniMap) setColorscheme() {
 func (m *MiniMap) mapScroll() {
 	absScreenTop := m.ws.curLine - m.ws.screen.cursor[0]
 
-	var absMapTop int
-	m.nvim.Eval("line('w0')", &absMapTop)
-
-	linePos := absScreenTop-absMapTop
-
 	win, ok := m.ws.screen.getWindow(m.ws.cursor.gridid)
 	if !ok {
 		return
 	}
 	regionHeight := win.rows
+	// Count a wrapped line once
+	if top, count, _, ok := win.shownLines(); ok {
+		absScreenTop = top
+		regionHeight = count
+	}
+
+	var absMapTop int
+	m.nvim.Eval("line('w0')", &absMapTop)
+
+	linePos := absScreenTop-absMapTop
 
 	if linePos < 0 {
 		regionHeight = regionHeight + linePos
@@ -568,7 +577,7 @@ func (w *Window) drawMinimap(p *gui.QPainter, y int, col int, cols int) {
 	wsfont := w.getFont()
 	font := p.Font()
 	line := w.content[y]
-	chars := map[Highlight][]int{}
+	chars := map[*Highlight][]int{}
 	specialChars := []int{}
 
 	for x := col; x < col+cols; x++ {
@@ -0,0 +1,184 @@
+package editor
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// MsgLine shows the contents of the command line row which are not messages,
+// msg_showmode, msg_showcmd and msg_ruler, at the bottom right of the screen.
+// With ext_messages nvim sends them instead of drawing them, so that the grid
+// needs no command line row ('cmdheight' = 0).
+// With showcmdNearCursor, msg_showcmd (the count and the pending operator) is
+// shown next to the cursor instead.
+type MsgLine struct {
+	ws     *Workspace
+	widget *widgets.QLabel
+	cmd    *widgets.QLabel
+
+	showmode  string
+	showcmd   string
+	ruler     string
+	recording string
+}
+
+func newMsgLine(ws *Workspace) *MsgLine {
+	m := &MsgLine{
+		ws:     ws,
+		widget: widgets.NewQLabel(ws.screen.widget, 0),
+		cmd:    widgets.NewQLabel(ws.screen.widget, 0),
+	}
+	for _, label := range []*widgets.QLabel{m.widget, m.cmd} {
+		label.SetTextFormat(core.Qt__RichText)
+		label.SetContentsMargins(6, 2, 6, 2)
+		label.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+		label.Hide()
+	}
+
+	return m
+}
+
+// chunksToHTML formats the [[attr_id, text], ...] chunks of the msg_ events.
+// Each of the args is the arguments of a call of the event, and only the last
+// one is shown.
+func (m *MsgLine) chunksToHTML(args []interface{}) string {
+	if len(args) < 1 {
+		return ""
+	}
+	call, _ := args[len(args)-1].([]interface{})
+	if len(call) < 1 {
+		return ""
+	}
+	items, _ := call[0].([]interface{})
+	var b strings.Builder
+	for _, i := range items {
+		chunk, ok := i.([]interface{})
+		if !ok || len(chunk) != 2 {
+			continue
+		}
+		text, _ := chunk[1].(string)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		color := m.ws.foreground
+		if hl := m.ws.screen.highAttrDef[util.ReflectToInt(chunk[0])]; hl != nil && hl.foreground != nil {
+			color = hl.foreground
+		}
+		fmt.Fprintf(&b, "<font color='%s'>%s</font>", color.Hex(), strings.Replace(html.EscapeString(text), " ", "&nbsp;", -1))
+	}
+
+	return b.String()
+}
+
+// msgShowmode handles msg_showmode, e.g. "-- INSERT --" and "recording @q"
+func (m *MsgLine) msgShowmode(args []interface{}) {
+	m.showmode = m.chunksToHTML(args)
+	m.update()
+}
+
+// msgShowcmd handles msg_showcmd, the pending keys and the selection size
+func (m *MsgLine) msgShowcmd(args []interface{}) {
+	if editor.config.Editor.ShowcmdNearCursor {
+		m.updateCmd(m.chunksToHTML(args))
+		return
+	}
+	m.showcmd = m.chunksToHTML(args)
+	m.update()
+}
+
+// setRecording handles gonvim_recording, the register of the macro being recorded ("" when stopped)
+func (m *MsgLine) setRecording(args []interface{}) {
+	m.recording = ""
+	if len(args) > 0 {
+		if reg, _ := args[0].(string); reg != "" {
+			m.recording = fmt.Sprintf("<font color='%s'>&#9679;&nbsp;REC&nbsp;@%s</font>", newRGBA(204, 62, 68, 1).Hex(), html.EscapeString(reg))
+		}
+	}
+	m.update()
+}
+
+// msgRuler handles msg_ruler, sent when 'ruler' is set and the window has no statusline
+func (m *MsgLine) msgRuler(args []interface{}) {
+	m.ruler = m.chunksToHTML(args)
+	m.update()
+}
+
+func (m *MsgLine) update() {
+	var parts []string
+	recording := m.recording
+	// 'showmode' shows "recording @q" by itself
+	if strings.Contains(m.showmode, "recording") {
+		recording = ""
+	}
+	for _, part := range []string{recording, m.showmode, m.showcmd, m.ruler} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		m.widget.Hide()
+		return
+	}
+
+	m.setStyle(m.widget)
+	m.widget.SetText(strings.Join(parts, "&nbsp;&nbsp;&nbsp;"))
+	m.widget.AdjustSize()
+	m.resize()
+	m.widget.Show()
+	m.widget.Raise()
+}
+
+func (m *MsgLine) setStyle(label *widgets.QLabel) {
+	label.SetFont(m.ws.font.fontNew)
+	label.SetStyleSheet(fmt.Sprintf(
+		" * { background-color: %s; border: 1px solid %s; border-radius: 3px; }",
+		warpColor(editor.colors.bg, -15).String(),
+		editor.colors.inactiveFg.String(),
+	))
+}
+
+// updateCmd shows the showcmd next to the cursor
+func (m *MsgLine) updateCmd(text string) {
+	if text == "" {
+		m.cmd.Hide()
+		return
+	}
+	m.setStyle(m.cmd)
+	m.cmd.SetText(text)
+	m.cmd.AdjustSize()
+	m.cmd.Show()
+	m.cmd.Raise()
+	m.updateCmdPos()
+}
+
+// updateCmdPos puts the showcmd at the upper right of the cursor, or below it on the first row
+func (m *MsgLine) updateCmdPos() {
+	if m.cmd == nil || !m.cmd.IsVisible() || m.ws.cursor == nil {
+		return
+	}
+	cursor := m.ws.cursor.widget
+	pos := m.ws.screen.widget.MapFromGlobal(cursor.MapToGlobal(core.NewQPoint2(0, 0)))
+	x := pos.X() + cursor.Width() + 4
+	y := pos.Y() - m.cmd.Height() - 2
+	if y < 0 {
+		y = pos.Y() + cursor.Height() + 2
+	}
+	if max := m.ws.screen.widget.Width() - m.cmd.Width(); x > max {
+		x = max
+	}
+	m.cmd.Move2(x, y)
+}
+
+func (m *MsgLine) resize() {
+	if m.ws.screen == nil || m.ws.scrollBar == nil {
+		return
+	}
+	x := m.ws.screen.widget.Width() - m.widget.Width() - m.ws.scrollBar.widget.Width() - 8
+	y := m.ws.screen.widget.Height() - m.widget.Height() - 4
+	m.widget.Move2(x, y)
+}
@@ -0,0 +1,122 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// ExtraCursor is a cursor, or the part of a selection on a row, registered by
+// a plugin of multiple cursors through require("gonvim").cursors()
+type ExtraCursor struct {
+	row     int
+	col     int
+	width   int
+	primary bool
+}
+
+// setCursors handles gonvim_cursors; the windows of the buffer of the cursors
+// and their regions. The cursors of the windows not listed are cleared.
+func (s *Screen) setCursors(args []interface{}) {
+	cursors := make(map[nvim.Window][]*ExtraCursor)
+	if len(args) > 0 {
+		items, _ := args[0].([]interface{})
+		for _, i := range items {
+			item, ok := i.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id := nvim.Window(util.ReflectToInt(item["win"]))
+			regions, _ := item["regions"].([]interface{})
+			list := []*ExtraCursor{}
+			for _, r := range regions {
+				region, ok := r.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				primary, _ := region["primary"].(bool)
+				list = append(list, &ExtraCursor{
+					row:     util.ReflectToInt(region["row"]),
+					col:     util.ReflectToInt(region["col"]),
+					width:   util.ReflectToInt(region["width"]),
+					primary: primary,
+				})
+			}
+			cursors[id] = list
+		}
+	}
+
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		list := cursors[win.id]
+		if len(win.extraCursors) == 0 && len(list) == 0 {
+			return true
+		}
+		win.extraCursors = list
+		if win.widget != nil {
+			win.widget.Update()
+		}
+		return true
+	})
+}
+
+// drawExtraCursors tints the selections, and draws the primary cursor as a
+// block and the secondary ones as outlined cells in the accent color
+func (w *Window) drawExtraCursors(p *gui.QPainter) {
+	if len(w.extraCursors) == 0 || !w.isShown() {
+		return
+	}
+	font := w.getFont()
+	lineHeight := float64(font.lineHeight)
+	fg := editor.colors.fg
+	accent := editor.colors.matchFg
+	if accent == nil {
+		accent = fg
+	}
+
+	p.Save()
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+	for _, c := range w.extraCursors {
+		if c.row < 0 || c.row >= w.rows {
+			continue
+		}
+		color := accent
+		if c.primary {
+			color = fg
+		}
+		qcolor := color.QColor()
+		if c.width > 0 {
+			// A selection
+			qcolor.SetAlphaF(0.25)
+			p.FillRect4(core.NewQRectF4(
+				float64(c.col)*font.truewidth,
+				float64(c.row)*lineHeight,
+				float64(c.width)*font.truewidth,
+				lineHeight,
+			), qcolor)
+			continue
+		}
+		rect := core.NewQRectF4(
+			float64(c.col)*font.truewidth+0.5,
+			float64(c.row)*lineHeight+0.5,
+			font.truewidth-1,
+			lineHeight-1,
+		)
+		if c.primary {
+			qcolor.SetAlphaF(0.5)
+			p.FillRect4(rect, qcolor)
+			continue
+		}
+		qcolor.SetAlphaF(0.9)
+		pen := gui.NewQPen3(qcolor)
+		pen.SetWidthF(1.5)
+		p.SetPen(pen)
+		p.SetBrush(gui.NewQBrush2(core.Qt__NoBrush))
+		p.DrawRect(rect)
+	}
+	p.Restore()
+}
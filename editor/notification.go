@@ -25,16 +25,26 @@ const (
 // Notification is
 type Notification struct {
 	widget    *widgets.QWidget
+	label     *widgets.QLabel
 	closeIcon *svg.QSvgWidget
 	pos       *core.QPoint
 	isDrag    bool
 	isMoved   bool
 	isHide    bool
+
+	timer        *core.QTimer
+	countdown    *widgets.QProgressBar
+	remaining    int
+	isHovered    bool
+	pauseOnHover bool
 }
 
 // NotifyOptions is
 type NotifyOptions struct {
-	buttons []*NotifyButton
+	buttons      []*NotifyButton
+	sticky       bool
+	countdown    bool
+	pauseOnHover bool
 }
 
 // NotifyOptionArg is
@@ -46,6 +56,27 @@ func notifyOptionArg(b []*NotifyButton) NotifyOptionArg {
 	}
 }
 
+// notifySticky keeps the notification until it is closed
+func notifySticky() NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.sticky = true
+	}
+}
+
+// notifyCountdown shows the remaining display period as a bar
+func notifyCountdown() NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.countdown = true
+	}
+}
+
+// notifyPauseOnHover stops the countdown while the mouse is over the notification
+func notifyPauseOnHover() NotifyOptionArg {
+	return func(option *NotifyOptions) {
+		option.pauseOnHover = true
+	}
+}
+
 func newNotification(l NotifyLevel, p int, message string, options ...NotifyOptionArg) *Notification {
 	e := editor
 
@@ -180,6 +211,7 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	startPos := editor.notifyStartPos
 
 	notification.widget = widget
+	notification.label = label
 	notification.closeIcon = closeIcon
 	notification.pos = startPos
 	notification.isDrag = isDrag
@@ -190,10 +222,12 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 		notification.closeNotification()
 	})
 	notification.widget.ConnectEnterEvent(func(event *core.QEvent) {
+		notification.isHovered = true
 		svgContent := e.getSvg("cross", nil)
 		notification.closeIcon.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
 	})
 	notification.widget.ConnectLeaveEvent(func(event *core.QEvent) {
+		notification.isHovered = false
 		svgContent := e.getSvg("cross", editor.colors.widgetBg)
 		notification.closeIcon.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
 	})
@@ -207,6 +241,8 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	})
 	notification.widget.ConnectMouseMoveEvent(func(event *gui.QMouseEvent) {
 		if !notification.isMoved {
+			// The moved notification is left out of the stack
+			notification.isMoved = true
 			notification.dropNotifications()
 		}
 		if notification.isDrag {
@@ -216,7 +252,6 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 			trans := notification.widget.MapToParent(newPos)
 			notification.widget.Move(trans)
 		}
-		notification.isMoved = true
 	})
 
 	// Drop shadow to widget
@@ -233,58 +268,64 @@ func newNotification(l NotifyLevel, p int, message string, options ...NotifyOpti
 	} else {
 		displayPeriod = p
 	}
+	if opts.sticky {
+		displayPeriod = 0
+	}
 	if displayPeriod > 0 {
-		timer := core.NewQTimer(nil)
-		timer.SetSingleShot(true)
-		timer.ConnectTimeout(notification.hideNotification)
-		timer.Start(displayPeriod * 1000)
+		notification.startTimer(displayPeriod*1000, opts.countdown, opts.pauseOnHover)
 	}
 
 	return notification
 }
 
-func (n *Notification) dropNotifications(fn ...func(*Notification)) {
-	e := editor
-	var newNotifications []*Notification
-	var x, y, self int
-	var isClose bool
-	var dropOK bool
-	dropHeight := 0
-	for i, item := range e.notifications {
-		if n == item {
-			self = i
-			dropHeight = item.widget.Height() + 4
-			if len(fn) > 0 {
-				for _, f := range fn {
-					f(item)
-				}
-			}
-		}
+const notificationTimerInterval = 100
+
+// startTimer hides the notification after the period. The countdown and the pause on hover
+// need the remaining period to be counted down, otherwise a single shot timer is used.
+func (n *Notification) startTimer(period int, countdown, pauseOnHover bool) {
+	n.timer = core.NewQTimer(nil)
+	if !countdown && !pauseOnHover {
+		n.timer.SetSingleShot(true)
+		n.timer.ConnectTimeout(n.hideNotification)
+		n.timer.Start(period)
+		return
+	}
 
-		isClose = fmt.Sprintf("%v", item.widget) == "&{{<nil>} {<nil>}}"
-		// Skip if widget is broken
-		if !isClose {
-			newNotifications = append(newNotifications, item)
+	n.remaining = period
+	n.pauseOnHover = pauseOnHover
+	if countdown {
+		n.countdown = widgets.NewQProgressBar(nil)
+		n.countdown.SetRange(0, period)
+		n.countdown.SetValue(period)
+		n.countdown.SetTextVisible(false)
+		n.countdown.SetFixedHeight(2)
+		n.widget.Layout().AddWidget(n.countdown)
+	}
+	n.timer.ConnectTimeout(func() {
+		if n.widget.Pointer() == nil {
+			n.timer.Stop()
+			return
 		}
-
-		// set drop flag when n == item
-		if n == item {
-			dropOK = (n.isDrag && !n.isHide && !isClose) || (!n.isMoved && n.isHide && !isClose) || (!n.isMoved && !n.isHide && isClose)
+		if n.pauseOnHover && n.isHovered {
+			return
 		}
-		if n != item && i > self && !item.isMoved && dropOK {
-			x = item.widget.Pos().X()
-			y = item.widget.Pos().Y() + dropHeight
-			item.widget.Move2(x, y)
-			item.widget.Hide()
-			if !item.isHide {
-				item.widget.Show()
-			}
+		n.remaining -= notificationTimerInterval
+		if n.countdown != nil {
+			n.countdown.SetValue(n.remaining)
 		}
+		if n.remaining <= 0 {
+			n.timer.Stop()
+			n.hideNotification()
+		}
+	})
+	n.timer.Start(notificationTimerInterval)
+}
+
+func (n *Notification) dropNotifications(fn ...func(*Notification)) {
+	for _, f := range fn {
+		f(n)
 	}
-	if dropOK {
-		e.notifyStartPos = core.NewQPoint2(editor.notifyStartPos.X(), editor.notifyStartPos.Y()+dropHeight)
-	}
-	e.notifications = newNotifications
+	editor.layoutNotifications(nil)
 }
 
 func (n *Notification) closeNotification() {
@@ -308,30 +349,19 @@ func (n *Notification) hideNotification() {
 }
 
 func (e *Editor) showNotifications() {
-	e.notifyStartPos = core.NewQPoint2(e.width-e.notificationWidth-10, e.height-30)
-	var x, y int
-	var newNotifications []*Notification
 	for _, item := range e.notifications {
-		x = e.notifyStartPos.X()
-		y = e.notifyStartPos.Y() - item.widget.Height() - 4
-		item.widget.Move2(x, y)
 		item.statusReset()
-		e.notifyStartPos = core.NewQPoint2(x, y)
-		newNotifications = append(newNotifications, item)
 	}
-	e.notifications = newNotifications
+	e.layoutNotifications(nil)
 	e.isDisplayNotifications = true
 }
 
 func (e *Editor) hideNotifications() {
-	var newNotifications []*Notification
 	for _, item := range e.notifications {
 		item.isHide = true
 		item.widget.Hide()
-		newNotifications = append(newNotifications, item)
 	}
-	e.notifications = newNotifications
-	e.notifyStartPos = core.NewQPoint2(e.width-e.notificationWidth-10, e.height-30)
+	e.layoutNotifications(nil)
 	e.isDisplayNotifications = false
 }
 
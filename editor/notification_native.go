@@ -0,0 +1,44 @@
+package editor
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/therecipe/qt/widgets"
+)
+
+// nativeNotification shows the message through the platform notification center
+func (e *Editor) nativeNotification(level NotifyLevel, message string) {
+	title := "Goneovim"
+	message = strings.TrimPrefix(message, "[Gonvim] ")
+
+	switch runtime.GOOS {
+	case "darwin":
+		// The message is passed as an argument, not in the string literal of the script
+		script := `on run argv
+display notification (item 1 of argv) with title (item 2 of argv)
+end run`
+		go exec.Command("osascript", "-e", script, message, title).Run()
+		return
+	case "linux", "freebsd", "netbsd", "openbsd":
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			urgency := "normal"
+			if level == NotifyWarn {
+				urgency = "critical"
+			}
+			go exec.Command("notify-send", "-a", title, "-u", urgency, title, message).Run()
+			return
+		}
+	}
+
+	// Windows toasts, or the fallback when there is no notification daemon
+	if e.sysTray == nil {
+		e.initSysTrayIcon()
+	}
+	icon := widgets.QSystemTrayIcon__Information
+	if level == NotifyWarn {
+		icon = widgets.QSystemTrayIcon__Warning
+	}
+	e.sysTray.ShowMessage(title, message, icon, 5000)
+}
@@ -0,0 +1,71 @@
+package editor
+
+import (
+	"github.com/therecipe/qt/widgets"
+)
+
+func parseNotifyLevel(level string) (NotifyLevel, bool) {
+	switch level {
+	case "info":
+		return NotifyInfo, true
+	case "warn", "warning":
+		return NotifyWarn, true
+	default:
+		return NotifyInfo, false
+	}
+}
+
+// isNotificationSuppressed reports whether the notification is only kept in the history.
+// The notifications asking for an action are always shown.
+func (e *Editor) isNotificationSuppressed(notify *Notify) bool {
+	if len(notify.buttons) > 0 {
+		return false
+	}
+	if e.config.Notification.DoNotDisturb {
+		return true
+	}
+	level, _ := parseNotifyLevel(e.config.Notification.Level)
+
+	return notify.level < level
+}
+
+// setDoNotDisturb sets the do-not-disturb mode by "on" / "off", or toggles it
+func (e *Editor) setDoNotDisturb(arg string) {
+	switch arg {
+	case "on":
+		e.config.Notification.DoNotDisturb = true
+	case "off":
+		e.config.Notification.DoNotDisturb = false
+	case "":
+		e.config.Notification.DoNotDisturb = !e.config.Notification.DoNotDisturb
+	default:
+		return
+	}
+	if e.doNotDisturbAction != nil {
+		e.doNotDisturbAction.SetChecked(e.config.Notification.DoNotDisturb)
+	}
+	if e.config.Notification.DoNotDisturb {
+		e.hideNotifications()
+	}
+}
+
+func (e *Editor) setNotifyLevel(arg string) {
+	if _, ok := parseNotifyLevel(arg); !ok {
+		return
+	}
+	e.config.Notification.Level = arg
+}
+
+// addNotificationActions adds the do-not-disturb and history actions to the menu
+func (e *Editor) addNotificationActions(menu *widgets.QMenu) {
+	e.doNotDisturbAction = menu.AddAction("Do Not Disturb")
+	e.doNotDisturbAction.SetCheckable(true)
+	e.doNotDisturbAction.SetChecked(e.config.Notification.DoNotDisturb)
+	e.doNotDisturbAction.ConnectTriggered(func(bool) {
+		e.setDoNotDisturb("")
+	})
+	historyAction := menu.AddAction("Notification History")
+	historyAction.ConnectTriggered(func(bool) {
+		e.showNotificationHistory()
+	})
+}
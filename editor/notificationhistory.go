@@ -0,0 +1,91 @@
+package editor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const notificationHistoryLen = 200
+
+// NotificationHistoryItem is a notification kept to be reviewed after it is dismissed
+type NotificationHistoryItem struct {
+	level   NotifyLevel
+	time    time.Time
+	message string
+}
+
+func (e *Editor) addNotificationHistory(level NotifyLevel, message string) {
+	e.notificationHistory = append(e.notificationHistory, &NotificationHistoryItem{
+		level:   level,
+		time:    time.Now(),
+		message: message,
+	})
+	if len(e.notificationHistory) > notificationHistoryLen {
+		e.notificationHistory = e.notificationHistory[len(e.notificationHistory)-notificationHistoryLen:]
+	}
+}
+
+func notifyLevelIcon(level NotifyLevel) *gui.QIcon {
+	var svg string
+	switch level {
+	case NotifyWarn:
+		svg = editor.getSvg("warn", newRGBA(255, 205, 0, 1))
+	default:
+		svg = editor.getSvg("info", newRGBA(27, 161, 226, 1))
+	}
+	pixmap := gui.NewQPixmap()
+	pixmap.LoadFromData2(core.NewQByteArray2(svg, len(svg)), "SVG", core.Qt__ColorOnly)
+	return gui.NewQIcon2(pixmap)
+}
+
+// showNotificationHistory shows the notifications of this session, newest first
+func (e *Editor) showNotificationHistory() {
+	dialog := widgets.NewQDialog(e.window, 0)
+	dialog.SetWindowTitle("Notifications")
+	dialog.SetAttribute(core.Qt__WA_DeleteOnClose, true)
+	dialog.Resize2(e.notificationWidth, e.height/2)
+	layout := widgets.NewQVBoxLayout()
+	dialog.SetLayout(layout)
+
+	list := widgets.NewQListWidget(nil)
+	list.SetWordWrap(true)
+	list.SetIconSize(core.NewQSize2(e.iconSize, e.iconSize))
+	list.SetFont(gui.NewQFont2(e.extFontFamily, e.extFontSize, 1, false))
+	fill := func() {
+		list.Clear()
+		if len(e.notificationHistory) == 0 {
+			list.AddItem("No notifications")
+			return
+		}
+		for i := len(e.notificationHistory) - 1; i >= 0; i-- {
+			n := e.notificationHistory[i]
+			item := widgets.NewQListWidgetItem3(
+				notifyLevelIcon(n.level),
+				fmt.Sprintf("%s  %s", n.time.Format("15:04:05"), n.message),
+				list,
+				0,
+			)
+			item.SetToolTip(n.time.Format("2006-01-02 15:04:05"))
+		}
+	}
+	fill()
+	layout.AddWidget(list, 1, 0)
+
+	buttons := widgets.NewQDialogButtonBox(nil)
+	clear := buttons.AddButton2("Clear", widgets.QDialogButtonBox__ResetRole)
+	clear.ConnectClicked(func(bool) {
+		e.notificationHistory = nil
+		fill()
+	})
+	closeButton := buttons.AddButton3(widgets.QDialogButtonBox__Close)
+	closeButton.ConnectClicked(func(bool) {
+		dialog.Close()
+	})
+	layout.AddWidget(buttons, 0, 0)
+
+	dialog.Show()
+}
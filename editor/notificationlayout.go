@@ -0,0 +1,144 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const notificationSpacing = 4
+
+// addNotification shows the notification on top of the stack
+func (e *Editor) addNotification(notification *Notification) {
+	notification.widget.SetParent(e.window)
+	notification.widget.AdjustSize()
+	e.notifications = append(e.notifications, notification)
+	e.layoutNotifications(notification)
+	notification.show()
+}
+
+// layoutNotifications stacks the notifications from the configured corner of the window, newest
+// nearest to the corner, and hides the ones over the max visible count behind "+N more".
+// The notifications moved by the user stay where they are.
+func (e *Editor) layoutNotifications(added *Notification) {
+	if e.window == nil || e.notificationWidth == 0 {
+		return
+	}
+	position := e.config.Notification.Position
+	top := strings.HasPrefix(position, "top")
+	left := strings.HasSuffix(position, "left")
+
+	x := e.width - e.notificationWidth - 10
+	if left {
+		x = 10
+	}
+	y := e.height - 30
+	if top {
+		y = 10 + e.tablineHeight
+	}
+
+	var notifications []*Notification
+	for _, n := range e.notifications {
+		// Skip if widget is broken
+		if n.widget.Pointer() == nil {
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	e.notifications = notifications
+
+	visible := 0
+	more := 0
+	for i := len(notifications) - 1; i >= 0; i-- {
+		n := notifications[i]
+		if n.isHide || n.isMoved {
+			continue
+		}
+		if e.config.Notification.MaxVisible > 0 && visible >= e.config.Notification.MaxVisible {
+			n.widget.Hide()
+			more++
+			continue
+		}
+		visible++
+		height := n.widget.Height()
+		if !top {
+			y -= height + notificationSpacing
+		}
+		n.moveTo(x, y, n == added, left)
+		if top {
+			y += height + notificationSpacing
+		}
+		if n != added {
+			n.widget.Show()
+		}
+	}
+
+	e.showMoreNotifications(more, x, y, top)
+	e.notifyStartPos = core.NewQPoint2(x, y)
+}
+
+// moveTo slides the notification to the position, or into the window if it is new
+func (n *Notification) moveTo(x, y int, isNew bool, fromLeft bool) {
+	width := n.widget.Width()
+	height := n.widget.Height()
+	start := n.widget.Geometry()
+	if isNew {
+		startX := editor.width
+		if fromLeft {
+			startX = -width
+		}
+		start = core.NewQRect4(startX, y, width, height)
+	}
+	if start.X() == x && start.Y() == y {
+		return
+	}
+	duration := editor.animationDuration(200)
+	if duration == 0 {
+		n.widget.Move2(x, y)
+		return
+	}
+	n.widget.SetGeometry(start)
+	animation := core.NewQPropertyAnimation2(n.widget, core.NewQByteArray2("geometry", len("geometry")), n.widget)
+	animation.SetDuration(duration)
+	animation.SetStartValue(core.NewQVariant31(start))
+	animation.SetEndValue(core.NewQVariant31(core.NewQRect4(x, y, width, height)))
+	animation.SetEasingCurve(core.NewQEasingCurve(core.QEasingCurve__OutCubic))
+	animation.Start(core.QAbstractAnimation__DeleteWhenStopped)
+}
+
+// showMoreNotifications shows "+N more" next to the stack, which shows the history when clicked
+func (e *Editor) showMoreNotifications(more, x, y int, top bool) {
+	if more == 0 {
+		if e.notificationMore != nil {
+			e.notificationMore.Hide()
+		}
+		return
+	}
+	if e.notificationMore == nil {
+		label := widgets.NewQLabel(e.window, 0)
+		label.SetAlignment(core.Qt__AlignCenter)
+		label.SetContentsMargins(10, 2, 10, 2)
+		label.ConnectMousePressEvent(func(*gui.QMouseEvent) {
+			e.showNotificationHistory()
+		})
+		e.notificationMore = label
+	}
+	label := e.notificationMore
+	if e.colors.widgetFg != nil && e.colors.widgetBg != nil {
+		fg := e.colors.widgetFg.String()
+		bg := e.colors.widgetBg
+		label.SetStyleSheet(fmt.Sprintf(" * {color: %s; background: rgba(%d, %d, %d, %f);}", fg, bg.R, bg.G, bg.B, transparent()))
+	}
+	label.SetText(fmt.Sprintf("+%d more", more))
+	label.SetFixedWidth(e.notificationWidth)
+	label.AdjustSize()
+	if !top {
+		y -= label.Height() + notificationSpacing
+	}
+	label.Move2(x, y)
+	label.Raise()
+	label.Show()
+}
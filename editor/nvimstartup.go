@@ -0,0 +1,179 @@
+package editor
+
+import (
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// stderrLimit is the size of the tail of the stderr of nvim to keep
+const stderrLimit = 64 * 1024
+
+// stderrBuffer keeps the tail of the stderr of the embedded nvim
+type stderrBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > stderrLimit {
+		b.buf = b.buf[len(b.buf)-stderrLimit:]
+	}
+	b.mu.Unlock()
+	return len(p), nil
+}
+
+func (b *stderrBuffer) String() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+// nvimProcess closes the stdin of the embedded nvim and waits for its process
+// when the connection is closed, as the one of nvim.NewChildProcess does
+type nvimProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	once  sync.Once
+	err   error
+}
+
+func (p *nvimProcess) Close() error {
+	p.once.Do(func() {
+		p.err = p.stdin.Close()
+		if err := p.cmd.Wait(); p.err == nil {
+			p.err = err
+		}
+	})
+	return p.err
+}
+
+// spawnNvim starts the embedded nvim, keeping its stderr to show it if nvim
+// exits on the startup
+func (w *Workspace) spawnNvim(command string, args []string) (*nvim.Nvim, error) {
+	cmd := exec.Command(command, args...)
+	util.PrepareRunProc(cmd)
+	w.nvimStderr = &stderrBuffer{}
+	cmd.Stderr = w.nvimStderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return nvim.New(stdout, stdin, &nvimProcess{cmd: cmd, stdin: stdin}, log.Printf)
+}
+
+// nvimArgs returns the arguments of nvim given to goneovim, with -u NONE
+// instead of the -u of them if the workspace starts with a clean config
+func (w *Workspace) nvimArgs() []string {
+	if !w.cleanConfig {
+		return editor.args
+	}
+	args := []string{"-u", "NONE"}
+	for i := 0; i < len(editor.args); i++ {
+		if editor.args[i] == "-u" {
+			i++
+			continue
+		}
+		args = append(args, editor.args[i])
+	}
+
+	return args
+}
+
+// reportStartupFailure shows what nvim has printed to the stderr after it has
+// exited before VimEnter
+func (w *Workspace) reportStartupFailure() {
+	n := w.nvim
+	stderr := w.nvimStderr
+	go func() {
+		// Closing the connection waits for the process, and its stderr
+		err := n.Close()
+		text := strings.TrimSpace(stderr.String())
+		if err != nil {
+			text = strings.TrimSpace(text + "\n\nnvim: " + err.Error())
+		}
+		w.guiUpdates <- []interface{}{"gonvim_startup_errors", text, true}
+		w.signal.GuiSignal()
+	}()
+}
+
+// showStartupErrors handles gonvim_startup_errors; the messages or the output
+// of the startup of nvim, and whether nvim has exited
+func (w *Workspace) showStartupErrors(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	text, _ := args[0].(string)
+	exited := util.IsTrue(args[1])
+
+	dialog := widgets.NewQDialog(editor.window, 0)
+	dialog.SetWindowTitle("Goneovim")
+	dialog.Resize2(editor.width*2/3, editor.height/2)
+	layout := widgets.NewQVBoxLayout()
+	dialog.SetLayout(layout)
+
+	message := "Neovim reported errors on the startup. The config, e.g. init.vim, may be broken."
+	if exited {
+		message = "Neovim exited on the startup. The config, e.g. init.vim, may be broken."
+	}
+	layout.AddWidget(widgets.NewQLabel2(message, nil, 0), 0, 0)
+
+	output := widgets.NewQPlainTextEdit(nil)
+	output.SetReadOnly(true)
+	output.SetLineWrapMode(widgets.QPlainTextEdit__NoWrap)
+	font := gui.NewQFont2(editor.extFontFamily, editor.extFontSize, 1, false)
+	font.SetStyleHint(gui.QFont__Monospace, gui.QFont__PreferDefault)
+	output.SetFont(font)
+	if text == "" {
+		text = "(No output)"
+	}
+	output.SetPlainText(text)
+	layout.AddWidget(output, 1, 0)
+
+	buttons := widgets.NewQDialogButtonBox(nil)
+	buttons.AddButton2("Start with clean config (-u NONE)", widgets.QDialogButtonBox__AcceptRole)
+	buttons.AddButton3(widgets.QDialogButtonBox__Close)
+	buttons.ConnectAccepted(dialog.Accept)
+	buttons.ConnectRejected(dialog.Reject)
+	layout.AddWidget(buttons, 0, 0)
+
+	if dialog.Exec() == int(widgets.QDialog__Accepted) {
+		w.startClean(exited)
+		return
+	}
+	if exited {
+		w.notifyNvimCrash()
+	}
+}
+
+// startClean starts nvim of the workspace again with -u NONE
+func (w *Workspace) startClean(exited bool) {
+	w.cleanConfig = true
+	if exited {
+		w.respawnNvim("", nil)
+		return
+	}
+	// The new nvim is started when the stop signal of this one is handled
+	w.cleanRestart = true
+	go w.nvim.Command("qa!")
+}
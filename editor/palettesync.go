@@ -0,0 +1,90 @@
+package editor
+
+import (
+	"fmt"
+)
+
+// paletteHighlights are the highlight groups the palette is built from, besides
+// the default colors of nvim
+var paletteHighlights = []string{"Pmenu", "StatusLine", "LineNr", "Comment"}
+
+// paletteHighlightsExpr evaluates to {group: [fg, bg]} of paletteHighlights in nvim
+func paletteHighlightsExpr() string {
+	groups := ""
+	for _, group := range paletteHighlights {
+		groups += fmt.Sprintf("'%s': 0, ", group)
+	}
+
+	return fmt.Sprintf(
+		`map({%s}, '[synIDattr(synIDtrans(hlID(v:key)), "fg#"), synIDattr(synIDtrans(hlID(v:key)), "bg#")]')`,
+		groups,
+	)
+}
+
+// HighlightColor is the foreground and background of a highlight group, nil if not set
+type HighlightColor struct {
+	fg *RGBA
+	bg *RGBA
+}
+
+// syncPalette handles gonvim_colorscheme, sent on ColorScheme with the colors of
+// paletteHighlights, and rebuilds the palette from them
+func (w *Workspace) syncPalette(args []interface{}) {
+	if !editor.config.Editor.SyncPalette || len(args) < 1 {
+		return
+	}
+	if editor.workspaces[editor.active] != w {
+		return
+	}
+	groups, ok := args[0].(map[string]interface{})
+	if !ok {
+		return
+	}
+	colors := make(map[string]*HighlightColor)
+	for name, group := range groups {
+		pair, ok := group.([]interface{})
+		if !ok || len(pair) < 2 {
+			continue
+		}
+		fg, _ := pair[0].(string)
+		bg, _ := pair[1].(string)
+		colors[name] = &HighlightColor{
+			fg: hexToRGBA(fg),
+			bg: hexToRGBA(bg),
+		}
+	}
+	editor.colors.highlights = colors
+	editor.colors.update()
+	editor.updateGUIColor()
+}
+
+// applyHighlights replaces the derived colors with the ones of the highlight groups
+func (c *ColorPalette) applyHighlights() {
+	if !c.e.config.Editor.SyncPalette {
+		return
+	}
+	if hl := c.highlights["Pmenu"]; hl != nil {
+		if hl.fg != nil {
+			c.widgetFg = hl.fg
+		}
+		if hl.bg != nil {
+			c.widgetBg = hl.bg
+			c.widgetInputArea = warpColor(hl.bg, -20)
+		}
+	}
+	if hl := c.highlights["StatusLine"]; hl != nil {
+		if hl.fg != nil {
+			c.sideBarFg = hl.fg
+		}
+		if hl.bg != nil {
+			c.sideBarBg = hl.bg
+			c.sideBarSelectedItemBg = warpColor(hl.bg, -10)
+		}
+	}
+	if hl := c.highlights["LineNr"]; hl != nil && hl.fg != nil {
+		c.inactiveFg = hl.fg
+	}
+	if hl := c.highlights["Comment"]; hl != nil && hl.fg != nil {
+		c.comment = hl.fg
+	}
+}
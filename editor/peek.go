@@ -0,0 +1,123 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Peek is the header of the floating window of require("gonvim").peek_definition;
+// the window itself is a grid of nvim, the header shows its location and the
+// buttons to open it in the current window or to close it
+type Peek struct {
+	ws     *Workspace
+	widget *widgets.QWidget
+	label  *widgets.QLabel
+	win    nvim.Window
+}
+
+func newPeek(ws *Workspace) *Peek {
+	p := &Peek{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		label:  widgets.NewQLabel(nil, 0),
+	}
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(8, 2, 8, 2)
+	layout.SetSpacing(8)
+	p.widget.SetLayout(layout)
+	layout.AddWidget(p.label, 1, 0)
+	for _, b := range []struct {
+		text string
+		lua  string
+	}{
+		{"Open", "require('gonvim').peek_open()"},
+		{"Close", "require('gonvim').peek_close()"},
+	} {
+		lua := b.lua
+		button := widgets.NewQPushButton2(b.text, nil)
+		button.SetFlat(true)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.SetCursor(gui.NewQCursor2(core.Qt__PointingHandCursor))
+		button.ConnectClicked(func(bool) {
+			go p.ws.execLua(lua)
+			editor.wsWidget.SetFocus2()
+		})
+		layout.AddWidget(button, 0, 0)
+	}
+	p.widget.Hide()
+
+	return p
+}
+
+func (p *Peek) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	font := p.ws.font
+	p.widget.SetStyleSheet(fmt.Sprintf(`
+	QWidget { color: %s; background-color: %s; font-family: %s; font-size: %dpt; }
+	QPushButton { color: %s; border: 0px; padding: 0px; }
+	QPushButton:hover { text-decoration: underline; }
+	`, fg.String(), bg.String(), font.fontNew.Family(), int(font.fontNew.PointSizeF()*0.9), editor.colors.comment.String()))
+}
+
+// handle handles gonvim_peek; the window, the path and the line
+func (p *Peek) handle(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	p.win = nvim.Window(util.ReflectToInt(args[0]))
+	path, _ := args[1].(string)
+	p.label.SetText(fmt.Sprintf("%s:%d", path, util.ReflectToInt(args[2])))
+	p.setColor()
+	p.widget.SetParent(editor.wsWidget)
+
+	// The grid may have been positioned before the notification
+	p.ws.screen.windows.Range(func(_, winITF interface{}) bool {
+		win := winITF.(*Window)
+		if win != nil && win.id == p.win && win.isFloatWin {
+			p.place(win)
+			return false
+		}
+		return true
+	})
+}
+
+// close handles gonvim_peek_close
+func (p *Peek) close(args []interface{}) {
+	if len(args) > 0 && nvim.Window(util.ReflectToInt(args[0])) != p.win {
+		return
+	}
+	p.win = 0
+	p.widget.Hide()
+}
+
+// place puts the header above the grid of the window, or below it at the top of the screen
+func (p *Peek) place(win *Window) {
+	if p.win == 0 || win.id != p.win {
+		return
+	}
+	height := p.widget.SizeHint().Height()
+	x := win.widget.X()
+	y := win.widget.Y() - height
+	if y < 0 {
+		y = win.widget.Y() + win.widget.Height()
+	}
+	p.widget.SetGeometry2(x, y, win.widget.Width(), height)
+	p.widget.Show()
+	p.widget.Raise()
+}
+
+// hideFor hides the header with the grid of the window
+func (p *Peek) hideFor(win *Window) {
+	if p.win != 0 && win.id == p.win {
+		p.widget.Hide()
+	}
+}
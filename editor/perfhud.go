@@ -0,0 +1,134 @@
+package editor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// PerfHUD is an overlay of the rendering statistics of the last second, toggled by
+// :GonvimPerfHUD. All the counters are updated on the Qt thread; they are not
+// counted while the HUD is hidden, when editor.perf is nil.
+type PerfHUD struct {
+	widget *widgets.QLabel
+	timer  *core.QTimer
+
+	frames      int
+	batches     int
+	batchEvents int
+	maxBatch    int
+	elapsed     map[string]time.Duration
+	counts      map[string]int
+}
+
+// togglePerfHUD shows or hides the performance HUD
+func (e *Editor) togglePerfHUD() {
+	if e.perf != nil {
+		e.perf.timer.Stop()
+		e.perf.widget.Hide()
+		e.perf.widget.DeleteLater()
+		e.perf = nil
+		return
+	}
+
+	label := widgets.NewQLabel(e.window, 0)
+	label.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+	label.SetContentsMargins(8, 6, 8, 6)
+	font := gui.NewQFont2(e.extFontFamily, e.extFontSize-1, 1, false)
+	font.SetStyleHint(gui.QFont__Monospace, gui.QFont__PreferDefault)
+	label.SetFont(font)
+	if e.colors.widgetFg != nil && e.colors.widgetBg != nil {
+		bg := e.colors.widgetBg
+		label.SetStyleSheet(fmt.Sprintf(" * {color: %s; background: rgba(%d, %d, %d, 0.85);}", e.colors.widgetFg.String(), bg.R, bg.G, bg.B))
+	}
+
+	h := &PerfHUD{
+		widget:  label,
+		timer:   core.NewQTimer(nil),
+		elapsed: make(map[string]time.Duration),
+		counts:  make(map[string]int),
+	}
+	h.timer.ConnectTimeout(h.refresh)
+	h.timer.Start(1000)
+	e.perf = h
+	h.refresh()
+}
+
+// measure adds the time since start to the subsystem, used as
+// defer editor.perf.measure("screen", time.Now())
+func (h *PerfHUD) measure(name string, start time.Time) {
+	if h == nil {
+		return
+	}
+	h.elapsed[name] += time.Since(start)
+	h.counts[name]++
+}
+
+func (h *PerfHUD) countFrame() {
+	if h == nil {
+		return
+	}
+	h.frames++
+}
+
+// countBatch counts the redraw events applied at once
+func (h *PerfHUD) countBatch(events int) {
+	if h == nil {
+		return
+	}
+	h.batches++
+	h.batchEvents += events
+	if events > h.maxBatch {
+		h.maxBatch = events
+	}
+}
+
+func (h *PerfHUD) refresh() {
+	e := editor
+	var lines []string
+	lines = append(lines, fmt.Sprintf("FPS     %d", h.frames))
+
+	avg := 0
+	if h.batches > 0 {
+		avg = h.batchEvents / h.batches
+	}
+	lines = append(lines, fmt.Sprintf("redraw  %d batches, %d avg / %d max events", h.batches, avg, h.maxBatch))
+
+	ws := e.workspaces[e.active]
+	if ws != nil {
+		lines = append(lines, fmt.Sprintf("queue   redraw %d, gui %d", len(ws.redrawUpdates), len(ws.guiUpdates)))
+	}
+	if e.latency != nil {
+		lines = append(lines, "latency "+e.latency.report())
+	}
+
+	var names []string
+	for name := range h.elapsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// The time per frame, and the total in the second
+		total := h.elapsed[name]
+		per := total / time.Duration(h.counts[name])
+		lines = append(lines, fmt.Sprintf("%-10s %.2fms x %d (%.1fms)", name, float64(per)/float64(time.Millisecond), h.counts[name], float64(total)/float64(time.Millisecond)))
+	}
+
+	h.widget.SetText(strings.Join(lines, "\n"))
+	h.widget.AdjustSize()
+	h.widget.Move2(e.width-h.widget.Width()-10, 10+e.tablineHeight)
+	h.widget.Raise()
+	h.widget.Show()
+
+	h.frames = 0
+	h.batches = 0
+	h.batchEvents = 0
+	h.maxBatch = 0
+	h.elapsed = make(map[string]time.Duration)
+	h.counts = make(map[string]int)
+}
@@ -0,0 +1,104 @@
+package editor
+
+import (
+	"time"
+
+	"github.com/therecipe/qt/core"
+)
+
+func (e *Editor) initPowerSaving() {
+	e.signal.ConnectPowerSavingSignal(func() {
+		e.updatePowerSaving()
+	})
+	e.updatePowerSaving()
+
+	if e.config.Editor.PowerSaving != "auto" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				if isOnBattery() != e.powerSaving {
+					e.signal.PowerSavingSignal()
+				}
+			}
+		}
+	}()
+}
+
+// setPowerSavingMode overrides the power saving mode with "on", "off" or "auto"
+func (e *Editor) setPowerSavingMode(mode string) {
+	switch mode {
+	case "on", "off", "auto":
+	default:
+		return
+	}
+	e.config.Editor.PowerSaving = mode
+	e.updatePowerSaving()
+}
+
+func (e *Editor) updatePowerSaving() {
+	var powerSaving bool
+	switch e.config.Editor.PowerSaving {
+	case "on":
+		powerSaving = true
+	case "off":
+		powerSaving = false
+	default:
+		powerSaving = isOnBattery()
+	}
+	if powerSaving == e.powerSaving {
+		return
+	}
+	e.powerSaving = powerSaving
+
+	for _, ws := range e.workspaces {
+		if ws == nil {
+			continue
+		}
+		if ws.statusline != nil {
+			ws.statusline.power.redraw(powerSaving)
+		}
+		// Catch up the minimap paused while power saving
+		if !powerSaving && ws.minimap != nil && ws.minimap.visible {
+			ws.minimap.bufUpdate()
+		}
+	}
+}
+
+// animationDuration returns the duration of the animation, which is disabled in power saving mode
+func (e *Editor) animationDuration(msec int) int {
+	if e.powerSaving {
+		return 0
+	}
+	return msec
+}
+
+// updateScreen updates the screen, with the redraw rate capped in power saving mode
+func (w *Workspace) updateScreen() {
+	if !editor.powerSaving {
+		w.screen.update()
+		return
+	}
+	if w.screenUpdatePending {
+		return
+	}
+	interval := time.Second / time.Duration(editor.config.Editor.PowerSavingFps)
+	elapsed := time.Since(w.lastScreenUpdate)
+	if elapsed >= interval {
+		w.lastScreenUpdate = time.Now()
+		w.screen.update()
+		return
+	}
+	w.screenUpdatePending = true
+	core.QTimer_SingleShot(int((interval-elapsed)/time.Millisecond), func() {
+		w.screenUpdatePending = false
+		w.lastScreenUpdate = time.Now()
+		w.screen.update()
+	})
+}
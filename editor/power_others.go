@@ -0,0 +1,45 @@
+// +build !windows
+
+package editor
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// isOnBattery reports whether the machine is running on battery power
+func isOnBattery() bool {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("pmset", "-g", "batt").Output()
+		if err != nil {
+			return false
+		}
+		return strings.Contains(string(out), "'Battery Power'")
+	}
+
+	supplies, err := filepath.Glob("/sys/class/power_supply/*")
+	if err != nil {
+		return false
+	}
+	hasBattery := false
+	for _, supply := range supplies {
+		typ, err := ioutil.ReadFile(filepath.Join(supply, "type"))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(typ)) {
+		case "Mains":
+			online, err := ioutil.ReadFile(filepath.Join(supply, "online"))
+			if err == nil && strings.TrimSpace(string(online)) == "1" {
+				return false
+			}
+		case "Battery":
+			hasBattery = true
+		}
+	}
+
+	return hasBattery
+}
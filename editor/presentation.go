@@ -0,0 +1,102 @@
+package editor
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// Presentation holds the state to be restored when presentation mode ends
+type Presentation struct {
+	fontFamily     string
+	fontSize       float64
+	sideShown      bool
+	drawTabline    bool
+	drawStatusline bool
+	fullscreen     bool
+}
+
+func (e *Editor) isFullscreen() bool {
+	return e.window.WindowState()&core.Qt__WindowFullScreen > 0
+}
+
+func (e *Editor) toggleFullscreen() {
+	if e.isFullscreen() {
+		e.window.ShowNormal()
+	} else {
+		e.window.ShowFullScreen()
+	}
+}
+
+// isFullscreenKey reports whether the key event is F11, or Cmd-Ctrl-F on macOS
+func isFullscreenKey(event *gui.QKeyEvent) bool {
+	mod := event.Modifiers()
+	if runtime.GOOS == "darwin" {
+		// On macOS, Qt reports Cmd as ControlModifier and Ctrl as MetaModifier
+		return core.Qt__Key(event.Key()) == core.Qt__Key_F &&
+			mod&core.Qt__ControlModifier > 0 && mod&core.Qt__MetaModifier > 0
+	}
+	return core.Qt__Key(event.Key()) == core.Qt__Key_F11 && mod&(core.Qt__ControlModifier|core.Qt__AltModifier|core.Qt__ShiftModifier) == 0
+}
+
+// togglePresentation enlarges the font and hides the GUI decorations,
+// and restores them when it is called again
+func (e *Editor) togglePresentation() {
+	w := e.workspaces[e.active]
+	if e.presentation != nil {
+		p := e.presentation
+		e.presentation = nil
+		w.guiFont(fmt.Sprintf("%s:h%f", p.fontFamily, p.fontSize))
+		if p.sideShown {
+			e.wsSide.show()
+		}
+		w.setDecorationVisible(p.drawTabline, p.drawStatusline)
+		if !p.fullscreen && e.isFullscreen() {
+			e.window.ShowNormal()
+		}
+		return
+	}
+
+	e.presentation = &Presentation{
+		fontFamily:     w.font.fontNew.Family(),
+		fontSize:       w.font.fontNew.PointSizeF(),
+		sideShown:      e.wsSide != nil && e.wsSide.isShown,
+		drawTabline:    w.drawTabline,
+		drawStatusline: w.drawStatusline,
+		fullscreen:     e.isFullscreen(),
+	}
+	if e.wsSide != nil && e.wsSide.isShown {
+		e.wsSide.scrollarea.Hide()
+		e.wsSide.isShown = false
+	}
+	w.setDecorationVisible(false, false)
+	w.guiFont(fmt.Sprintf(
+		"%s:h%f",
+		e.presentation.fontFamily,
+		e.presentation.fontSize*e.config.Editor.PresentationFontScale,
+	))
+	if !e.presentation.fullscreen {
+		e.window.ShowFullScreen()
+	}
+}
+
+// setDecorationVisible shows or hides the tabline and statusline of the workspace
+func (w *Workspace) setDecorationVisible(tabline, statusline bool) {
+	w.drawTabline = tabline
+	w.drawStatusline = statusline
+	if tabline {
+		w.tabline.widget.Show()
+	} else {
+		w.tabline.widget.Hide()
+		w.tabline.height = 0
+	}
+	if statusline {
+		w.statusline.widget.Show()
+	} else {
+		w.statusline.widget.Hide()
+		w.statusline.height = 0
+	}
+	w.updateSize()
+}
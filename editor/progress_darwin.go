@@ -0,0 +1,22 @@
+// +build darwin
+
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/macextras"
+)
+
+// setProgress shows the progress as the dock badge, a negative value hides it
+func (e *Editor) setProgress(value int) {
+	if value < 0 {
+		macextras.QtMac_SetBadgeLabelText("")
+		return
+	}
+	macextras.QtMac_SetBadgeLabelText(fmt.Sprintf("%d%%", value))
+}
+
+func (e *Editor) setBadge(text string) {
+	macextras.QtMac_SetBadgeLabelText(text)
+}
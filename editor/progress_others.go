@@ -0,0 +1,10 @@
+// +build !darwin,!windows
+
+package editor
+
+// setProgress does nothing since there is no common taskbar progress on this platform
+func (e *Editor) setProgress(value int) {
+}
+
+func (e *Editor) setBadge(text string) {
+}
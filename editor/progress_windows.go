@@ -0,0 +1,30 @@
+// +build windows
+
+package editor
+
+import (
+	"github.com/therecipe/qt/winextras"
+)
+
+var taskbarButton *winextras.QWinTaskbarButton
+
+// setProgress shows the progress on the taskbar button, a negative value hides it
+func (e *Editor) setProgress(value int) {
+	if taskbarButton == nil {
+		taskbarButton = winextras.NewQWinTaskbarButton(e.window)
+		taskbarButton.SetWindow(e.window.WindowHandle())
+	}
+	progress := taskbarButton.Progress()
+	if value < 0 {
+		progress.Reset()
+		progress.Hide()
+		return
+	}
+	progress.SetRange(0, 100)
+	progress.SetValue(value)
+	progress.Show()
+}
+
+// setBadge does nothing since the taskbar has no text badge
+func (e *Editor) setBadge(text string) {
+}
@@ -0,0 +1,191 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// QuickfixPanel mirrors the quickfix list of nvim, which require("gonvim").quickfix()
+// sends, grouping the items by file; a click on an item jumps to it by :cc
+type QuickfixPanel struct {
+	ws     *Workspace
+	widget *widgets.QWidget
+	title  *widgets.QLabel
+	filter *widgets.QLineEdit
+	tree   *widgets.QTreeWidget
+
+	// The tree items by the index of the quickfix item (1-based)
+	items map[int]*widgets.QTreeWidgetItem
+}
+
+func newQuickfixPanel(ws *Workspace) *QuickfixPanel {
+	q := &QuickfixPanel{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		title:  widgets.NewQLabel(nil, 0),
+		filter: widgets.NewQLineEdit(nil),
+		tree:   widgets.NewQTreeWidget(nil),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 4, 6, 4)
+	layout.SetSpacing(4)
+	q.widget.SetLayout(layout)
+
+	toolbar := widgets.NewQHBoxLayout()
+	toolbar.SetSpacing(4)
+	toolbar.AddWidget(q.title, 1, 0)
+	q.filter.SetPlaceholderText("Filter")
+	q.filter.SetClearButtonEnabled(true)
+	q.filter.SetFixedWidth(200)
+	q.filter.ConnectTextChanged(q.applyFilter)
+	toolbar.AddWidget(q.filter, 0, 0)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	closeButton.SetFocusPolicy(core.Qt__NoFocus)
+	closeButton.ConnectClicked(func(bool) {
+		q.widget.Hide()
+	})
+	toolbar.AddWidget(closeButton, 0, 0)
+	layout.AddLayout(toolbar, 0)
+
+	q.tree.SetHeaderHidden(true)
+	q.tree.SetColumnCount(1)
+	q.tree.SetFocusPolicy(core.Qt__NoFocus)
+	q.tree.ConnectItemClicked(q.jump)
+	layout.AddWidget(q.tree, 1, 0)
+
+	q.widget.SetFixedHeight(editor.height / 4)
+	q.widget.Hide()
+
+	return q
+}
+
+func (q *QuickfixPanel) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	q.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QTreeWidget { border: 0px; background-color: %s; }
+	QLineEdit { border: 1px solid %s; background-color: %s; }
+	QPushButton { border: 1px solid %s; padding: 2px 8px; }
+	QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.bg.String(), editor.colors.inactiveFg.String(), editor.colors.widgetInputArea.String(), editor.colors.inactiveFg.String(), editor.colors.selectedBg.String()))
+}
+
+// toggle shows the panel with the current quickfix list, or hides it
+func (q *QuickfixPanel) toggle() {
+	if q.widget.IsVisible() {
+		q.widget.Hide()
+		return
+	}
+	go q.ws.execLua("require('gonvim').quickfix()")
+}
+
+func (q *QuickfixPanel) jump(item *widgets.QTreeWidgetItem, column int) {
+	for idx, i := range q.items {
+		if i.Pointer() == item.Pointer() {
+			go q.ws.nvim.Command(fmt.Sprintf("cc %d", idx))
+			editor.wsWidget.SetFocus2()
+			return
+		}
+	}
+}
+
+func quickfixIcon(kind string) *gui.QIcon {
+	var svg string
+	switch strings.ToUpper(kind) {
+	case "E":
+		svg = editor.getSvg("emsg", newRGBA(204, 62, 68, 1))
+	case "W":
+		svg = editor.getSvg("warn", newRGBA(255, 205, 0, 1))
+	case "I", "N":
+		svg = editor.getSvg("info", newRGBA(27, 161, 226, 1))
+	default:
+		svg = editor.getSvg("circle", editor.colors.inactiveFg)
+	}
+	pixmap := gui.NewQPixmap()
+	pixmap.LoadFromData2(core.NewQByteArray2(svg, len(svg)), "SVG", core.Qt__ColorOnly)
+	return gui.NewQIcon2(pixmap)
+}
+
+// handle handles gonvim_quickfix; the title and the items of the list
+func (q *QuickfixPanel) handle(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	title, _ := args[0].(string)
+	items, _ := args[1].([]interface{})
+
+	q.tree.Clear()
+	q.items = make(map[int]*widgets.QTreeWidgetItem)
+	files := make(map[string]*widgets.QTreeWidgetItem)
+	counts := make(map[string]int)
+	var order []string
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		filename, _ := item["filename"].(string)
+		text, _ := item["text"].(string)
+		kind, _ := item["type"].(string)
+		text = strings.TrimSpace(strings.Replace(text, "\n", " ", -1))
+		if !util.IsTrue(item["valid"]) || filename == "" {
+			// The lines of the output which are not locations, e.g. the summary of make
+			filename = "[No location]"
+		}
+		parent, ok := files[filename]
+		if !ok {
+			parent = widgets.NewQTreeWidgetItem4(q.tree, []string{filename}, 0)
+			parent.SetExpanded(true)
+			files[filename] = parent
+			order = append(order, filename)
+		}
+		counts[filename]++
+		label := text
+		if lnum := util.ReflectToInt(item["lnum"]); lnum > 0 {
+			label = fmt.Sprintf("%d:%d  %s", lnum, util.ReflectToInt(item["col"]), text)
+		}
+		child := widgets.NewQTreeWidgetItem7(parent, []string{label}, 0)
+		child.SetIcon(0, quickfixIcon(kind))
+		q.items[util.ReflectToInt(item["idx"])] = child
+		child.SetToolTip(0, text)
+	}
+	for _, filename := range order {
+		files[filename].SetText(0, fmt.Sprintf("%s (%d)", filename, counts[filename]))
+	}
+
+	if title == "" {
+		title = "Quickfix"
+	}
+	q.title.SetText(fmt.Sprintf("%s  %d items", title, len(items)))
+	q.setColor()
+	q.applyFilter(q.filter.Text())
+	q.widget.Show()
+}
+
+// applyFilter hides the items which do not contain the text, and the files without any item shown
+func (q *QuickfixPanel) applyFilter(text string) {
+	text = strings.ToLower(text)
+	for i := 0; i < q.tree.TopLevelItemCount(); i++ {
+		parent := q.tree.TopLevelItem(i)
+		fileMatched := strings.Contains(strings.ToLower(parent.Text(0)), text)
+		shown := 0
+		for j := 0; j < parent.ChildCount(); j++ {
+			child := parent.Child(j)
+			hidden := !fileMatched && !strings.Contains(strings.ToLower(child.Text(0)), text)
+			child.SetHidden(hidden)
+			if !hidden {
+				shown++
+			}
+		}
+		parent.SetHidden(shown == 0)
+	}
+}
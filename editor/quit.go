@@ -0,0 +1,128 @@
+package editor
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// initCloseEvent asks whether to save the modified buffers before the window is closed
+func (e *Editor) initCloseEvent() {
+	e.window.ConnectCloseEvent(func(event *gui.QCloseEvent) {
+		if !e.confirmQuit() {
+			event.Ignore()
+			return
+		}
+		if runtime.GOOS == "darwin" {
+			e.app.DisconnectEvent()
+		}
+		event.Accept()
+	})
+}
+
+// confirmQuit reports whether the window can be closed
+func (e *Editor) confirmQuit() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+	}
+
+	modified, err := e.modifiedBuffers()
+	if err != nil {
+		// The buffers can not be saved either while nvim is busy
+		answer := widgets.QMessageBox_Warning(
+			e.window,
+			"Goneovim",
+			err.Error()+" Quit anyway? Unsaved changes will be lost.",
+			widgets.QMessageBox__Yes|widgets.QMessageBox__No,
+			widgets.QMessageBox__No,
+		)
+		return answer == widgets.QMessageBox__Yes
+	}
+	if len(modified) == 0 {
+		return true
+	}
+
+	dialog := widgets.NewQMessageBox2(
+		widgets.QMessageBox__Warning,
+		"Goneovim",
+		"Do you want to save the changes before quitting?",
+		widgets.QMessageBox__SaveAll|widgets.QMessageBox__Discard|widgets.QMessageBox__Cancel,
+		e.window,
+		0,
+	)
+	dialog.SetInformativeText("Your changes will be lost if you don't save them.")
+	dialog.SetDetailedText(strings.Join(modified, "\n"))
+	dialog.SetDefaultButton2(widgets.QMessageBox__SaveAll)
+
+	switch widgets.QMessageBox__StandardButton(dialog.Exec()) {
+	case widgets.QMessageBox__SaveAll:
+		for _, ws := range e.workspaces {
+			if ws.uiRemoteAttached {
+				continue
+			}
+			ws.nvim.Command("silent! wall")
+		}
+		// Buffers without a name or with write errors remain modified
+		if modified, err := e.modifiedBuffers(); err != nil || len(modified) > 0 {
+			e.pushNotification(NotifyWarn, -1, "[Gonvim] Some buffers could not be saved.")
+			return false
+		}
+		return true
+	case widgets.QMessageBox__Discard:
+		return true
+	default:
+		return false
+	}
+}
+
+// errNvimBusy is returned when nvim does not answer in time, e.g. at a prompt
+var errNvimBusy = errors.New("Neovim is not responding.")
+
+// modifiedBuffers returns the names of the modified buffers of all workspaces,
+// or errNvimBusy if they are unknown
+func (e *Editor) modifiedBuffers() ([]string, error) {
+	var modified []string
+	for _, ws := range e.workspaces {
+		// The buffers of the remote nvim are kept after the UI is detached
+		if ws.uiRemoteAttached || ws.nvim == nil {
+			continue
+		}
+		names, err := ws.modifiedBuffers()
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, names...)
+	}
+
+	return modified, nil
+}
+
+func (w *Workspace) modifiedBuffers() ([]string, error) {
+	doneChannel := make(chan error, 1)
+	var names []string
+	go func() {
+		doneChannel <- w.nvim.Eval(`map(filter(getbufinfo({'buflisted': 1}), 'v:val.changed'), 'v:val.name')`, &names)
+	}()
+
+	select {
+	case err := <-doneChannel:
+		if err != nil {
+			return nil, err
+		}
+	case <-time.After(500 * time.Millisecond):
+		return nil, errNvimBusy
+	}
+	for i, name := range names {
+		if name == "" {
+			names[i] = "[No Name]"
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,277 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+const recentLen = 10
+
+// Recent is the list of recently opened files and workspaces
+type Recent struct {
+	files      []string
+	workspaces []*RecentWorkspace
+}
+
+// RecentWorkspace is a cwd of a workspace; the pinned ones are never dropped
+// from the list, and are listed first
+type RecentWorkspace struct {
+	path   string
+	opened time.Time
+	pinned bool
+}
+
+func recentFilePath(home, name string) string {
+	return filepath.Join(home, ".goneovim", name)
+}
+
+func loadRecentList(path string) []string {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []string{}
+	}
+	var list []string
+	for _, line := range strings.Split(string(bytes), "\n") {
+		if line == "" {
+			continue
+		}
+		list = append(list, line)
+	}
+
+	return list
+}
+
+func saveRecentList(path string, list []string) {
+	os.MkdirAll(filepath.Dir(path), 0755)
+	ioutil.WriteFile(path, []byte(strings.Join(list, "\n")+"\n"), 0644)
+}
+
+// pushRecentList moves the item to the head of the list
+func pushRecentList(list []string, item string) []string {
+	newList := []string{item}
+	for _, l := range list {
+		if l == item {
+			continue
+		}
+		newList = append(newList, l)
+		if len(newList) >= recentLen {
+			break
+		}
+	}
+
+	return newList
+}
+
+// loadRecentWorkspaces reads the lines of "path<Tab>unix time<Tab>pinned";
+// the lines of the older versions have only the path
+func loadRecentWorkspaces(path string) []*RecentWorkspace {
+	var list []*RecentWorkspace
+	for _, line := range loadRecentList(path) {
+		fields := strings.Split(line, "\t")
+		ws := &RecentWorkspace{
+			path: fields[0],
+		}
+		if len(fields) > 1 {
+			if sec, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				ws.opened = time.Unix(sec, 0)
+			}
+		}
+		if len(fields) > 2 {
+			ws.pinned = fields[2] == "1"
+		}
+		list = append(list, ws)
+	}
+
+	return list
+}
+
+func saveRecentWorkspaces(path string, list []*RecentWorkspace) {
+	var lines []string
+	for _, ws := range list {
+		pinned := "0"
+		if ws.pinned {
+			pinned = "1"
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%d\t%s", ws.path, ws.opened.Unix(), pinned))
+	}
+	saveRecentList(path, lines)
+}
+
+func (e *Editor) initRecent() {
+	e.recent = &Recent{
+		files:      loadRecentList(recentFilePath(e.homeDir, "recentfiles")),
+		workspaces: loadRecentWorkspaces(recentFilePath(e.homeDir, "recentworkspaces")),
+	}
+	e.updateRecentMenu()
+}
+
+func (e *Editor) addRecentFile(path string) {
+	if e.recent == nil || path == "" || !isFileExist(path) {
+		return
+	}
+	if len(e.recent.files) > 0 && e.recent.files[0] == path {
+		return
+	}
+	e.recent.files = pushRecentList(e.recent.files, path)
+	saveRecentList(recentFilePath(e.homeDir, "recentfiles"), e.recent.files)
+	e.updateRecentMenu()
+}
+
+func (e *Editor) addRecentWorkspace(path string) {
+	if e.recent == nil || path == "" {
+		return
+	}
+	ws := e.recentWorkspace(path)
+	if ws == nil {
+		ws = &RecentWorkspace{
+			path: path,
+		}
+		e.recent.workspaces = append(e.recent.workspaces, ws)
+	}
+	ws.opened = time.Now()
+	e.saveRecentWorkspaces()
+}
+
+func (e *Editor) recentWorkspace(path string) *RecentWorkspace {
+	for _, ws := range e.recent.workspaces {
+		if ws.path == path {
+			return ws
+		}
+	}
+	return nil
+}
+
+// pinRecentWorkspace pins or unpins the recent workspace
+func (e *Editor) pinRecentWorkspace(path string, pinned bool) {
+	if e.recent == nil {
+		return
+	}
+	ws := e.recentWorkspace(path)
+	if ws == nil {
+		return
+	}
+	ws.pinned = pinned
+	e.saveRecentWorkspaces()
+}
+
+// removeRecentWorkspace removes the workspace from the recent list, even if it is pinned
+func (e *Editor) removeRecentWorkspace(path string) {
+	if e.recent == nil {
+		return
+	}
+	var list []*RecentWorkspace
+	for _, ws := range e.recent.workspaces {
+		if ws.path != path {
+			list = append(list, ws)
+		}
+	}
+	e.recent.workspaces = list
+	e.saveRecentWorkspaces()
+}
+
+// saveRecentWorkspaces sorts the list, drops the old ones which are not pinned and saves it
+func (e *Editor) saveRecentWorkspaces() {
+	list := e.recent.workspaces
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].pinned != list[j].pinned {
+			return list[i].pinned
+		}
+		return list[i].opened.After(list[j].opened)
+	})
+	var kept []*RecentWorkspace
+	n := 0
+	for _, ws := range list {
+		if !ws.pinned {
+			if n >= recentLen {
+				continue
+			}
+			n++
+		}
+		kept = append(kept, ws)
+	}
+	e.recent.workspaces = kept
+	saveRecentWorkspaces(recentFilePath(e.homeDir, "recentworkspaces"), kept)
+	e.updateRecentMenu()
+}
+
+// recentWorkspacePaths returns the paths of the recent workspaces, the pinned ones first
+func (e *Editor) recentWorkspacePaths() []string {
+	var paths []string
+	for _, ws := range e.recent.workspaces {
+		paths = append(paths, ws.path)
+	}
+	return paths
+}
+
+// showRecentWorkspaces shows the menu of the recent workspaces on the sidebar,
+// to reopen them as workspaces and to pin them
+func (side *WorkspaceSide) showRecentWorkspaces(pos *core.QPoint) {
+	if editor.recent == nil {
+		return
+	}
+	menu := widgets.NewQMenu(side.widget)
+	menu.AddSection("Recent Workspaces")
+	for _, ws := range editor.recent.workspaces {
+		path := ws.path
+		text := shortenHomePath(path)
+		if ws.pinned {
+			text = "\u2605 " + text
+		}
+		sub := menu.AddMenu2(text)
+		sub.AddAction("Open").ConnectTriggered(func(bool) {
+			editor.openFolder(path)
+		})
+		pinned := ws.pinned
+		pin := "Pin"
+		if pinned {
+			pin = "Unpin"
+		}
+		sub.AddAction(pin).ConnectTriggered(func(bool) {
+			editor.pinRecentWorkspace(path, !pinned)
+		})
+		sub.AddAction("Remove from Recent").ConnectTriggered(func(bool) {
+			editor.removeRecentWorkspace(path)
+		})
+	}
+	if len(editor.recent.workspaces) == 0 {
+		menu.AddAction("No Recent Workspaces").SetEnabled(false)
+	}
+	menu.Exec2(side.widget.MapToGlobal(pos), nil)
+}
+
+// openPath opens the file, or changes the tab directory to the directory, in the active workspace
+func (e *Editor) openPath(path string, line, column int) {
+	goneovim := e.workspaces[e.active].nvim
+	modified := e.workspaces[e.active].modified
+	go func() {
+		// The | and the % of the path are not commands and file names of Vim
+		var escaped string
+		if err := goneovim.Call("fnameescape", &escaped, path); err != nil {
+			return
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			goneovim.Command(fmt.Sprintf("silent :tchdir %s", escaped))
+			return
+		}
+		if modified {
+			goneovim.Command(fmt.Sprintf(":tabe %s", escaped))
+		} else {
+			goneovim.Command(fmt.Sprintf(":e %s", escaped))
+		}
+		if line > 0 {
+			if column < 1 {
+				column = 1
+			}
+			goneovim.Command(fmt.Sprintf("call cursor(%d, %d)", line, column))
+		}
+	}()
+}
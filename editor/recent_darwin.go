@@ -0,0 +1,42 @@
+// +build darwin
+
+package editor
+
+import (
+	"path/filepath"
+
+	"github.com/therecipe/qt/widgets"
+)
+
+var dockMenu *widgets.QMenu
+
+// updateRecentMenu populates the dock menu with the recent files and workspaces
+func (e *Editor) updateRecentMenu() {
+	if dockMenu == nil {
+		dockMenu = widgets.NewQMenu(nil)
+		dockMenu.SetAsDockMenu()
+	}
+	dockMenu.Clear()
+	for _, path := range e.recent.files {
+		p := path
+		action := dockMenu.AddAction(filepath.Base(p))
+		action.SetToolTip(p)
+		action.ConnectTriggered(func(bool) {
+			e.window.Show()
+			e.window.Raise()
+			e.openPath(p, 0, 0)
+		})
+	}
+	if len(e.recent.files) > 0 && len(e.recent.workspaces) > 0 {
+		dockMenu.AddSeparator()
+	}
+	for _, path := range e.recentWorkspacePaths() {
+		p := path
+		action := dockMenu.AddAction(p)
+		action.ConnectTriggered(func(bool) {
+			e.window.Show()
+			e.window.Raise()
+			e.openPath(p, 0, 0)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// +build !darwin,!windows
+
+package editor
+
+// updateRecentMenu does nothing since there is no common recent list on this platform
+func (e *Editor) updateRecentMenu() {
+}
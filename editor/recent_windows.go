@@ -0,0 +1,37 @@
+// +build windows
+
+package editor
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/therecipe/qt/winextras"
+)
+
+// updateRecentMenu populates the taskbar jump list with the recent files and workspaces
+func (e *Editor) updateRecentMenu() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	jumpList := winextras.NewQWinJumpList(nil)
+	jumpList.Clear()
+
+	files := winextras.NewQWinJumpListCategory2("Recent Files")
+	for _, path := range e.recent.files {
+		files.AddLink(filepath.Base(path), exe, []string{pathToURL(path)})
+	}
+	files.SetVisible(len(e.recent.files) > 0)
+	jumpList.AddCategory(files)
+
+	workspaces := winextras.NewQWinJumpListCategory2("Recent Workspaces")
+	for _, path := range e.recentWorkspacePaths() {
+		workspaces.AddLink(path, exe, []string{pathToURL(path)})
+	}
+	workspaces.SetVisible(len(e.recent.workspaces) > 0)
+	jumpList.AddCategory(workspaces)
+
+	// The jump list is committed when it is destroyed
+	jumpList.DestroyQWinJumpList()
+}
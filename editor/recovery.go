@@ -0,0 +1,119 @@
+package editor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// isNvimCrashed reports whether the embedded nvim exited without VimLeavePre
+func (w *Workspace) isNvimCrashed() bool {
+	if w.uiRemoteAttached || w.exiting {
+		return false
+	}
+	select {
+	case <-editor.stop:
+		return false
+	default:
+	}
+
+	return true
+}
+
+// notifyNvimCrash asks whether to restart nvim instead of closing the workspace
+func (w *Workspace) notifyNvimCrash() {
+	w.crashed = true
+	opts := []*NotifyButton{}
+	opts = append(opts, &NotifyButton{
+		action: func() {
+			w.restartNvim(false)
+		},
+		text: "Restart",
+	})
+	opts = append(opts, &NotifyButton{
+		action: func() {
+			w.restartNvim(true)
+		},
+		text: "Restore from swap",
+	})
+	editor.pushNotification(NotifyWarn, 0, fmt.Sprintf("[Gonvim] Neovim (pid %d) exited unexpectedly.", w.nvimPid), notifyOptionArg(opts))
+}
+
+// restartNvim respawns nvim, re-attaches the UI and re-opens the previously listed buffers
+func (w *Workspace) restartNvim(recover bool) {
+	if !w.crashed {
+		return
+	}
+	w.crashed = false
+	buffers := w.listedBuffers
+	cwd := w.cwd
+
+	w.respawnNvim("", func() {
+		w.reopenBuffers(cwd, buffers, recover)
+	})
+}
+
+// respawnNvim starts a new nvim in this workspace after the previous one has exited
+func (w *Workspace) respawnNvim(path string, after func()) {
+	w.reapNvim()
+	// The signals are connected again when the UI is attached
+	w.signal.DisconnectStatuslineSignal()
+	w.signal.DisconnectLintSignal()
+	w.signal.DisconnectGitSignal()
+	w.signal.DisconnectMessageSignal()
+
+	w.stop = make(chan struct{})
+	w.stopOnce = sync.Once{}
+	w.exiting = false
+	w.uiAttached = false
+	w.entered = false
+	w.resetRedraw()
+	// The environment of the project is set again to the new nvim
+	w.env = nil
+	w.envDir = ""
+
+	go func() {
+		err := w.startNvim(path)
+		if err != nil {
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to restart Neovim: "+err.Error())
+			return
+		}
+		if after != nil {
+			after()
+		}
+	}()
+}
+
+func (w *Workspace) reopenBuffers(cwd string, buffers []string, recover bool) {
+	if cwd != "" {
+		w.nvim.Command(fmt.Sprintf("execute 'silent! cd' fnameescape('%s')", escapeVimString(cwd)))
+	}
+	for _, buffer := range buffers {
+		if recover {
+			w.nvim.Command(fmt.Sprintf("execute 'silent! recover' fnameescape('%s')", escapeVimString(buffer)))
+		} else {
+			w.nvim.Command(fmt.Sprintf("execute 'badd' fnameescape('%s')", escapeVimString(buffer)))
+		}
+	}
+	if !recover && len(buffers) > 0 {
+		w.nvim.Command(fmt.Sprintf("execute 'buffer' fnameescape('%s')", escapeVimString(buffers[0])))
+	}
+}
+
+func (w *Workspace) setListedBuffers(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	list, ok := args[0].([]interface{})
+	if !ok {
+		return
+	}
+	var buffers []string
+	for _, b := range list {
+		name, ok := b.(string)
+		if !ok || name == "" {
+			continue
+		}
+		buffers = append(buffers, name)
+	}
+	w.listedBuffers = buffers
+}
@@ -0,0 +1,159 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/grid"
+	"github.com/akiyosi/goneovim/util"
+)
+
+// redrawBatchMax is the number of events to post to the Qt thread
+// even if nvim has not sent "flush" yet
+const redrawBatchMax = 4096
+
+// isShadowedEvent reports whether the Qt thread may apply the event before the
+// grid_line events preceding it, which are merged in the shadow grids
+func isShadowedEvent(event string) bool {
+	switch event {
+	case "grid_line", "grid_scroll", "grid_clear", "grid_resize", "hl_attr_define":
+		return true
+	}
+
+	return false
+}
+
+// shadowRedraw applies an event to the shadow grids, and returns the events
+// left to post to the Qt thread
+func (w *Workspace) shadowRedraw(event string, args []interface{}) []interface{} {
+	var rest []interface{}
+	for _, a := range args {
+		arg, ok := a.([]interface{})
+		if !ok || len(arg) == 0 {
+			rest = append(rest, a)
+			continue
+		}
+		gridid := util.ReflectToInt(arg[0])
+		switch event {
+		case "grid_resize":
+			if len(arg) >= 3 {
+				cols, rows := util.ReflectToInt(arg[1]), util.ReflectToInt(arg[2])
+				// As Screen.resizeWindow, which keeps the content except for the global grid
+				if gridid == 1 {
+					w.shadowGrids[gridid] = grid.New(cols, rows)
+				} else {
+					w.shadowGrids[gridid] = w.shadowGrids[gridid].Resize(cols, rows)
+				}
+			}
+		case "grid_clear":
+			if g, ok := w.shadowGrids[gridid]; ok {
+				g.Clear()
+			}
+		case "grid_destroy":
+			delete(w.shadowGrids, gridid)
+		case "grid_scroll":
+			if g, ok := w.shadowGrids[gridid]; ok && len(arg) >= 6 {
+				g.Scroll(
+					util.ReflectToInt(arg[1]),
+					util.ReflectToInt(arg[2]),
+					util.ReflectToInt(arg[3]),
+					util.ReflectToInt(arg[4]),
+					util.ReflectToInt(arg[5]),
+				)
+			}
+		}
+		rest = append(rest, a)
+	}
+
+	return rest
+}
+
+// takeShadowLines returns the final content of the rows changed since the previous call
+func (w *Workspace) takeShadowLines() []interface{} {
+	var lines []*grid.Line
+	for gridid, g := range w.shadowGrids {
+		lines = append(lines, g.TakeDirty(gridid)...)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []interface{}{"grid_line", lines}
+}
+
+// queueRedraw is called on the rpc goroutine. The redraw events are decoded
+// there and applied to the shadow grids, and posted to the Qt thread once per
+// batch, which nvim terminates by "flush", with a grid_line of each row changed.
+func (w *Workspace) queueRedraw(updates [][]interface{}) {
+	w.redrawMu.Lock()
+	if w.shadowGrids == nil {
+		w.shadowGrids = make(map[gridId]*grid.Grid)
+	}
+	flush := false
+	for _, update := range updates {
+		if len(update) == 0 {
+			continue
+		}
+		event, _ := update[0].(string)
+		w.redrawStats.count(event, len(update)-1)
+		if !isShadowedEvent(event) {
+			// e.g. win_hide must be applied after the lines drawn before it
+			if lines := w.takeShadowLines(); lines != nil {
+				w.pendingRedraw = append(w.pendingRedraw, lines)
+			}
+		}
+		switch event {
+		case "grid_line":
+			// The lines out of the shadow grids are posted as they are
+			var rest []*grid.Line
+			for _, line := range grid.DecodeLines(update[1:]) {
+				g, ok := w.shadowGrids[line.Grid]
+				if !ok || !g.Put(line) {
+					rest = append(rest, line)
+				}
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			update = []interface{}{event, rest}
+		case "flush":
+			flush = true
+		default:
+			update = append([]interface{}{event}, w.shadowRedraw(event, update[1:])...)
+		}
+		w.pendingRedraw = append(w.pendingRedraw, update)
+	}
+	if !flush && len(w.pendingRedraw) < redrawBatchMax {
+		w.redrawMu.Unlock()
+		return
+	}
+	if lines := w.takeShadowLines(); lines != nil {
+		w.pendingRedraw = append(w.pendingRedraw, lines)
+	}
+	batch := w.pendingRedraw
+	w.pendingRedraw = nil
+	w.redrawMu.Unlock()
+
+	// Sent out of the lock, which the Qt thread takes in resetRedraw
+	w.redrawUpdates <- batch
+	w.signal.RedrawSignal()
+}
+
+// resetRedraw drops the events of the previous nvim
+func (w *Workspace) resetRedraw() {
+	w.redrawMu.Lock()
+	w.pendingRedraw = nil
+	w.shadowGrids = nil
+	w.redrawMu.Unlock()
+}
+
+// takeRedraw returns all the batches posted to the Qt thread so far, so that
+// a lagging UI applies them at once
+func (w *Workspace) takeRedraw() [][]interface{} {
+	var updates [][]interface{}
+	for {
+		select {
+		case batch := <-w.redrawUpdates:
+			updates = append(updates, batch...)
+		default:
+			return updates
+		}
+	}
+}
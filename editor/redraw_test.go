@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/akiyosi/goneovim/grid"
+)
+
+// testGridLine returns the grid_line event of a line of the text
+func testGridLine(gridid, row, col int, text string) []interface{} {
+	var cells []interface{}
+	for _, c := range text {
+		cells = append(cells, []interface{}{string(c), int64(1)})
+	}
+	return []interface{}{"grid_line", []interface{}{int64(gridid), int64(row), int64(col), cells}}
+}
+
+func testGridEvent(event string, args ...int) []interface{} {
+	arg := make([]interface{}, len(args))
+	for i, a := range args {
+		arg[i] = int64(a)
+	}
+	return []interface{}{event, arg}
+}
+
+// describeRedraw describes the events as "name" or "grid_line(grid:row:col:text ...)"
+func describeRedraw(updates [][]interface{}) string {
+	var events []string
+	for _, update := range updates {
+		event, _ := update[0].(string)
+		if lines, ok := update[1].([]*grid.Line); ok {
+			var texts []string
+			for _, line := range lines {
+				var b strings.Builder
+				for _, cell := range line.Cells {
+					b.WriteString(cell.Text)
+				}
+				texts = append(texts, fmt.Sprintf("%d:%d:%d:%s", line.Grid, line.Row, line.Col, b.String()))
+			}
+			event = fmt.Sprintf("%s(%s)", event, strings.Join(texts, " "))
+		}
+		events = append(events, event)
+	}
+	return strings.Join(events, ", ")
+}
+
+func TestQueueRedraw(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates [][]interface{}
+		pending string
+		shadow  string
+	}{
+		{
+			name: "lines of a row merged",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridLine(2, 0, 0, "abcd"),
+				testGridLine(2, 0, 1, "XY"),
+			},
+			pending: "grid_resize",
+			shadow:  "grid_line(2:0:0:aXYd)",
+		},
+		{
+			name: "lines of an unknown grid posted as they are",
+			updates: [][]interface{}{
+				testGridLine(5, 0, 0, "ab"),
+			},
+			pending: "grid_line(5:0:0:ab)",
+		},
+		{
+			name: "lines out of the grid posted as they are",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridLine(2, 3, 0, "ab"),
+			},
+			pending: "grid_resize, grid_line(2:3:0:ab)",
+		},
+		{
+			name: "lines taken before the other events",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridLine(2, 1, 0, "ab"),
+				testGridEvent("win_hide", 2),
+				testGridLine(2, 1, 1, "c"),
+			},
+			pending: "grid_resize, grid_line(2:1:0:ab), win_hide",
+			shadow:  "grid_line(2:1:0:ac)",
+		},
+		{
+			name: "lines dropped by grid_clear",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridLine(2, 0, 0, "ab"),
+				testGridEvent("grid_clear", 2),
+			},
+			pending: "grid_resize, grid_clear",
+		},
+		{
+			name: "lines moved by grid_scroll",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 3),
+				testGridLine(2, 2, 0, "ab"),
+				testGridEvent("grid_scroll", 2, 0, 3, 0, 4, 1, 0),
+			},
+			pending: "grid_resize, grid_scroll",
+			shadow:  "grid_line(2:1:0:ab)",
+		},
+		{
+			name: "content of the window kept by grid_resize",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridLine(2, 0, 0, "abcd"),
+				testGridEvent("grid_resize", 2, 2, 2),
+			},
+			pending: "grid_resize, grid_resize",
+			shadow:  "grid_line(2:0:0:ab)",
+		},
+		{
+			name: "content of the global grid dropped by grid_resize",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 1, 4, 2),
+				testGridLine(1, 0, 0, "abcd"),
+				testGridEvent("grid_resize", 1, 2, 2),
+			},
+			pending: "grid_resize, grid_resize",
+		},
+		{
+			name: "lines of a destroyed grid posted as they are",
+			updates: [][]interface{}{
+				testGridEvent("grid_resize", 2, 4, 2),
+				testGridEvent("grid_destroy", 2),
+				testGridLine(2, 0, 0, "ab"),
+			},
+			pending: "grid_resize, grid_destroy, grid_line(2:0:0:ab)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Workspace{}
+			w.queueRedraw(tt.updates)
+			if got := describeRedraw(w.pendingRedraw); got != tt.pending {
+				t.Errorf("pending: got %q, want %q", got, tt.pending)
+			}
+			shadow := ""
+			if lines := w.takeShadowLines(); lines != nil {
+				shadow = describeRedraw([][]interface{}{lines})
+			}
+			if shadow != tt.shadow {
+				t.Errorf("shadow: got %q, want %q", shadow, tt.shadow)
+			}
+		})
+	}
+}
+
+func TestResetRedraw(t *testing.T) {
+	w := &Workspace{}
+	w.queueRedraw([][]interface{}{
+		testGridEvent("grid_resize", 2, 4, 2),
+		testGridLine(2, 0, 0, "ab"),
+	})
+	w.resetRedraw()
+	if w.pendingRedraw != nil || w.takeShadowLines() != nil {
+		t.Errorf("events of the previous nvim are left: %q", describeRedraw(w.pendingRedraw))
+	}
+}
@@ -0,0 +1,185 @@
+package editor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akiyosi/goneovim/grid"
+	"github.com/neovim/go-client/msgpack"
+)
+
+// RedrawRecorder writes the redraw notifications of the nvim of a workspace
+// to the file of --record-redraw, each as a msgpack array of the nanoseconds
+// since the start and the events, so that --replay-redraw can replay the
+// session into the renderer to compare its performance between changes. The
+// workspaces opened later are recorded to the files suffixed by .2, .3, ...
+type RedrawRecorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	buf   *bufio.Writer
+	enc   *msgpack.Encoder
+	start time.Time
+}
+
+func newRedrawRecorder(path string) (*RedrawRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	return &RedrawRecorder{
+		file:  file,
+		buf:   buf,
+		enc:   msgpack.NewEncoder(buf),
+		start: time.Now(),
+	}, nil
+}
+
+// record is called with the redraw events of nvim, in the goroutine of the rpc
+func (r *RedrawRecorder) record(updates [][]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	r.enc.Encode([]interface{}{time.Since(r.start).Nanoseconds(), updates})
+}
+
+// close flushes the file; it is called when the workspace is closed, or on the cleanup
+func (r *RedrawRecorder) close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	r.buf.Flush()
+	r.file.Close()
+	r.file = nil
+}
+
+// newRedrawRecorder returns the recorder of a new workspace, or nil without --record-redraw
+func (e *Editor) newRedrawRecorder() *RedrawRecorder {
+	if e.opts.RecordRedraw == "" {
+		return nil
+	}
+	e.redrawRecords++
+	path := e.opts.RecordRedraw
+	if e.redrawRecords > 1 {
+		path = fmt.Sprintf("%s.%d", path, e.redrawRecords)
+	}
+	recorder, err := newRedrawRecorder(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+
+	return recorder
+}
+
+// readRedrawRecord reads the events of the notifications recorded by --record-redraw
+func readRedrawRecord(path string) ([][][]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	dec := msgpack.NewDecoder(bufio.NewReader(file))
+	var notifications [][][]interface{}
+	for {
+		var record []interface{}
+		if err := dec.Decode(&record); err != nil {
+			if err == io.EOF {
+				return notifications, nil
+			}
+			return notifications, err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		events, _ := record[1].([]interface{})
+		updates := make([][]interface{}, 0, len(events))
+		for _, ev := range events {
+			if update, ok := ev.([]interface{}); ok {
+				updates = append(updates, update)
+			}
+		}
+		notifications = append(notifications, updates)
+	}
+}
+
+// replayRedraw applies the recorded redraw events to the workspace as fast as
+// possible, a frame per "flush", repaints after each frame, prints the timing
+// and exits. The redraw events of the nvim of the workspace are ignored.
+func (e *Editor) replayRedraw() {
+	notifications, err := readRedrawRecord(e.opts.ReplayRedraw)
+	if err != nil && len(notifications) == 0 {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+	ws := e.workspaces[e.active]
+	ws.replaying = true
+
+	var frames []time.Duration
+	var handleTotal, paintTotal time.Duration
+	events := 0
+	var batch [][]interface{}
+	frame := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		ws.handleRedraw(batch)
+		handled := time.Now()
+		ws.widget.Repaint()
+		end := time.Now()
+		handleTotal += handled.Sub(start)
+		paintTotal += end.Sub(handled)
+		frames = append(frames, end.Sub(start))
+		batch = nil
+	}
+	start := time.Now()
+	for _, updates := range notifications {
+		for _, update := range updates {
+			if len(update) == 0 {
+				continue
+			}
+			events++
+			event, _ := update[0].(string)
+			switch event {
+			case "grid_line":
+				update = []interface{}{event, grid.DecodeLines(update[1:])}
+			}
+			batch = append(batch, update)
+			if event == "flush" {
+				frame()
+			}
+		}
+	}
+	frame()
+	total := time.Since(start)
+
+	if len(frames) == 0 {
+		fmt.Println("replay: no frames")
+		os.Exit(0)
+	}
+	sorted := append([]time.Duration{}, frames...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	percentile := func(p float64) time.Duration {
+		return sorted[int(float64(len(sorted)-1)*p)]
+	}
+	fmt.Printf("replay: %d notifications, %d events, %d frames in %s\n", len(notifications), events, len(frames), total)
+	fmt.Printf("replay: handle %s, paint %s\n", handleTotal, paintTotal)
+	fmt.Printf("replay: frame mean %s, p50 %s, p95 %s, p99 %s, max %s\n",
+		total/time.Duration(len(frames)), percentile(0.5), percentile(0.95), percentile(0.99), sorted[len(sorted)-1])
+	os.Exit(0)
+}
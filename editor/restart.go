@@ -0,0 +1,72 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// restart saves the session, stops nvim and starts a fresh one restoring the session,
+// e.g. to reload init.vim. The other workspaces are not touched.
+func (w *Workspace) restart(args []interface{}) {
+	if w.uiRemoteAttached || w.restartSession != "" {
+		return
+	}
+	force := len(args) > 0 && util.IsTrue(args[0])
+	// The zoom level is only touched on the GUI thread
+	zoomLevel := w.zoomLevel
+
+	go func() {
+		if modified, err := w.modifiedBuffers(); !force && (err != nil || len(modified) > 0) {
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] There are unsaved changes. Use :GonvimRestart! to discard them.")
+			return
+		}
+
+		file, err := ioutil.TempFile("", "goneovim-restart-*.vim")
+		if err != nil {
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+			return
+		}
+		path := file.Name()
+		file.Close()
+
+		err = w.nvim.Command(fmt.Sprintf("execute 'mksession!' fnameescape('%s')", escapeVimString(path)))
+		if err != nil {
+			os.Remove(path)
+			editor.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to save the session: "+err.Error())
+			return
+		}
+		// The session file removes itself when it is sourced
+		file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		if err == nil {
+			file.WriteString("call delete(expand('<sfile>:p'))\n")
+			file.Close()
+		}
+		appendZoomToSession(path, zoomLevel)
+
+		w.guiUpdates <- []interface{}{"gonvim_restart_session", path}
+		w.signal.GuiSignal()
+	}()
+}
+
+// setRestartSession handles gonvim_restart_session; the session saved by
+// restart. The new nvim is started when the stop signal of this one is handled.
+func (w *Workspace) setRestartSession(args []interface{}) {
+	path, _ := args[0].(string)
+	if w.restartSession != "" {
+		// Saved by another restart in the meantime
+		os.Remove(path)
+		return
+	}
+	w.restartSession = path
+	go w.nvim.Command("qa!")
+}
+
+// finishRestart starts the new nvim with the session saved by restart
+func (w *Workspace) finishRestart() {
+	path := w.restartSession
+	w.restartSession = ""
+	w.respawnNvim(path, nil)
+}
@@ -35,15 +35,16 @@ func (rgba *RGBA) equals(other *RGBA) bool {
 	if other == nil {
 		return false
 	}
-	return rgba.R == other.R && rgba.G == other.G && rgba.B == other.B
+	return rgba.R == other.R && rgba.G == other.G && rgba.B == other.B && rgba.A == other.A
 }
 
 func (rgba *RGBA) String() string {
 	return fmt.Sprintf("rgba(%d, %d, %d, %f)", rgba.R, rgba.G, rgba.B, rgba.A)
 }
 
+// StringTransparent is the color in the transparency of the window, and its own alpha
 func (rgba *RGBA) StringTransparent() string {
-	transparent := editor.config.Editor.Transparent
+	transparent := editor.config.Editor.Transparent * rgba.A
 	return fmt.Sprintf("rgba(%d, %d, %d, %f)", rgba.R, rgba.G, rgba.B, transparent)
 }
 
@@ -57,19 +58,58 @@ func (rgba *RGBA) Hex() string {
 	return fmt.Sprintf("#%02x%02x%02x", uint8(rgba.R), uint8(rgba.G), uint8(rgba.B))
 }
 
+// HexAlpha is #RRGGBBAA, which hexToRGBA parses back to the same color.
+// Hex is used for the stylesheets and SVGs, which do not accept this form.
+func (rgba *RGBA) HexAlpha() string {
+	return fmt.Sprintf("%s%02x", rgba.Hex(), uint8(math.Round(clampAlpha(rgba.A)*255)))
+}
+
+func clampAlpha(a float64) float64 {
+	if a < 0 {
+		return 0
+	}
+	if a > 1 {
+		return 1
+	}
+	return a
+}
+
+// namedColors are the CSS basic color keywords
+var namedColors = map[string]string{
+	"black":       "#000000",
+	"silver":      "#c0c0c0",
+	"gray":        "#808080",
+	"grey":        "#808080",
+	"white":       "#ffffff",
+	"maroon":      "#800000",
+	"red":         "#ff0000",
+	"purple":      "#800080",
+	"fuchsia":     "#ff00ff",
+	"green":       "#008000",
+	"lime":        "#00ff00",
+	"olive":       "#808000",
+	"yellow":      "#ffff00",
+	"navy":        "#000080",
+	"blue":        "#0000ff",
+	"teal":        "#008080",
+	"aqua":        "#00ffff",
+	"orange":      "#ffa500",
+	"transparent": "#00000000",
+}
+
 // input color *RGBA, aplpha (0.0...-1.0..) int
 func (rgba *RGBA) brend(color *RGBA, alpha float64) *RGBA {
 	return &RGBA{
 		R: int((float64(rgba.R) * (1.0 - alpha)) + (float64(color.R) * alpha)),
 		G: int((float64(rgba.G) * (1.0 - alpha)) + (float64(color.G) * alpha)),
 		B: int((float64(rgba.B) * (1.0 - alpha)) + (float64(color.B) * alpha)),
-		A: 1,
+		A: clampAlpha(rgba.A*(1.0-alpha) + color.A*alpha),
 	}
 }
 
 // QColor is
 func (rgba *RGBA) QColor() *gui.QColor {
-	return gui.NewQColor3(rgba.R, rgba.G, rgba.B, int(rgba.A*255))
+	return gui.NewQColor3(rgba.R, rgba.G, rgba.B, int(clampAlpha(rgba.A)*255))
 }
 
 func calcColor(c int) *RGBA {
@@ -320,6 +360,52 @@ func (hsv *HSV) RGB() *RGBA {
 		R: int(r),
 		G: int(g),
 		B: int(b),
-		A: 255,
+		A: 1,
+	}
+}
+
+// luminance is the relative luminance of WCAG 2.0
+func (rgba *RGBA) luminance() float64 {
+	channel := func(c int) float64 {
+		v := float64(c) / 255.0
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(rgba.R) + 0.7152*channel(rgba.G) + 0.0722*channel(rgba.B)
+}
+
+// contrastRatio is the contrast ratio of WCAG 2.0, from 1 to 21
+func contrastRatio(a, b *RGBA) float64 {
+	la := a.luminance()
+	lb := b.luminance()
+	if la < lb {
+		la, lb = lb, la
 	}
+
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// ensureContrast moves fg away from bg, toward white or black whichever is farther,
+// until the contrast ratio reaches ratio
+func ensureContrast(fg, bg *RGBA, ratio float64) *RGBA {
+	if fg == nil || bg == nil || contrastRatio(fg, bg) >= ratio {
+		return fg
+	}
+	target := newRGBA(255, 255, 255, fg.A)
+	if bg.luminance() > 0.5 {
+		target = newRGBA(0, 0, 0, fg.A)
+	}
+	color := fg.copy()
+	for alpha := 0.1; alpha <= 1.0; alpha += 0.1 {
+		color = fg.brend(target, alpha)
+		color.A = fg.A
+		if contrastRatio(color, bg) >= ratio {
+			break
+		}
+	}
+
+	return color
 }
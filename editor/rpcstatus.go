@@ -0,0 +1,298 @@
+package editor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// RPCStats counts the events received from a source by their names, and keeps
+// the last error of the source. It is counted by the goroutines receiving the
+// events, and read by the RPC status panel.
+type RPCStats struct {
+	mu     sync.Mutex
+	counts map[string]int
+	totals map[string]int
+	// The counts are since the previous snapshot
+	since time.Time
+
+	lastError string
+	errorTime time.Time
+}
+
+// rpcRate is the rate of the events of a name in a snapshot of RPCStats
+type rpcRate struct {
+	name  string
+	rate  float64
+	total int
+}
+
+func newRPCStats() *RPCStats {
+	return &RPCStats{
+		counts: make(map[string]int),
+		totals: make(map[string]int),
+		since:  time.Now(),
+	}
+}
+
+func (s *RPCStats) count(name string, n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.counts[name] += n
+	s.totals[name] += n
+	s.mu.Unlock()
+}
+
+func (s *RPCStats) setError(err string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.lastError = err
+	s.errorTime = time.Now()
+	s.mu.Unlock()
+}
+
+// snapshot returns the rates of the events since the previous snapshot, the
+// highest first, and the last error
+func (s *RPCStats) snapshot() ([]*rpcRate, string) {
+	if s == nil {
+		return nil, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	seconds := now.Sub(s.since).Seconds()
+	s.since = now
+	var rates []*rpcRate
+	for name, total := range s.totals {
+		rate := 0.0
+		if seconds > 0 {
+			rate = float64(s.counts[name]) / seconds
+		}
+		rates = append(rates, &rpcRate{name: name, rate: rate, total: total})
+	}
+	s.counts = make(map[string]int)
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].rate != rates[j].rate {
+			return rates[i].rate > rates[j].rate
+		}
+		return rates[i].name < rates[j].name
+	})
+	lastError := s.lastError
+	if lastError != "" {
+		lastError = fmt.Sprintf("%s (%s)", lastError, s.errorTime.Format("15:04:05"))
+	}
+
+	return rates, lastError
+}
+
+// RPCStatusPanel shows the RPC channels of nvim, the remote plugin hosts among
+// them, and the extensions of the GUI, with the rates of the events received
+// from them and their last errors, to find the plugin flooding the GUI.
+// It is toggled by :GonvimRPCStatus, and refreshed every second while shown.
+type RPCStatusPanel struct {
+	ws     *Workspace
+	widget *widgets.QWidget
+	title  *widgets.QLabel
+	tree   *widgets.QTreeWidget
+	timer  *core.QTimer
+}
+
+func newRPCStatusPanel(ws *Workspace) *RPCStatusPanel {
+	p := &RPCStatusPanel{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		title:  widgets.NewQLabel(nil, 0),
+		tree:   widgets.NewQTreeWidget(nil),
+		timer:  core.NewQTimer(nil),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 4, 6, 4)
+	layout.SetSpacing(4)
+	p.widget.SetLayout(layout)
+
+	toolbar := widgets.NewQHBoxLayout()
+	toolbar.SetSpacing(4)
+	toolbar.AddWidget(p.title, 1, 0)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	closeButton.SetFocusPolicy(core.Qt__NoFocus)
+	closeButton.ConnectClicked(func(bool) {
+		p.close()
+	})
+	toolbar.AddWidget(closeButton, 0, 0)
+	layout.AddLayout(toolbar, 0)
+
+	p.tree.SetColumnCount(5)
+	p.tree.SetHeaderLabels([]string{"Source", "Type", "Events/s", "Total", "Last error"})
+	p.tree.SetFocusPolicy(core.Qt__NoFocus)
+	layout.AddWidget(p.tree, 1, 0)
+
+	p.timer.ConnectTimeout(p.request)
+	p.widget.SetFixedHeight(editor.height / 4)
+	p.widget.Hide()
+
+	return p
+}
+
+func (p *RPCStatusPanel) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	p.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QTreeWidget { border: 0px; background-color: %s; }
+	QHeaderView::section { border: 0px; padding: 2px 4px; background-color: %s; }
+	QPushButton { border: 1px solid %s; padding: 2px 8px; }
+	QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.bg.String(), bg.String(), editor.colors.inactiveFg.String(), editor.colors.selectedBg.String()))
+}
+
+// toggle shows the panel, measuring the rates from now, or hides it
+func (p *RPCStatusPanel) toggle() {
+	if p.widget.IsVisible() {
+		p.close()
+		return
+	}
+	for _, stats := range p.stats() {
+		stats.snapshot()
+	}
+	p.tree.Clear()
+	p.title.SetText("Measuring the events...")
+	p.widget.Show()
+	p.timer.Start(1000)
+}
+
+func (p *RPCStatusPanel) close() {
+	p.timer.Stop()
+	p.widget.Hide()
+}
+
+// stats returns all the sources of the events shown in the panel
+func (p *RPCStatusPanel) stats() []*RPCStats {
+	stats := []*RPCStats{p.ws.redrawStats, p.ws.guiStats}
+	for _, ext := range editor.extensions.all() {
+		stats = append(stats, ext.stats)
+	}
+
+	return stats
+}
+
+func (p *RPCStatusPanel) request() {
+	if p.ws.nvim == nil {
+		return
+	}
+	go p.ws.execLua("require('gonvim').rpc_channels()")
+}
+
+// handle handles gonvim_rpc_channels; the RPC channels of nvim, and the id of
+// the channel of this GUI
+func (p *RPCStatusPanel) handle(args []interface{}) {
+	if len(args) < 2 || !p.timer.IsActive() {
+		return
+	}
+	channels, _ := args[0].([]interface{})
+	self := util.ReflectToInt(args[1])
+
+	scroll := p.tree.VerticalScrollBar().Value()
+	p.tree.Clear()
+	var top *rpcRate
+	topSource := ""
+	addStats := func(parent *widgets.QTreeWidgetItem, stats *RPCStats, source string) {
+		rates, lastError := stats.snapshot()
+		var rate float64
+		var total int
+		for _, r := range rates {
+			rate += r.rate
+			total += r.total
+			if top == nil || r.rate > top.rate {
+				top = r
+				topSource = source
+			}
+			widgets.NewQTreeWidgetItem7(parent, []string{r.name, "", fmt.Sprintf("%.1f", r.rate), fmt.Sprint(r.total), ""}, 0)
+		}
+		parent.SetText(2, fmt.Sprintf("%.1f", rate))
+		parent.SetText(3, fmt.Sprint(total))
+		parent.SetText(4, lastError)
+		parent.SetToolTip(4, lastError)
+	}
+
+	group := widgets.NewQTreeWidgetItem4(p.tree, []string{"Neovim channels"}, 0)
+	group.SetExpanded(true)
+	for _, c := range channels {
+		ch, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := util.ReflectToInt(ch["id"])
+		name, _ := ch["name"].(string)
+		typ, _ := ch["type"].(string)
+		stream, _ := ch["stream"].(string)
+		if name == "" {
+			name = "(unnamed)"
+		}
+		kind := rpcClientKind(typ)
+		if id == self {
+			kind = "this GUI"
+		}
+		if stream != "" {
+			kind = fmt.Sprintf("%s, %s", kind, stream)
+		}
+		item := widgets.NewQTreeWidgetItem7(group, []string{fmt.Sprintf("%d: %s", id, name), kind, "", "", ""}, 0)
+		if id != self {
+			continue
+		}
+		// Only the events to this GUI are seen
+		item.SetExpanded(true)
+		redraw := widgets.NewQTreeWidgetItem7(item, []string{"redraw", "UI events"}, 0)
+		addStats(redraw, p.ws.redrawStats, "redraw")
+		guiItem := widgets.NewQTreeWidgetItem7(item, []string{"Gui", "rpcnotify"}, 0)
+		addStats(guiItem, p.ws.guiStats, "Gui")
+		guiItem.SetExpanded(true)
+	}
+
+	if extensions := editor.extensions.all(); len(extensions) > 0 {
+		group := widgets.NewQTreeWidgetItem4(p.tree, []string{"Extensions"}, 0)
+		group.SetExpanded(true)
+		for _, ext := range extensions {
+			item := widgets.NewQTreeWidgetItem7(group, []string{ext.name, "GUI extension"}, 0)
+			addStats(item, ext.stats, ext.name)
+		}
+	}
+
+	if top != nil && top.rate > 0 {
+		p.title.SetText(fmt.Sprintf("Most events: %s %s, %.1f/s", topSource, top.name, top.rate))
+	} else {
+		p.title.SetText("No events in the last second")
+	}
+	p.tree.ResizeColumnToContents(0)
+	p.tree.VerticalScrollBar().SetValue(scroll)
+}
+
+// rpcClientKind describes the type of nvim_set_client_info()
+func rpcClientKind(typ string) string {
+	switch typ {
+	case "host":
+		return "remote plugin host"
+	case "remote":
+		return "remote client"
+	case "ui":
+		return "UI"
+	case "embedder":
+		return "embedder"
+	case "plugin":
+		return "plugin"
+	case "":
+		return "unknown client"
+	}
+	return typ
+}
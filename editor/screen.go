@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/akiyosi/goneovim/grid"
 	"github.com/akiyosi/goneovim/util"
 	"github.com/bluele/gcache"
 	"github.com/neovim/go-client/nvim"
@@ -38,6 +39,10 @@ type Highlight struct {
 	strikethrough bool
 }
 
+// undefinedHighlight is the highlight of the cells of the ids which have not
+// been defined by hl_attr_define
+var undefinedHighlight = &Highlight{}
+
 type HlChars struct {
 	text string
 	fg   *RGBA
@@ -58,7 +63,8 @@ type HlChars struct {
 type Cell struct {
 	normalWidth bool
 	char        string
-	highlight   Highlight
+	// The highlight of hl_attr_define shared by the cells of the same id
+	highlight *Highlight
 }
 
 // Window is
@@ -91,6 +97,12 @@ type Window struct {
 	scrollRegion     []int
 	devicePixelRatio float64
 	textCache        gcache.Cache
+	indentGuides     *IndentGuides
+	folds            []int
+	wraps            *WrapInfo
+	delimiters       *Delimiters
+	snippet          *SnippetPlaceholders
+	extraCursors     []*ExtraCursor
 	// glyphMap         map[HlChar]gui.QImage
 
 	font         *Font
@@ -123,6 +135,8 @@ type Screen struct {
 	scrollRegion     []int
 	scrollDust       [2]int
 	scrollDustDeltaY int
+	wheelAngle       [2]int
+	zoomAngle        int
 
 	highAttrDef    map[int]*Highlight
 	highlightGroup map[string]int
@@ -132,6 +146,19 @@ type Screen struct {
 	textCache       gcache.Cache
 
 	resizeCount uint
+
+	// The position of the cursor for the cursorline overlay
+	cursorLine CursorLine
+
+	// The press on the fold column is not sent to nvim, nor its release
+	foldClicked bool
+	// The right click on a spell error shows the suggestions instead
+	spellClicked bool
+
+	// The fonts of gonvim_window_font for the windows not drawn yet
+	pendingFonts map[nvim.Window]string
+
+	separatorDrag *SeparatorDrag
 }
 
 func newScreen() *Screen {
@@ -227,8 +254,7 @@ func (s *Screen) dropEvent(e *gui.QDropEvent) {
 }
 
 func fileOpenInBuf(file string) {
-	isModified, _ := editor.workspaces[editor.active].nvim.CommandOutput("echo &modified")
-	if isModified == "1" {
+	if editor.workspaces[editor.active].modified {
 		editor.workspaces[editor.active].nvim.Command(fmt.Sprintf(":tabnew %s", file))
 	} else {
 		editor.workspaces[editor.active].nvim.Command(fmt.Sprintf(":e %s", file))
@@ -384,8 +410,31 @@ func (s *Screen) gridFont(update interface{}) {
 		return
 	}
 
-	args := update.(string)
+	args, _ := update.(string)
+	if args == "" {
+		return
+	}
+	s.setWindowFont(win, args)
+}
+
+// setWindowFont sets the font of the window, "" for the font of the
+// workspace, and resizes only its grid to keep the size of the window
+func (s *Screen) setWindowFont(win *Window, args string) {
 	if args == "" {
+		if win.font == nil {
+			return
+		}
+		oldWidth := float64(win.cols) * win.font.truewidth
+		oldHeight := win.rows * win.font.lineHeight
+		win.font = nil
+		win.width = 0
+		win.height = 0
+		win.localWindows = &[4]localWindow{}
+		font := win.getFont()
+		_ = s.ws.nvim.TryResizeUIGrid(win.grid, int(oldWidth/font.truewidth), oldHeight/font.lineHeight)
+		if win.grid == s.ws.cursor.gridid {
+			s.ws.cursor.updateFont(font)
+		}
 		return
 	}
 	parts := strings.Split(args, ":")
@@ -441,9 +490,11 @@ func (s *Screen) gridFont(update interface{}) {
 		win.textCache.Purge()
 	}
 
-	_ = s.ws.nvim.TryResizeUIGrid(s.ws.cursor.gridid, newCols, newRows)
-	font := win.getFont()
-	s.ws.cursor.updateFont(font)
+	_ = s.ws.nvim.TryResizeUIGrid(win.grid, newCols, newRows)
+	if win.grid == s.ws.cursor.gridid {
+		font := win.getFont()
+		s.ws.cursor.updateFont(font)
+	}
 }
 
 func (s *Screen) purgeTextCacheForWins() {
@@ -547,6 +598,16 @@ func (s *Screen) toolTip(text string) {
 }
 
 func (w *Window) paint(event *gui.QPaintEvent) {
+	if !editor.doneFirstPaint && w.s.name != "minimap" {
+		editor.firstPaint()
+	}
+	if w.s.name == "minimap" {
+		defer editor.perf.measure("minimap", time.Now())
+	} else {
+		defer editor.latency.painted()
+		defer editor.perf.measure("screen", time.Now())
+		editor.perf.countFrame()
+	}
 	w.paintMutex.Lock()
 
 	p := gui.NewQPainter2(w.widget)
@@ -554,7 +615,7 @@ func (w *Window) paint(event *gui.QPaintEvent) {
 
 	// Set devicePixelRatio if it is not set
 	if w.devicePixelRatio == 0 {
-		w.devicePixelRatio = float64(p.PaintEngine().PaintDevice().DevicePixelRatio())
+		w.devicePixelRatio = p.PaintEngine().PaintDevice().DevicePixelRatioF()
 	}
 
 	// Draw text with DrawText if screen name is "minimap" or CachedDrawing is false
@@ -573,8 +634,26 @@ func (w *Window) paint(event *gui.QPaintEvent) {
 			continue
 		}
 		w.fillBackground(p, y, col, cols)
+		if w.s.name != "minimap" && (editor.config.Editor.CursorLineOverlay || editor.config.Editor.CursorColumnOverlay) {
+			w.drawCursorLine(p, y)
+		}
 		w.drawContents(p, y, col, cols)
 		w.drawTextDecoration(p, y, col, cols)
+		if w.s.name != "minimap" {
+			if len(editor.config.VirtualText) > 0 {
+				w.drawVirtualText(p, y)
+			}
+			w.drawDebugSigns(p, y, col, cols)
+			if editor.config.Editor.FoldColumn {
+				w.drawFoldColumn(p, y, col, cols)
+			}
+			if editor.config.Editor.WrapIndicators {
+				w.drawWrapIndicator(p, y)
+			}
+			if editor.config.Editor.RainbowDelimiters || editor.config.Editor.PairGuides {
+				w.drawDelimiters(p, y)
+			}
+		}
 	}
 
 	// If Window is Message Area, draw separator
@@ -587,9 +666,20 @@ func (w *Window) paint(event *gui.QPaintEvent) {
 
 	// Draw indent guide
 	if editor.config.Editor.IndentGuide {
-		w.drawIndentguide(p, row, rows)
+		w.drawIndentGuides(p, row, rows)
 	}
 
+	// Draw the guide between the brackets around the cursor
+	if editor.config.Editor.PairGuides {
+		w.drawPairGuide(p, row, rows)
+	}
+
+	// Draw the placeholders of the active snippet
+	w.drawSnippet(p)
+
+	// Draw the cursors of the plugins of multiple cursors
+	w.drawExtraCursors(p)
+
 	// Update markdown preview
 	if w.grid != 1 {
 		w.s.ws.markdown.updatePos()
@@ -607,164 +697,6 @@ func (w *Window) getFont() *Font {
 	}
 }
 
-func (w *Window) drawIndentguide(p *gui.QPainter, row, rows int) {
-	if w == nil {
-		return
-	}
-	if w.isMsgGrid {
-		return
-	}
-	if w.isFloatWin {
-		return
-	}
-	if w.s.ws.ts == 0 {
-		return
-	}
-	if !w.isShown() {
-		return
-	}
-	for y := row; y < rows; y++ {
-		if y+1 >= len(w.content) {
-			return
-		}
-		nextline := w.content[y+1]
-		line := w.content[y]
-		res := 0
-		skipDraw := false
-		for x := 0; x < w.lenLine[y]; x++ {
-			skipDraw = false
-
-			if x+1 >= len(nextline) {
-				break
-			}
-			nlnc := nextline[x+1]
-			if nlnc == nil {
-				continue
-			}
-			nlc := nextline[x]
-			if nlc == nil {
-				continue
-			}
-			nc := line[x+1]
-			if nc == nil {
-				continue
-			}
-			c := line[x]
-			if c == nil {
-				continue
-			}
-			if c.isSignColumn() {
-				res++
-			}
-			if c.char != " " && !c.isSignColumn() {
-				break
-			}
-			if x > res &&
-				(x+1-res)%w.s.ws.ts == 0 &&
-				c.char == " " && nc.char != " " &&
-				nlc.char == " " && nlnc.char == " " {
-
-				if w.lenLine[y] >= len(line) {
-					break
-				}
-				bra := line[w.lenLine[y]-1].char
-				cket := getCket(bra)
-
-				for row := y; row < len(w.content); row++ {
-					if row+1 == len(w.content) {
-						break
-					}
-
-					for z := y + 1; z < len(w.content); z++ {
-						if w.content[z][x+1] == nil {
-							break
-						}
-						if w.content[z][x+1].char != " " {
-							if w.content[z][x+1].char != cket {
-								break
-							}
-
-							for v := x; v >= res; v-- {
-								if w.content[z][v] == nil {
-									break
-								}
-								if w.content[z][v].char == " " {
-									skipDraw = true
-								} else {
-									skipDraw = false
-									break
-								}
-							}
-							if skipDraw {
-								break
-							}
-						}
-					}
-					if !skipDraw {
-						break
-					}
-
-					ylen, _ := w.countHeadSpaceOfLine(y)
-					ynlen, _ := w.countHeadSpaceOfLine(y + 1)
-					if ynlen <= ylen {
-						break
-					}
-					if w.content[row+1][x+1] == nil {
-						break
-					}
-					if w.content[row+1][x+1].char != " " {
-						break
-					}
-					w.drawIndentline(p, x+1, row+1)
-				}
-				break
-			}
-		}
-	}
-}
-
-func getCket(bra string) string {
-	cket := " "
-
-	switch bra {
-	case "{":
-		cket = "}"
-	case "[":
-		cket = "]"
-	case "(":
-		cket = ")"
-	case "<":
-		cket = ">"
-	case `"`:
-		cket = `"`
-	case `'`:
-		cket = `'`
-	case "`":
-		cket = "`"
-	}
-
-	return cket
-}
-
-func (w *Window) drawIndentline(p *gui.QPainter, x int, y int) {
-	font := w.getFont()
-	X := float64(x) * font.truewidth
-	Y := float64(y * font.lineHeight)
-	p.FillRect4(
-		core.NewQRectF4(
-			X,
-			Y,
-			1,
-			float64(font.lineHeight),
-		),
-		editor.colors.indentGuide.QColor(),
-	)
-
-	if w.lenContent[y] < x {
-		w.lenContent[y] = x
-	}
-}
-
 func (w *Window) drawMsgSeparator(p *gui.QPainter) {
 	highNo, ok := w.s.highlightGroup["MsgSeparator"]
 	if !ok {
@@ -934,23 +866,44 @@ func (s *Screen) bottomWindowPos() int {
 }
 
 func (s *Screen) wheelEvent(event *gui.QWheelEvent) {
+	if s.zoomWheel(event) {
+		return
+	}
 	var v, h, vert, horiz int
 	var vertKey string
 	var horizKey string
 	var accel int
 	font := s.font
+	input := editor.config.Input
+
+	pixels := event.PixelDelta()
+	smooth := false
+	switch input.WheelMode {
+	case "smooth":
+		smooth = true
+	case "step":
+		smooth = false
+	default:
+		// The trackpads report the pixels to scroll, the mouse wheels only the angle
+		smooth = pixels != nil && !pixels.IsNull()
+		if runtime.GOOS == "darwin" {
+			smooth = true
+		}
+	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		pixels := event.PixelDelta()
-		if pixels != nil {
+	if smooth {
+		if pixels != nil && !pixels.IsNull() {
 			v = pixels.Y()
 			h = pixels.X()
+		} else {
+			// A step of a mouse wheel scrolls wheelScrollLines in the pixels
+			v = event.AngleDelta().Y() * font.lineHeight * input.WheelScrollLines / 120
+			h = int(float64(event.AngleDelta().X()*input.WheelScrollLines) * font.truewidth / 120)
 		}
-		if pixels.X() < 0 && s.scrollDust[0] > 0 {
+		if h < 0 && s.scrollDust[0] > 0 {
 			s.scrollDust[0] = 0
 		}
-		if pixels.Y() < 0 && s.scrollDust[1] > 0 {
+		if v < 0 && s.scrollDust[1] > 0 {
 			s.scrollDust[1] = 0
 		}
 
@@ -984,11 +937,23 @@ func (s *Screen) wheelEvent(event *gui.QWheelEvent) {
 		if accel == 0 {
 			accel = 1
 		}
+	} else {
+		// A step of a wheel is 120, the high resolution wheels send a part of it
+		s.wheelAngle[0] += event.AngleDelta().X()
+		s.wheelAngle[1] += event.AngleDelta().Y()
+		horiz = s.wheelAngle[0] / 120
+		vert = s.wheelAngle[1] / 120
+		s.wheelAngle[0] -= horiz * 120
+		s.wheelAngle[1] -= vert * 120
+		accel = int(math.Abs(float64(vert))) * input.WheelScrollLines
+	}
 
-	default:
-		vert = event.AngleDelta().Y()
-		horiz = event.AngleDelta().X()
-		accel = 2
+	if input.InvertScroll {
+		vert = -vert
+		horiz = -horiz
+	}
+	if !input.HorizontalScroll {
+		horiz = 0
 	}
 
 	if vert > 0 {
@@ -1095,6 +1060,15 @@ func (s *Screen) focusWindow(event *gui.QWheelEvent) {
 }
 
 func (s *Screen) mouseEvent(event *gui.QMouseEvent) {
+	if s.separatorMouseEvent(event) {
+		return
+	}
+	if s.foldMouseEvent(event) {
+		return
+	}
+	if editor.config.Editor.SpellSuggestions && s.spellMouseEvent(event) {
+		return
+	}
 	inp := s.convertMouse(event)
 	if inp == "" {
 		return
@@ -1230,7 +1204,11 @@ func (s *Screen) resizeWindow(gridid gridId, cols int, rows int) {
 
 		// first cursor pos at startup app
 		if gridid == 1 && s.name != "minimap" {
-			s.ws.cursor.widget.SetParent(win.widget)
+			if editor.config.Editor.CursorLayer {
+				s.ws.cursor.setParentWindow(win)
+			} else {
+				s.ws.cursor.widget.SetParent(win.widget)
+			}
 		}
 	}
 	winOldCols := win.cols
@@ -1433,6 +1411,10 @@ func (s *Screen) gridCursorGoto(args []interface{}) {
 			continue
 		}
 
+		if editor.config.Editor.CursorLineOverlay || editor.config.Editor.CursorColumnOverlay {
+			s.updateCursorLine(gridid, s.cursor[0], s.cursor[1])
+		}
+
 		if s.ws.cursor.gridid != gridid {
 			s.ws.cursor.gridid = gridid
 			s.ws.cursor.font = win.getFont()
@@ -1641,13 +1623,16 @@ func (s *Screen) gridClear(args []interface{}) {
 }
 
 func (s *Screen) gridLine(args []interface{}) {
-	for _, arg := range args {
-		gridid := util.ReflectToInt(arg.([]interface{})[0])
-		if isSkipGlobalId(gridid) {
+	s.applyGridLines(grid.DecodeLines(args))
+}
+
+func (s *Screen) applyGridLines(lines []*grid.Line) {
+	for _, line := range lines {
+		if isSkipGlobalId(line.Grid) {
 			continue
 		}
 
-		s.updateGridContent(arg.([]interface{}))
+		s.updateGridContent(line)
 		// win, ok := s.windows[gridid]
 		// if !ok {
 		// 	continue
@@ -1655,7 +1640,7 @@ func (s *Screen) gridLine(args []interface{}) {
 		// if win == nil {
 		// 	continue
 		// }
-		win, ok := s.getWindow(gridid)
+		win, ok := s.getWindow(line.Grid)
 		if !ok {
 			continue
 		}
@@ -1665,10 +1650,10 @@ func (s *Screen) gridLine(args []interface{}) {
 	}
 }
 
-func (s *Screen) updateGridContent(arg []interface{}) {
-	gridid := util.ReflectToInt(arg[0])
-	row := util.ReflectToInt(arg[1])
-	colStart := util.ReflectToInt(arg[2])
+func (s *Screen) updateGridContent(gridLine *grid.Line) {
+	gridid := gridLine.Grid
+	row := gridLine.Row
+	colStart := gridLine.Col
 
 	if isSkipGlobalId(gridid) {
 		return
@@ -1691,61 +1676,29 @@ func (s *Screen) updateGridContent(arg []interface{}) {
 	}
 	col := colStart
 	line := content[row]
-	cells := arg[3].([]interface{})
 
-	for _, arg := range cells {
+	// The new cells of the row are allocated at once
+	var cells []Cell
+	for _, cell := range gridLine.Cells {
 		if col >= len(line) {
-			continue
-		}
-		cell := arg.([]interface{})
-
-		var hi, repeat int
-		hi = -1
-		text := cell[0]
-		if len(cell) >= 2 {
-			hi = util.ReflectToInt(cell[1])
+			break
 		}
-
-		if len(cell) == 3 {
-			repeat = util.ReflectToInt(cell[2])
+		if line[col] == nil {
+			if cells == nil {
+				cells = make([]Cell, len(line))
+			}
+			line[col] = &cells[col]
 		}
 
-		// If `repeat` is present, the cell should be
-		// repeated `repeat` times (including the first time), otherwise just
-		// once.
-		r := 1
-		if repeat == 0 {
-			repeat = 1
+		line[col].char = cell.Text
+		line[col].normalWidth = win.isNormalWidth(line[col].char)
+		if hl, ok := s.highAttrDef[cell.HlID]; ok && hl != nil {
+			line[col].highlight = hl
+		} else if line[col].highlight == nil {
+			line[col].highlight = undefinedHighlight
 		}
-		for r <= repeat {
-			if col >= len(line) {
-				break
-			}
 
-			if line[col] == nil {
-				line[col] = &Cell{}
-			}
-
-			line[col].char = text.(string)
-			line[col].normalWidth = win.isNormalWidth(line[col].char)
-
-			// If `hl_id` is not present the most recently seen `hl_id` in
-			//	the same call should be used (it is always sent for the first
-			//	cell in the event).
-			switch col {
-			case 0:
-				line[col].highlight = *s.highAttrDef[hi]
-			default:
-				if hi == -1 {
-					line[col].highlight = line[col-1].highlight
-				} else {
-					line[col].highlight = *s.highAttrDef[hi]
-				}
-			}
-
-			col++
-			r++
-		}
+		col++
 	}
 
 	lenLine := win.cols-1
@@ -2123,7 +2076,7 @@ func (w *Window) fillBackground(p *gui.QPainter, y int, col int, cols int) {
 			if line[x] == nil {
 				highlight = w.s.highAttrDef[0]
 			} else {
-				highlight = &line[x].highlight
+				highlight = line[x].highlight
 			}
 		} else {
 			highlight = w.s.highAttrDef[0]
@@ -2165,7 +2118,7 @@ func (w *Window) drawTextWithCache(p *gui.QPainter, y int, col int, cols int) {
 	wsfont := w.getFont()
 	// font := p.Font()
 	line := w.content[y]
-	chars := map[Highlight][]int{}
+	chars := map[*Highlight][]int{}
 	specialChars := []int{}
 	textCache := w.getCache()
 	var image *gui.QImage
@@ -2267,7 +2220,7 @@ func (w *Window) drawTextWithCache(p *gui.QPainter, y int, col int, cols int) {
 	}
 }
 
-func (w *Window) newTextCache(text string, highlight Highlight, isNormalWidth bool) *gui.QImage {
+func (w *Window) newTextCache(text string, highlight *Highlight, isNormalWidth bool) *gui.QImage {
 	// * Ref: https://stackoverflow.com/questions/40458515/a-best-way-to-draw-a-lot-of-independent-characters-in-qt5/40476430#40476430
 
 	font := w.getFont()
@@ -2507,7 +2460,7 @@ func (w *Window) drawText(p *gui.QPainter, y int, col int, cols int) {
 	wsfont := w.getFont()
 	font := p.Font()
 	line := w.content[y]
-	chars := map[Highlight][]int{}
+	chars := map[*Highlight][]int{}
 	specialChars := []int{}
 
 	for x := col; x <= col+cols; x++ {
@@ -2611,7 +2564,8 @@ func (w *Window) drawTextDecoration(p *gui.QPainter, y int, col int, cols int) {
 		if line[x] == nil {
 			continue
 		}
-		if !line[x].highlight.underline && !line[x].highlight.undercurl && !line[x].highlight.strikethrough {
+		spell := editor.config.Editor.SpellDots && isSpellCell(line[x])
+		if !line[x].highlight.underline && !line[x].highlight.undercurl && !line[x].highlight.strikethrough && !spell {
 			continue
 		}
 		pen := gui.NewQPen()
@@ -2625,6 +2579,10 @@ func (w *Window) drawTextDecoration(p *gui.QPainter, y int, col int, cols int) {
 			color = fg.QColor()
 			pen.SetColor(color)
 		}
+		if spell {
+			w.drawSpellDots(p, y, x, color)
+			continue
+		}
 		p.SetPen(pen)
 		start := float64(x) * font.truewidth
 		end := float64(x+1) * font.truewidth
@@ -2748,6 +2706,7 @@ func (s *Screen) windowPosition(args []interface{}) {
 		win.move(col, row)
 		// win.hideOverlappingWindows()
 		win.show()
+		s.applyPendingFont(win)
 	}
 }
 
@@ -2806,6 +2765,7 @@ func (s *Screen) gridDestroy(args []interface{}) {
 			continue
 		}
 		win.isGridDirty = true
+		s.ws.peek.hideFor(win)
 	}
 }
 
@@ -2857,6 +2817,7 @@ func (s *Screen) windowFloatPosition(args []interface{}) {
 		win.move(x, y)
 		win.setShadow()
 		win.show()
+		s.ws.peek.place(win)
 	}
 }
 
@@ -2871,6 +2832,7 @@ func (s *Screen) windowHide(args []interface{}) {
 			continue
 		}
 		win.hide()
+		s.ws.peek.hideFor(win)
 	}
 }
 
@@ -3005,9 +2967,7 @@ func (w *Window) raise() {
 
 	font := w.getFont()
 	w.s.ws.cursor.updateFont(font)
-	w.s.ws.cursor.widget.SetParent(w.widget)
-	w.s.ws.cursor.widget.Hide()
-	w.s.ws.cursor.widget.Show()
+	w.s.ws.cursor.setParentWindow(w)
 
 }
 
@@ -3126,6 +3086,9 @@ func (w *Window) move(col int, row int) {
 		if w.s.ws.drawTabline {
 			y += 6 + w.s.ws.tabline.widget.Height()
 		}
+		if w.s.ws.breadcrumbs.widget.IsVisible() {
+			y += w.s.ws.breadcrumbs.widget.Height()
+		}
 	}
 	w.widget.Move2(x, y)
 
@@ -0,0 +1,164 @@
+package editor
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+const recordingFps = 10
+
+// Recording is a screen recording in progress, the frames are saved as PNG files until it ends
+type Recording struct {
+	dir    string
+	path   string
+	frames []string
+	timer  *core.QTimer
+}
+
+func defaultCapturePath(ext string) string {
+	dir := core.QStandardPaths_WritableLocation(core.QStandardPaths__PicturesLocation)
+	if dir == "" {
+		dir = editor.homeDir
+	}
+	return filepath.Join(dir, "goneovim-"+time.Now().Format("20060102-150405")+ext)
+}
+
+// grabScreen captures the whole window, or only the grid of the current workspace
+func (w *Workspace) grabScreen(gridOnly bool) *gui.QPixmap {
+	rect := core.NewQRect4(0, 0, -1, -1)
+	if gridOnly {
+		return w.screen.widget.Grab(rect)
+	}
+	return editor.window.Grab(rect)
+}
+
+// screenshot handles :GonvimScreenshot[!] [path], the bang captures only the grid
+func (w *Workspace) screenshot(args []interface{}) {
+	gridOnly := len(args) > 0 && util.IsTrue(args[0])
+	path := ""
+	if len(args) > 1 {
+		path, _ = args[1].(string)
+	}
+	if path == "" {
+		path = defaultCapturePath(".png")
+	}
+	if !w.grabScreen(gridOnly).Save(path, "PNG", -1) {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to save the screenshot to "+path)
+		return
+	}
+	editor.pushNotification(NotifyInfo, -1, "[Gonvim] Saved the screenshot to "+path)
+}
+
+// record handles :GonvimRecord[!] {seconds} [path], recording the window into an
+// animated GIF, or WebM with ffmpeg if the path ends with ".webm"
+func (w *Workspace) record(args []interface{}) {
+	if w.recording != nil {
+		return
+	}
+	gridOnly := len(args) > 0 && util.IsTrue(args[0])
+	seconds := 5
+	if len(args) > 1 {
+		// A string by <f-args>, or a number by rpcnotify
+		if s, err := strconv.Atoi(fmt.Sprint(args[1])); err == nil && s > 0 {
+			seconds = s
+		}
+	}
+	path := ""
+	if len(args) > 2 {
+		path, _ = args[2].(string)
+	}
+	if path == "" {
+		path = defaultCapturePath(".gif")
+	}
+	dir, err := ioutil.TempDir("", "goneovim-record")
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+
+	r := &Recording{
+		dir:   dir,
+		path:  path,
+		timer: core.NewQTimer(nil),
+	}
+	w.recording = r
+	limit := seconds * recordingFps
+	r.timer.ConnectTimeout(func() {
+		frame := filepath.Join(r.dir, fmt.Sprintf("%05d.png", len(r.frames)))
+		if w.grabScreen(gridOnly).Save(frame, "PNG", -1) {
+			r.frames = append(r.frames, frame)
+		}
+		if len(r.frames) < limit {
+			return
+		}
+		r.timer.Stop()
+		w.recording = nil
+		go r.encode()
+	})
+	r.timer.Start(1000 / recordingFps)
+	editor.pushNotification(NotifyInfo, seconds, fmt.Sprintf("[Gonvim] Recording for %d seconds", seconds))
+}
+
+func (r *Recording) encode() {
+	defer os.RemoveAll(r.dir)
+	var err error
+	if strings.HasSuffix(strings.ToLower(r.path), ".webm") {
+		cmd := exec.Command(
+			"ffmpeg", "-y",
+			"-framerate", fmt.Sprintf("%d", recordingFps),
+			"-i", filepath.Join(r.dir, "%05d.png"),
+			"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p",
+			r.path,
+		)
+		util.PrepareRunProc(cmd)
+		err = cmd.Run()
+	} else {
+		err = r.encodeGif()
+	}
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to save the recording: "+err.Error())
+		return
+	}
+	editor.pushNotification(NotifyInfo, -1, "[Gonvim] Saved the recording to "+r.path)
+}
+
+func (r *Recording) encodeGif() error {
+	anim := &gif.GIF{}
+	for _, frame := range r.frames {
+		file, err := os.Open(frame)
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.ZP)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, 100/recordingFps)
+	}
+	out, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return gif.EncodeAll(out, anim)
+}
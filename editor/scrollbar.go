@@ -13,6 +13,8 @@ type ScrollBar struct {
 	thumb  *widgets.QWidget
 	pos    int
 	height int
+
+	maxLineRequested bool
 }
 
 func newScrollBar() *ScrollBar {
@@ -47,30 +49,27 @@ func (s *ScrollBar) update() {
 		bot = s.ws.rows - 1
 	}
 	relativeCursorY := int(float64(s.ws.cursor.y) / float64(s.ws.font.lineHeight))
-	if s.ws.maxLine == 0 {
-		//s.ws.nvim.Eval("line('$')", &s.ws.maxLine)
-		lnITF, err := s.ws.nvimEval("line('$')")
-		if err != nil {
-			s.ws.maxLine = 0
-		} else {
-			switch lnITF := lnITF.(type) {
-			case uint64:
-			case uint:
-			case int64:
-				s.ws.maxLine = int(lnITF)
-			case int:
-				s.ws.maxLine = lnITF
-			}
+	if s.ws.maxLine == 0 && !s.maxLineRequested && s.ws.nvim != nil {
+		// Asked once without waiting for it, then the autocmd of the scrollbar keeps it
+		s.maxLineRequested = true
+		go s.ws.nvim.Command(`call rpcnotify(0, "Gui", "gonvim_get_maxline", line("$"))`)
+	}
+	total := s.ws.maxLine
+	shown := bot - top
+	first := s.ws.curLine - relativeCursorY
+	// Count a wrapped line once
+	if win, ok := s.ws.screen.getWindow(s.ws.cursor.gridid); ok {
+		if t, c, n, ok := win.shownLines(); ok {
+			first, shown, total = t, c, n
 		}
-
 	}
-	if s.ws.maxLine > bot-top {
-		s.height = int(float64(bot-top) / float64(s.ws.maxLine) * float64(s.ws.screen.widget.Height()))
+	if total > shown {
+		s.height = int(float64(shown) / float64(total) * float64(s.ws.screen.widget.Height()))
 		if s.height < 20 {
 			s.height = 20
 		}
 		s.thumb.SetFixedHeight(s.height)
-		s.pos = int(float64(s.ws.curLine-relativeCursorY) / float64(s.ws.maxLine) * float64(s.ws.screen.widget.Height()))
+		s.pos = int(float64(first) / float64(total) * float64(s.ws.screen.widget.Height()))
 		s.thumb.Move2(0, s.pos)
 		s.widget.Show()
 	} else {
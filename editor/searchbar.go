@@ -0,0 +1,122 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// SearchBar is the bar above the statusline showing the last search pattern
+// and the number of its matches while 'hlsearch' highlights them, with the
+// buttons of n and N
+type SearchBar struct {
+	ws      *Workspace
+	widget  *widgets.QWidget
+	pattern *widgets.QLabel
+	count   *widgets.QLabel
+	prev    *widgets.QPushButton
+	next    *widgets.QPushButton
+}
+
+func newSearchBar(ws *Workspace) *SearchBar {
+	b := &SearchBar{
+		ws:      ws,
+		widget:  widgets.NewQWidget(nil, 0),
+		pattern: widgets.NewQLabel(nil, 0),
+		count:   widgets.NewQLabel(nil, 0),
+		prev:    widgets.NewQPushButton2("↑", nil),
+		next:    widgets.NewQPushButton2("↓", nil),
+	}
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(8, 1, 8, 1)
+	layout.SetSpacing(6)
+	b.widget.SetLayout(layout)
+
+	b.pattern.SetObjectName("pattern")
+	b.pattern.SetTextFormat(core.Qt__PlainText)
+	layout.AddWidget(b.pattern, 0, 0)
+	layout.AddWidget(b.count, 0, 0)
+	layout.AddStretch(1)
+	for _, button := range []*widgets.QPushButton{b.prev, b.next} {
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		layout.AddWidget(button, 0, 0)
+	}
+	b.prev.SetToolTip("Previous match (N)")
+	b.prev.ConnectClicked(func(bool) {
+		b.jump("N")
+	})
+	b.next.SetToolTip("Next match (n)")
+	b.next.ConnectClicked(func(bool) {
+		b.jump("n")
+	})
+	b.widget.Hide()
+
+	return b
+}
+
+func (b *SearchBar) setColor() {
+	fg := editor.colors.inactiveFg
+	bg := editor.colors.bg
+	if fg == nil || bg == nil {
+		return
+	}
+	font := b.ws.font
+	b.widget.SetStyleSheet(fmt.Sprintf(`
+	QWidget { color: %s; background-color: %s; font-family: %s; font-size: %dpt; }
+	QLabel#pattern { color: %s; }
+	QPushButton { border: 0px; padding: 0px 6px; }
+	QPushButton:hover { color: %s; background-color: %s; }
+	`, fg.String(), warpColor(bg, -5).String(), font.fontNew.Family(), int(font.fontNew.PointSizeF()*0.9),
+		editor.colors.fg.String(), editor.colors.fg.String(), editor.colors.selectedBg.String()))
+}
+
+// handle handles gonvim_search; the pattern ("": hidden), the index of the
+// match at the cursor, the number of the matches, and whether the count is incomplete
+func (b *SearchBar) handle(args []interface{}) {
+	if len(args) < 4 {
+		return
+	}
+	pattern, _ := args[0].(string)
+	if pattern == "" {
+		b.setVisible(false)
+		return
+	}
+	current := util.ReflectToInt(args[1])
+	total := util.ReflectToInt(args[2])
+	incomplete := util.ReflectToInt(args[3])
+
+	b.pattern.SetText("/" + pattern)
+	switch {
+	case total == 0:
+		b.count.SetText("No matches")
+	case incomplete == 1:
+		// Timed out
+		b.count.SetText(fmt.Sprintf("%d / ?", current))
+	case incomplete == 2:
+		// More than maxcount
+		b.count.SetText(fmt.Sprintf("%d / >%d", current, total))
+	default:
+		b.count.SetText(fmt.Sprintf("%d / %d", current, total))
+	}
+	b.prev.SetEnabled(total > 0)
+	b.next.SetEnabled(total > 0)
+	b.setVisible(true)
+}
+
+func (b *SearchBar) setVisible(visible bool) {
+	if b.widget.IsVisible() == visible {
+		return
+	}
+	b.widget.SetVisible(visible)
+	b.ws.updateSize()
+}
+
+func (b *SearchBar) jump(key string) {
+	go func() {
+		b.ws.nvim.Command("silent! normal! " + key)
+		b.ws.execLua("require('gonvim').search()")
+	}()
+	editor.wsWidget.SetFocus2()
+}
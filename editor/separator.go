@@ -0,0 +1,116 @@
+package editor
+
+import (
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// SeparatorDrag is the window whose separator is being dragged
+type SeparatorDrag struct {
+	win      *Window
+	vertical bool
+	size     int
+}
+
+// separatorAt returns the window whose right separator (vertical) or status
+// line (horizontal) is at the cell of the grid 1, if the split can be resized
+func (s *Screen) separatorAt(col, row int) (*Window, bool) {
+	var wins []*Window
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if ok && win != nil && win.grid != 1 && !win.isMsgGrid && !win.isFloatWin && win.isShown() {
+			wins = append(wins, win)
+		}
+		return true
+	})
+
+	for _, win := range wins {
+		if col == win.pos[0]+win.cols && row >= win.pos[1] && row <= win.pos[1]+win.rows {
+			return win, true
+		}
+	}
+	for _, win := range wins {
+		if row != win.pos[1]+win.rows || col < win.pos[0] || col >= win.pos[0]+win.cols {
+			continue
+		}
+		// The status line of the bottom windows resizes the command line, not a split
+		for _, below := range wins {
+			if below.pos[1] == row+1 && below.pos[0] < win.pos[0]+win.cols && below.pos[0]+below.cols > win.pos[0] {
+				return win, false
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// separatorMouseEvent resizes the splits by dragging their separators, and
+// changes the mouse cursor on hover. It reports whether the event is consumed.
+func (s *Screen) separatorMouseEvent(event *gui.QMouseEvent) bool {
+	if !editor.config.Editor.SeparatorDrag {
+		return false
+	}
+	font := s.font
+	col := int(float64(event.X()) / font.truewidth)
+	row := event.Y() / font.lineHeight
+
+	switch event.Type() {
+	case core.QEvent__MouseMove:
+		if s.separatorDrag != nil {
+			s.dragSeparator(col, row)
+			return true
+		}
+		if event.Buttons() != core.Qt__NoButton {
+			return false
+		}
+		win, vertical := s.separatorAt(col, row)
+		switch {
+		case win == nil:
+			s.widget.UnsetCursor()
+		case vertical:
+			s.widget.SetCursor(gui.NewQCursor2(core.Qt__SplitHCursor))
+		default:
+			s.widget.SetCursor(gui.NewQCursor2(core.Qt__SplitVCursor))
+		}
+	case core.QEvent__MouseButtonPress:
+		if event.Button() != core.Qt__LeftButton {
+			return false
+		}
+		win, vertical := s.separatorAt(col, row)
+		if win == nil {
+			return false
+		}
+		s.separatorDrag = &SeparatorDrag{
+			win:      win,
+			vertical: vertical,
+		}
+		return true
+	case core.QEvent__MouseButtonRelease:
+		if s.separatorDrag != nil {
+			s.separatorDrag = nil
+			s.widget.UnsetCursor()
+			return true
+		}
+	}
+
+	return false
+}
+
+// dragSeparator resizes the window so that its separator follows the mouse
+func (s *Screen) dragSeparator(col, row int) {
+	d := s.separatorDrag
+	size := row - d.win.pos[1]
+	if d.vertical {
+		size = col - d.win.pos[0]
+	}
+	if size < 1 || size == d.size {
+		return
+	}
+	d.size = size
+	win := d.win.id
+	if d.vertical {
+		go s.ws.nvim.SetWindowWidth(win, size)
+	} else {
+		go s.ws.nvim.SetWindowHeight(win, size)
+	}
+}
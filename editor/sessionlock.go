@@ -0,0 +1,127 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func sessionLockPath(dir string) string {
+	return filepath.Join(dir, "lock")
+}
+
+// lockSession locks the session directory of this window, so that another
+// goneovim does not remove or overwrite its sessions on quit. If a running
+// goneovim holds the lock, the sessions are restored but not saved.
+func (e *Editor) lockSession() {
+	if e.opts.Clean {
+		return
+	}
+	e.sessionReadOnly = !acquireSessionLock(e.sessionDir())
+}
+
+// acquireSessionLock writes the pid of this process to the lock file of the
+// directory, unless the lock is held by another running process
+func acquireSessionLock(dir string) bool {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false
+	}
+	path := sessionLockPath(dir)
+	// The second try is after the lock of an exited process is removed
+	for i := 0; i < 2; i++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d", os.Getpid())
+			file.Close()
+			return true
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		if pid == os.Getpid() {
+			return true
+		}
+		if pid > 0 && isProcessAlive(pid) {
+			return false
+		}
+		os.Remove(path)
+	}
+	return false
+}
+
+// releaseSessionLock removes the lock file if it is held by this process
+func (e *Editor) releaseSessionLock() {
+	if e.sessionReadOnly {
+		return
+	}
+	path := sessionLockPath(e.sessionDir())
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if pid, _ := strconv.Atoi(strings.TrimSpace(string(data))); pid == os.Getpid() {
+		os.Remove(path)
+	}
+}
+
+// offerSessionLock tells that the sessions are used by another goneovim, and
+// offers to save the sessions of this one in a separate profile instead
+func (e *Editor) offerSessionLock() {
+	if !e.sessionReadOnly {
+		return
+	}
+	buttons := []*NotifyButton{}
+	buttons = append(buttons, &NotifyButton{
+		action: func() {},
+		text:   "Continue read-only",
+	})
+	buttons = append(buttons, &NotifyButton{
+		action: e.useSeparateProfile,
+		text:   "Use a separate profile",
+	})
+	buttons = append(buttons, &NotifyButton{
+		action: e.takeOverSessionLock,
+		text:   "Take over the lock",
+	})
+	e.pushNotification(NotifyWarn, 0, "[Gonvim] The sessions are used by another goneovim. They are restored, but the sessions of this one are not saved on quit.", notifyOptionArg(buttons))
+}
+
+// useSeparateProfile saves the sessions and the window geometry of this
+// goneovim in a new profile, which --profile restores in the next start
+func (e *Editor) useSeparateProfile() {
+	profile := e.opts.Profile
+	for i := 1; ; i++ {
+		e.opts.Profile = fmt.Sprintf("instance%d", i)
+		if profile != "" {
+			e.opts.Profile = fmt.Sprintf("%s-instance%d", profile, i)
+		}
+		// The sessions of a profile used before are not overwritten
+		sessions, _ := filepath.Glob(filepath.Join(e.sessionDir(), "*.vim"))
+		if len(sessions) == 0 && acquireSessionLock(e.sessionDir()) {
+			break
+		}
+		if i >= 100 {
+			e.opts.Profile = profile
+			return
+		}
+	}
+	e.sessionReadOnly = false
+	e.pushNotification(NotifyInfo, 0, fmt.Sprintf("[Gonvim] The sessions are saved in the profile %q. Start goneovim with --profile %s to restore them.", e.opts.Profile, e.opts.Profile))
+}
+
+// takeOverSessionLock writes the pid of this process to the lock file. The pid
+// in the lock may be reused by an unrelated process after goneovim has
+// crashed, which would otherwise keep the sessions read-only.
+func (e *Editor) takeOverSessionLock() {
+	path := sessionLockPath(e.sessionDir())
+	if err := ioutil.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to take over the lock: "+err.Error())
+		return
+	}
+	e.sessionReadOnly = false
+}
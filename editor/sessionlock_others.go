@@ -0,0 +1,13 @@
+// +build !windows
+
+package editor
+
+import (
+	"syscall"
+)
+
+// isProcessAlive reports whether the process exists, by the signal 0
+func isProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
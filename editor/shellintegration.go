@@ -0,0 +1,127 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// sendPathsToRunningInstance opens the files and folders in an already running goneovim, if any
+func sendPathsToRunningInstance(home string, args []string) bool {
+	sent := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+") {
+			continue
+		}
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			continue
+		}
+		if !sendURLToRunningInstance(home, pathToURL(path)) {
+			return false
+		}
+		sent = true
+	}
+
+	return sent
+}
+
+// registerShellIntegration registers the file associations and
+// the "Open with Gonvim" / "Open folder as workspace" context menu entries for the current user
+func registerShellIntegration(home string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return registerShellIntegrationWindows(exe)
+	case "darwin":
+		// Finder uses CFBundleDocumentTypes of Info.plist
+		fmt.Println("File associations are registered when goneovim.app is placed in /Applications")
+		return nil
+	default:
+		return registerShellIntegrationLinux(home, exe)
+	}
+}
+
+func registerShellIntegrationWindows(exe string) error {
+	classes := `HKCU\Software\Classes\`
+	command := fmt.Sprintf(`"%s" --reuse "%%1"`, exe)
+	cmds := [][]string{
+		// "Open with" list
+		{"reg", "add", classes + `Applications\goneovim.exe\shell\open\command`, "/ve", "/d", command, "/f"},
+		// Context menu of files
+		{"reg", "add", classes + `*\shell\Goneovim`, "/ve", "/d", "Open with Gonvim", "/f"},
+		{"reg", "add", classes + `*\shell\Goneovim`, "/v", "Icon", "/d", exe, "/f"},
+		{"reg", "add", classes + `*\shell\Goneovim\command`, "/ve", "/d", command, "/f"},
+		// Context menu of folders
+		{"reg", "add", classes + `Directory\shell\Goneovim`, "/ve", "/d", "Open folder as workspace", "/f"},
+		{"reg", "add", classes + `Directory\shell\Goneovim`, "/v", "Icon", "/d", exe, "/f"},
+		{"reg", "add", classes + `Directory\shell\Goneovim\command`, "/ve", "/d", command, "/f"},
+		{"reg", "add", classes + `Directory\Background\shell\Goneovim`, "/ve", "/d", "Open folder as workspace", "/f"},
+		{"reg", "add", classes + `Directory\Background\shell\Goneovim`, "/v", "Icon", "/d", exe, "/f"},
+		{"reg", "add", classes + `Directory\Background\shell\Goneovim\command`, "/ve", "/d", fmt.Sprintf(`"%s" --reuse "%%V"`, exe), "/f"},
+	}
+	for _, c := range cmds {
+		cmd := exec.Command(c[0], c[1:]...)
+		util.PrepareRunProc(cmd)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return registerURLScheme()
+}
+
+func registerShellIntegrationLinux(home, exe string) error {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	desktop := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Goneovim
+Comment=Goneovim - Neovim GUI
+Exec=%s --reuse %%F
+Icon=goneovim
+Terminal=false
+Categories=Utility;TextEditor;
+MimeType=text/plain;inode/directory;x-scheme-handler/%s;
+Actions=Folder;
+
+[Desktop Action Folder]
+Name=Open folder as workspace
+Exec=%s --reuse %%F
+`, exe, urlScheme, exe)
+	applications := filepath.Join(dataHome, "applications")
+	os.MkdirAll(applications, 0755)
+	err := ioutil.WriteFile(filepath.Join(applications, "goneovim.desktop"), []byte(desktop), 0644)
+	if err != nil {
+		return err
+	}
+
+	// Nautilus context menu
+	script := fmt.Sprintf(`#!/bin/sh
+IFS='
+'
+exec "%s" --reuse $NAUTILUS_SCRIPT_SELECTED_FILE_PATHS
+`, exe)
+	scripts := filepath.Join(dataHome, "nautilus", "scripts")
+	os.MkdirAll(scripts, 0755)
+	err = ioutil.WriteFile(filepath.Join(scripts, "Open with Gonvim"), []byte(script), 0755)
+	if err != nil {
+		return err
+	}
+
+	exec.Command("update-desktop-database", applications).Run()
+
+	return registerURLScheme()
+}
@@ -0,0 +1,230 @@
+package editor
+
+import (
+	"runtime"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// snapMargin is the distance from the edge of the monitor in which the pointer
+// snaps the dragged window
+const snapMargin = 8
+
+// snapAreas are the areas of :GonvimSnap, as the x, y, width and height in the
+// halves of the available geometry of the monitor
+var snapAreas = map[string][4]int{
+	"left":         {0, 0, 1, 2},
+	"right":        {1, 0, 1, 2},
+	"top":          {0, 0, 2, 1},
+	"bottom":       {0, 1, 2, 1},
+	"top-left":     {0, 0, 1, 1},
+	"top-right":    {1, 0, 1, 1},
+	"bottom-left":  {0, 1, 1, 1},
+	"bottom-right": {1, 1, 1, 1},
+}
+
+// WindowSnap snaps the frameless window, which has no native snapping on some
+// platforms, to the halves and the quarters of the monitor when it is dragged
+// to the edges and the corners, and by the keys of snapKeys
+type WindowSnap struct {
+	timer   *core.QTimer
+	preview *widgets.QRubberBand
+	area    string
+
+	// The geometry before the window was snapped, restored by "restore"
+	restore *core.QRect
+}
+
+func (e *Editor) initWindowSnap() {
+	if !e.isFrameless() || !e.config.Editor.EdgeSnap {
+		return
+	}
+	s := &WindowSnap{
+		timer:   core.NewQTimer(nil),
+		preview: widgets.NewQRubberBand(widgets.QRubberBand__Rectangle, nil),
+	}
+	e.snap = s
+	// The frameless window moves itself while its titlebar is dragged; the
+	// pointer is watched until the button is released
+	s.timer.ConnectTimeout(e.watchSnapDrag)
+	e.window.ConnectMoveEvent(func(event *gui.QMoveEvent) {
+		e.window.MoveEventDefault(event)
+		if gui.QGuiApplication_MouseButtons()&core.Qt__LeftButton != 0 && !s.timer.IsActive() && e.isOnTitlebar(gui.QCursor_Pos()) {
+			s.timer.Start(30)
+		}
+	})
+}
+
+// isOnTitlebar reports whether the pointer is on the titlebar; the window
+// dragged by it moves with the pointer, unlike the one resized by its edges
+func (e *Editor) isOnTitlebar(pos *core.QPoint) bool {
+	titlebar := e.window.TitleBar
+	if titlebar == nil || titlebar.Pointer() == nil || !titlebar.IsVisible() {
+		return false
+	}
+
+	return titlebar.Rect().Contains(titlebar.MapFromGlobal(pos), false)
+}
+
+// watchSnapDrag previews the area of the edge under the pointer while the
+// window is dragged, and snaps the window to it when the button is released
+func (e *Editor) watchSnapDrag() {
+	s := e.snap
+	pos := gui.QCursor_Pos()
+	area := snapAreaAt(pos)
+	if gui.QGuiApplication_MouseButtons()&core.Qt__LeftButton == 0 {
+		s.timer.Stop()
+		s.preview.Hide()
+		if area != "" {
+			e.snapWindowTo(area, gui.QGuiApplication_ScreenAt(pos))
+		}
+		return
+	}
+	if area == s.area {
+		return
+	}
+	s.area = area
+	if area == "" {
+		s.preview.Hide()
+		return
+	}
+	screen := gui.QGuiApplication_ScreenAt(pos)
+	if screen == nil || screen.Pointer() == nil {
+		return
+	}
+	rect := screen.AvailableGeometry()
+	if area != "maximize" {
+		rect = snapRect(area, rect)
+	}
+	s.preview.SetGeometry(rect)
+	s.preview.Show()
+	s.preview.Raise()
+}
+
+// snapAreaAt returns the area of the edge or the corner of the monitor under
+// the pointer, "maximize" for the top edge, or "" if it is not at the edges
+func snapAreaAt(pos *core.QPoint) string {
+	screen := gui.QGuiApplication_ScreenAt(pos)
+	if screen == nil || screen.Pointer() == nil {
+		return ""
+	}
+	geo := screen.AvailableGeometry()
+	left := pos.X() <= geo.Left()+snapMargin
+	right := pos.X() >= geo.Right()-snapMargin
+	top := pos.Y() <= geo.Top()+snapMargin
+	bottom := pos.Y() >= geo.Bottom()-snapMargin
+	corner := geo.Height() / 4
+
+	switch {
+	case left || right:
+		side := "left"
+		if right {
+			side = "right"
+		}
+		if pos.Y() < geo.Top()+corner {
+			return "top-" + side
+		}
+		if pos.Y() > geo.Bottom()-corner {
+			return "bottom-" + side
+		}
+		return side
+	case top:
+		return "maximize"
+	case bottom:
+		return "bottom"
+	}
+	return ""
+}
+
+func snapRect(area string, geo *core.QRect) *core.QRect {
+	a := snapAreas[area]
+	width := geo.Width() / 2
+	height := geo.Height() / 2
+	return core.NewQRect4(geo.X()+a[0]*width, geo.Y()+a[1]*height, a[2]*width, a[3]*height)
+}
+
+// snapWindow handles :GonvimSnap {area} and gonvim_snap; an area of snapAreas,
+// "maximize" or "restore", on the monitor of the window
+func (e *Editor) snapWindow(area string) {
+	var screen *gui.QScreen
+	if handle := e.window.WindowHandle(); handle != nil && handle.Pointer() != nil {
+		screen = handle.Screen()
+	}
+	e.snapWindowTo(area, screen)
+}
+
+func (e *Editor) snapWindowTo(area string, screen *gui.QScreen) {
+	if e.snap == nil {
+		e.snap = &WindowSnap{}
+	}
+	s := e.snap
+	switch area {
+	case "restore":
+		if e.window.IsMaximized() {
+			e.window.ShowNormal()
+		}
+		if s.restore != nil {
+			e.window.SetGeometry(s.restore)
+			s.restore = nil
+		}
+		return
+	case "maximize":
+		if s.restore == nil && !e.window.IsMaximized() {
+			s.restore = e.window.Geometry()
+		}
+		e.window.WindowMaximize()
+		return
+	}
+	if _, ok := snapAreas[area]; !ok || screen == nil || screen.Pointer() == nil {
+		return
+	}
+	if e.window.IsMaximized() {
+		e.window.ShowNormal()
+	} else if s.restore == nil {
+		s.restore = e.window.Geometry()
+	}
+	e.window.SetGeometry(snapRect(area, screen.AvailableGeometry()))
+}
+
+// isSnapKey returns the area of Ctrl+Alt+arrows (halves), Ctrl+Alt+U/I/J/K
+// (quarters), Ctrl+Alt+Enter (maximize) and Ctrl+Alt+Backspace (restore), if
+// snapKeys is set, or "" for the other keys
+func isSnapKey(event *gui.QKeyEvent) string {
+	if !editor.config.Editor.SnapKeys {
+		return ""
+	}
+	mod := event.Modifiers() &^ core.Qt__KeypadModifier
+	ctrl := core.Qt__ControlModifier
+	if runtime.GOOS == "darwin" {
+		// On macOS, Qt reports Cmd as ControlModifier and Ctrl as MetaModifier
+		ctrl = core.Qt__MetaModifier
+	}
+	if mod != ctrl|core.Qt__AltModifier {
+		return ""
+	}
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_Left:
+		return "left"
+	case core.Qt__Key_Right:
+		return "right"
+	case core.Qt__Key_Up:
+		return "top"
+	case core.Qt__Key_Down:
+		return "bottom"
+	case core.Qt__Key_U:
+		return "top-left"
+	case core.Qt__Key_I:
+		return "top-right"
+	case core.Qt__Key_J:
+		return "bottom-left"
+	case core.Qt__Key_K:
+		return "bottom-right"
+	case core.Qt__Key_Return, core.Qt__Key_Enter:
+		return "maximize"
+	case core.Qt__Key_Backspace:
+		return "restore"
+	}
+	return ""
+}
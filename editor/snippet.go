@@ -0,0 +1,141 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// SnippetRegion is the part of a placeholder of a snippet on a row of the window
+type SnippetRegion struct {
+	index int
+	row   int
+	col   int
+	width int
+}
+
+// SnippetPlaceholders are the placeholders of the active snippet on a window,
+// sent by require("gonvim").snippet() for the snippet engines
+type SnippetPlaceholders struct {
+	current int
+	total   int
+	regions []*SnippetRegion
+}
+
+// setSnippet handles gonvim_snippet; the window, the current placeholder, the
+// number of the placeholders and the regions; the window 0 clears them
+func (s *Screen) setSnippet(args []interface{}) {
+	if len(args) < 4 {
+		return
+	}
+	id := util.ReflectToInt(args[0])
+	var placeholders *SnippetPlaceholders
+	if id != 0 {
+		placeholders = &SnippetPlaceholders{
+			current: util.ReflectToInt(args[1]),
+			total:   util.ReflectToInt(args[2]),
+		}
+		items, _ := args[3].([]interface{})
+		for _, i := range items {
+			item, ok := i.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			placeholders.regions = append(placeholders.regions, &SnippetRegion{
+				index: util.ReflectToInt(item["index"]),
+				row:   util.ReflectToInt(item["row"]),
+				col:   util.ReflectToInt(item["col"]),
+				width: util.ReflectToInt(item["width"]),
+			})
+		}
+	}
+
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		var p *SnippetPlaceholders
+		if int(win.id) == id {
+			p = placeholders
+		}
+		if win.snippet == nil && p == nil {
+			return true
+		}
+		win.snippet = p
+		if win.widget != nil {
+			win.widget.Update()
+		}
+		return true
+	})
+}
+
+// drawSnippet tints the current placeholder, outlines the others, and shows the
+// "current/total" indicator after the current one
+func (w *Window) drawSnippet(p *gui.QPainter) {
+	sp := w.snippet
+	if sp == nil || len(sp.regions) == 0 || !w.isShown() {
+		return
+	}
+	font := w.getFont()
+	lineHeight := float64(font.lineHeight)
+	accent := editor.colors.matchFg
+	if accent == nil {
+		accent = editor.colors.fg
+	}
+
+	p.Save()
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+	var indicator *SnippetRegion
+	for _, r := range sp.regions {
+		if r.row < 0 || r.row >= w.rows {
+			continue
+		}
+		rect := core.NewQRectF4(
+			float64(r.col)*font.truewidth+0.5,
+			float64(r.row)*lineHeight+0.5,
+			float64(r.width)*font.truewidth-1,
+			lineHeight-1,
+		)
+		qcolor := accent.QColor()
+		if r.index == sp.current {
+			qcolor.SetAlphaF(0.2)
+			p.FillRect4(rect, qcolor)
+			qcolor.SetAlphaF(0.9)
+			indicator = r
+		} else {
+			qcolor.SetAlphaF(0.5)
+		}
+		pen := gui.NewQPen3(qcolor)
+		pen.SetWidthF(1)
+		if r.index != sp.current {
+			pen.SetStyle(core.Qt__DashLine)
+		}
+		p.SetPen(pen)
+		p.SetBrush(gui.NewQBrush2(core.Qt__NoBrush))
+		p.DrawRoundedRect(rect, 2, 2, core.Qt__AbsoluteSize)
+	}
+
+	if indicator != nil && sp.total > 0 {
+		text := fmt.Sprintf("%d/%d", sp.current, sp.total)
+		small := gui.NewQFont2(font.fontNew.Family(), int(font.fontNew.PointSizeF()*0.75), 1, false)
+		metrics := gui.NewQFontMetricsF(small)
+		width := metrics.HorizontalAdvance(text, -1) + 6
+		height := metrics.Height()
+		x := float64(indicator.col+indicator.width)*font.truewidth + 4
+		if x+width > float64(w.widget.Width()) {
+			x = float64(indicator.col)*font.truewidth - width - 4
+		}
+		y := float64(indicator.row)*lineHeight + (lineHeight-height)/2
+		rect := core.NewQRectF4(x, y, width, height)
+		p.SetPen3(core.Qt__NoPen)
+		p.SetBrush(gui.NewQBrush3(accent.QColor(), core.Qt__SolidPattern))
+		p.DrawRoundedRect(rect, 3, 3, core.Qt__AbsoluteSize)
+		p.SetFont(small)
+		p.SetPen2(editor.colors.bg.QColor())
+		p.DrawText6(rect, text, gui.NewQTextOption2(core.Qt__AlignCenter))
+	}
+	p.Restore()
+}
@@ -0,0 +1,121 @@
+package editor
+
+import (
+	"math"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// isSpellCell reports whether the cell is highlighted by the spell checking,
+// SpellBad, SpellCap, SpellRare or SpellLocal
+func isSpellCell(cell *Cell) bool {
+	return cell != nil && cell.highlight != nil && strings.HasPrefix(cell.highlight.hlName, "Spell")
+}
+
+// drawSpellDots draws the dotted underline of the spell errors, instead of the
+// undercurl of nvim, so that they are told from the diagnostics
+func (w *Window) drawSpellDots(p *gui.QPainter, y, x int, color *gui.QColor) {
+	font := w.getFont()
+	radius := float64(font.lineHeight) / 20
+	if radius < 1 {
+		radius = 1
+	}
+	Y := float64(y*font.lineHeight) + float64(font.height)*1.04 + float64(font.lineSpace/2) - radius
+	start := float64(x) * font.truewidth
+	end := start + font.truewidth
+	step := radius * 3
+
+	p.Save()
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+	p.SetPen3(core.Qt__NoPen)
+	p.SetBrush(gui.NewQBrush3(color, core.Qt__SolidPattern))
+	// The dots continue across the cells at the same interval
+	for X := math.Ceil(start/step) * step; X < end; X += step {
+		p.DrawEllipse4(core.NewQPointF3(X+radius, Y), radius, radius)
+	}
+	p.Restore()
+}
+
+// spellMouseEvent shows the suggestions by a right click on a spell error
+func (s *Screen) spellMouseEvent(event *gui.QMouseEvent) bool {
+	switch event.Type() {
+	case core.QEvent__MouseButtonPress:
+		if event.Button() != core.Qt__RightButton {
+			return false
+		}
+		win, col, row := s.windowAt(event.X(), event.Y())
+		if win == nil || row >= len(win.content) || col >= len(win.content[row]) || !isSpellCell(win.content[row][col]) {
+			return false
+		}
+		s.spellClicked = true
+		go s.ws.execLua("require('gonvim').spell_suggest(...)", win.id, row, col)
+		return true
+	case core.QEvent__MouseButtonRelease:
+		if s.spellClicked {
+			s.spellClicked = false
+			return true
+		}
+	}
+
+	return false
+}
+
+// showSpellSuggestions handles gonvim_spell; the window, the row and the column
+// to show the menu at, the buffer, the line, the byte column of the word, the
+// word, and the suggestions of spellsuggest()
+func (s *Screen) showSpellSuggestions(args []interface{}) {
+	if len(args) < 8 {
+		return
+	}
+	winid := nvim.Window(util.ReflectToInt(args[0]))
+	row := util.ReflectToInt(args[1])
+	col := util.ReflectToInt(args[2])
+	buf := util.ReflectToInt(args[3])
+	lnum := util.ReflectToInt(args[4])
+	start := util.ReflectToInt(args[5])
+	word, _ := args[6].(string)
+	suggestions, _ := args[7].([]interface{})
+
+	var win *Window
+	s.windows.Range(func(_, v interface{}) bool {
+		w, ok := v.(*Window)
+		if ok && w != nil && w.id == winid && w.widget != nil {
+			win = w
+			return false
+		}
+		return true
+	})
+	if win == nil {
+		return
+	}
+
+	menu := widgets.NewQMenu(s.widget)
+	if len(suggestions) == 0 {
+		menu.AddAction("(No suggestions)").SetEnabled(false)
+	}
+	for _, sg := range suggestions {
+		suggestion, ok := sg.(string)
+		if !ok {
+			continue
+		}
+		menu.AddAction(suggestion).ConnectTriggered(func(bool) {
+			go s.ws.execLua("require('gonvim').spell_apply(...)", buf, lnum, start, word, suggestion)
+		})
+	}
+	menu.AddSeparator()
+	menu.AddAction("Add to Dictionary").ConnectTriggered(func(bool) {
+		go s.ws.execLua("require('gonvim').spell_good(...)", word, false)
+	})
+	menu.AddAction("Ignore").ConnectTriggered(func(bool) {
+		go s.ws.execLua("require('gonvim').spell_good(...)", word, true)
+	})
+
+	font := win.getFont()
+	pos := core.NewQPoint2(int(float64(col)*font.truewidth), (row+1)*font.lineHeight)
+	menu.Popup(win.widget.MapToGlobal(pos), nil)
+}
@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/therecipe/qt/core"
+)
+
+// StartupTime records the timing of the startup for --startuptime
+type StartupTime struct {
+	mu    sync.Mutex
+	start time.Time
+	last  time.Time
+	lines []string
+}
+
+func newStartupTime() *StartupTime {
+	now := time.Now()
+	return &StartupTime{
+		start: now,
+		last:  now,
+		lines: []string{"times in msec", " clock   self: sourced script", ""},
+	}
+}
+
+// markStartup records the time elapsed since the previous mark, in the format of nvim --startuptime
+func (e *Editor) markStartup(label string) {
+	t := e.startupTime
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.lines = append(t.lines, fmt.Sprintf(
+		"%07.3f  %07.3f: %s",
+		float64(now.Sub(t.start))/float64(time.Millisecond),
+		float64(now.Sub(t.last))/float64(time.Millisecond),
+		label,
+	))
+	t.last = now
+}
+
+func (e *Editor) writeStartupTime() {
+	t := e.startupTime
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := ioutil.WriteFile(e.opts.StartupTime, []byte(strings.Join(t.lines, "\n")+"\n"), 0644)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// firstPaint is called when the grid is painted for the first time
+func (e *Editor) firstPaint() {
+	if e.doneFirstPaint {
+		return
+	}
+	e.doneFirstPaint = true
+	e.markStartup("first paint")
+	if e.opts.ReplayRedraw != "" {
+		if e.headlessTimer != nil {
+			e.headlessTimer.Stop()
+		}
+		core.QTimer_SingleShot(200, e.replayRedraw)
+		return
+	}
+	if e.opts.Headless {
+		// Give nvim a moment to draw the rest of the first screen
+		core.QTimer_SingleShot(200, e.finishHeadless)
+		return
+	}
+	// Let Qt finish the current frame before the deferred initialization
+	core.QTimer_SingleShot(0, e.deferredInit)
+}
+
+// deferredInit constructs the parts of the GUI which are not needed for the first frame
+func (e *Editor) deferredInit() {
+	e.deferredOnce.Do(func() {
+		e.initNotifications()
+		e.initSysTray()
+		e.offerCrashRecovery()
+		e.offerSessionLock()
+		if e.colorsErr != nil {
+			e.pushNotification(NotifyWarn, -1, "[Gonvim] Ignored the colors of "+e.colorsErr.Error())
+		}
+		if e.opts.Clean {
+			e.pushNotification(NotifyInfo, -1, "[Gonvim] Started with --clean, without the config of goneovim and nvim.")
+		}
+		e.checkUpdate()
+		if !e.config.SideBar.Visible {
+			e.wsSide.addItems()
+			for _, ws := range e.workspaces {
+				if ws.cwd != "" {
+					ws.setCwd(ws.cwd)
+				}
+			}
+			e.workspaceUpdate()
+		}
+		e.restoreWindows()
+		e.updateBlurBehind()
+		e.markStartup("deferred init")
+		e.writeStartupTime()
+	})
+}
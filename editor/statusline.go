@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/akiyosi/goneovim/util"
 	"github.com/therecipe/qt/core"
@@ -44,6 +45,10 @@ type Statusline struct {
 	encoding   *StatuslineEncoding
 	fileFormat *StatuslineFileFormat
 	lint       *StatuslineLint
+	power      *StatuslinePower
+
+	// The segments of the extensions by the extension and the id
+	extensions map[string]*StatuslineComponent
 
 	updates chan []interface{}
 }
@@ -147,6 +152,12 @@ type StatuslineFileFormat struct {
 	c          *StatuslineComponent
 }
 
+// StatuslinePower is
+type StatuslinePower struct {
+	powerSaving bool
+	c           *StatuslineComponent
+}
+
 func initStatusline() *Statusline {
 	widget := widgets.NewQWidget(nil, 0)
 	widget.SetContentsMargins(0, 0, 0, 0)
@@ -284,6 +295,15 @@ func initStatusline() *Statusline {
 	s.pos = pos
 	s.pos.c.hide()
 
+	powerLabel := widgets.NewQLabel(nil, 0)
+	power := &StatuslinePower{
+		c: &StatuslineComponent{
+			label: powerLabel,
+		},
+	}
+	s.power = power
+	s.power.c.hide()
+
 	filetypeLabel := widgets.NewQLabel(nil, 0)
 	filetype := &StatuslineFiletype{
 		c: &StatuslineComponent{
@@ -390,6 +410,10 @@ func (s *Statusline) setWidget() {
 			s.widget.Layout().AddWidget(s.lint.c.widget)
 			s.lint.c.isInclude = true
 			s.lint.c.show()
+		case "power":
+			s.widget.Layout().AddWidget(s.power.c.label)
+			s.power.c.isInclude = true
+			s.power.redraw(editor.powerSaving)
 		default:
 		}
 	}
@@ -436,6 +460,10 @@ func (left *LeftStatusItem) setWidget() {
 			left.widget.Layout().AddWidget(left.s.lint.c.widget)
 			left.s.lint.c.isInclude = true
 			left.s.lint.c.show()
+		case "power":
+			left.widget.Layout().AddWidget(left.s.power.c.label)
+			left.s.power.c.isInclude = true
+			left.s.power.redraw(editor.powerSaving)
 		default:
 		}
 	}
@@ -449,6 +477,7 @@ func (s *Statusline) setContentsMarginsForWidgets(l int, u int, r int, d int) {
 	s.fileFormat.c.label.SetContentsMargins(l, u, r, d)
 	s.encoding.c.label.SetContentsMargins(l, u, r, d)
 	s.lint.c.widget.SetContentsMargins(l, u, r, d)
+	s.power.c.label.SetContentsMargins(l, u, r, d)
 }
 
 func (s *Statusline) setColor() {
@@ -467,6 +496,12 @@ func (s *Statusline) setColor() {
 	s.hl = hl
 	fg := s.hl.fg()
 	bg := s.hl.bg()
+	if editor.colors.statuslineFg != nil {
+		fg = editor.colors.statuslineFg
+	}
+	if editor.colors.statuslineBg != nil {
+		bg = editor.colors.statuslineBg
+	}
 	s.widget.SetStyleSheet(fmt.Sprintf(
 		"QWidget#statusline { background-color: %s; } * { color: %s; }",
 		bg.String(),
@@ -500,6 +535,10 @@ func (s *Statusline) setColor() {
 	s.encoding.c.setColor(fg, bg)
 	s.fileFormat.c.setColor(fg, bg)
 	s.pos.c.setColor(fg, bg)
+	s.power.c.setColor(fg, bg)
+	for _, c := range s.extensions {
+		c.setColor(fg, bg)
+	}
 
 	s.lint.c.fg = fg
 	s.lint.c.bg = bg
@@ -555,6 +594,7 @@ func (s *Statusline) subscribe() {
 	}
 	s.ws.signal.ConnectStatuslineSignal(func() {
 		updates := <-s.updates
+		defer editor.perf.measure("statusline", time.Now())
 		s.handleUpdates(updates)
 	})
 	s.ws.signal.ConnectLintSignal(func() {
@@ -892,6 +932,17 @@ func (s *StatuslineFileFormat) redraw(fileFormat string) {
 	s.c.show()
 }
 
+func (s *StatuslinePower) redraw(powerSaving bool) {
+	s.powerSaving = powerSaving
+	if !powerSaving {
+		s.c.label.SetText("")
+		s.c.hide()
+		return
+	}
+	s.c.label.SetText("Power Saving")
+	s.c.show()
+}
+
 func (s *StatuslineFiletype) redraw(filetype string) {
 	if filetype == s.filetype {
 		return
@@ -954,3 +1005,33 @@ func (s *StatuslineLint) redraw(errors, warnings int) {
 
 	s.setColor()
 }
+
+// setExtension sets the text of a segment of an extension, which is added to
+// the right of the statusline the first time; the empty text hides it
+func (s *Statusline) setExtension(key, text, tooltip string) {
+	if s.extensions == nil {
+		s.extensions = make(map[string]*StatuslineComponent)
+	}
+	c, ok := s.extensions[key]
+	if !ok {
+		label := widgets.NewQLabel(nil, 0)
+		m := s.pos.c.label.ContentsMargins()
+		label.SetContentsMargins(m.Left(), m.Top(), m.Right(), m.Bottom())
+		c = &StatuslineComponent{
+			label:     label,
+			isInclude: true,
+		}
+		s.widget.Layout().AddWidget(label)
+		if s.pos.c.fg != nil {
+			c.setColor(s.pos.c.fg, s.pos.c.bg)
+		}
+		s.extensions[key] = c
+	}
+	c.label.SetText(text)
+	c.label.SetToolTip(tooltip)
+	if text == "" {
+		c.hide()
+	} else {
+		c.show()
+	}
+}
@@ -0,0 +1,89 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// nvimShutdownTimeout is the time to wait for the embedded nvim to exit before it is killed
+const nvimShutdownTimeout = 3 * time.Second
+
+// watchNvim records the pid of the embedded nvim, so that it can be killed if
+// it does not exit when the workspace or the application is closed
+func (w *Workspace) watchNvim() {
+	if w.uiRemoteAttached {
+		return
+	}
+	var pid int
+	if err := w.nvim.Call("getpid", &pid); err != nil {
+		return
+	}
+	w.nvimPid = pid
+}
+
+// reapNvim closes the connection of the nvim which has exited, which waits for
+// its process, and kills the process if it is still alive after the timeout
+func (w *Workspace) reapNvim() {
+	if w.uiRemoteAttached || w.nvim == nil {
+		return
+	}
+	n := w.nvim
+	pid := w.nvimPid
+	go func() {
+		done := make(chan struct{})
+		go func() {
+			n.Close()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(nvimShutdownTimeout):
+			killNvim(pid)
+			editor.pushNotification(NotifyWarn, -1, fmt.Sprintf("[Gonvim] Neovim (pid %d) did not exit and was killed.", pid))
+		}
+	}()
+}
+
+// shutdownNvim quits the nvim by :qa!, and kills it if it does not exit in time
+func (w *Workspace) shutdownNvim() {
+	if w.uiRemoteAttached || w.nvim == nil {
+		return
+	}
+	select {
+	case <-w.stop:
+	default:
+		go w.nvim.Command("qa!")
+		select {
+		case <-w.stop:
+		case <-time.After(nvimShutdownTimeout):
+			fmt.Fprintf(os.Stderr, "nvim (pid %d) did not exit by :qa!, killing it\n", w.nvimPid)
+			killNvim(w.nvimPid)
+		}
+	}
+	w.nvim.Close()
+}
+
+func killNvim(pid int) {
+	if pid <= 0 {
+		return
+	}
+	if p, err := os.FindProcess(pid); err == nil {
+		p.Kill()
+	}
+}
+
+// shutdownWorkspaces quits the nvim of all workspaces on quit, so that no
+// orphan nvim --embed is left behind
+func (e *Editor) shutdownWorkspaces() {
+	var wg sync.WaitGroup
+	for _, ws := range e.workspaces {
+		wg.Add(1)
+		go func(ws *Workspace) {
+			defer wg.Done()
+			ws.shutdownNvim()
+		}(ws)
+	}
+	wg.Wait()
+}
@@ -63,12 +63,18 @@ func (t *Tabline) subscribe() {
 
 func (t *Tabline) setColor() {
 	inactiveFg := editor.colors.inactiveFg.String()
+	if editor.colors.tablineFg != nil {
+		inactiveFg = editor.colors.tablineFg.String()
+	}
 	// bg := editor.colors.bg.StringTransparent()
 	t.widget.SetStyleSheet(fmt.Sprintf(`
 	.QWidget { 
 		border-bottom: 0px solid;
 		border-right: 0px solid;
 		background-color: rgba(0, 0, 0, 0); } QWidget { color: %s; } `, inactiveFg))
+	for _, tab := range t.Tabs {
+		tab.updateStyle()
+	}
 }
 
 func initTabline() *Tabline {
@@ -171,6 +177,14 @@ func newTab() *Tab {
 		// fileIcon:  fileIcon,
 		closeIcon: closeIcon,
 	}
+	// The file icons are shown only with the glyphs of a Nerd Font,
+	// which are readable in the size of the tab
+	if editor.config.Icons.NerdFont {
+		tab.fileIcon = svg.NewQSvgWidget(nil)
+		tab.fileIcon.SetFixedWidth(editor.iconSize)
+		tab.fileIcon.SetFixedHeight(editor.iconSize)
+		l.InsertWidget(0, tab.fileIcon, 0, 0)
+	}
 	tab.closeIcon.Hide()
 
 	tab.widget.ConnectEnterEvent(tab.enterEvent)
@@ -189,16 +203,33 @@ func (t *Tab) updateStyle() {
 	if editor.colors.fg == nil || editor.colors.bg == nil {
 		return
 	}
-	fg := editor.colors.fg
+	fg := warpColor(editor.colors.fg, -30)
+	if editor.colors.tablineActiveFg != nil {
+		fg = editor.colors.tablineActiveFg
+	}
 	inactiveFg := editor.colors.inactiveFg
-	accent := t.t.ws.screen.highAttrDef[t.t.ws.screen.highlightGroup["TabLineFill"]].foreground.Hex()
+	tabFg := warpColor(inactiveFg, -30)
+	if editor.colors.tablineFg != nil {
+		inactiveFg = editor.colors.tablineFg
+		tabFg = inactiveFg
+	}
+	var accent string
+	if editor.colors.tablineAccent != nil {
+		accent = editor.colors.tablineAccent.Hex()
+	} else {
+		hl, ok := t.t.ws.screen.highAttrDef[t.t.ws.screen.highlightGroup["TabLineFill"]]
+		if !ok || hl == nil {
+			return
+		}
+		accent = hl.foreground.Hex()
+	}
 
 	if t.active {
 		activeStyle := fmt.Sprintf(`
 		.QWidget { 
 			border-bottom: 2.0px solid %s; 
 			background-color: rgba(0, 0, 0, 0); 
-		} QWidget{ color: %s; } `, accent, warpColor(fg, -30))
+		} QWidget{ color: %s; } `, accent, fg)
 		t.widget.SetStyleSheet(activeStyle)
 		svgContent := editor.getSvg("cross", nil)
 		t.closeIcon.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
@@ -207,7 +238,7 @@ func (t *Tab) updateStyle() {
 		.QWidget { 
 			border: 0px solid %s; 
 			background-color: rgba(0, 0, 0, 0); 
-		} QWidget{ color: %s; } `, accent, warpColor(inactiveFg, -30))
+		} QWidget{ color: %s; } `, accent, tabFg)
 		t.widget.SetStyleSheet(inActiveStyle)
 		svgContent := editor.getSvg("cross", inactiveFg)
 		t.closeIcon.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
@@ -272,10 +303,16 @@ func (t *Tab) updateSize() {
 		-1,
 	))
 	height := int(fontmetrics.Height()) + t.t.marginTop + t.t.marginBottom
+	if t.fileIcon != nil {
+		width += editor.iconSize
+	}
 	t.widget.SetFixedSize2(width+editor.iconSize+5+10+5, height)
 }
 
 func (t *Tab) updateFileIcon() {
+	if t.fileIcon == nil {
+		return
+	}
 	svgContent := editor.getSvg(t.fileType, nil)
 	t.fileIcon.Load2(core.NewQByteArray2(svgContent, len(svgContent)))
 }
@@ -320,7 +357,7 @@ func (t *Tabline) update(args []interface{}) {
 		fileType := getFileType(text)
 		if fileType != tab.fileType {
 			tab.fileType = fileType
-			// tab.updateFileIcon()
+			tab.updateFileIcon()
 		}
 
 		if text != tab.fileText {
@@ -0,0 +1,299 @@
+package editor
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// taskErrorRegexp matches the file:line[:col] at the head of the output lines of compilers and tests
+var taskErrorRegexp = regexp.MustCompile(`^\s*([^\s:][^:]*):(\d+)(?::(\d+))?:`)
+
+// taskEscapeRegexp matches the escape sequences of the colors in the output
+var taskEscapeRegexp = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// Task is a shell command defined in [tasks] of the config, or in .gonvim/tasks.toml of the project
+type Task struct {
+	name    string
+	command string
+}
+
+// TaskPanel runs the tasks of the workspace and shows their output; a
+// double click on a file:line of the output jumps to it in nvim
+type TaskPanel struct {
+	ws      *Workspace
+	widget  *widgets.QWidget
+	toolbar *widgets.QHBoxLayout
+	status  *widgets.QLabel
+	stop    *widgets.QPushButton
+	output  *widgets.QPlainTextEdit
+	buttons []*widgets.QPushButton
+
+	group   *taskGroup
+	running string
+	// The output of the previous runs is dropped by the id
+	id int
+	// The directory the task runs in, for the relative paths in the output
+	dir string
+}
+
+func newTaskPanel(ws *Workspace) *TaskPanel {
+	t := &TaskPanel{
+		ws:     ws,
+		widget: widgets.NewQWidget(nil, 0),
+		status: widgets.NewQLabel(nil, 0),
+		output: widgets.NewQPlainTextEdit(nil),
+	}
+	layout := widgets.NewQVBoxLayout()
+	layout.SetContentsMargins(6, 4, 6, 4)
+	layout.SetSpacing(4)
+	t.widget.SetLayout(layout)
+
+	t.toolbar = widgets.NewQHBoxLayout()
+	t.toolbar.SetSpacing(4)
+	t.toolbar.AddWidget(t.status, 1, 0)
+	t.stop = widgets.NewQPushButton2("Stop", nil)
+	t.stop.SetFocusPolicy(core.Qt__NoFocus)
+	t.stop.ConnectClicked(func(bool) {
+		t.kill()
+	})
+	t.toolbar.AddWidget(t.stop, 0, 0)
+	closeButton := widgets.NewQPushButton2("Close", nil)
+	closeButton.SetFocusPolicy(core.Qt__NoFocus)
+	closeButton.ConnectClicked(func(bool) {
+		t.widget.Hide()
+	})
+	t.toolbar.AddWidget(closeButton, 0, 0)
+	layout.AddLayout(t.toolbar, 0)
+
+	t.output.SetReadOnly(true)
+	t.output.SetFocusPolicy(core.Qt__NoFocus)
+	t.output.SetFrameShape(widgets.QFrame__NoFrame)
+	t.output.SetMaximumBlockCount(10000)
+	t.output.ConnectMouseDoubleClickEvent(t.jump)
+	layout.AddWidget(t.output, 1, 0)
+
+	t.widget.SetFixedHeight(editor.height / 4)
+	t.widget.Hide()
+
+	return t
+}
+
+func (t *TaskPanel) setColor() {
+	fg := editor.colors.widgetFg
+	bg := editor.colors.widgetBg
+	if fg == nil || bg == nil {
+		return
+	}
+	t.widget.SetStyleSheet(fmt.Sprintf(`
+	* { color: %s; background-color: %s; }
+	QPlainTextEdit { border: 0px; background-color: %s; }
+	QPushButton { border: 1px solid %s; padding: 2px 8px; }
+	QPushButton:hover { background-color: %s; }
+	`, fg.String(), bg.String(), editor.colors.bg.String(), editor.colors.inactiveFg.String(), editor.colors.selectedBg.String()))
+	t.output.SetFont(t.ws.font.fontNew)
+}
+
+// tasks returns the tasks of the config and of .gonvim/tasks.toml in the cwd,
+// sorted by the name. The ones of the project are read by updateEnv, if the
+// user trusts them.
+func (t *TaskPanel) tasks() []*Task {
+	commands := make(map[string]string)
+	for name, command := range editor.config.Tasks {
+		commands[name] = command
+	}
+	for name, command := range t.ws.projectTasks {
+		commands[name] = command
+	}
+	var tasks []*Task
+	for name, command := range commands {
+		tasks = append(tasks, &Task{name: name, command: command})
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].name < tasks[j].name
+	})
+
+	return tasks
+}
+
+// updateToolbar adds the buttons of the tasks to the toolbar
+func (t *TaskPanel) updateToolbar(tasks []*Task) {
+	for _, b := range t.buttons {
+		b.DeleteLater()
+	}
+	t.buttons = nil
+	for i, task := range tasks {
+		name := task.name
+		button := widgets.NewQPushButton2(name, nil)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.SetToolTip(task.command)
+		button.ConnectClicked(func(bool) {
+			t.run(name)
+		})
+		t.toolbar.InsertWidget(1+i, button, 0, 0)
+		t.buttons = append(t.buttons, button)
+	}
+}
+
+// handle handles gonvim_task; the name of the task, or "" to choose it from the menu
+func (t *TaskPanel) handle(args []interface{}) {
+	name := ""
+	if len(args) > 0 {
+		name, _ = args[0].(string)
+	}
+	if name != "" {
+		t.run(name)
+		return
+	}
+	tasks := t.tasks()
+	if len(tasks) == 0 {
+		editor.pushNotification(NotifyInfo, -1, "[Gonvim] No tasks are defined in [tasks] of setting.toml or .gonvim/tasks.toml")
+		return
+	}
+	menu := widgets.NewQMenu(t.ws.screen.widget)
+	for _, task := range tasks {
+		name := task.name
+		menu.AddAction(fmt.Sprintf("%s\t%s", name, task.command)).ConnectTriggered(func(bool) {
+			t.run(name)
+		})
+	}
+	pos := core.NewQPoint2(t.ws.screen.widget.Width()/3, t.ws.screen.widget.Height()/4)
+	menu.Popup(t.ws.screen.widget.MapToGlobal(pos), nil)
+}
+
+// run runs the task in the cwd of the workspace, stopping the running one
+func (t *TaskPanel) run(name string) {
+	tasks := t.tasks()
+	var task *Task
+	for _, tk := range tasks {
+		if tk.name == name {
+			task = tk
+		}
+	}
+	if task == nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] No such task: "+name)
+		return
+	}
+	t.kill()
+
+	t.setColor()
+	t.updateToolbar(tasks)
+	t.output.Clear()
+	t.widget.Show()
+	t.dir = t.ws.cwd
+	t.running = name
+	t.id++
+	id := t.id
+	t.status.SetText(fmt.Sprintf("%s: %s", name, task.command))
+	t.stop.SetEnabled(true)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", task.command)
+	} else {
+		cmd = exec.Command("sh", "-c", task.command)
+	}
+	cmd.Dir = t.dir
+	cmd.Env = t.ws.environ()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.finish(name, err.Error())
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+	group, err := startTask(cmd)
+	if err != nil {
+		t.finish(name, err.Error())
+		return
+	}
+	t.group = group
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			t.ws.guiUpdates <- []interface{}{"gonvim_task_output", id, scanner.Text()}
+			t.ws.signal.GuiSignal()
+		}
+		result := "done"
+		if err := cmd.Wait(); err != nil {
+			result = err.Error()
+		}
+		group.release()
+		t.ws.guiUpdates <- []interface{}{"gonvim_task_exit", id, result}
+		t.ws.signal.GuiSignal()
+	}()
+}
+
+// kill kills the running task with the processes started by it
+func (t *TaskPanel) kill() {
+	if t.group != nil {
+		t.group.kill()
+	}
+	t.group = nil
+}
+
+// appendOutput handles gonvim_task_output; the id of the run and a line of its output
+func (t *TaskPanel) appendOutput(args []interface{}) {
+	if len(args) < 2 || util.ReflectToInt(args[0]) != t.id {
+		return
+	}
+	line, _ := args[1].(string)
+	line = taskEscapeRegexp.ReplaceAllString(line, "")
+	format := gui.NewQTextCharFormat()
+	if taskErrorRegexp.MatchString(line) {
+		format.SetForeground(gui.NewQBrush3(editor.colors.matchFg.QColor(), core.Qt__SolidPattern))
+		format.SetFontUnderline(true)
+	}
+	cursor := t.output.TextCursor()
+	cursor.MovePosition(gui.QTextCursor__End, gui.QTextCursor__MoveAnchor, 1)
+	if !t.output.Document().IsEmpty() {
+		cursor.InsertBlock()
+	}
+	cursor.InsertText2(line, format)
+	t.output.EnsureCursorVisible()
+}
+
+// exit handles gonvim_task_exit; the id of the run and the result
+func (t *TaskPanel) exit(args []interface{}) {
+	if len(args) < 2 || util.ReflectToInt(args[0]) != t.id {
+		return
+	}
+	result, _ := args[1].(string)
+	t.finish(t.running, result)
+}
+
+func (t *TaskPanel) finish(name, result string) {
+	t.group = nil
+	t.stop.SetEnabled(false)
+	t.status.SetText(fmt.Sprintf("%s: %s", name, result))
+}
+
+// jump edits the file at the line of the output under the mouse
+func (t *TaskPanel) jump(event *gui.QMouseEvent) {
+	line := t.output.CursorForPosition(event.Pos()).Block().Text()
+	m := taskErrorRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	path := m[1]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.dir, path)
+	}
+	lnum, _ := strconv.Atoi(m[2])
+	col := 1
+	if m[3] != "" {
+		col, _ = strconv.Atoi(m[3])
+	}
+	go t.ws.nvim.Command(fmt.Sprintf("execute 'edit ' . fnameescape('%s') | call cursor(%d, %d)", escapeVimString(path), lnum, col))
+	editor.wsWidget.SetFocus2()
+}
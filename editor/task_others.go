@@ -0,0 +1,43 @@
+// +build !windows
+
+package editor
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// taskGroup is the process group of a task, so that the processes started by
+// the shell of the task are killed with it
+type taskGroup struct {
+	mu     sync.Mutex
+	pid    int
+	exited bool
+}
+
+func startTask(cmd *exec.Cmd) (*taskGroup, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &taskGroup{pid: cmd.Process.Pid}, nil
+}
+
+func (g *taskGroup) kill() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// The pid may be reused after the task has been waited for
+	if g.exited {
+		return
+	}
+	syscall.Kill(-g.pid, syscall.SIGKILL)
+}
+
+// release is called after the task has been waited for
+func (g *taskGroup) release() {
+	g.mu.Lock()
+	g.exited = true
+	g.mu.Unlock()
+}
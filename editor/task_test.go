@@ -0,0 +1,31 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTaskErrorRegexp(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"main.go:12:5: undefined: foo", []string{"main.go", "12", "5"}},
+		{"./editor/task.go:7: missing return", []string{"./editor/task.go", "7", ""}},
+		{"    task_test.go:31: got 1, want 2", []string{"task_test.go", "31", ""}},
+		{"src/lib.rs:3:14: error", []string{"src/lib.rs", "3", "14"}},
+		{"ok  	github.com/akiyosi/goneovim/grid	0.003s", nil},
+		{"error: cannot find main.go", nil},
+		{"main.go:line: error", nil},
+		{":12: no file", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		if m := taskErrorRegexp.FindStringSubmatch(tt.line); m != nil {
+			got = m[1:]
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
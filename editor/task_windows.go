@@ -0,0 +1,82 @@
+// +build windows
+
+package editor
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+)
+
+const (
+	processTerminate = 0x0001
+	processSetQuota  = 0x0100
+)
+
+// taskGroup is the job object of a task, so that the processes started by the
+// shell of the task are killed with it. Without the job object, only the
+// shell is killed.
+type taskGroup struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	job    syscall.Handle
+	exited bool
+}
+
+func startTask(cmd *exec.Cmd) (*taskGroup, error) {
+	util.PrepareRunProc(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	g := &taskGroup{cmd: cmd}
+	job, _, _ := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return g, nil
+	}
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		syscall.CloseHandle(syscall.Handle(job))
+		return g, nil
+	}
+	defer syscall.CloseHandle(process)
+	if ok, _, _ := procAssignProcessToJobObject.Call(job, uintptr(process)); ok == 0 {
+		syscall.CloseHandle(syscall.Handle(job))
+		return g, nil
+	}
+	g.job = syscall.Handle(job)
+
+	return g, nil
+}
+
+func (g *taskGroup) kill() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.exited {
+		return
+	}
+	if g.job != 0 {
+		procTerminateJobObject.Call(uintptr(g.job), 1)
+		return
+	}
+	g.cmd.Process.Kill()
+}
+
+// release is called after the task has been waited for
+func (g *taskGroup) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.exited = true
+	if g.job != 0 {
+		syscall.CloseHandle(g.job)
+		g.job = 0
+	}
+}
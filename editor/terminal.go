@@ -0,0 +1,431 @@
+package editor
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Terminal is the terminal docked below the screen of the workspace. The shell
+// runs in a pty of its own instead of nvim's :terminal, so its scrollback and
+// selection are kept apart from the buffers. It is a line oriented terminal
+// (TERM=dumb); the colors of SGR are shown, the other escape sequences are
+// dropped, so full screen programs are not supported.
+type Terminal struct {
+	ws     *Workspace
+	widget *widgets.QPlainTextEdit
+	proc   *termProcess
+	cwd    string
+	format *gui.QTextCharFormat
+	// The input to the shell, written in order by one goroutine
+	input chan []byte
+
+	// The cwd of the workspace to change to when the shell is idle
+	pendingCwd string
+
+	// The incomplete escape sequence at the end of the last output
+	pending string
+	// A carriage return has been read; the next text replaces the current line
+	overwrite bool
+}
+
+// The 16 colors of SGR 30-37 and 90-97
+var terminalColors = []string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+func newTerminal(ws *Workspace) *Terminal {
+	t := &Terminal{
+		ws:     ws,
+		widget: widgets.NewQPlainTextEdit(nil),
+		format: gui.NewQTextCharFormat(),
+	}
+	t.widget.SetReadOnly(true)
+	t.widget.SetTextInteractionFlags(core.Qt__TextSelectableByMouse | core.Qt__TextSelectableByKeyboard)
+	t.widget.SetFrameShape(widgets.QFrame__NoFrame)
+	t.widget.SetMaximumBlockCount(editor.config.Editor.TerminalScrollback)
+	t.widget.SetFixedHeight(editor.height / 3)
+	t.widget.ConnectKeyPressEvent(t.keyPress)
+	t.widget.ConnectResizeEvent(func(event *gui.QResizeEvent) {
+		t.widget.ResizeEventDefault(event)
+		t.resize()
+	})
+	t.widget.Hide()
+
+	return t
+}
+
+func (t *Terminal) setColor() {
+	fg := editor.colors.fg
+	bg := editor.colors.bg
+	if fg == nil || bg == nil {
+		return
+	}
+	t.widget.SetStyleSheet(fmt.Sprintf(
+		" QPlainTextEdit { color: %s; background-color: %s; border-top: 1px solid %s; selection-background-color: %s; }",
+		fg.String(), bg.String(), editor.colors.windowSeparator.String(), editor.colors.selectedBg.String(),
+	))
+	t.widget.SetFont(t.ws.font.fontNew)
+}
+
+// toggle shows the terminal, starting the shell at the cwd of the workspace if needed, or hides it
+func (t *Terminal) toggle() {
+	if t.widget.IsVisible() {
+		t.widget.Hide()
+		editor.wsWidget.SetFocus2()
+		return
+	}
+	t.setColor()
+	t.widget.Show()
+	t.widget.SetFocus2()
+	if t.proc != nil {
+		return
+	}
+	t.cwd = t.ws.cwd
+	cols, rows := t.size()
+	proc, err := startTermProcess(terminalShell(), t.cwd, t.ws.environ(), cols, rows)
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] Failed to start the terminal: "+err.Error())
+		return
+	}
+	t.proc = proc
+	t.input = make(chan []byte, 256)
+	go t.read(proc)
+	go t.write(proc, t.input)
+}
+
+func terminalShell() string {
+	if shell := editor.config.Editor.TerminalShell; shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		if shell := os.Getenv("COMSPEC"); shell != "" {
+			return shell
+		}
+		return "cmd.exe"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// write writes the input to the shell until the shell exits or the terminal
+// is closed
+func (t *Terminal) write(proc *termProcess, input chan []byte) {
+	for {
+		select {
+		case b := <-input:
+			proc.Write(b)
+		case <-proc.done:
+			return
+		}
+	}
+}
+
+// read passes the output of the shell to the GUI thread until the shell exits
+// or the terminal is closed
+func (t *Terminal) read(proc *termProcess) {
+	post := func(update []interface{}) bool {
+		select {
+		case t.ws.guiUpdates <- update:
+			t.ws.signal.GuiSignal()
+			return true
+		case <-proc.done:
+			return false
+		}
+	}
+	buf := make([]byte, 8192)
+	// The bytes of a character split by the read
+	var carry []byte
+	for {
+		n, err := proc.Read(buf)
+		if n > 0 {
+			data := append(carry, buf[:n]...)
+			complete := utf8Complete(data)
+			carry = append([]byte(nil), data[complete:]...)
+			if complete > 0 && !post([]interface{}{"gonvim_terminal_output", string(data[:complete])}) {
+				return
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	post([]interface{}{"gonvim_terminal_exit"})
+}
+
+// utf8Complete returns the length of the bytes up to the incomplete character at the end
+func utf8Complete(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if utf8.FullRune(b[i:]) {
+			return len(b)
+		}
+		return i
+	}
+
+	return len(b)
+}
+
+// exit is called when the shell has exited
+func (t *Terminal) exit() {
+	if t.proc != nil {
+		t.proc.Close()
+		t.proc = nil
+	}
+	t.output("\n[Process exited]\n")
+}
+
+// close ends the shell when the workspace is closed
+func (t *Terminal) close() {
+	if t.proc == nil {
+		return
+	}
+	t.proc.Close()
+	t.proc = nil
+}
+
+// output appends the output of the shell to the scrollback
+func (t *Terminal) output(text string) {
+	text = t.pending + text
+	t.pending = ""
+	cursor := t.widget.TextCursor()
+	cursor.MovePosition(gui.QTextCursor__End, gui.QTextCursor__MoveAnchor, 1)
+
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() == 0 {
+			return
+		}
+		if t.overwrite {
+			cursor.MovePosition(gui.QTextCursor__StartOfBlock, gui.QTextCursor__KeepAnchor, 1)
+			cursor.RemoveSelectedText()
+			t.overwrite = false
+		}
+		cursor.InsertText2(plain.String(), t.format)
+		plain.Reset()
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\x1b':
+			flush()
+			n, ok := t.escape(text[i:])
+			if !ok {
+				t.pending = text[i:]
+				i = len(text)
+				continue
+			}
+			i += n - 1
+		case '\r':
+			flush()
+			t.overwrite = true
+		case '\n':
+			t.overwrite = false
+			plain.WriteByte(c)
+		case '\b':
+			flush()
+			cursor.DeletePreviousChar()
+		case '\a', '\x00':
+		default:
+			plain.WriteByte(c)
+		}
+	}
+	flush()
+	t.widget.SetTextCursor(cursor)
+	t.widget.EnsureCursorVisible()
+	// The shell prints the prompt when the program has exited
+	if t.pendingCwd != "" {
+		t.syncCwd(t.pendingCwd)
+	}
+}
+
+// escape applies the escape sequence at the head of the text, and returns its
+// length; it reports false if the sequence is not complete yet
+func (t *Terminal) escape(text string) (int, bool) {
+	if len(text) < 2 {
+		return 0, false
+	}
+	switch text[1] {
+	case '[':
+		// CSI: parameters and a final byte in 0x40-0x7e
+		for i := 2; i < len(text); i++ {
+			if text[i] >= 0x40 && text[i] <= 0x7e {
+				if text[i] == 'm' {
+					t.sgr(text[2:i])
+				}
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case ']':
+		// OSC: terminated by BEL or ST
+		for i := 2; i < len(text); i++ {
+			if text[i] == '\a' {
+				t.osc(text[2:i])
+				return i + 1, true
+			}
+			if text[i] == '\x1b' && i+1 < len(text) && text[i+1] == '\\' {
+				t.osc(text[2:i])
+				return i + 2, true
+			}
+		}
+		return 0, false
+	default:
+		return 2, true
+	}
+}
+
+// osc takes the cwd of the shell from OSC 7 (file://host/path), which the
+// shells configured for it report on every prompt
+func (t *Terminal) osc(params string) {
+	if !strings.HasPrefix(params, "7;") {
+		return
+	}
+	u, err := url.Parse(params[2:])
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return
+	}
+	t.cwd = filepath.FromSlash(u.Path)
+}
+
+// sgr sets the format of the following text by the parameters of SGR
+func (t *Terminal) sgr(params string) {
+	if params == "" {
+		params = "0"
+	}
+	for _, p := range strings.Split(params, ";") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			t.format = gui.NewQTextCharFormat()
+		case n == 1:
+			t.format.SetFontWeight(int(gui.QFont__Bold))
+		case n == 22:
+			t.format.SetFontWeight(int(gui.QFont__Normal))
+		case n == 39:
+			t.format.ClearForeground()
+		case n >= 30 && n <= 37:
+			t.format.SetForeground(gui.NewQBrush3(gui.NewQColor6(terminalColors[n-30]), core.Qt__SolidPattern))
+		case n >= 90 && n <= 97:
+			t.format.SetForeground(gui.NewQBrush3(gui.NewQColor6(terminalColors[n-90+8]), core.Qt__SolidPattern))
+		}
+	}
+}
+
+// keyPress sends the keys to the shell, except the copy and paste keys of the GUI
+func (t *Terminal) keyPress(event *gui.QKeyEvent) {
+	mod := event.Modifiers()
+	ctrlShift := core.Qt__ControlModifier | core.Qt__ShiftModifier
+	if mod&ctrlShift == ctrlShift && core.Qt__Key(event.Key()) == core.Qt__Key_C {
+		t.widget.Copy()
+		return
+	}
+	if mod&ctrlShift == ctrlShift && core.Qt__Key(event.Key()) == core.Qt__Key_V {
+		t.send(gui.QGuiApplication_Clipboard().Text(gui.QClipboard__Clipboard))
+		return
+	}
+	if core.Qt__Key(event.Key()) == core.Qt__Key_Escape && mod&core.Qt__ControlModifier != 0 {
+		// Back to the editor
+		editor.wsWidget.SetFocus2()
+		return
+	}
+
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_Return, core.Qt__Key_Enter:
+		t.send("\r")
+	case core.Qt__Key_Backspace:
+		t.send("\x7f")
+	case core.Qt__Key_Tab:
+		t.send("\t")
+	case core.Qt__Key_Up:
+		t.send("\x1b[A")
+	case core.Qt__Key_Down:
+		t.send("\x1b[B")
+	case core.Qt__Key_Right:
+		t.send("\x1b[C")
+	case core.Qt__Key_Left:
+		t.send("\x1b[D")
+	case core.Qt__Key_Home:
+		t.send("\x1b[H")
+	case core.Qt__Key_End:
+		t.send("\x1b[F")
+	case core.Qt__Key_Delete:
+		t.send("\x1b[3~")
+	default:
+		key := event.Key()
+		if mod&core.Qt__ControlModifier != 0 && key >= int(core.Qt__Key_A) && key <= int(core.Qt__Key_Z) {
+			t.send(string(rune(key - int(core.Qt__Key_A) + 1)))
+			return
+		}
+		t.send(event.Text())
+	}
+}
+
+func (t *Terminal) send(text string) {
+	if t.proc == nil || text == "" {
+		return
+	}
+	t.widget.MoveCursor(gui.QTextCursor__End, gui.QTextCursor__MoveAnchor)
+	if termLocalEcho {
+		t.output(strings.NewReplacer("\r", "\r\n", "\x7f", "\b").Replace(text))
+	}
+	select {
+	case t.input <- []byte(text):
+	case <-t.proc.done:
+	}
+}
+
+// size returns the columns and rows which fit in the widget
+func (t *Terminal) size() (int, int) {
+	font := t.ws.font
+	cols := int(float64(t.widget.Viewport().Width()) / font.truewidth)
+	rows := t.widget.Viewport().Height() / font.height
+	if cols < 20 {
+		cols = 20
+	}
+	if rows < 2 {
+		rows = 2
+	}
+	return cols, rows
+}
+
+func (t *Terminal) resize() {
+	if t.proc == nil {
+		return
+	}
+	t.proc.resize(t.size())
+}
+
+// syncCwd changes the directory of the shell when the cwd of the workspace is
+// changed. The cd command is typed only while the shell waits for a command,
+// not into the program running in it; otherwise it is done after the program.
+// On Windows it is never known, so the directory is not changed.
+func (t *Terminal) syncCwd(cwd string) {
+	t.pendingCwd = ""
+	if t.proc == nil || cwd == "" || cwd == t.cwd || runtime.GOOS == "windows" {
+		return
+	}
+	if !t.proc.idle() {
+		t.pendingCwd = cwd
+		return
+	}
+	t.cwd = cwd
+	t.send(fmt.Sprintf("cd '%s'\r", strings.Replace(cwd, "'", `'\''`, -1)))
+}
@@ -0,0 +1,75 @@
+// +build !windows
+
+package editor
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	// The pty of the terminal; the only dependency of it, not used on Windows
+	"github.com/creack/pty"
+)
+
+// The pty echoes the input
+const termLocalEcho = false
+
+// termProcess is the shell of the terminal, running in a pty
+type termProcess struct {
+	cmd  *exec.Cmd
+	pty  *os.File
+	done chan struct{}
+}
+
+func startTermProcess(shell, dir string, env []string, cols, rows int) (*termProcess, error) {
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	cmd.Env = append(env, "TERM=dumb", "GONEOVIM_TERMINAL=1")
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &termProcess{
+		cmd:  cmd,
+		pty:  f,
+		done: make(chan struct{}),
+	}, nil
+}
+
+func (p *termProcess) Read(b []byte) (int, error) {
+	return p.pty.Read(b)
+}
+
+func (p *termProcess) Write(b []byte) (int, error) {
+	return p.pty.Write(b)
+}
+
+func (p *termProcess) resize(cols, rows int) {
+	pty.Setsize(p.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// idle reports whether the shell waits for a command, i.e. it is the
+// foreground process group of the pty, in which a program would read the input
+func (p *termProcess) idle() bool {
+	if p.cmd.Process == nil {
+		return false
+	}
+	var pgrp int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, p.pty.Fd(), uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	if errno != 0 {
+		return false
+	}
+
+	return int(pgrp) == p.cmd.Process.Pid
+}
+
+func (p *termProcess) Close() {
+	close(p.done)
+	p.pty.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+		p.cmd.Wait()
+	}
+}
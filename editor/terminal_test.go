@@ -0,0 +1,32 @@
+package editor
+
+import (
+	"testing"
+)
+
+func TestUTF8Complete(t *testing.T) {
+	euro := []byte("€")  // 3 bytes
+	emoji := []byte("😀") // 4 bytes
+	tests := []struct {
+		name string
+		b    []byte
+		want int
+	}{
+		{"empty", nil, 0},
+		{"ascii", []byte("abc"), 3},
+		{"complete", append([]byte("a"), euro...), 4},
+		{"first byte", append([]byte("a"), euro[:1]...), 1},
+		{"two of three bytes", append([]byte("a"), euro[:2]...), 1},
+		{"three of four bytes", append([]byte("ab"), emoji[:3]...), 2},
+		{"complete four bytes", emoji, 4},
+		{"invalid byte", []byte{'a', 0xff}, 2},
+		{"stray continuation bytes", []byte{'a', 0x80, 0x80, 0x80, 0x80}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utf8Complete(tt.b); got != tt.want {
+				t.Errorf("utf8Complete(%q) = %d, want %d", tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// +build windows
+
+package editor
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+)
+
+// The shell does not echo the input without a pty
+const termLocalEcho = true
+
+// termProcess is the shell of the terminal. There is no pty on Windows, the
+// shell runs with pipes, so it does not know the size of the terminal.
+type termProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	output *io.PipeReader
+	done   chan struct{}
+}
+
+func startTermProcess(shell, dir string, env []string, cols, rows int) (*termProcess, error) {
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	cmd.Env = append(env, "GONEOVIM_TERMINAL=1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		cmd.Wait()
+		w.Close()
+	}()
+
+	return &termProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		output: r,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+func (p *termProcess) Read(b []byte) (int, error) {
+	return p.output.Read(b)
+}
+
+func (p *termProcess) Write(b []byte) (int, error) {
+	// The shell reads the lines ended by CRLF
+	return p.stdin.Write(bytes.Replace(b, []byte("\r"), []byte("\r\n"), -1))
+}
+
+func (p *termProcess) resize(cols, rows int) {
+}
+
+// idle reports false; whether the shell runs a program is not known without a pty
+func (p *termProcess) idle() bool {
+	return false
+}
+
+func (p *termProcess) Close() {
+	close(p.done)
+	p.stdin.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+}
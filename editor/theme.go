@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A theme is a toml file in ~/.goneovim/themes/, named by the file name without
+// the extension. It sets the colors of the GUI instead of deriving them from the
+// foreground and background of nvim. The keys are the entries of the ColorPalette:
+//
+//	fg = "#d8dee9"
+//	bg = "#2e3440"
+//	sideBarFg = "#d8dee9"
+//	sideBarBg = "#3b4252"
+//	scrollBarFg = "#4c566a"
+//	tablineFg = "#616e88"
+//	tablineActiveFg = "#eceff4"
+//	tablineAccent = "#88c0d0"
+//	statuslineFg = "#e5e9f0"
+//	statuslineBg = "#3b4252"
+//
+// The entries not in the theme are derived as before. [colors] of the config
+// overrides the theme in the same way.
+
+// entries maps the lower case names of the palette entries to the entries
+func (c *ColorPalette) entries() map[string]**RGBA {
+	return map[string]**RGBA{
+		"fg":                    &c.fg,
+		"bg":                    &c.bg,
+		"inactivefg":            &c.inactiveFg,
+		"comment":               &c.comment,
+		"abyss":                 &c.abyss,
+		"matchfg":               &c.matchFg,
+		"selectedbg":            &c.selectedBg,
+		"sidebarfg":             &c.sideBarFg,
+		"sidebarbg":             &c.sideBarBg,
+		"sidebarselecteditembg": &c.sideBarSelectedItemBg,
+		"scrollbarfg":           &c.scrollBarFg,
+		"scrollbarbg":           &c.scrollBarBg,
+		"widgetfg":              &c.widgetFg,
+		"widgetbg":              &c.widgetBg,
+		"widgetinputarea":       &c.widgetInputArea,
+		"minimapcurrentregion":  &c.minimapCurrentRegion,
+		"windowseparator":       &c.windowSeparator,
+		"indentguide":           &c.indentGuide,
+		"indentguidescope":      &c.indentGuideScope,
+		"tablinefg":             &c.tablineFg,
+		"tablineactivefg":       &c.tablineActiveFg,
+		"tablineaccent":         &c.tablineAccent,
+		"statuslinefg":          &c.statuslineFg,
+		"statuslinebg":          &c.statuslineBg,
+	}
+}
+
+// parseColors converts the hex colors keyed by the palette entry names. The
+// bad entries are skipped, and returned in the error.
+func parseColors(colors map[string]string) (map[string]*RGBA, error) {
+	entries := (&ColorPalette{}).entries()
+	parsed := make(map[string]*RGBA)
+	var bad []string
+	for name, hex := range colors {
+		key := strings.ToLower(name)
+		if _, ok := entries[key]; !ok {
+			bad = append(bad, fmt.Sprintf("unknown color %s", name))
+			continue
+		}
+		rgba := hexToRGBA(hex)
+		if rgba == nil {
+			bad = append(bad, fmt.Sprintf("invalid color %s = %s", name, hex))
+			continue
+		}
+		parsed[key] = rgba
+	}
+	if len(bad) > 0 {
+		sort.Strings(bad)
+		return parsed, errors.New(strings.Join(bad, ", "))
+	}
+
+	return parsed, nil
+}
+
+// applyColors sets the palette entries to the colors
+func (c *ColorPalette) applyColors(colors map[string]*RGBA) {
+	if len(colors) == 0 {
+		return
+	}
+	entries := c.entries()
+	for key, rgba := range colors {
+		*entries[key] = rgba.copy()
+	}
+}
+
+// builtinThemes are used if there is no theme file of the same name
+var builtinThemes = map[string]map[string]string{
+	"high-contrast": {
+		"fg":                    "#ffffff",
+		"bg":                    "#000000",
+		"inactiveFg":            "#d0d0d0",
+		"comment":               "#c0c0c0",
+		"abyss":                 "#000000",
+		"matchFg":               "#ffd700",
+		"selectedBg":            "#1a3a6e",
+		"sideBarFg":             "#ffffff",
+		"sideBarBg":             "#000000",
+		"sideBarSelectedItemBg": "#1a3a6e",
+		"scrollBarFg":           "#ffffff",
+		"scrollBarBg":           "#000000",
+		"widgetFg":              "#ffffff",
+		"widgetBg":              "#0a0a0a",
+		"widgetInputArea":       "#1e1e1e",
+		"minimapCurrentRegion":  "#333333",
+		"windowSeparator":       "#ffffff",
+		"indentGuide":           "#808080",
+		"indentGuideScope":      "#ffd700",
+		"tablineFg":             "#d0d0d0",
+		"tablineActiveFg":       "#ffffff",
+		"tablineAccent":         "#ffd700",
+		"statuslineFg":          "#ffffff",
+		"statuslineBg":          "#000000",
+	},
+}
+
+func (e *Editor) loadTheme(name string) (map[string]*RGBA, error) {
+	var colors map[string]string
+	path := filepath.Join(e.homeDir, ".goneovim", "themes", name+".toml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if builtin, ok := builtinThemes[name]; ok {
+			return parseColors(builtin)
+		}
+	}
+	if _, err := toml.DecodeFile(path, &colors); err != nil {
+		return nil, err
+	}
+
+	return parseColors(colors)
+}
+
+// setTheme switches to the theme, or back to the derived colors if name is empty
+func (e *Editor) setTheme(name string) {
+	if name == "" {
+		e.themeColors = nil
+	} else {
+		colors, err := e.loadTheme(name)
+		if err != nil {
+			e.pushNotification(NotifyWarn, -1, fmt.Sprintf("Failed to load the theme %s: %s", name, err))
+		}
+		if colors == nil {
+			return
+		}
+		e.themeColors = colors
+	}
+	e.config.Editor.Theme = name
+	e.colors.update()
+	e.updateGUIColor()
+}
@@ -0,0 +1,49 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseColors(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors map[string]string
+		want   map[string]*RGBA
+		err    string
+	}{
+		{
+			name:   "entry names are case insensitive",
+			colors: map[string]string{"MatchFg": "#ff0000", "widgetbg": "black"},
+			want: map[string]*RGBA{
+				"matchfg":  {R: 255, G: 0, B: 0, A: 1},
+				"widgetbg": {R: 0, G: 0, B: 0, A: 1},
+			},
+		},
+		{
+			name:   "bad entries skipped",
+			colors: map[string]string{"matchfg": "#00ff00", "nosuchentry": "#ffffff", "widgetfg": "#12"},
+			want:   map[string]*RGBA{"matchfg": {R: 0, G: 255, B: 0, A: 1}},
+			err:    "invalid color widgetfg = #12, unknown color nosuchentry",
+		},
+		{
+			name: "empty",
+			want: map[string]*RGBA{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseColors(tt.colors)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			errText := ""
+			if err != nil {
+				errText = err.Error()
+			}
+			if errText != tt.err {
+				t.Errorf("error: got %q, want %q", errText, tt.err)
+			}
+		})
+	}
+}
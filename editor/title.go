@@ -0,0 +1,56 @@
+package editor
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// setTitle sets the title of the frameless titlebar and the window
+func (e *Editor) setTitle(title string) {
+	e.window.SetupTitle(title)
+	if !e.isFrameless() {
+		e.window.SetWindowTitle(title)
+	}
+}
+
+// updateTitle renders Editor.TitleFormat with the state of the active workspace
+func (e *Editor) updateTitle() {
+	if e.config.Editor.TitleFormat == "" {
+		return
+	}
+	if e.active >= len(e.workspaces) {
+		return
+	}
+	w := e.workspaces[e.active]
+	filename := filepath.Base(w.titleFile)
+	if w.titleFile == "" {
+		filename = "[No Name]"
+	}
+	modified := ""
+	if w.modified {
+		modified = "[+]"
+	}
+	title := strings.NewReplacer(
+		"{filename}", filename,
+		"{filepath}", w.titleFile,
+		"{modified}", modified,
+		"{cwd}", w.cwdlabel,
+		"{workspace}", strconv.Itoa(e.active+1),
+	).Replace(e.config.Editor.TitleFormat)
+
+	e.setTitle(strings.TrimSpace(title))
+}
+
+func (w *Workspace) setTitleInfo(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	w.titleFile, _ = args[0].(string)
+	w.modified = util.IsTrue(args[1])
+	if w == editor.workspaces[editor.active] {
+		editor.updateTitle()
+	}
+}
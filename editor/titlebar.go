@@ -0,0 +1,127 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// titlebarButtonLabels are the buttons which can be put in titlebarButtons
+var titlebarButtonLabels = map[string]string{
+	"minimize":  "─",
+	"maximize":  "□",
+	"close":     "✕",
+	"workspace": "+",
+	"settings":  "⚙",
+	"theme":     "◐",
+}
+
+// TitlebarButtons is the buttons of titlebarButtons in the frameless titlebar,
+// which replace the buttons of the window drawn by the frameless window
+type TitlebarButtons struct {
+	widget  *widgets.QWidget
+	buttons []*widgets.QToolButton
+	left    bool
+}
+
+func (e *Editor) initTitlebarButtons() {
+	// The titlebar is left untouched unless the buttons are set
+	if !e.isFrameless() || e.window.TitleBar == nil || len(e.config.Editor.TitlebarButtons) == 0 {
+		return
+	}
+	b := &TitlebarButtons{
+		widget: widgets.NewQWidget(nil, 0),
+		left:   e.config.Editor.TitlebarButtonsSide == "left",
+	}
+	if e.config.Editor.TitlebarButtonsSide == "" {
+		b.left = runtime.GOOS == "darwin"
+	}
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(4, 0, 4, 0)
+	layout.SetSpacing(2)
+	b.widget.SetLayout(layout)
+
+	for _, name := range e.config.Editor.TitlebarButtons {
+		label, ok := titlebarButtonLabels[name]
+		if !ok {
+			fmt.Println("unknown titlebar button:", name)
+			continue
+		}
+		name := name
+		button := widgets.NewQToolButton(nil)
+		button.SetObjectName(name)
+		button.SetText(label)
+		button.SetToolTip(name)
+		button.SetFocusPolicy(core.Qt__NoFocus)
+		button.SetAutoRaise(true)
+		button.ConnectClicked(func(bool) {
+			e.titlebarAction(name)
+		})
+		layout.AddWidget(button, 0, 0)
+		b.buttons = append(b.buttons, button)
+	}
+
+	titlebar := widgets.NewQHBoxLayoutFromPointer(e.window.TitleBar.Layout().Pointer())
+	// Hide the buttons of the frameless window; the title is the only label in the titlebar
+	for i := 0; i < titlebar.Count(); i++ {
+		widget := titlebar.ItemAt(i).Widget()
+		if widget == nil || widget.Pointer() == nil {
+			continue
+		}
+		if widget.MetaObject().ClassName() != "QLabel" {
+			widget.Hide()
+		}
+	}
+	if b.left {
+		titlebar.InsertWidget(0, b.widget, 0, 0)
+	} else {
+		titlebar.AddWidget(b.widget, 0, 0)
+	}
+	e.titlebarButtons = b
+}
+
+func (e *Editor) titlebarAction(name string) {
+	switch name {
+	case "minimize":
+		e.window.ShowMinimized()
+	case "maximize":
+		if e.window.IsMaximized() {
+			e.window.ShowNormal()
+		} else {
+			e.window.WindowMaximize()
+		}
+	case "close":
+		e.window.Close()
+	case "workspace":
+		e.workspaceNew()
+	case "settings":
+		e.openPath(filepath.Join(e.homeDir, ".goneovim", "setting.toml"), 0, 0)
+	case "theme":
+		e.toggleAppearance()
+	}
+}
+
+// isLeft reports whether the buttons are on the left of the titlebar, as on macOS
+func (b *TitlebarButtons) isLeft() bool {
+	return b != nil && b.left
+}
+
+func (b *TitlebarButtons) setColor() {
+	if b == nil {
+		return
+	}
+	fg := editor.colors.fg
+	bg := editor.colors.bg
+	if fg == nil || bg == nil {
+		return
+	}
+	b.widget.SetStyleSheet(fmt.Sprintf(`
+	QWidget { background: transparent; }
+	QToolButton { color: %s; border: 0px; padding: 2px 8px; }
+	QToolButton:hover { color: %s; background-color: %s; }
+	QToolButton#close:hover { color: #ffffff; background-color: %s; }
+	`, editor.colors.inactiveFg.String(), fg.String(), editor.colors.selectedBg.String(), newRGBA(204, 62, 68, 1).String()))
+}
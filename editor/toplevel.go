@@ -0,0 +1,132 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Each additional top-level window is hosted by its own goneovim process started with --window,
+// which keeps the focus, key routing and workspaces of the windows independent.
+
+// sessionDir returns the directory to save the sessions of this window
+func (e *Editor) sessionDir() string {
+	sessions := filepath.Join(e.homeDir, ".goneovim", "sessions")
+	if e.opts.Window > 0 {
+		sessions = filepath.Join(sessions, fmt.Sprintf("window%d", e.opts.Window))
+	}
+	if e.opts.Profile != "" {
+		sessions = filepath.Join(sessions, "profile-"+e.opts.Profile)
+	}
+	return sessions
+}
+
+func windowSocketPath(home string, index int) string {
+	return filepath.Join(home, ".goneovim", fmt.Sprintf("window%d.sock", index))
+}
+
+func isWindowRunning(home string, index int) bool {
+	conn, err := net.Dial("unix", windowSocketPath(home, index))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// claimWindow marks the window index as being used by this process
+func (e *Editor) claimWindow() {
+	if e.opts.Window <= 0 {
+		return
+	}
+	path := windowSocketPath(e.homeDir, e.opts.Window)
+	os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return
+	}
+	go func() {
+		<-e.stop
+		listener.Close()
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+}
+
+// newWindow opens a new top-level window
+func (e *Editor) newWindow() {
+	index := 1
+	for isWindowRunning(e.homeDir, index) || index == e.opts.Window {
+		index++
+	}
+	// Start with an empty session
+	os.RemoveAll(filepath.Join(e.homeDir, ".goneovim", "sessions", fmt.Sprintf("window%d", index)))
+	e.startWindowProcess(index)
+}
+
+func (e *Editor) startWindowProcess(index int) {
+	exe, err := os.Executable()
+	if err != nil {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+	args := []string{"--window", strconv.Itoa(index)}
+	if e.opts.Nvim != "" {
+		args = append(args, "--nvim", e.opts.Nvim)
+	}
+	cmd := exec.Command(exe, args...)
+	err = cmd.Start()
+	if err != nil {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+	go cmd.Wait()
+}
+
+// restoreWindows reopens the additional windows saved in the previous session
+func (e *Editor) restoreWindows() {
+	if e.opts.Window > 0 || !e.config.Workspace.RestoreSession || e.opts.Clean {
+		return
+	}
+	root := filepath.Join(e.homeDir, ".goneovim", "sessions")
+	dirs, err := ioutil.ReadDir(root)
+	if err != nil {
+		return
+	}
+	var indexes []int
+	for _, dir := range dirs {
+		if !dir.IsDir() || !strings.HasPrefix(dir.Name(), "window") {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(dir.Name(), "window"))
+		if err != nil || index <= 0 || isWindowRunning(e.homeDir, index) {
+			continue
+		}
+		path := filepath.Join(root, dir.Name())
+		// The window was closed without workspaces; the directory is removed
+		// unless something else, e.g. a profile, is left in it
+		if sessions, _ := filepath.Glob(filepath.Join(path, "*.vim")); len(sessions) == 0 {
+			os.Remove(sessionLockPath(path))
+			os.Remove(path)
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		e.startWindowProcess(index)
+	}
+}
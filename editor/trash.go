@@ -0,0 +1,151 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+// TrashedItem is a file moved to the trash, which can be put back
+type TrashedItem struct {
+	original string
+	trashed  string
+	info     string
+}
+
+// moveToTrash moves the file or directory to the trash / recycle bin
+func moveToTrash(path string) (*TrashedItem, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return nil, err
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		return moveToRecycleBin(path)
+	case "darwin":
+		return moveToMacTrash(path)
+	default:
+		return moveToXDGTrash(path)
+	}
+}
+
+func uniqueTrashPath(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := filepath.Join(dir, name)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s %d%s", base, i, ext))
+	}
+}
+
+// moveToXDGTrash follows the FreeDesktop.org Trash specification
+func moveToXDGTrash(path string) (*TrashedItem, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(editor.homeDir, ".local", "share")
+	}
+	filesDir := filepath.Join(dataHome, "Trash", "files")
+	infoDir := filepath.Join(dataHome, "Trash", "info")
+	os.MkdirAll(filesDir, 0700)
+	os.MkdirAll(infoDir, 0700)
+
+	trashed := uniqueTrashPath(filesDir, filepath.Base(path))
+	info := filepath.Join(infoDir, filepath.Base(trashed)+".trashinfo")
+	content := fmt.Sprintf(
+		"[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: path}).EscapedPath(),
+		time.Now().Format("2006-01-02T15:04:05"),
+	)
+	if err := ioutil.WriteFile(info, []byte(content), 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path, trashed); err != nil {
+		os.Remove(info)
+		// The home trash can not be used across filesystems
+		cmd := exec.Command("gio", "trash", path)
+		if gioErr := cmd.Run(); gioErr != nil {
+			return nil, err
+		}
+		return &TrashedItem{original: path}, nil
+	}
+
+	return &TrashedItem{original: path, trashed: trashed, info: info}, nil
+}
+
+// moveToMacTrash deletes the file by Finder, which moves it to the trash of
+// its volume and lets it be put back from the trash
+func moveToMacTrash(path string) (*TrashedItem, error) {
+	script := `on run argv
+tell application "Finder" to set trashed to delete (POSIX file (item 1 of argv) as alias)
+return POSIX path of (trashed as alias)
+end run`
+	out, err := exec.Command("osascript", "-e", script, path).Output()
+	if err != nil {
+		return nil, err
+	}
+	trashed := strings.TrimSuffix(strings.TrimSpace(string(out)), "/")
+
+	return &TrashedItem{original: path, trashed: trashed}, nil
+}
+
+func moveToRecycleBin(path string) (*TrashedItem, error) {
+	method := "DeleteFile"
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		method = "DeleteDirectory"
+	}
+	script := fmt.Sprintf(
+		`Add-Type -AssemblyName Microsoft.VisualBasic; [Microsoft.VisualBasic.FileIO.FileSystem]::%s('%s', 'OnlyErrorDialogs', 'SendToRecycleBin')`,
+		method,
+		strings.Replace(path, "'", "''", -1),
+	)
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+	util.PrepareRunProc(cmd)
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return &TrashedItem{original: path}, nil
+}
+
+// restore puts the trashed item back to the original location
+func (t *TrashedItem) restore() error {
+	if runtime.GOOS == "windows" {
+		script := fmt.Sprintf(
+			`$bin = (New-Object -ComObject Shell.Application).NameSpace(10); $bin.Items() | Where-Object { (Join-Path $bin.GetDetailsOf($_, 1) $_.Name) -eq '%s' } | Select-Object -First 1 | ForEach-Object { $_.InvokeVerb('undelete') }`,
+			strings.Replace(t.original, "'", "''", -1),
+		)
+		cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+		util.PrepareRunProc(cmd)
+		return cmd.Run()
+	}
+	if t.trashed == "" {
+		return errors.New("the item can not be restored from the trash")
+	}
+	if _, err := os.Lstat(t.original); err == nil {
+		return fmt.Errorf("%s already exists", t.original)
+	}
+	if err := os.Rename(t.trashed, t.original); err != nil {
+		return err
+	}
+	if t.info != "" {
+		os.Remove(t.info)
+	}
+
+	return nil
+}
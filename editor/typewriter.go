@@ -0,0 +1,15 @@
+package editor
+
+// setTypewriter turns the typewriter scrolling of the workspace on or off,
+// which keeps the cursor line at the center of the window. The scroll is
+// animated unless in power saving mode.
+func (w *Workspace) setTypewriter(enable bool) {
+	w.typewriter = enable
+	animate := editor.config.Editor.TypewriterAnimation && editor.animationDuration(1) > 0
+	go w.execLua("require('gonvim').typewriter(...)", enable, animate)
+}
+
+// toggleTypewriter handles gonvim_typewriter
+func (w *Workspace) toggleTypewriter() {
+	w.setTypewriter(!w.typewriter)
+}
@@ -0,0 +1,70 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/widgets"
+)
+
+const uiCallback = "require('gonvim')._ui_callback(...)"
+
+// uiSelect shows the list of the items of vim.ui.select, and passes the 1-based index
+// of the selected item, or nil if cancelled, to the callback
+func (w *Workspace) uiSelect(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	id := util.ReflectToInt(args[0])
+	prompt, _ := args[1].(string)
+	var labels []string
+	if list, ok := args[2].([]interface{}); ok {
+		for _, l := range list {
+			label, _ := l.(string)
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		go w.execLua(uiCallback, id, nil)
+		return
+	}
+
+	dialog := widgets.NewQInputDialog(editor.window, 0)
+	dialog.SetWindowTitle("Goneovim")
+	dialog.SetLabelText(prompt)
+	dialog.SetComboBoxItems(labels)
+	dialog.SetOption(widgets.QInputDialog__UseListViewForComboBoxItems, true)
+
+	var index interface{}
+	if dialog.Exec() == int(widgets.QDialog__Accepted) {
+		selected := dialog.TextValue()
+		for i, label := range labels {
+			if label == selected {
+				index = i + 1
+				break
+			}
+		}
+	}
+	go w.execLua(uiCallback, id, index)
+}
+
+// uiInput shows the input dialog of vim.ui.input, and passes the text,
+// or nil if cancelled, to the callback
+func (w *Workspace) uiInput(args []interface{}) {
+	if len(args) < 3 {
+		return
+	}
+	id := util.ReflectToInt(args[0])
+	prompt, _ := args[1].(string)
+	text, _ := args[2].(string)
+
+	dialog := widgets.NewQInputDialog(editor.window, 0)
+	dialog.SetWindowTitle("Goneovim")
+	dialog.SetInputMode(widgets.QInputDialog__TextInput)
+	dialog.SetLabelText(prompt)
+	dialog.SetTextValue(text)
+
+	var input interface{}
+	if dialog.Exec() == int(widgets.QDialog__Accepted) {
+		input = dialog.TextValue()
+	}
+	go w.execLua(uiCallback, id, input)
+}
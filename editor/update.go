@@ -0,0 +1,131 @@
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+const latestReleaseURL = "https://api.github.com/repos/akiyosi/goneovim/releases/latest"
+
+// Release is the part of the GitHub release used by the update check
+type Release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// checkUpdate notifies the newer release if Editor.CheckUpdate is enabled.
+// The binary is not replaced by itself, since a release is an archive bundling Qt.
+func (e *Editor) checkUpdate() {
+	if !e.config.Editor.CheckUpdate {
+		return
+	}
+	go func() {
+		release, err := fetchLatestRelease()
+		if err != nil {
+			return
+		}
+		if compareVersion(release.TagName, e.version) <= 0 {
+			return
+		}
+		download := release.downloadURL()
+		buttons := []*NotifyButton{}
+		buttons = append(buttons, &NotifyButton{
+			action: func() {
+				openURLInBrowser(download)
+			},
+			text: "Download",
+		})
+		buttons = append(buttons, &NotifyButton{
+			action: func() {
+				openURLInBrowser(release.HTMLURL)
+			},
+			text: "Changelog",
+		})
+		e.pushNotification(NotifyInfo, 0, fmt.Sprintf("[Gonvim] Goneovim %s is available (current: %s).", release.TagName, e.version), notifyOptionArg(buttons))
+	}()
+}
+
+func fetchLatestRelease() (*Release, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(latestReleaseURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	release := &Release{}
+	err = json.NewDecoder(resp.Body).Decode(release)
+	if err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// downloadURL is the archive for this platform, or the release page if there is none
+func (r *Release) downloadURL() string {
+	platform := runtime.GOOS
+	if platform == "darwin" {
+		platform = "macos"
+	}
+	for _, asset := range r.Assets {
+		if strings.Contains(strings.ToLower(asset.Name), platform) {
+			return asset.BrowserDownloadURL
+		}
+	}
+
+	return r.HTMLURL
+}
+
+// compareVersion compares "v1.2.3" style versions, ignoring the pre-release suffix
+func compareVersion(a, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var nums []int
+		for _, part := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(part)
+			nums = append(nums, n)
+		}
+		return nums
+	}
+	va := parse(a)
+	vb := parse(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	return 0
+}
+
+func openURLInBrowser(url string) {
+	gui.QDesktopServices_OpenUrl(core.NewQUrl3(url, core.QUrl__TolerantMode))
+}
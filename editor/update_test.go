@@ -0,0 +1,27 @@
+package editor
+
+import (
+	"testing"
+)
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.6.0", "v0.6.0", 0},
+		{"0.6.0", "v0.6.0", 0},
+		{"v0.6.1", "v0.6.0", 1},
+		{"v0.6.0", "v0.10.0", -1},
+		{"v1.0.0", "v0.99.99", 1},
+		{"v0.6", "v0.6.0", 0},
+		{"v0.6.0.1", "v0.6.0", 1},
+		{"v0.6.0-rc1", "v0.6.0", 0},
+		{"v0.6.0+build.5", "v0.6.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersion(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+const urlScheme = "gonvim"
+
+// GonvimURL is a parsed gonvim:// link such as gonvim://open?file=/path/to/file&line=42
+type GonvimURL struct {
+	file   string
+	line   int
+	column int
+}
+
+func parseGonvimURL(s string) (*GonvimURL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != urlScheme {
+		return nil, fmt.Errorf("not a %s:// url: %s", urlScheme, s)
+	}
+	if u.Host != "open" {
+		return nil, fmt.Errorf("unknown action: %s", u.Host)
+	}
+	q := u.Query()
+	g := &GonvimURL{
+		file: q.Get("file"),
+	}
+	if g.file == "" {
+		return nil, errors.New("no file is specified")
+	}
+	// The file is passed to nvim as an argument on a cold start
+	if strings.HasPrefix(g.file, "-") || strings.HasPrefix(g.file, "+") {
+		return nil, fmt.Errorf("invalid file: %s", g.file)
+	}
+	g.line, _ = strconv.Atoi(q.Get("line"))
+	g.column, _ = strconv.Atoi(q.Get("column"))
+
+	return g, nil
+}
+
+// nvimArgs appends the command line arguments to open the url location at
+// nvim startup. The file follows "--", so that it is never taken as an option
+// or a command.
+func (g *GonvimURL) nvimArgs(args []string) []string {
+	for _, arg := range args {
+		// The rest of the arguments are already files
+		if arg == "--" {
+			return append(args, g.file)
+		}
+	}
+	if g.line > 0 {
+		args = append(args, fmt.Sprintf("+%d", g.line))
+	}
+	return append(args, "--", g.file)
+}
+
+// pathToURL returns the gonvim:// link to open the path in the running instance
+func pathToURL(path string) string {
+	return fmt.Sprintf("%s://open?file=%s", urlScheme, url.QueryEscape(path))
+}
+
+// splitURLArg picks up a gonvim:// url from the positional arguments
+func splitURLArg(args []string) (string, []string) {
+	var rest []string
+	urlArg := ""
+	for _, arg := range args {
+		if urlArg == "" && strings.HasPrefix(arg, urlScheme+"://") {
+			urlArg = arg
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	return urlArg, rest
+}
+
+func urlSocketPath(home string) string {
+	return filepath.Join(home, ".goneovim", "goneovim.sock")
+}
+
+// sendURLToRunningInstance passes the url to an already running goneovim, if any
+func sendURLToRunningInstance(home, urlArg string) bool {
+	conn, err := net.DialTimeout("unix", urlSocketPath(home), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	_, err = fmt.Fprintln(conn, urlArg)
+
+	return err == nil
+}
+
+// initURLServer accepts urls passed from other goneovim processes
+func (e *Editor) initURLServer() {
+	path := urlSocketPath(e.homeDir)
+	// Remove the socket left behind by a crashed process
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	e.signal.ConnectOpenURLSignal(func() {
+		e.openURL(<-e.openURLs)
+	})
+
+	go func() {
+		<-e.stop
+		listener.Close()
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				e.openURLs <- scanner.Text()
+				e.signal.OpenURLSignal()
+			}
+			conn.Close()
+		}
+	}()
+}
+
+// openURL focuses the window and jumps to the location of the url
+func (e *Editor) openURL(s string) {
+	g, err := parseGonvimURL(s)
+	if err != nil {
+		e.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+
+	e.window.Show()
+	e.window.Raise()
+	e.window.ActivateWindow()
+
+	e.openPath(g.file, g.line, g.column)
+}
+
+// registerURLScheme associates gonvim:// links with this executable for the current user
+func registerURLScheme() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "windows":
+		key := `HKCU\Software\Classes\` + urlScheme
+		cmds := [][]string{
+			{"reg", "add", key, "/ve", "/d", "URL:Goneovim Protocol", "/f"},
+			{"reg", "add", key, "/v", "URL Protocol", "/d", "", "/f"},
+			{"reg", "add", key + `\shell\open\command`, "/ve", "/d", fmt.Sprintf(`"%s" "%%1"`, exe), "/f"},
+		}
+		for _, c := range cmds {
+			cmd := exec.Command(c[0], c[1:]...)
+			util.PrepareRunProc(cmd)
+			if err := cmd.Run(); err != nil {
+				return err
+			}
+		}
+	case "darwin":
+		// The scheme is declared with CFBundleURLTypes in Info.plist
+		fmt.Println("gonvim:// is registered when goneovim.app is placed in /Applications")
+	default:
+		return exec.Command("xdg-mime", "default", "goneovim.desktop", "x-scheme-handler/"+urlScheme).Run()
+	}
+
+	return nil
+}
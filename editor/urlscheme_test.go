@@ -0,0 +1,52 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGonvimURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want *GonvimURL
+	}{
+		{"gonvim://open?file=/tmp/a.go", &GonvimURL{file: "/tmp/a.go"}},
+		{"gonvim://open?file=%2Ftmp%2Fa%20b.go&line=42&column=7", &GonvimURL{file: "/tmp/a b.go", line: 42, column: 7}},
+		{"gonvim://open?file=a.go&line=x", &GonvimURL{file: "a.go"}},
+		{"gonvim://open", nil},
+		{"gonvim://edit?file=a.go", nil},
+		{"vscode://open?file=a.go", nil},
+		{"gonvim://open?file=%2B!touch%20/tmp/x", nil},
+		{"gonvim://open?file=--cmd", nil},
+	}
+	for _, tt := range tests {
+		got, err := parseGonvimURL(tt.url)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("parseGonvimURL(%q) = %+v, want an error", tt.url, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGonvimURL(%q) = %+v, %v, want %+v", tt.url, got, err, tt.want)
+		}
+	}
+}
+
+func TestGonvimURLNvimArgs(t *testing.T) {
+	tests := []struct {
+		url  *GonvimURL
+		args []string
+		want []string
+	}{
+		{&GonvimURL{file: "a.go"}, nil, []string{"--", "a.go"}},
+		{&GonvimURL{file: "a.go", line: 3}, []string{"-n"}, []string{"-n", "+3", "--", "a.go"}},
+		{&GonvimURL{file: "a.go", line: 3}, []string{"--", "b.go"}, []string{"--", "b.go", "a.go"}},
+	}
+	for _, tt := range tests {
+		got := tt.url.nvimArgs(tt.args)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nvimArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
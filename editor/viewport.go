@@ -0,0 +1,142 @@
+package editor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akiyosi/goneovim/util"
+)
+
+const viewportsLen = 1000
+
+// Viewport is the cursor and the scroll position of a buffer, restored when
+// the buffer is opened again, also after a restart
+type Viewport struct {
+	lnum    int
+	col     int
+	topline int
+	leftcol int
+	used    time.Time
+}
+
+func viewportsPath(home string) string {
+	return filepath.Join(home, ".goneovim", "viewports")
+}
+
+// loadViewports reads the lines of "path<Tab>lnum<Tab>col<Tab>topline<Tab>leftcol<Tab>unix time"
+func (e *Editor) loadViewports() {
+	e.viewports = make(map[string]*Viewport)
+	if !e.config.Editor.RestoreViewport || e.opts.Clean {
+		return
+	}
+	bytes, err := ioutil.ReadFile(viewportsPath(e.homeDir))
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(bytes), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 6 {
+			continue
+		}
+		var values [5]int
+		for i := range values {
+			values[i], _ = strconv.Atoi(fields[i+1])
+		}
+		e.viewports[fields[0]] = &Viewport{
+			lnum:    values[0],
+			col:     values[1],
+			topline: values[2],
+			leftcol: values[3],
+			used:    time.Unix(int64(values[4]), 0),
+		}
+	}
+}
+
+// saveViewports writes the most recently used viewports
+func (e *Editor) saveViewports() {
+	if !e.config.Editor.RestoreViewport || e.opts.Clean || len(e.viewports) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(e.viewports))
+	for path := range e.viewports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return e.viewports[paths[i]].used.After(e.viewports[paths[j]].used)
+	})
+	if len(paths) > viewportsLen {
+		paths = paths[:viewportsLen]
+	}
+	var lines []string
+	for _, path := range paths {
+		v := e.viewports[path]
+		lines = append(lines, fmt.Sprintf("%s\t%d\t%d\t%d\t%d\t%d", path, v.lnum, v.col, v.topline, v.leftcol, v.used.Unix()))
+	}
+	path := viewportsPath(e.homeDir)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// setViewport handles gonvim_viewport; the path and the view of the buffer
+func (e *Editor) setViewport(args []interface{}) {
+	if len(args) < 2 || e.viewports == nil {
+		return
+	}
+	path, _ := args[0].(string)
+	view, ok := args[1].(map[string]interface{})
+	if path == "" || strings.Contains(path, "\t") || !ok {
+		return
+	}
+	e.viewports[path] = &Viewport{
+		lnum:    util.ReflectToInt(view["lnum"]),
+		col:     util.ReflectToInt(view["col"]),
+		topline: util.ReflectToInt(view["topline"]),
+		leftcol: util.ReflectToInt(view["leftcol"]),
+		used:    time.Now(),
+	}
+}
+
+// restoreViewport handles gonvim_viewport_request; the path of the buffer and the window
+func (w *Workspace) restoreViewport(args []interface{}) {
+	if len(args) < 2 || editor.viewports == nil {
+		return
+	}
+	path, _ := args[0].(string)
+	v, ok := editor.viewports[path]
+	if !ok || v.lnum <= 1 {
+		return
+	}
+	view := map[string]int{
+		"lnum":    v.lnum,
+		"col":     v.col,
+		"topline": v.topline,
+		"leftcol": v.leftcol,
+	}
+	go w.execLua("require('gonvim').viewport_restore(...)", util.ReflectToInt(args[1]), path, view)
+}
+
+// switchViewports saves the views of the windows of the workspace which is
+// hidden, and restores the ones of the workspace shown again
+func (e *Editor) switchViewports() {
+	if !e.config.Editor.RestoreViewport || e.active >= len(e.workspaces) {
+		return
+	}
+	ws := e.workspaces[e.active]
+	prev := e.shownWorkspace
+	e.shownWorkspace = ws
+	if prev == ws {
+		return
+	}
+	if prev != nil && prev.nvim != nil {
+		go prev.execLua("require('gonvim').viewport_save_all()")
+	}
+	if prev != nil && ws.nvim != nil {
+		go ws.execLua("require('gonvim').viewport_restore_all()")
+	}
+}
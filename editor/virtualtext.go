@@ -0,0 +1,155 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// virtualTextRun is a run of the cells of a row drawn with the highlight groups
+// of a source of virtual text in [virtualText] of the config
+type virtualTextRun struct {
+	start int
+	end   int
+	style virtualTextConfig
+}
+
+func virtualTextStyle(hlName string) (virtualTextConfig, bool) {
+	if hlName == "" {
+		return virtualTextConfig{}, false
+	}
+	for _, style := range editor.config.VirtualText {
+		if style.Highlight != "" && strings.HasPrefix(hlName, style.Highlight) {
+			return style, true
+		}
+	}
+	return virtualTextConfig{}, false
+}
+
+// virtualTextRuns finds the runs of the virtual text on the row, without the
+// spaces around the text
+func (w *Window) virtualTextRuns(y int) []virtualTextRun {
+	line := w.content[y]
+	var runs []virtualTextRun
+	for x := 0; x < len(line); x++ {
+		if line[x] == nil {
+			continue
+		}
+		style, ok := virtualTextStyle(line[x].highlight.hlName)
+		if !ok {
+			continue
+		}
+		run := virtualTextRun{start: x, style: style}
+		for x < len(line) && line[x] != nil && line[x].highlight.hlName != "" {
+			if s, ok := virtualTextStyle(line[x].highlight.hlName); !ok || s != style {
+				break
+			}
+			x++
+		}
+		run.end = x
+		x--
+		for run.start < run.end && isBlankCell(line[run.start]) {
+			run.start++
+		}
+		for run.end > run.start && isBlankCell(line[run.end-1]) {
+			run.end--
+		}
+		if run.end > run.start {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+func isBlankCell(cell *Cell) bool {
+	return cell == nil || cell.char == " "
+}
+
+// drawVirtualText redraws the virtual text of the row in the style of its source
+func (w *Window) drawVirtualText(p *gui.QPainter, y int) {
+	if y >= len(w.content) || w.isMsgGrid {
+		return
+	}
+	line := w.content[y]
+	runs := w.virtualTextRuns(y)
+	if len(runs) == 0 {
+		return
+	}
+	font := w.getFont()
+	top := float64(y * font.lineHeight)
+	lineHeight := float64(font.lineHeight)
+
+	for _, run := range runs {
+		style := run.style
+		if !style.Pill && style.Align != "right" && !style.Ellipsis {
+			continue
+		}
+		hl := line[run.start].highlight
+		var text strings.Builder
+		for x := run.start; x < run.end; x++ {
+			text.WriteString(line[x].char)
+		}
+		width := run.end - run.start
+
+		// The text reaching the edge of the window has been cut by nvim
+		str := text.String()
+		if style.Ellipsis && run.end >= w.cols {
+			runes := []rune(str)
+			if len(runes) > 1 {
+				str = string(runes[:len(runes)-1]) + "…"
+			}
+		}
+
+		target := run.start
+		if style.Align == "right" {
+			blank := true
+			for x := run.end; x < len(line); x++ {
+				if !isBlankCell(line[x]) {
+					blank = false
+					break
+				}
+			}
+			if r := w.cols - width - 1; blank && r > run.start {
+				target = r
+			}
+		}
+
+		// Clear the text drawn by nvim with the background of the row before it
+		rowBg := w.background
+		if rowBg == nil {
+			rowBg = editor.colors.bg
+		}
+		if run.start > 0 && line[run.start-1] != nil {
+			rowBg = line[run.start-1].highlight.bg()
+		}
+		p.FillRect4(
+			core.NewQRectF4(float64(run.start)*font.truewidth, top, float64(width)*font.truewidth, lineHeight),
+			rowBg.QColor(),
+		)
+
+		x := float64(target) * font.truewidth
+		if style.Pill {
+			var color *gui.QColor
+			if hl.background != nil && !hl.background.equals(rowBg) {
+				color = hl.background.QColor()
+			} else {
+				color = hl.fg().QColor()
+				color.SetAlphaF(0.15)
+			}
+			pad := font.truewidth / 3
+			p.Save()
+			p.SetRenderHint(gui.QPainter__Antialiasing, true)
+			p.SetPen3(core.Qt__NoPen)
+			p.SetBrush(gui.NewQBrush3(color, core.Qt__SolidPattern))
+			p.DrawRoundedRect(
+				core.NewQRectF4(x-pad, top+1, float64(width)*font.truewidth+2*pad, lineHeight-2),
+				lineHeight/2-1, lineHeight/2-1, core.Qt__AbsoluteSize,
+			)
+			p.Restore()
+		}
+		p.SetFont(font.fontNew)
+		p.SetPen2(hl.fg().QColor())
+		p.DrawText(core.NewQPointF3(x, top+float64(font.shift)), str)
+	}
+}
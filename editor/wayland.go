@@ -0,0 +1,52 @@
+package editor
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// isWayland reports whether goneovim runs as a native Wayland client
+func isWayland() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	platform := os.Getenv("QT_QPA_PLATFORM")
+	if platform != "" {
+		return strings.HasPrefix(platform, "wayland")
+	}
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// putWaylandEnv prefers the native Wayland platform plugin over XWayland
+func putWaylandEnv() {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return
+	}
+	// Respect the platform explicitly specified by the user
+	if os.Getenv("QT_QPA_PLATFORM") == "" {
+		_ = os.Setenv("QT_QPA_PLATFORM", "wayland;xcb")
+	}
+	// Apply the fractional scale of each output as is
+	if os.Getenv("QT_SCALE_FACTOR_ROUNDING_POLICY") == "" {
+		_ = os.Setenv("QT_SCALE_FACTOR_ROUNDING_POLICY", "PassThrough")
+	}
+}
+
+// setPrimarySelection copies the text to the primary selection for middle-click pasting
+func setPrimarySelection(text string) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	var cmd *exec.Cmd
+	if isWayland() {
+		cmd = exec.Command("wl-copy", "--primary")
+	} else if _, err := exec.LookPath("xclip"); err == nil {
+		cmd = exec.Command("xclip", "-in", "-selection", "primary")
+	} else {
+		cmd = exec.Command("xsel", "--input", "--primary")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Run()
+}
@@ -0,0 +1,60 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+// windowFont handles gonvim_window_font; the window (0: the current one) and
+// the guifont string ("": the font of the workspace). The font of a window
+// which is not drawn yet is set when its position is known.
+func (s *Screen) windowFont(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	id := nvim.Window(util.ReflectToInt(args[0]))
+	font, _ := args[1].(string)
+	if id == 0 {
+		win, ok := s.getWindow(s.ws.cursor.gridid)
+		if !ok {
+			return
+		}
+		s.setWindowFont(win, font)
+		return
+	}
+
+	var win *Window
+	s.windows.Range(func(_, v interface{}) bool {
+		w, ok := v.(*Window)
+		if ok && w != nil && w.id == id && w.grid != 1 && !w.isMsgGrid {
+			win = w
+			return false
+		}
+		return true
+	})
+	if win == nil {
+		if s.pendingFonts == nil {
+			s.pendingFonts = make(map[nvim.Window]string)
+		}
+		s.pendingFonts[id] = font
+		return
+	}
+	s.setWindowFont(win, font)
+}
+
+// applyPendingFont sets the font requested before the window was drawn
+func (s *Screen) applyPendingFont(win *Window) {
+	font, ok := s.pendingFonts[win.id]
+	if !ok {
+		return
+	}
+	delete(s.pendingFonts, win.id)
+	s.setWindowFont(win, font)
+}
+
+// setFiletypeFonts sets the fonts of the filetypes of [editor] filetypeFonts
+func (w *Workspace) setFiletypeFonts() {
+	for filetype, font := range editor.config.Editor.FiletypeFonts {
+		w.execLua("require('gonvim').font.set_filetype(...)", filetype, font)
+	}
+}
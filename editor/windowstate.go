@@ -0,0 +1,73 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+func (e *Editor) toggleAlwaysOnTop() {
+	e.alwaysOnTop = !e.alwaysOnTop
+	e.window.SetWindowFlag(core.Qt__WindowStaysOnTopHint, e.alwaysOnTop)
+	// Changing window flags hides the window
+	e.window.Show()
+	if e.alwaysOnTopAction != nil {
+		e.alwaysOnTopAction.SetChecked(e.alwaysOnTop)
+	}
+}
+
+// setOpacity fades the window opacity to the value in 0.1 - 1.0
+func (e *Editor) setOpacity(opacity float64) {
+	if opacity < 0.1 {
+		opacity = 0.1
+	}
+	if opacity > 1.0 {
+		opacity = 1.0
+	}
+	animation := core.NewQPropertyAnimation2(e.window, core.NewQByteArray2("windowOpacity", len("windowOpacity")), e.window)
+	animation.SetDuration(e.animationDuration(200))
+	animation.SetStartValue(core.NewQVariant12(e.opacity))
+	animation.SetEndValue(core.NewQVariant12(opacity))
+	animation.Start(core.QAbstractAnimation__DeleteWhenStopped)
+	e.opacity = opacity
+}
+
+func (e *Editor) guiOpacity(arg interface{}) {
+	var opacity float64
+	var err error
+	switch a := arg.(type) {
+	case string:
+		opacity, err = strconv.ParseFloat(a, 64)
+		if err != nil {
+			return
+		}
+	default:
+		s := fmt.Sprintf("%v", a)
+		opacity, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return
+		}
+	}
+	e.setOpacity(opacity)
+}
+
+// addWindowStateActions adds the always-on-top and opacity actions to the menu
+func (e *Editor) addWindowStateActions(menu *widgets.QMenu) {
+	e.alwaysOnTopAction = menu.AddAction("Always on Top")
+	e.alwaysOnTopAction.SetCheckable(true)
+	e.alwaysOnTopAction.SetChecked(e.alwaysOnTop)
+	e.alwaysOnTopAction.ConnectTriggered(func(bool) {
+		e.toggleAlwaysOnTop()
+	})
+
+	opacityMenu := menu.AddMenu2("Opacity")
+	for _, o := range []float64{1.0, 0.9, 0.8, 0.7, 0.5} {
+		opacity := o
+		action := opacityMenu.AddAction(fmt.Sprintf("%d%%", int(opacity*100)))
+		action.ConnectTriggered(func(bool) {
+			e.setOpacity(opacity)
+		})
+	}
+}
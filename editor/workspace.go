@@ -12,6 +12,7 @@ import (
 
 	"github.com/akiyosi/goneovim/filer"
 	"github.com/akiyosi/goneovim/fuzzy"
+	"github.com/akiyosi/goneovim/grid"
 	"github.com/akiyosi/goneovim/util"
 	shortpath "github.com/akiyosi/short_path"
 	"github.com/neovim/go-client/nvim"
@@ -53,27 +54,82 @@ type Workspace struct {
 	signature  *Signature
 	message    *Message
 	minimap    *MiniMap
+	debug      *DebugPanel
+	git        *GitPopup
+	diff       *DiffView
+	conflicts  *Conflicts
+	swatches   *ColorSwatches
+
+	foldPreview *FoldPreview
+	msgline     *MsgLine
+	dashboard   *Dashboard
+	terminal    *Terminal
+	tasks       *TaskPanel
+	quickfix    *QuickfixPanel
+	breadcrumbs *Breadcrumbs
+	peek        *Peek
+	extensions  *ExtensionPanel
+	rpcStatus   *RPCStatusPanel
+	searchBar   *SearchBar
+	findBar     *FindBar
+	screenArea  *widgets.QWidget
+	typewriter  bool
+	activity    string
 
 	width  int
 	height int
 	hidden bool
 
-	nvim               *nvim.Nvim
-	rows               int
-	cols               int
-	uiAttached         bool
-	uiRemoteAttached   bool
-	screenbg           string
-	colorscheme        string
-	foreground         *RGBA
-	background         *RGBA
-	special            *RGBA
-	mode               string
-	modeIdx            int
-	filepath           string
-	cwd                string
-	cwdBase            string
-	cwdlabel           string
+	nvim             *nvim.Nvim
+	rows             int
+	cols             int
+	uiAttached       bool
+	uiRemoteAttached bool
+	screenbg         string
+	colorscheme      string
+	foreground       *RGBA
+	background       *RGBA
+	special          *RGBA
+	mode             string
+	modeIdx          int
+	filepath         string
+	cwd              string
+	cwdBase          string
+	cwdlabel         string
+	titleFile        string
+	modified         bool
+
+	screenUpdatePending bool
+	lastScreenUpdate    time.Time
+
+	exiting       bool
+	crashed       bool
+	listedBuffers []string
+	// The redraw events are replayed from --replay-redraw instead
+	replaying bool
+	// The redraw events are recorded to the file of --record-redraw
+	redrawRecorder *RedrawRecorder
+
+	restartSession string
+	// The nvim is started again with -u NONE when it exits
+	cleanRestart bool
+	cleanConfig  bool
+	// The tail of the stderr of the embedded nvim, shown if it exits on the startup
+	nvimStderr *stderrBuffer
+	// VimEnter has been sent by the nvim, after the startup
+	entered bool
+
+	capability *Capability
+
+	recording *Recording
+
+	accessibility *Accessibility
+
+	zoomLevel    int
+	zoomBaseSize float64
+	zoomOverlay  *widgets.QWidget
+	zoomTimer    *core.QTimer
+
 	maxLine            int
 	curLine            int
 	curColm            int
@@ -90,15 +146,32 @@ type Workspace struct {
 
 	signal        *workspaceSignal
 	redrawUpdates chan [][]interface{}
+	pendingRedraw [][]interface{}
+	shadowGrids   map[gridId]*grid.Grid
+	redrawMu      sync.Mutex
 	guiUpdates    chan []interface{}
 	doneNvimStart chan bool
 	stopOnce      sync.Once
 	stop          chan struct{}
+	nvimPid       int
 	fontMutex     sync.Mutex
 
 	drawStatusline bool
 	drawTabline    bool
 	drawLint       bool
+
+	// The directory to change to on VimEnter, set by openFolder
+	folder string
+
+	// The events received from nvim, for the RPC status panel
+	redrawStats *RPCStats
+	guiStats    *RPCStats
+
+	// The environment variables of [env] for the project in envDir, set to nvim
+	env    map[string]string
+	envDir string
+	// The tasks of .gonvim/tasks.toml of the project in envDir
+	projectTasks map[string]string
 }
 
 func newWorkspace(path string) (*Workspace, error) {
@@ -111,6 +184,8 @@ func newWorkspace(path string) (*Workspace, error) {
 		foreground:    newRGBA(180, 185, 190, 1),
 		background:    newRGBA(9, 13, 17, 1),
 		special:       newRGBA(255, 255, 255, 1),
+		redrawStats:   newRPCStats(),
+		guiStats:      newRPCStats(),
 	}
 	w.font = initFontNew(editor.extFontFamily, float64(editor.extFontSize), editor.config.Editor.Linespace, true)
 	go func() {
@@ -162,6 +237,7 @@ func newWorkspace(path string) (*Workspace, error) {
 	w.markdown.webview.SetParent(w.screen.widget)
 	w.cursor = initCursorNew()
 	w.cursor.ws = w
+	w.initAccessibility()
 	w.popup = initPopupmenuNew()
 	w.popup.widget.SetParent(editor.wsWidget)
 	w.popup.ws = w
@@ -174,6 +250,23 @@ func newWorkspace(path string) (*Workspace, error) {
 	w.cmdline.ws = w
 	w.minimap = newMiniMap()
 	w.minimap.ws = w
+	w.debug = newDebugPanel(w)
+	w.git = newGitPopup(w)
+	w.diff = newDiffView(w)
+	w.conflicts = newConflicts(w)
+	w.swatches = newColorSwatches(w)
+	w.foldPreview = newFoldPreview(w)
+	w.msgline = newMsgLine(w)
+	w.dashboard = newDashboard(w)
+	w.terminal = newTerminal(w)
+	w.tasks = newTaskPanel(w)
+	w.quickfix = newQuickfixPanel(w)
+	w.breadcrumbs = newBreadcrumbs(w)
+	w.peek = newPeek(w)
+	w.extensions = newExtensionPanel(w)
+	w.rpcStatus = newRPCStatusPanel(w)
+	w.searchBar = newSearchBar(w)
+	w.findBar = newFindBar(w)
 
 	layout := widgets.NewQVBoxLayout()
 	w.widget = widgets.NewQWidget(nil, 0)
@@ -186,6 +279,8 @@ func newWorkspace(path string) (*Workspace, error) {
 
 	// screen widget and scrollBar widget
 	scrWidget := widgets.NewQWidget(nil, 0)
+	scrWidget.SetObjectName("screenArea")
+	scrWidget.SetAttribute(core.Qt__WA_StyledBackground, true)
 	scrWidget.SetContentsMargins(0, 0, 0, 0)
 	scrWidget.SetAttribute(core.Qt__WA_OpaquePaintEvent, true)
 	scrLayout := widgets.NewQHBoxLayout()
@@ -194,13 +289,34 @@ func newWorkspace(path string) (*Workspace, error) {
 	scrLayout.AddWidget(w.screen.widget, 0, 0)
 	scrLayout.AddWidget(w.minimap.widget, 0, 0)
 	scrLayout.AddWidget(w.scrollBar.widget, 0, 0)
+	scrLayout.AddWidget(w.diff.gutter, 0, 0)
 	scrWidget.SetLayout(scrLayout)
+	w.screenArea = scrWidget
 
 	layout.AddWidget(w.tabline.widget, 0, 0)
+	layout.AddWidget(w.breadcrumbs.widget, 0, 0)
+	layout.AddWidget(w.findBar.widget, 0, 0)
 	layout.AddWidget(scrWidget, 1, 0)
+	layout.AddWidget(w.debug.widget, 0, 0)
+	layout.AddWidget(w.quickfix.widget, 0, 0)
+	layout.AddWidget(w.tasks.widget, 0, 0)
+	layout.AddWidget(w.extensions.widget, 0, 0)
+	layout.AddWidget(w.rpcStatus.widget, 0, 0)
+	layout.AddWidget(w.terminal.widget, 0, 0)
+	layout.AddWidget(w.searchBar.widget, 0, 0)
 	layout.AddWidget(w.statusline.widget, 0, 0)
 	layout.SetContentsMargins(0, 0, 0, 0)
 	layout.SetSpacing(0)
+	// The rows of nvim are changed when a panel below the screen is shown or
+	// hidden, after the visibility of the panel has been updated
+	for _, panel := range w.bottomPanels() {
+		panel.ConnectShowEvent(func(event *gui.QShowEvent) {
+			core.QTimer_SingleShot(0, w.updateSize)
+		})
+		panel.ConnectHideEvent(func(event *gui.QHideEvent) {
+			core.QTimer_SingleShot(0, w.updateSize)
+		})
+	}
 
 	w.popup.widget.Hide()
 	w.palette.hide()
@@ -211,6 +327,9 @@ func newWorkspace(path string) (*Workspace, error) {
 	w.widget.SetParent(editor.wsWidget)
 	w.widget.Move2(0, 0)
 	w.updateSize()
+	if editor.extensions != nil {
+		editor.extensions.attach(w)
+	}
 
 	if !w.uiRemoteAttached {
 		go func() {
@@ -230,7 +349,13 @@ func newWorkspace(path string) (*Workspace, error) {
 
 func (w *Workspace) registerSignal() {
 	w.signal.ConnectRedrawSignal(func() {
-		updates := <-w.redrawUpdates
+		// The batches posted before this signal may have been taken by the previous one
+		updates := w.takeRedraw()
+		if len(updates) == 0 {
+			return
+		}
+		defer editor.perf.measure("redraw", time.Now())
+		editor.perf.countBatch(len(updates))
 		w.handleRedraw(updates)
 	})
 	w.signal.ConnectGuiSignal(func() {
@@ -238,9 +363,32 @@ func (w *Workspace) registerSignal() {
 		w.handleRPCGui(updates)
 	})
 	w.signal.ConnectStopSignal(func() {
+		if w.restartSession != "" {
+			w.finishRestart()
+			return
+		}
+		if w.cleanRestart {
+			w.cleanRestart = false
+			w.respawnNvim("", nil)
+			return
+		}
+		if w.isNvimCrashed() {
+			if !w.entered {
+				w.reportStartupFailure()
+				return
+			}
+			w.notifyNvimCrash()
+			return
+		}
 		if !w.uiRemoteAttached {
 			editor.workspaces[editor.active].minimap.exit()
 		}
+		w.reapNvim()
+		w.stopLspProgress()
+		w.git.worker.stop()
+		w.terminal.close()
+		w.tasks.kill()
+		w.redrawRecorder.close()
 		workspaces := []*Workspace{}
 		index := 0
 		for i, ws := range editor.workspaces {
@@ -254,10 +402,10 @@ func (w *Workspace) registerSignal() {
 			editor.close()
 			return
 		}
-		for i := 0; i <= len(editor.wsSide.items) && i <= len(editor.workspaces); i++ {
-			if i >= index {
-				editor.wsSide.items[i].cwdpath = editor.wsSide.items[i+1].cwdpath
-			}
+		// The items are not added yet if the sidebar has not been initialized
+		items := editor.wsSide.items
+		for i := index; i < len(items)-1 && i < len(editor.workspaces); i++ {
+			items[i].cwdpath = items[i+1].cwdpath
 		}
 		editor.workspaces = workspaces
 		w.hide()
@@ -291,39 +439,56 @@ func (w *Workspace) startNvim(path string) error {
 	var neovim *nvim.Nvim
 	var err error
 
-	childProcessArgs := nvim.ChildProcessArgs(
-		append([]string{
-			"--cmd",
-			"let g:gonvim_running=1",
-			"--embed",
-		}, editor.args...)...,
-	)
+	childProcessArgs := append([]string{
+		"--cmd",
+		"let g:gonvim_running=1",
+		"--embed",
+	}, w.nvimArgs()...)
 	if editor.opts.Server != "" {
 		// Attaching to remote nvim session
 		neovim, err = nvim.Dial(editor.opts.Server)
 		w.uiRemoteAttached = true
 	} else if editor.opts.Nvim != "" {
 		// Attaching to /path/to/nvim
-		childProcessCmd := nvim.ChildProcessCommand(editor.opts.Nvim)
-		neovim, err = nvim.NewChildProcess(childProcessArgs, childProcessCmd)
+		neovim, err = w.spawnNvim(editor.opts.Nvim, childProcessArgs)
 	} else {
 		// Attaching to nvim normaly
-		neovim, err = nvim.NewChildProcess(childProcessArgs)
+		neovim, err = w.spawnNvim("nvim", childProcessArgs)
 	}
 	if err != nil {
 		return err
 	}
 	w.nvim = neovim
+	editor.markStartup("nvim spawn")
 	w.nvim.RegisterHandler("Gui", func(updates ...interface{}) {
+		event, _ := updates[0].(string)
+		w.guiStats.count(event, 1)
+		// Set here, since the stop signal may be handled before the gui signal
+		if event == "gonvim_exit" {
+			w.exiting = true
+		}
 		w.guiUpdates <- updates
 		w.signal.GuiSignal()
 	})
+	// The errors of the notifications sent by the GUI
+	w.nvim.RegisterHandler("nvim_error_event", func(kind int, message string) {
+		w.guiStats.setError(message)
+	})
+	if w.redrawRecorder == nil {
+		w.redrawRecorder = editor.newRedrawRecorder()
+	}
 	w.nvim.RegisterHandler("redraw", func(updates ...[]interface{}) {
-		w.redrawUpdates <- updates
-		w.signal.RedrawSignal()
+		if w.redrawRecorder != nil {
+			w.redrawRecorder.record(updates)
+		}
+		if w.replaying {
+			return
+		}
+		w.queueRedraw(updates)
 	})
 
 	go func() {
+		defer editor.handleCrash()
 		err := w.nvim.Serve()
 		if err != nil {
 			fmt.Println(err)
@@ -347,6 +512,7 @@ func (w *Workspace) init(path string) {
 	w.configure()
 	w.attachUI(path)
 	w.loadGinitVim()
+	w.applyAppearance()
 	w.getNvimOptions()
 }
 
@@ -368,6 +534,8 @@ func (w *Workspace) configure() {
 
 func (w *Workspace) attachUI(path string) error {
 	w.nvim.Subscribe("Gui")
+	// Set before initGonvim, which loads the lua module by the API level
+	w.capability = w.getCapability()
 	go w.initGonvim()
 	w.tabline.subscribe()
 	w.statusline.subscribe()
@@ -382,7 +550,12 @@ func (w *Workspace) attachUI(path string) error {
 	err := w.nvim.AttachUI(w.cols, w.rows, w.attachUIOption())
 	if err != nil {
 		fmt.Println(err)
-		editor.close()
+		select {
+		case <-w.stop:
+			// The nvim which has exited on the startup is reported by the stop signal
+		case <-time.After(time.Second):
+			editor.close()
+		}
 		return err
 	}
 	if path != "" {
@@ -393,18 +566,31 @@ func (w *Workspace) attachUI(path string) error {
 }
 
 func (w *Workspace) initGonvim() {
+	w.watchNvim()
+	// The autocmds and the settings below require the lua module, which is
+	// not loaded before API level 5
+	luaLoaded := w.loadLuaModule()
 	gonvimAutoCmds := `
 	aug GonvimAu | au! | aug END
 	au GonvimAu VimEnter * call rpcnotify(1, "Gui", "gonvim_enter", getcwd())
 	au GonvimAu TermEnter * call rpcnotify(0, "Gui", "gonvim_termenter")
 	au GonvimAu TermLeave * call rpcnotify(0, "Gui", "gonvim_termleave")
+	au GonvimAu VimLeavePre * call rpcnotify(0, "Gui", "gonvim_exit")
+	aug GonvimAuBuffers | au! | aug END
+	au GonvimAuBuffers BufEnter,BufWritePost * call rpcnotify(0, "Gui", "gonvim_buffers", map(filter(getbufinfo({'buflisted': 1}), 'v:val.name != ""'), 'v:val.name'))
 	aug GonvimAuWorkspace | au! | aug END
 	au GonvimAuWorkspace DirChanged * call rpcnotify(0, "Gui", "gonvim_workspace_cwd", getcwd())
 	aug GonvimAuFilepath | au! | aug END
 	au GonvimAuFilepath BufEnter,TabEnter,DirChanged,TermOpen,TermClose * silent call rpcnotify(0, "Gui", "gonvim_workspace_filepath", expand("%:p"))
+	aug GonvimAuTitle | au! | aug END
+	au GonvimAuTitle BufEnter,BufWritePost,BufModifiedSet,DirChanged * silent call rpcnotify(0, "Gui", "gonvim_title", expand("%:p"), &modified)
+	aug GonvimAuRecent | au! | aug END
+	au GonvimAuRecent BufReadPost * silent call rpcnotify(0, "Gui", "gonvim_recent_file", expand("%:p"))
 	aug GonvimAuMd | au! | aug END
 	au GonvimAuMd TextChanged,TextChangedI *.md call rpcnotify(0, "Gui", "gonvim_markdown_update")
 	au GonvimAuMd BufEnter *.md call rpcnotify(0, "Gui", "gonvim_markdown_new_buffer")
+	aug GonvimAuColorScheme | au! | aug END
+	au GonvimAuColorScheme ColorScheme * call rpcnotify(0, "Gui", "gonvim_colorscheme", ` + paletteHighlightsExpr() + `)
 	`
 	if !w.uiRemoteAttached {
 		gonvimAutoCmds = gonvimAutoCmds + `
@@ -418,7 +604,7 @@ func (w *Workspace) initGonvim() {
 	if editor.config.ScrollBar.Visible {
 		gonvimAutoCmds = gonvimAutoCmds + `
 	aug GonvimAuScrollbar | au! | aug END
-	au GonvimAuScrollbar TextChanged,TextChangedI,BufReadPost * call rpcnotify(0, "Gui", "gonvim_get_maxline", line("$"))
+	au GonvimAuScrollbar TextChanged,TextChangedI,BufReadPost,BufEnter * call rpcnotify(0, "Gui", "gonvim_get_maxline", line("$"))
 	`
 	}
 	if editor.config.Editor.Clipboard {
@@ -427,6 +613,105 @@ func (w *Workspace) initGonvim() {
 	au GonvimAuClipboard TextYankPost * call rpcnotify(0, "Gui", "gonvim_copy_clipboard")
 	`
 	}
+	if editor.config.Editor.IndentGuide && luaLoaded {
+		indentGuideEvents := "WinScrolled,TextChanged,TextChangedI,BufWinEnter,WinEnter,VimResized"
+		if editor.config.Editor.IndentGuideScope {
+			// The scope follows the cursor when it rests
+			indentGuideEvents += ",CursorHold,CursorHoldI"
+		}
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuIndentGuide | au! | aug END
+	au GonvimAuIndentGuide ` + indentGuideEvents + ` * lua require('gonvim').later('indent_guides')
+	au GonvimAuIndentGuide OptionSet shiftwidth,tabstop,wrap,foldenable lua require('gonvim').later('indent_guides')
+	`
+	}
+	if editor.config.Editor.Dashboard {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuDashboard | au! | aug END
+	au GonvimAuDashboard BufEnter,BufWinEnter,TextChanged,TermOpen * call rpcnotify(0, "Gui", "gonvim_dashboard", ` + dashboardEmptyExpr + `)
+	au GonvimAuDashboard InsertEnter,CmdwinEnter * call rpcnotify(0, "Gui", "gonvim_dashboard", 0)
+	`
+	}
+	if editor.config.Editor.ExtMessages {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuRecording | au! | aug END
+	silent! au GonvimAuRecording RecordingEnter * call rpcnotify(0, "Gui", "gonvim_recording", reg_recording())
+	silent! au GonvimAuRecording RecordingLeave * call rpcnotify(0, "Gui", "gonvim_recording", "")
+	`
+	}
+	if editor.config.Editor.Breadcrumbs && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuBreadcrumbs | au! | aug END
+	au GonvimAuBreadcrumbs BufEnter,CursorHold,CursorHoldI,BufWritePost,DirChanged * lua require('gonvim').breadcrumbs()
+	`
+	}
+	if editor.config.Editor.RestoreViewport && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuViewport | au! | aug END
+	au GonvimAuViewport BufLeave * lua require('gonvim').viewport_save()
+	au GonvimAuViewport BufWinEnter * lua require('gonvim').viewport_request()
+	au GonvimAuViewport VimLeavePre * lua require('gonvim').viewport_save_all()
+	`
+	}
+	if editor.config.Editor.SearchBar && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuSearch | au! | aug END
+	au GonvimAuSearch CursorMoved,CursorHold,CmdlineLeave,BufEnter * lua require('gonvim').search()
+	au GonvimAuSearch OptionSet hlsearch lua require('gonvim').search()
+	`
+	}
+	if editor.config.Editor.QuickfixPanel && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuQuickfix | au! | aug END
+	au GonvimAuQuickfix QuickFixCmdPost [^l]* lua require('gonvim').quickfix()
+	`
+	}
+	if editor.config.Editor.FoldColumn && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuFolds | au! | aug END
+	au GonvimAuFolds WinScrolled,TextChanged,BufWinEnter,WinEnter,VimResized,CursorHold * lua require('gonvim').later('folds')
+	au GonvimAuFolds OptionSet foldcolumn lua require('gonvim').later('folds')
+	`
+	}
+	if editor.config.Editor.WrapIndicators && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuWraps | au! | aug END
+	au GonvimAuWraps WinScrolled,TextChanged,TextChangedI,BufWinEnter,WinEnter,VimResized,CursorHold * lua require('gonvim').later('wraps')
+	au GonvimAuWraps OptionSet wrap,showbreak,number,relativenumber,signcolumn,foldcolumn lua require('gonvim').later('wraps')
+	`
+	}
+	if (editor.config.Editor.RainbowDelimiters || editor.config.Editor.PairGuides) && luaLoaded {
+		delimiterEvents := "WinScrolled,TextChanged,TextChangedI,BufWinEnter,WinEnter,VimResized"
+		if editor.config.Editor.PairGuides {
+			delimiterEvents += ",CursorMoved,CursorMovedI"
+		}
+		gonvimAutoCmds = gonvimAutoCmds + fmt.Sprintf(`
+	lua require('gonvim').setup_delimiters(%v, %v)`, editor.config.Editor.RainbowDelimiters, editor.config.Editor.PairGuides) + `
+	aug GonvimAuDelimiters | au! | aug END
+	au GonvimAuDelimiters ` + delimiterEvents + ` * lua require('gonvim').later('delimiters')
+	au GonvimAuDelimiters OptionSet wrap,number,relativenumber,signcolumn,foldcolumn lua require('gonvim').later('delimiters')
+	`
+	}
+	if editor.config.Editor.ActivityBadges && luaLoaded {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuActivity | au! | aug END
+	au GonvimAuActivity TermOpen * lua require('gonvim').activity_terminal(tonumber(vim.fn.expand('<abuf>')))
+	au GonvimAuActivity TermClose,QuickFixCmdPost * lua require('gonvim').activity_job()
+	lua if vim.fn.exists('##DiagnosticChanged') == 1 then vim.cmd([[au GonvimAuActivity DiagnosticChanged * lua require('gonvim').activity_diagnostics()]]) end
+	`
+	}
+	if editor.config.Editor.DiffConnectors || editor.config.Editor.DiffGutter {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuDiff | au! | aug END
+	au GonvimAuDiff DiffUpdated,BufEnter,WinEnter * call rpcnotify(0, "Gui", "gonvim_diff", &diff, &diff ? ` + diffMarksExpr + ` : [], line("$"))
+	`
+	}
+	if editor.config.Editor.GitBlameOnHover {
+		gonvimAutoCmds = gonvimAutoCmds + `
+	aug GonvimAuGitBlame | au! | aug END
+	au GonvimAuGitBlame CursorHold * if &buftype == "" && !&modified | call rpcnotify(0, "Gui", "gonvim_git_blame", expand("%:p"), line(".")) | endif
+	`
+	}
 	if editor.config.Statusline.Visible {
 		gonvimAutoCmds = gonvimAutoCmds + `
 	aug GonvimAuStatusline | au! | aug END
@@ -440,10 +725,49 @@ func (w *Workspace) initGonvim() {
 	gonvimCommands := fmt.Sprintf(`
 	command! GonvimSidebarShow call rpcnotify(0, "Gui", "side_open")
 	command! GonvimMarkdown call rpcnotify(0, "Gui", "gonvim_markdown_toggle")
+	command! GonvimOpen call rpcnotify(0, "Gui", "gonvim_open_dialog")
+	command! GonvimSaveAs call rpcnotify(0, "Gui", "gonvim_saveas_dialog")
+	command! GonvimFullscreen call rpcnotify(0, "Gui", "gonvim_fullscreen")
+	command! GonvimPresentation call rpcnotify(0, "Gui", "gonvim_presentation")
+	command! GonvimZen call rpcnotify(0, "Gui", "gonvim_zen")
+	command! GonvimTypewriter call rpcnotify(0, "Gui", "gonvim_typewriter")
+	command! GonvimAlwaysOnTop call rpcnotify(0, "Gui", "gonvim_always_on_top")
+	command! -nargs=1 GonvimOpacity call rpcnotify(0, "Gui", "gonvim_opacity", <args>)
+	command! -nargs=1 GonvimSnap call rpcnotify(0, "Gui", "gonvim_snap", <q-args>)
+	command! -nargs=1 GonvimProgress call rpcnotify(0, "Gui", "gonvim_progress", <args>)
+	command! -nargs=? GonvimBadge call rpcnotify(0, "Gui", "gonvim_badge", <q-args>)
+	command! -nargs=1 GonvimPowerSaving call rpcnotify(0, "Gui", "gonvim_power_saving", <q-args>)
+	command! GonvimNotifications call rpcnotify(0, "Gui", "gonvim_notifications")
+	command! -nargs=? GonvimDoNotDisturb call rpcnotify(0, "Gui", "gonvim_do_not_disturb", <q-args>)
+	command! -nargs=1 GonvimNotifyLevel call rpcnotify(0, "Gui", "gonvim_notify_level", <q-args>)
+	command! GonvimPerfHUD call rpcnotify(0, "Gui", "gonvim_perf_hud")
+	command! GonvimLatency call rpcnotify(0, "Gui", "gonvim_latency")
+	command! GonvimRPCStatus call rpcnotify(0, "Gui", "gonvim_rpc_status")
+	command! -bang -nargs=? -complete=file GonvimScreenshot call rpcnotify(0, "Gui", "gonvim_screenshot", <bang>0, <q-args>)
+	command! -bang -nargs=* -complete=file GonvimRecord call rpcnotify(0, "Gui", "gonvim_record", <bang>0, <f-args>)
+	command! -nargs=? GonvimZoom call rpcnotify(0, "Gui", "gonvim_zoom", <q-args>)
+	command! GonvimGitBlame call rpcnotify(0, "Gui", "gonvim_git_blame", expand("%:p"), line("."), &modified)
+	command! GonvimGitHunk call rpcnotify(0, "Gui", "gonvim_git_hunk", expand("%:p"), line("."), &modified)
+	command! -nargs=1 GonvimDapWatch lua require('gonvim').dap.watch(<q-args>)
+	command! -nargs=1 GonvimDapUnwatch lua require('gonvim').dap.unwatch(<q-args>)
+	command! -nargs=? -complete=dir GonvimOpenFolder call rpcnotify(0, "Gui", "gonvim_open_folder", <q-args>)
+	command! GonvimTerminal call rpcnotify(0, "Gui", "gonvim_terminal")
+	command! -nargs=? GonvimTask call rpcnotify(0, "Gui", "gonvim_task", <q-args>)
+	command! GonvimPeekDefinition lua require('gonvim').peek_definition()
+	command! GonvimQuickfix call rpcnotify(0, "Gui", "gonvim_quickfix_toggle")
+	command! GonvimSpellSuggest lua require('gonvim').spell_suggest()
+	command! GonvimExtensionPanel call rpcnotify(0, "Gui", "gonvim_extension_panel")
+	command! GonvimGotoLine call rpcnotify(0, "Gui", "gonvim_goto_line")
+	command! GonvimFind call rpcnotify(0, "Gui", "gonvim_find_bar", v:false)
+	command! GonvimReplace call rpcnotify(0, "Gui", "gonvim_find_bar", v:true)
+	command! GonvimDashboard call rpcnotify(0, "Gui", "gonvim_dashboard", 1, 1)
+	command! -nargs=? GonvimTheme call rpcnotify(0, "Gui", "gonvim_theme", <q-args>)
 	command! GonvimVersion echo "%s"`, editor.version)
 	if !w.uiRemoteAttached {
 		gonvimCommands = gonvimCommands + `
 	command! GonvimWorkspaceNew call rpcnotify(0, "Gui", "gonvim_workspace_new")
+	command! GonvimNewWindow call rpcnotify(0, "Gui", "gonvim_new_window")
+	command! -bang GonvimRestart call rpcnotify(0, "Gui", "gonvim_restart", <bang>0)
 	command! GonvimWorkspaceNext call rpcnotify(0, "Gui", "gonvim_workspace_next")
 	command! GonvimWorkspacePrevious call rpcnotify(0, "Gui", "gonvim_workspace_previous")
 	command! -nargs=1 GonvimWorkspaceSwitch call rpcnotify(0, "Gui", "gonvim_workspace_switch", <args>)
@@ -453,15 +777,34 @@ func (w *Workspace) initGonvim() {
 	}
 	registerScripts = fmt.Sprintf(`call execute(%s)`, util.SplitVimscript(gonvimCommands))
 	w.nvim.Command(registerScripts)
+	if editor.extensions != nil {
+		editor.extensions.registerCommands(w)
+	}
+
+	if editor.config.Editor.CmdheightZero {
+		w.nvim.Command("set cmdheight=0")
+	}
+	if editor.config.Editor.Typewriter && luaLoaded {
+		w.setTypewriter(true)
+	}
+	if luaLoaded {
+		w.setFiletypeFonts()
+	}
 
 	gonvimInitNotify := `
 	call rpcnotify(0, "statusline", "bufenter", expand("%:p"), &filetype, &fileencoding, &fileformat, &ro)
+	call rpcnotify(0, "Gui", "gonvim_colorscheme", ` + paletteHighlightsExpr() + `)
 	`
 	if !w.uiRemoteAttached {
 		gonvimInitNotify = gonvimInitNotify + `
 		call rpcnotify(0, "Gui", "gonvim_minimap_update")
 		`
 	}
+	if editor.config.Editor.Dashboard {
+		gonvimInitNotify = gonvimInitNotify + `
+		call rpcnotify(0, "Gui", "gonvim_dashboard", ` + dashboardEmptyExpr + `)
+		`
+	}
 	initialNotify := fmt.Sprintf(`call execute(%s)`, util.SplitVimscript(gonvimInitNotify))
 	w.nvim.Command(initialNotify)
 }
@@ -480,7 +823,7 @@ func (w *Workspace) getNvimOptions() {
 	w.ts = ts
 	w.getColorscheme()
 	screenbg := ""
-	w.nvim.Eval(":echo &background", &screenbg)
+	w.nvim.Option("background", &screenbg)
 	w.screenbg = screenbg
 	if w.screenbg == "light" {
 		fg := newRGBA(editor.colors.fg.R, editor.colors.fg.G, editor.colors.fg.B, 1)
@@ -558,8 +901,19 @@ func (w *Workspace) nvimEval(s string) (interface{}, error) {
 	}
 }
 
+// sideItem returns the sidebar item of this workspace, adding the items if they have not been added yet
+func (w *Workspace) sideItem() *WorkspaceSideItem {
+	editor.deferredInit()
+	return editor.wsSide.items[w.getNum()]
+}
+
 func (w *Workspace) setCwd(cwd string) {
 	w.cwd = cwd
+	w.updateEnv(cwd)
+	editor.addRecentWorkspace(cwd)
+	if w.terminal != nil {
+		w.terminal.syncCwd(cwd)
+	}
 	if editor.wsSide == nil {
 		return
 	}
@@ -577,6 +931,9 @@ func (w *Workspace) setCwd(cwd string) {
 	}
 	w.cwdlabel = labelpath
 	w.cwdBase = filepath.Base(cwd)
+	if editor.wsTabs != nil {
+		editor.wsTabs.update()
+	}
 	for i, ws := range editor.workspaces {
 		if i >= len(editor.wsSide.items) {
 			return
@@ -599,54 +956,53 @@ func (w *Workspace) setCwd(cwd string) {
 func (w *Workspace) attachUIOption() map[string]interface{} {
 	o := make(map[string]interface{})
 	o["rgb"] = true
-	// o["ext_multigrid"] = editor.config.Editor.ExtMultigrid
-	o["ext_multigrid"] = true
-	o["ext_hlstate"] = true
-
-	apiInfo, err := w.nvim.APIInfo()
-	if err == nil {
-		for _, item := range apiInfo {
-			i, ok := item.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			for k, v := range i {
-				if k != "ui_events" {
-					continue
-				}
-				events, ok := v.([]interface{})
-				if !ok {
-					continue
-				}
-				for _, event := range events {
-					function, ok := event.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					name, ok := function["name"]
-					if !ok {
-						continue
-					}
-
-					switch name {
-					// case "wildmenu_show" :
-					// 	o["ext_wildmenu"] = editor.config.Editor.ExtCmdline
-					case "cmdline_show":
-						o["ext_cmdline"] = editor.config.Editor.ExtCmdline
-					case "msg_show":
-						o["ext_messages"] = editor.config.Editor.ExtMessages
-					case "popupmenu_show":
-						o["ext_popupmenu"] = editor.config.Editor.ExtPopupmenu
-					case "tabline_update":
-						o["ext_tabline"] = editor.config.Editor.ExtTabline
-					}
-				}
-			}
+
+	requested := map[string]bool{
+		// "ext_multigrid": editor.config.Editor.ExtMultigrid,
+		"ext_multigrid": true,
+		"ext_hlstate":   true,
+		// "ext_wildmenu": editor.config.Editor.ExtCmdline,
+		"ext_cmdline":   editor.config.Editor.ExtCmdline,
+		"ext_messages":  editor.config.Editor.ExtMessages,
+		"ext_popupmenu": editor.config.Editor.ExtPopupmenu,
+		"ext_tabline":   editor.config.Editor.ExtTabline,
+	}
+	for option, enabled := range requested {
+		if !w.capability.hasUIOption(option) {
+			continue
 		}
+		o[option] = enabled
 	}
+	w.notifyUnsupported(requested)
+
 	return o
 }
 
+// bottomPanels returns the panels between the screen and the statusline
+func (w *Workspace) bottomPanels() []*widgets.QWidget {
+	var panels []*widgets.QWidget
+	if w.debug != nil {
+		panels = append(panels, w.debug.widget)
+	}
+	if w.quickfix != nil {
+		panels = append(panels, w.quickfix.widget)
+	}
+	if w.tasks != nil {
+		panels = append(panels, w.tasks.widget)
+	}
+	if w.extensions != nil {
+		panels = append(panels, w.extensions.widget)
+	}
+	if w.rpcStatus != nil {
+		panels = append(panels, w.rpcStatus.widget)
+	}
+	if w.terminal != nil {
+		panels = append(panels, w.terminal.widget.QWidget_PTR())
+	}
+
+	return panels
+}
+
 func (w *Workspace) updateSize() {
 	e := editor
 	width := e.wsWidget.Width()
@@ -673,6 +1029,25 @@ func (w *Workspace) updateSize() {
 
 	if w.screen != nil {
 		w.screen.height = w.height - w.tabline.height - w.statusline.height
+		if w.breadcrumbs != nil && w.breadcrumbs.widget.IsVisible() {
+			w.screen.height -= w.breadcrumbs.widget.SizeHint().Height()
+		}
+		if w.searchBar != nil && w.searchBar.widget.IsVisible() {
+			w.screen.height -= w.searchBar.widget.SizeHint().Height()
+		}
+		if w.findBar != nil && w.findBar.widget.IsVisible() {
+			w.screen.height -= w.findBar.widget.SizeHint().Height()
+		}
+		// The panels below the screen have fixed heights
+		for _, panel := range w.bottomPanels() {
+			if panel.IsVisible() {
+				w.screen.height -= panel.MinimumHeight()
+			}
+		}
+		if w.screenArea != nil {
+			margin := w.zenMargin()
+			w.screenArea.Layout().SetContentsMargins(margin, 0, margin, 0)
+		}
 		w.screen.updateSize()
 	}
 	if w.palette != nil {
@@ -684,6 +1059,12 @@ func (w *Workspace) updateSize() {
 	if w.message != nil {
 		w.message.resize()
 	}
+	if w.msgline != nil {
+		w.msgline.resize()
+	}
+	if w.dashboard != nil {
+		w.dashboard.resize()
+	}
 
 	// notification
 	e.updateNotificationPos()
@@ -692,19 +1073,7 @@ func (w *Workspace) updateSize() {
 func (e *Editor) updateNotificationPos() {
 	e.width = e.window.Width()
 	e.height = e.window.Height()
-	e.notifyStartPos = core.NewQPoint2(e.width-e.notificationWidth-10, e.height-30)
-	var x, y int
-	var newNotifications []*Notification
-	for _, item := range e.notifications {
-		x = e.notifyStartPos.X()
-		y = e.notifyStartPos.Y() - item.widget.Height() - 4
-		if !item.isHide && !item.isMoved {
-			item.widget.Move2(x, y)
-			e.notifyStartPos = core.NewQPoint2(x, y)
-		}
-		newNotifications = append(newNotifications, item)
-	}
-	e.notifications = newNotifications
+	e.layoutNotifications(nil)
 }
 
 func (w *Workspace) handleRedraw(updates [][]interface{}) {
@@ -716,11 +1085,12 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 
 		// Global Events
 		case "set_title":
-			titleStr := (update[1].([]interface{}))[0].(string)
-			editor.window.SetupTitle(titleStr)
-			if runtime.GOOS == "linux" {
-				editor.window.SetWindowTitle(titleStr)
+			// TitleFormat takes precedence over 'titlestring'
+			if editor.config.Editor.TitleFormat != "" {
+				continue
 			}
+			titleStr := (update[1].([]interface{}))[0].(string)
+			editor.setTitle(titleStr)
 		case "set_icon":
 		case "mode_info_set":
 			w.modeInfoSet(args)
@@ -746,7 +1116,12 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 		case "bell":
 		case "visual_bell":
 		case "flush":
+			editor.latency.flush()
 			w.cursor.update()
+			w.updateAccessibility()
+			w.diff.update()
+			w.conflicts.update()
+			w.swatches.update()
 
 		// Grid Events
 		case "grid_resize":
@@ -760,7 +1135,11 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 		case "hl_group_set":
 			s.setHighlightGroup(args)
 		case "grid_line":
-			s.gridLine(args)
+			if lines, ok := args[0].([]*grid.Line); ok {
+				s.applyGridLines(lines)
+			} else {
+				s.gridLine(args)
+			}
 		case "grid_clear":
 			s.gridClear(args)
 		case "grid_destroy":
@@ -846,8 +1225,11 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 		case "msg_clear":
 			w.message.msgClear()
 		case "msg_showmode":
+			w.msgline.msgShowmode(args)
 		case "msg_showcmd":
+			w.msgline.msgShowcmd(args)
 		case "msg_ruler":
+			w.msgline.msgRuler(args)
 		case "msg_history_show":
 			w.message.msgHistoryShow(args)
 
@@ -855,7 +1237,7 @@ func (w *Workspace) handleRedraw(updates [][]interface{}) {
 		}
 	}
 
-	s.update()
+	w.updateScreen()
 	w.drawOtherUI()
 }
 
@@ -980,6 +1362,17 @@ func (w *Workspace) updateWorkspaceColor() {
 	w.signature.setColor()
 	w.message.setColor()
 	w.screen.setColor()
+	w.debug.setColor()
+	w.terminal.setColor()
+	w.tasks.setColor()
+	w.quickfix.setColor()
+	w.breadcrumbs.setColor()
+	w.peek.setColor()
+	w.extensions.setColor()
+	w.rpcStatus.setColor()
+	w.searchBar.setColor()
+	w.findBar.setColor()
+	w.setZenColor()
 	if w.drawTabline {
 		w.tabline.setColor()
 	}
@@ -1090,8 +1483,16 @@ func (w *Workspace) handleRPCGui(updates []interface{}) {
 	event := updates[0].(string)
 	switch event {
 	case "gonvim_enter":
-		editor.window.SetWindowOpacity(1.0)
-		w.setCwd(updates[1].(string))
+		w.entered = true
+		if !w.uiRemoteAttached {
+			go w.execLua("require('gonvim').startup_errors()")
+		}
+		editor.window.SetWindowOpacity(editor.opacity)
+		if w.folder != "" {
+			w.changeToFolder()
+		} else {
+			w.setCwd(updates[1].(string))
+		}
 	case "Font":
 		w.guiFont(updates[1].(string))
 	case "Linespace":
@@ -1128,34 +1529,157 @@ func (w *Workspace) handleRPCGui(updates []interface{}) {
 			go w.nvim.Call("rpcnotify", nil, 0, "GonvimFiler", "redraw")
 		}
 	case "filer_open":
-		editor.wsSide.items[w.getNum()].isContentHide = false
-		editor.wsSide.items[w.getNum()].openContent()
+		w.sideItem().isContentHide = false
+		w.sideItem().openContent()
 	case "filer_clear":
-		editor.wsSide.items[w.getNum()].clear()
+		w.sideItem().clear()
 	case "filer_resize":
-		editor.wsSide.items[w.getNum()].resizeContent()
+		w.sideItem().resizeContent()
 	case "filer_item_add":
-		editor.wsSide.items[w.getNum()].addItem(updates[1:])
+		w.sideItem().addItem(updates[1:])
 	case "filer_item_select":
-		editor.wsSide.items[w.getNum()].selectItem(updates[1:])
+		w.sideItem().selectItem(updates[1:])
+	case "gonvim_notify":
+		w.guiNotify(updates[1:])
+	case "gonvim_ui_select":
+		w.uiSelect(updates[1:])
+	case "gonvim_ui_input":
+		w.uiInput(updates[1:])
+	case "gonvim_lsp_progress":
+		w.lspProgress(updates[1:])
+	case "gonvim_notifications":
+		editor.showNotificationHistory()
+	case "gonvim_do_not_disturb":
+		arg, _ := updates[1].(string)
+		editor.setDoNotDisturb(arg)
+	case "gonvim_notify_level":
+		level, _ := updates[1].(string)
+		editor.setNotifyLevel(level)
+	case "gonvim_colorscheme":
+		w.syncPalette(updates[1:])
+	case "gonvim_screenshot":
+		w.screenshot(updates[1:])
+	case "gonvim_record":
+		w.record(updates[1:])
+	case "gonvim_zoom":
+		arg := ""
+		if len(updates) > 1 {
+			arg = fmt.Sprint(updates[1])
+		}
+		w.zoom(arg)
+	case "gonvim_git_blame", "gonvim_git_hunk":
+		w.git.handle(event, updates[1:])
+	case "gonvim_git_popup":
+		w.git.show(updates[1:])
+	case "gonvim_indent_guides":
+		w.screen.setIndentGuides(updates[1:])
+	case "gonvim_harness_snapshot":
+		if reply, ok := updates[1].(chan harnessScreen); ok {
+			reply <- w.screen.snapshot()
+		}
+	case "gonvim_terminal":
+		w.terminal.toggle()
+	case "gonvim_terminal_output":
+		w.terminal.output(updates[1].(string))
+	case "gonvim_terminal_exit":
+		w.terminal.exit()
+	case "gonvim_restart_session":
+		w.setRestartSession(updates[1:])
+	case "gonvim_recording":
+		w.msgline.setRecording(updates[1:])
+	case "gonvim_cursors":
+		w.screen.setCursors(updates[1:])
+	case "gonvim_snippet":
+		w.screen.setSnippet(updates[1:])
+	case "gonvim_peek":
+		w.peek.handle(updates[1:])
+	case "gonvim_peek_close":
+		w.peek.close(updates[1:])
+	case "gonvim_breadcrumbs":
+		w.breadcrumbs.handle(updates[1:])
+	case "gonvim_quickfix":
+		w.quickfix.handle(updates[1:])
+	case "gonvim_quickfix_toggle":
+		w.quickfix.toggle()
+	case "gonvim_spell":
+		w.screen.showSpellSuggestions(updates[1:])
+	case "gonvim_search":
+		w.searchBar.handle(updates[1:])
+	case "gonvim_viewport":
+		editor.setViewport(updates[1:])
+	case "gonvim_viewport_request":
+		w.restoreViewport(updates[1:])
+	case "gonvim_extension_panel":
+		w.extensions.toggle()
+	case "gonvim_extension_command":
+		editor.extensions.command(updates[1:])
+	case "gonvim_task":
+		w.tasks.handle(updates[1:])
+	case "gonvim_task_output":
+		w.tasks.appendOutput(updates[1:])
+	case "gonvim_task_exit":
+		w.tasks.exit(updates[1:])
+	case "gonvim_dashboard":
+		w.dashboard.handle(updates[1:])
+	case "gonvim_goto_line":
+		w.gotoLine()
+	case "gonvim_find_bar":
+		w.findBar.open(len(updates) > 1 && util.IsTrue(updates[1]))
+	case "gonvim_find":
+		w.findBar.handle(updates[1:])
+	case "gonvim_activity":
+		w.setActivity(updates[1:])
+	case "gonvim_wraps":
+		w.screen.setWraps(updates[1:])
+	case "gonvim_delimiters":
+		w.screen.setDelimiters(updates[1:])
+	case "gonvim_folds":
+		w.screen.setFolds(updates[1:])
+	case "gonvim_fold_preview":
+		w.foldPreview.show(updates[1:])
+	case "gonvim_diff":
+		w.diff.handle(updates[1:])
+	case "gonvim_dap":
+		w.debug.handle(updates[1:])
+	case "gonvim_perf_hud":
+		editor.togglePerfHUD()
+	case "gonvim_latency":
+		editor.toggleLatency()
+	case "gonvim_startup_errors":
+		w.showStartupErrors(updates[1:])
+	case "gonvim_rpc_status":
+		w.rpcStatus.toggle()
+	case "gonvim_rpc_channels":
+		w.rpcStatus.handle(updates[1:])
+	case "gonvim_theme":
+		name, _ := updates[1].(string)
+		editor.setTheme(name)
 	case "gonvim_grid_font":
 		w.screen.gridFont(updates[1])
+	case "gonvim_window_font":
+		w.screen.windowFont(updates[1:])
 	case "gonvim_minimap_update":
-		if w.minimap.visible {
+		if w.minimap.visible && !editor.powerSaving {
 			w.minimap.bufUpdate()
 		}
 	case "gonvim_minimap_sync":
-		if w.minimap.visible {
+		if w.minimap.visible && !editor.powerSaving {
 			go w.minimap.bufSync()
 		}
 	case "gonvim_minimap_toggle":
 		go w.minimap.toggle()
 	case "gonvim_copy_clipboard":
 		go editor.copyClipBoard()
+	case "gonvim_project_files":
+		w.setProjectFiles(updates[1:])
 	case "gonvim_get_maxline":
 		w.maxLine = util.ReflectToInt(updates[1])
 	case "gonvim_workspace_new":
 		editor.workspaceNew()
+	case "gonvim_new_window":
+		editor.newWindow()
+	case "gonvim_restart":
+		w.restart(updates[1:])
 	case "gonvim_workspace_next":
 		editor.workspaceNext()
 	case "gonvim_workspace_previous":
@@ -1166,6 +1690,48 @@ func (w *Workspace) handleRPCGui(updates []interface{}) {
 		w.setCwd(updates[1].(string))
 	case "gonvim_workspace_filepath":
 		w.filepath = updates[1].(string)
+	case "gonvim_title":
+		w.setTitleInfo(updates[1:])
+	case "gonvim_exit":
+	case "gonvim_buffers":
+		w.setListedBuffers(updates[1:])
+	case "gonvim_recent_file":
+		editor.addRecentFile(updates[1].(string))
+	case "gonvim_open_dialog":
+		w.openFileDialog()
+	case "gonvim_open_folder":
+		if path, _ := updates[1].(string); path != "" {
+			editor.openFolder(path)
+		} else {
+			w.openFolderDialog()
+		}
+	case "gonvim_saveas_dialog":
+		w.saveAsFileDialog()
+	case "gonvim_fullscreen":
+		editor.toggleFullscreen()
+	case "gonvim_presentation":
+		editor.togglePresentation()
+	case "gonvim_zen":
+		editor.toggleZen()
+	case "gonvim_typewriter":
+		w.toggleTypewriter()
+	case "gonvim_always_on_top":
+		editor.toggleAlwaysOnTop()
+	case "gonvim_opacity":
+		editor.guiOpacity(updates[1])
+	case "gonvim_snap":
+		if len(updates) > 1 {
+			area, _ := updates[1].(string)
+			editor.snapWindow(area)
+		}
+	case "gonvim_progress":
+		editor.setProgress(util.ReflectToInt(updates[1]))
+	case "gonvim_badge":
+		badge, _ := updates[1].(string)
+		editor.setBadge(badge)
+	case "gonvim_power_saving":
+		mode, _ := updates[1].(string)
+		editor.setPowerSavingMode(mode)
 	case "gonvim_termenter":
 		w.mode = "terminal-input"
 	case "gonvim_termleave":
@@ -1343,6 +1909,9 @@ func (w *Workspace) getPointInWidget(col, row, grid int) (int, int) {
 	if w.drawTabline {
 		y += w.tabline.widget.Height()
 	}
+	if w.breadcrumbs.widget.IsVisible() {
+		y += w.breadcrumbs.widget.Height()
+	}
 	x += int(float64(win.pos[0]) * font.truewidth)
 	y += win.pos[1] * font.lineHeight
 
@@ -1378,9 +1947,19 @@ func newWorkspaceSide() *WorkspaceSide {
 
 	layout.AddWidget(header)
 	side.header.Show()
+	widget.SetContextMenuPolicy(core.Qt__CustomContextMenu)
+	widget.ConnectCustomContextMenuRequested(side.showRecentWorkspaces)
+
+	side.items = []*WorkspaceSideItem{}
+
+	return side
+}
 
-	items := []*WorkspaceSideItem{}
-	side.items = items
+func (side *WorkspaceSide) addItems() {
+	if len(side.items) > 0 {
+		return
+	}
+	layout := side.widget.Layout()
 	for i := 0; i < WorkspaceLen; i++ {
 		item := newWorkspaceSideItem()
 		side.items = append(side.items, item)
@@ -1388,8 +1967,15 @@ func newWorkspaceSide() *WorkspaceSide {
 		layout.AddWidget(side.items[len(side.items)-1].widget)
 		side.items[len(side.items)-1].hide()
 	}
-
-	return side
+	if side.scrollarea != nil {
+		width := side.scrollarea.Width()
+		for _, item := range side.items {
+			item.label.SetMaximumWidth(width)
+			item.label.SetMinimumWidth(width)
+			item.content.SetMinimumWidth(width)
+		}
+	}
+	side.setColor()
 }
 
 func (side *WorkspaceSide) newScrollArea() {
@@ -1429,6 +2015,9 @@ func (side *WorkspaceSide) toggle() {
 		side.scrollarea.Hide()
 		side.isShown = false
 	} else {
+		if len(side.items) == 0 {
+			editor.deferredInit()
+		}
 		side.scrollarea.Show()
 		side.isShown = true
 	}
@@ -1441,6 +2030,10 @@ func (side *WorkspaceSide) show() {
 	if side.isShown {
 		return
 	}
+	// The items of the hidden sidebar are added in deferredInit
+	if len(side.items) == 0 {
+		editor.deferredInit()
+	}
 	side.scrollarea.Show()
 	side.isShown = true
 }
@@ -1486,6 +2079,7 @@ type WorkspaceSideItem struct {
 
 	labelWidget *widgets.QWidget
 	label       *widgets.QLabel
+	badge       *widgets.QLabel
 
 	content       *widgets.QListWidget
 	isContentHide bool
@@ -1530,10 +2124,13 @@ func newWorkspaceSideItem() *WorkspaceSideItem {
 	labelLayout.AddWidget(openIcon, 0, 0)
 	labelLayout.AddWidget(closeIcon, 0, 0)
 	labelLayout.AddWidget(label, 0, 0)
+	badge := newActivityBadge()
+	labelLayout.AddWidget(badge, 0, 0)
 
 	labelLayout.SetAlignment(openIcon, core.Qt__AlignLeft)
 	labelLayout.SetAlignment(closeIcon, core.Qt__AlignLeft)
 	labelLayout.SetAlignment(label, core.Qt__AlignLeft)
+	labelLayout.SetAlignment(badge, core.Qt__AlignLeft)
 	// layout.AddWidget(flwidget, 0, 0)
 
 	layout.AddWidget(labelWidget, 1, 0)
@@ -1549,6 +2146,7 @@ func newWorkspaceSideItem() *WorkspaceSideItem {
 		layout:        layout,
 		labelWidget:   labelWidget,
 		label:         label,
+		badge:         badge,
 		openIcon:      openIcon,
 		closeIcon:     closeIcon,
 		content:       content,
@@ -1557,6 +2155,8 @@ func newWorkspaceSideItem() *WorkspaceSideItem {
 
 	sideitem.widget.ConnectMousePressEvent(sideitem.toggleContent)
 	content.ConnectItemDoubleClicked(sideitem.fileDoubleClicked)
+	content.SetContextMenuPolicy(core.Qt__CustomContextMenu)
+	content.ConnectCustomContextMenuRequested(sideitem.contentMenu)
 
 	return sideitem
 }
@@ -1594,6 +2194,57 @@ func (i *WorkspaceSideItem) fileDoubleClicked(item *widgets.QListWidgetItem) {
 	}
 }
 
+func (i *WorkspaceSideItem) contentMenu(pos *core.QPoint) {
+	item := i.content.ItemAt(pos)
+	if item == nil || item.Pointer() == nil {
+		return
+	}
+	path := filepath.Join(i.cwdpath, strings.TrimSuffix(item.Text(), "/"))
+
+	menu := widgets.NewQMenu(i.content)
+	trashAction := menu.AddAction("Move to Trash")
+	trashAction.ConnectTriggered(func(bool) {
+		i.trashFile(path)
+	})
+	menu.Exec2(i.content.MapToGlobal(pos), nil)
+}
+
+// trashFile moves the file to the trash, and notifies with the button to undo it
+func (i *WorkspaceSideItem) trashFile(path string) {
+	trashed, err := moveToTrash(path)
+	if err != nil {
+		editor.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+		return
+	}
+	i.redrawContent()
+
+	opts := []*NotifyButton{}
+	opts = append(opts, &NotifyButton{
+		action: func() {
+			err := trashed.restore()
+			if err != nil {
+				editor.pushNotification(NotifyWarn, -1, "[Gonvim] "+err.Error())
+				return
+			}
+			i.redrawContent()
+		},
+		text: "Undo",
+	})
+	message := fmt.Sprintf("[Gonvim] %s was moved to the trash", filepath.Base(path))
+	editor.pushNotification(NotifyInfo, -1, message, notifyOptionArg(opts))
+}
+
+func (i *WorkspaceSideItem) redrawContent() {
+	for j, ws := range editor.workspaces {
+		if j >= len(editor.wsSide.items) {
+			return
+		}
+		if editor.wsSide.items[j] == i {
+			go ws.nvim.Call("rpcnotify", nil, 0, "GonvimFiler", "redraw")
+		}
+	}
+}
+
 func (i *WorkspaceSideItem) toggleContent(event *gui.QMouseEvent) {
 	if i.hidden {
 		return
@@ -1710,7 +2361,7 @@ func (side *WorkspaceSide) setColor() {
 	}
 	side.scrollarea.SetStyleSheet(fmt.Sprintf(".QScrollBar { border-width: 0px; background-color: %s; width: 5px; margin: 0 0 0 0; } .QScrollBar::handle:vertical {background-color: %s; min-height: 25px;} .QScrollBar::handle:vertical:hover {background-color: %s; min-height: 25px;} .QScrollBar::add-line:vertical, .QScrollBar::sub-line:vertical { border: none; background: none; } .QScrollBar::add-page:vertical, QScrollBar::sub-page:vertical { background: none; }", sbg, sfg, editor.config.SideBar.AccentColor))
 
-	if len(editor.workspaces) == 1 {
+	if len(editor.workspaces) == 1 && len(side.items) > 0 {
 		side.items[0].active = true
 		side.items[0].labelWidget.SetStyleSheet(
 			fmt.Sprintf(
@@ -1792,6 +2443,7 @@ func (i *WorkspaceSideItem) hide() {
 	}
 	i.hidden = true
 	i.label.Hide()
+	i.badge.Hide()
 	i.openIcon.Hide()
 	i.closeIcon.Hide()
 
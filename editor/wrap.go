@@ -0,0 +1,123 @@
+package editor
+
+import (
+	"github.com/akiyosi/goneovim/util"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// WrapInfo is the lines shown in a window and the rows continuing the wrapped
+// lines, sent by require("gonvim").wraps()
+type WrapInfo struct {
+	topline   int
+	botline   int
+	lineCount int
+	textoff   int
+	rows      map[int]bool
+}
+
+// setWraps handles gonvim_wraps
+func (s *Screen) setWraps(args []interface{}) {
+	if len(args) < 1 {
+		return
+	}
+	items, _ := args[0].([]interface{})
+	wraps := make(map[int]*WrapInfo)
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		info := &WrapInfo{
+			topline:   util.ReflectToInt(item["topline"]),
+			botline:   util.ReflectToInt(item["botline"]),
+			lineCount: util.ReflectToInt(item["lines"]),
+			textoff:   util.ReflectToInt(item["textoff"]),
+			rows:      make(map[int]bool),
+		}
+		rows, _ := item["rows"].([]interface{})
+		for _, r := range rows {
+			info.rows[util.ReflectToInt(r)] = true
+		}
+		wraps[util.ReflectToInt(item["win"])] = info
+	}
+	s.windows.Range(func(_, v interface{}) bool {
+		win, ok := v.(*Window)
+		if !ok || win == nil {
+			return true
+		}
+		if info, ok := wraps[int(win.id)]; ok {
+			changed := win.wraps == nil || len(win.wraps.rows) != len(info.rows) || win.wraps.textoff != info.textoff
+			if !changed {
+				for r := range info.rows {
+					if !win.wraps.rows[r] {
+						changed = true
+						break
+					}
+				}
+			}
+			win.wraps = info
+			if changed && win.widget != nil {
+				win.widget.Update()
+			}
+		}
+		return true
+	})
+	if editor.config.ScrollBar.Visible {
+		s.ws.scrollBar.update()
+	}
+	if s.ws.minimap.visible {
+		s.ws.minimap.mapScroll()
+	}
+}
+
+// shownLines returns the first line shown in the window, the number of the
+// lines shown, which counts a wrapped line once, and the lines of the buffer;
+// ok is false until they are sent by gonvim_wraps
+func (w *Window) shownLines() (top, count, total int, ok bool) {
+	if w == nil || w.wraps == nil || w.wraps.lineCount == 0 {
+		return 0, 0, 0, false
+	}
+	return w.wraps.topline, w.wraps.botline - w.wraps.topline + 1, w.wraps.lineCount, true
+}
+
+// drawWrapIndicator draws a hooked arrow in the last cell of the gutter of the
+// rows continuing the wrapped lines
+func (w *Window) drawWrapIndicator(p *gui.QPainter, y int) {
+	if w.wraps == nil || !w.wraps.rows[y] || w.wraps.textoff <= 0 || y >= len(w.content) {
+		return
+	}
+	x := w.wraps.textoff - 1
+	if x >= len(w.content[y]) {
+		return
+	}
+	fg := editor.colors.inactiveFg
+	if cell := w.content[y][x]; cell != nil && cell.highlight.foreground != nil {
+		fg = cell.highlight.foreground
+	}
+	if fg == nil {
+		return
+	}
+	font := w.getFont()
+	size := font.truewidth * 0.6
+	cx := float64(x)*font.truewidth + font.truewidth/2
+	cy := float64(y*font.lineHeight) + float64(font.lineHeight)/2
+
+	qcolor := fg.QColor()
+	qcolor.SetAlphaF(0.7)
+	pen := gui.NewQPen3(qcolor)
+	pen.SetWidthF(1.2)
+	p.Save()
+	p.SetRenderHint(gui.QPainter__Antialiasing, true)
+	p.SetPen(pen)
+	p.SetBrush(gui.NewQBrush2(core.Qt__NoBrush))
+	path := gui.NewQPainterPath()
+	path.MoveTo2(cx-size/2, cy-size/2)
+	path.LineTo2(cx-size/2, cy+size/4)
+	path.LineTo2(cx+size/2, cy+size/4)
+	path.MoveTo2(cx+size/4, cy)
+	path.LineTo2(cx+size/2, cy+size/4)
+	path.LineTo2(cx+size/4, cy+size/2)
+	p.DrawPath(path)
+	p.Restore()
+}
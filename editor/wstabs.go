@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// WorkspaceTabs shows the workspaces as tabs in the titlebar, like the tabs of
+// a browser, instead of the list of the sidebar. If the window is not
+// frameless, they are put above the workspaces.
+type WorkspaceTabs struct {
+	widget *widgets.QWidget
+	tabbar *widgets.QTabBar
+	add    *widgets.QPushButton
+
+	// The tabs are being updated from the workspaces
+	updating bool
+}
+
+func newWorkspaceTabs() *WorkspaceTabs {
+	t := &WorkspaceTabs{
+		widget: widgets.NewQWidget(nil, 0),
+		tabbar: widgets.NewQTabBar(nil),
+		add:    widgets.NewQPushButton2("+", nil),
+	}
+	layout := widgets.NewQHBoxLayout()
+	layout.SetContentsMargins(4, 0, 4, 0)
+	layout.SetSpacing(2)
+	t.widget.SetLayout(layout)
+
+	t.tabbar.SetTabsClosable(true)
+	t.tabbar.SetMovable(true)
+	t.tabbar.SetExpanding(false)
+	t.tabbar.SetDocumentMode(true)
+	t.tabbar.SetDrawBase(false)
+	t.tabbar.SetElideMode(core.Qt__ElideMiddle)
+	t.tabbar.SetFocusPolicy(core.Qt__NoFocus)
+	t.tabbar.ConnectCurrentChanged(func(index int) {
+		if t.updating || index < 0 {
+			return
+		}
+		editor.workspaceSwitch(index + 1)
+	})
+	t.tabbar.ConnectTabCloseRequested(func(index int) {
+		if index < 0 || index >= len(editor.workspaces) {
+			return
+		}
+		// The workspace is removed when its nvim exits
+		go editor.workspaces[index].nvim.Command("confirm qall")
+	})
+	t.tabbar.ConnectTabMoved(t.move)
+	layout.AddWidget(t.tabbar, 0, 0)
+
+	t.add.SetFlat(true)
+	t.add.SetFocusPolicy(core.Qt__NoFocus)
+	t.add.SetToolTip("New workspace")
+	t.add.ConnectClicked(func(bool) {
+		if len(editor.workspaces) >= WorkspaceLen {
+			return
+		}
+		editor.workspaceNew()
+	})
+	layout.AddWidget(t.add, 0, 0)
+	layout.AddStretch(1)
+
+	return t
+}
+
+// attach puts the tabs in the titlebar, and the splitter of the sidebar and the workspaces in the layout
+func (t *WorkspaceTabs) attach(l *widgets.QBoxLayout) {
+	e := editor
+	if !e.isFrameless() {
+		container := widgets.NewQWidget(nil, 0)
+		layout := widgets.NewQVBoxLayout2(container)
+		layout.SetContentsMargins(0, 0, 0, 0)
+		layout.SetSpacing(0)
+		layout.AddWidget(t.widget, 0, 0)
+		layout.AddWidget(e.split, 1, 0)
+		l.AddWidget(container, 1, 0)
+		return
+	}
+	l.AddWidget(e.split, 1, 0)
+	titlebar := widgets.NewQHBoxLayoutFromPointer(e.window.TitleBar.Layout().Pointer())
+	// After the buttons of the window on macOS, which are on the left
+	index := 0
+	if (e.titlebarButtons == nil && runtime.GOOS == "darwin") || e.titlebarButtons.isLeft() {
+		index = 1
+	}
+	titlebar.InsertWidget(index, t.widget, 1, 0)
+}
+
+func (t *WorkspaceTabs) setColor() {
+	fg := editor.colors.fg
+	bg := editor.colors.bg
+	if fg == nil || bg == nil {
+		return
+	}
+	t.widget.SetStyleSheet(fmt.Sprintf(`
+	QWidget { background: transparent; }
+	QTabBar::tab { color: %s; background: transparent; padding: 4px 10px; border: 0px; border-bottom: 2px solid transparent; }
+	QTabBar::tab:selected { color: %s; background-color: %s; border-bottom: 2px solid %s; }
+	QTabBar::tab:hover { color: %s; }
+	QPushButton { color: %s; border: 0px; padding: 2px 8px; }
+	QPushButton:hover { color: %s; background-color: %s; }
+	`, editor.colors.inactiveFg.String(), fg.String(), warpColor(bg, -10).String(), editor.config.SideBar.AccentColor,
+		fg.String(), editor.colors.inactiveFg.String(), fg.String(), editor.colors.selectedBg.String()))
+}
+
+// update makes the tabs the same as the workspaces, called by workspaceUpdate
+func (t *WorkspaceTabs) update() {
+	e := editor
+	t.updating = true
+	defer func() {
+		t.updating = false
+	}()
+	for t.tabbar.Count() > len(e.workspaces) {
+		t.tabbar.RemoveTab(t.tabbar.Count() - 1)
+	}
+	for t.tabbar.Count() < len(e.workspaces) {
+		t.tabbar.AddTab("")
+	}
+	for i, ws := range e.workspaces {
+		label := ws.cwdBase
+		if label == "" || label == "." {
+			label = fmt.Sprintf("Workspace %d", i+1)
+		}
+		if ws.activity != "" {
+			label += " ●"
+		}
+		t.tabbar.SetTabText(i, label)
+		t.tabbar.SetTabToolTip(i, ws.cwdlabel)
+	}
+	t.tabbar.SetCurrentIndex(e.active)
+	t.add.SetVisible(len(e.workspaces) < WorkspaceLen)
+	t.setColor()
+}
+
+// move handles the drag of a tab, it reorders the workspaces and the sidebar items
+func (t *WorkspaceTabs) move(from, to int) {
+	e := editor
+	if from < 0 || to < 0 || from >= len(e.workspaces) || to >= len(e.workspaces) {
+		return
+	}
+	active := e.workspaces[e.active]
+	ws := e.workspaces[from]
+	workspaces := append([]*Workspace{}, e.workspaces[:from]...)
+	workspaces = append(workspaces, e.workspaces[from+1:]...)
+	workspaces = append(workspaces[:to], append([]*Workspace{ws}, workspaces[to:]...)...)
+	e.workspaces = workspaces
+	for i, w := range e.workspaces {
+		if w == active {
+			e.active = i
+		}
+		if e.wsSide != nil && i < len(e.wsSide.items) {
+			e.wsSide.items[i].cwdpath = w.cwd
+		}
+	}
+	e.workspaceUpdate()
+}
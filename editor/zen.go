@@ -0,0 +1,95 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// ZenMode holds the state of the workspace to be restored when zen mode ends
+type ZenMode struct {
+	ws             *Workspace
+	sideShown      bool
+	drawTabline    bool
+	drawStatusline bool
+	minimap        bool
+	typewriter     bool
+}
+
+// isZenKey reports whether the key event is Ctrl+Shift+Z (Cmd on macOS)
+func isZenKey(event *gui.QKeyEvent) bool {
+	if !editor.config.Editor.ZenKey {
+		return false
+	}
+	mod := event.Modifiers() &^ core.Qt__KeypadModifier
+	return core.Qt__Key(event.Key()) == core.Qt__Key_Z && mod == core.Qt__ControlModifier|core.Qt__ShiftModifier
+}
+
+// toggleZen centers the text of the workspace in a column of zenWidth, dims the
+// background around it and hides the tabline, the statusline, the sidebar and
+// the minimap, and restores them when it is called again
+func (e *Editor) toggleZen() {
+	if e.zen != nil {
+		z := e.zen
+		e.zen = nil
+		w := z.ws
+		if z.sideShown {
+			e.wsSide.show()
+		}
+		if z.minimap && !w.minimap.visible {
+			go w.minimap.toggle()
+		}
+		if w.typewriter != z.typewriter {
+			w.setTypewriter(z.typewriter)
+		}
+		w.screenArea.SetStyleSheet("")
+		w.setDecorationVisible(z.drawTabline, z.drawStatusline)
+		return
+	}
+
+	w := e.workspaces[e.active]
+	e.zen = &ZenMode{
+		ws:             w,
+		sideShown:      e.wsSide != nil && e.wsSide.isShown,
+		drawTabline:    w.drawTabline,
+		drawStatusline: w.drawStatusline,
+		minimap:        w.minimap.visible,
+		typewriter:     w.typewriter,
+	}
+	if e.wsSide != nil && e.wsSide.isShown {
+		e.wsSide.scrollarea.Hide()
+		e.wsSide.isShown = false
+	}
+	if w.minimap.visible {
+		go w.minimap.toggle()
+	}
+	if e.config.Editor.ZenTypewriter && !w.typewriter {
+		w.setTypewriter(true)
+	}
+	w.setZenColor()
+	w.setDecorationVisible(false, false)
+}
+
+// setZenColor dims the background around the column of the text
+func (w *Workspace) setZenColor() {
+	if editor.zen == nil || editor.zen.ws != w || editor.colors.bg == nil {
+		return
+	}
+	bg := editor.colors.bg.brend(newRGBA(0, 0, 0, 1), editor.config.Editor.ZenDim)
+	w.screenArea.SetStyleSheet(fmt.Sprintf("#screenArea { background-color: %s; }", bg.String()))
+}
+
+// zenMargin is the margin on both sides of the screen to center a column of
+// zenWidth in the workspace, or 0 out of zen mode
+func (w *Workspace) zenMargin() int {
+	if editor.zen == nil || editor.zen.ws != w || w.screen == nil || w.screen.font == nil {
+		return 0
+	}
+	width := int(float64(editor.config.Editor.ZenWidth)*w.screen.font.truewidth) + 1
+	margin := (w.width - width) / 2
+	if margin < 0 {
+		return 0
+	}
+	return margin
+}
@@ -0,0 +1,204 @@
+package editor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Each zoom step scales the font size by 10%
+const (
+	zoomStep = 1.1
+	zoomMin  = -8
+	zoomMax  = 12
+
+	zoomAnimationDuration = 100
+)
+
+// zoom handles :GonvimZoom [in|out|reset|{level}] and gonvim_zoom.
+// The zoom level is of the workspace, relative to the font size when it was zoomed first.
+func (w *Workspace) zoom(arg string) {
+	level := w.zoomLevel
+	switch arg {
+	case "in", "+", "":
+		level++
+	case "out", "-":
+		level--
+	case "reset", "0":
+		level = 0
+	default:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return
+		}
+		level = n
+	}
+	w.setZoom(level, nil)
+	w.notifyZoom()
+}
+
+func (w *Workspace) notifyZoom() {
+	editor.pushNotification(NotifyInfo, 1, fmt.Sprintf("Zoom %d%%", int(math.Round(zoomScale(w.zoomLevel)*100))))
+}
+
+func zoomScale(level int) float64 {
+	return math.Pow(zoomStep, float64(level))
+}
+
+// setZoom sets the zoom level keeping the cell under the anchor, a point of the
+// screen, at the same place; the anchor is the cursor if it is nil
+func (w *Workspace) setZoom(level int, anchor *core.QPoint) {
+	if level < zoomMin {
+		level = zoomMin
+	}
+	if level > zoomMax {
+		level = zoomMax
+	}
+	if w.zoomLevel == 0 {
+		w.zoomBaseSize = w.font.fontNew.PointSizeF()
+	}
+	if level == w.zoomLevel {
+		return
+	}
+	w.zoomLevel = level
+
+	oldSize := w.font.fontNew.PointSizeF()
+	oldLineHeight := w.font.lineHeight
+	if anchor == nil {
+		anchor = w.screen.widget.MapFromGlobal(w.cursor.widget.MapToGlobal(core.NewQPoint2(0, w.font.lineHeight/2)))
+	}
+	win, _, row := w.screen.windowAt(anchor.X(), anchor.Y())
+	if editor.config.Editor.SmoothZoom && oldSize > 0 {
+		w.animateZoom(w.zoomBaseSize*zoomScale(level)/oldSize, anchor)
+	}
+
+	w.guiFont(fmt.Sprintf("%s:h%f", w.font.fontNew.Family(), w.zoomBaseSize*zoomScale(level)))
+
+	// Scroll the window so that the line under the anchor stays there, as the
+	// rows of the window are laid out from its top
+	if win == nil || win.isFloatWin || win.isMsgGrid || w.font.lineHeight <= 0 {
+		return
+	}
+	y := row*oldLineHeight + oldLineHeight/2
+	if delta := row - y/w.font.lineHeight; delta != 0 {
+		go w.execLua("require('gonvim').scroll_window(...)", win.id, delta)
+	}
+}
+
+// animateZoom scales a snapshot of the screen from the old cell size to the new
+// one around the anchor, while the screen is redrawn in the font of the new size
+// under it
+func (w *Workspace) animateZoom(scale float64, anchor *core.QPoint) {
+	duration := editor.animationDuration(zoomAnimationDuration)
+	if duration == 0 || scale == 1 || !w.screen.widget.IsVisible() {
+		return
+	}
+	if w.zoomOverlay != nil {
+		// Zoomed again while animating; start from the screen as it is now
+		w.zoomTimer.Stop()
+		w.zoomOverlay.Hide()
+		w.zoomOverlay.DeleteLater()
+		w.zoomOverlay = nil
+	}
+	snapshot := w.screen.widget.Grab(core.NewQRect4(0, 0, -1, -1)).ToImage()
+	overlay := widgets.NewQWidget(w.screen.widget, 0)
+	overlay.SetAttribute(core.Qt__WA_TransparentForMouseEvents, true)
+	overlay.SetGeometry2(0, 0, w.screen.widget.Width(), w.screen.widget.Height())
+	w.zoomOverlay = overlay
+
+	start := time.Now()
+	progress := 0.0
+	ax := float64(anchor.X())
+	ay := float64(anchor.Y())
+	overlay.ConnectPaintEvent(func(*gui.QPaintEvent) {
+		p := gui.NewQPainter2(overlay)
+		defer p.DestroyQPainter()
+		p.FillRect4(core.NewQRectF4(0, 0, float64(overlay.Width()), float64(overlay.Height())), editor.colors.bg.QColor())
+		k := 1 + (scale-1)*progress
+		p.SetRenderHint(gui.QPainter__SmoothPixmapTransform, true)
+		p.Translate(core.NewQPointF3(ax*(1-k), ay*(1-k)))
+		p.Scale(k, k)
+		p.DrawImage7(core.NewQPointF3(0, 0), snapshot)
+	})
+
+	if w.zoomTimer == nil {
+		w.zoomTimer = core.NewQTimer(nil)
+	} else {
+		w.zoomTimer.DisconnectTimeout()
+	}
+	w.zoomTimer.ConnectTimeout(func() {
+		t := float64(time.Since(start).Milliseconds()) / float64(duration)
+		if t >= 1 {
+			w.zoomTimer.Stop()
+			overlay.Hide()
+			overlay.DeleteLater()
+			if w.zoomOverlay == overlay {
+				w.zoomOverlay = nil
+			}
+			return
+		}
+		// OutCubic
+		progress = 1 - math.Pow(1-t, 3)
+		overlay.Update()
+	})
+	overlay.Show()
+	overlay.Raise()
+	w.zoomTimer.Start(16)
+}
+
+// isZoomKey returns the zoom of Ctrl+= / Ctrl+- / Ctrl+0 (Cmd on macOS), or "" for the other keys
+func isZoomKey(event *gui.QKeyEvent) string {
+	if !editor.config.Editor.ZoomKeys {
+		return ""
+	}
+	mod := event.Modifiers() &^ core.Qt__KeypadModifier
+	if mod&^core.Qt__ShiftModifier != core.Qt__ControlModifier {
+		return ""
+	}
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_Equal, core.Qt__Key_Plus:
+		return "in"
+	case core.Qt__Key_Minus:
+		return "out"
+	case core.Qt__Key_0:
+		return "reset"
+	}
+	return ""
+}
+
+// zoomWheel zooms the workspace by Ctrl+wheel (Cmd on macOS) around the
+// pointer, if zoomKeys is set
+func (s *Screen) zoomWheel(event *gui.QWheelEvent) bool {
+	if !editor.config.Editor.ZoomKeys || event.Modifiers() != core.Qt__ControlModifier {
+		return false
+	}
+	s.zoomAngle += event.AngleDelta().Y()
+	steps := s.zoomAngle / 120
+	s.zoomAngle -= steps * 120
+	if steps != 0 {
+		s.ws.setZoom(s.ws.zoomLevel+steps, event.Pos())
+		s.ws.notifyZoom()
+	}
+	event.Accept()
+
+	return true
+}
+
+// appendZoomToSession makes the session restore the zoom level when it is sourced
+func appendZoomToSession(path string, zoomLevel int) {
+	if zoomLevel == 0 {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(fmt.Sprintf("call rpcnotify(0, 'Gui', 'gonvim_zoom', '%d')\n", zoomLevel))
+}
@@ -0,0 +1,224 @@
+// Package grid is the model of a grid of the nvim UI protocol (ext_linegrid)
+// without the GUI: the text and the highlight id of its cells, updated by
+// grid_line, grid_scroll, grid_clear and grid_resize.
+//
+// The editor keeps a Grid for each grid on the rpc goroutine, to merge the
+// grid_line events of a batch into the rows they have changed. Other programs,
+// e.g. the tools which replay or check the redraw events, can use it without Qt.
+package grid
+
+// Cell is a cell of a grid_line event with the repeat expanded and the
+// highlight id resolved. The right half of a double width character is a cell
+// with the empty text.
+type Cell struct {
+	Text string
+	HlID int
+}
+
+// Line is a decoded grid_line event
+type Line struct {
+	Grid  int
+	Row   int
+	Col   int
+	Cells []Cell
+}
+
+// DecodeLines converts the arguments of the grid_line event into Lines
+func DecodeLines(args []interface{}) []*Line {
+	lines := make([]*Line, 0, len(args))
+	for _, a := range args {
+		arg, ok := a.([]interface{})
+		if !ok || len(arg) < 4 {
+			continue
+		}
+		cells, ok := arg[3].([]interface{})
+		if !ok {
+			continue
+		}
+		line := &Line{
+			Grid:  toInt(arg[0]),
+			Row:   toInt(arg[1]),
+			Col:   toInt(arg[2]),
+			Cells: make([]Cell, 0, len(cells)),
+		}
+
+		// If `hl_id` is not present the most recently seen `hl_id` in
+		// the same call should be used (it is always sent for the first
+		// cell in the event).
+		hl := 0
+		for _, c := range cells {
+			cell, ok := c.([]interface{})
+			if !ok || len(cell) == 0 {
+				continue
+			}
+			text, _ := cell[0].(string)
+			if len(cell) >= 2 {
+				hl = toInt(cell[1])
+			}
+			// If `repeat` is present, the cell should be
+			// repeated `repeat` times (including the first time), otherwise just
+			// once.
+			repeat := 1
+			if len(cell) == 3 {
+				repeat = toInt(cell[2])
+			}
+			for r := 0; r < repeat; r++ {
+				line.Cells = append(line.Cells, Cell{Text: text, HlID: hl})
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// toInt converts an integer decoded by msgpack
+func toInt(v interface{}) int {
+	switch i := v.(type) {
+	case int64:
+		return int(i)
+	case uint64:
+		return int(i)
+	case int:
+		return i
+	}
+	return 0
+}
+
+// Grid is the content of a grid as nvim has drawn it so far, and the rows
+// changed since they were taken. A nil cell is not drawn yet.
+type Grid struct {
+	rows  [][]*Cell
+	dirty []bool
+}
+
+// New returns the grid of the size with no cells drawn
+func New(cols, rows int) *Grid {
+	g := &Grid{
+		rows:  make([][]*Cell, rows),
+		dirty: make([]bool, rows),
+	}
+	for i := range g.rows {
+		g.rows[i] = make([]*Cell, cols)
+	}
+
+	return g
+}
+
+// Size returns the columns and the rows of the grid
+func (g *Grid) Size() (int, int) {
+	if len(g.rows) == 0 {
+		return 0, 0
+	}
+	return len(g.rows[0]), len(g.rows)
+}
+
+// Cell returns the cell, nil if it is not drawn yet or out of the grid
+func (g *Grid) Cell(row, col int) *Cell {
+	if row < 0 || row >= len(g.rows) || col < 0 || col >= len(g.rows[row]) {
+		return nil
+	}
+	return g.rows[row][col]
+}
+
+// Resize returns the grid of the new size with the content kept; a nil grid
+// is resized to an empty one
+func (g *Grid) Resize(cols, rows int) *Grid {
+	resized := New(cols, rows)
+	if g == nil {
+		return resized
+	}
+	for i := 0; i < rows && i < len(g.rows); i++ {
+		copy(resized.rows[i], g.rows[i])
+		resized.dirty[i] = g.dirty[i]
+	}
+
+	return resized
+}
+
+// Put writes a line, and returns false if it is out of the grid
+func (g *Grid) Put(line *Line) bool {
+	if line.Row < 0 || line.Row >= len(g.rows) || line.Col < 0 {
+		return false
+	}
+	row := g.rows[line.Row]
+	for i := range line.Cells {
+		col := line.Col + i
+		if col >= len(row) {
+			break
+		}
+		cell := line.Cells[i]
+		row[col] = &cell
+	}
+	g.dirty[line.Row] = true
+
+	return true
+}
+
+// Clear drops all the cells, as grid_clear does
+func (g *Grid) Clear() {
+	for i := range g.rows {
+		g.rows[i] = make([]*Cell, len(g.rows[i]))
+		g.dirty[i] = false
+	}
+}
+
+// Scroll moves the region by the rows of count, as grid_scroll does; the
+// rows scrolled into the region are not drawn yet. A row moved from a dirty
+// row becomes dirty. The zero region is the whole grid.
+func (g *Grid) Scroll(top, bot, left, right, count int) {
+	if top == 0 && bot == 0 && left == 0 && right == 0 {
+		right, bot = g.Size()
+	}
+	if top < 0 || left < 0 || bot > len(g.rows) || top >= bot {
+		return
+	}
+	move := func(row int) {
+		src := row + count
+		if src >= top && src < bot {
+			for col := left; col < right && col < len(g.rows[row]); col++ {
+				g.rows[row][col] = g.rows[src][col]
+			}
+			g.dirty[row] = g.dirty[row] || g.dirty[src]
+			return
+		}
+		for col := left; col < right && col < len(g.rows[row]); col++ {
+			g.rows[row][col] = nil
+		}
+	}
+	if count > 0 {
+		for row := top; row < bot; row++ {
+			move(row)
+		}
+	} else {
+		for row := bot - 1; row >= top; row-- {
+			move(row)
+		}
+	}
+}
+
+// TakeDirty returns the rows changed since the previous call as the lines of
+// their drawn cells, of the grid of the id
+func (g *Grid) TakeDirty(id int) []*Line {
+	var lines []*Line
+	for i, row := range g.rows {
+		if !g.dirty[i] {
+			continue
+		}
+		g.dirty[i] = false
+		var line *Line
+		for col, cell := range row {
+			if cell == nil {
+				line = nil
+				continue
+			}
+			if line == nil {
+				line = &Line{Grid: id, Row: i, Col: col}
+				lines = append(lines, line)
+			}
+			line.Cells = append(line.Cells, *cell)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,203 @@
+package grid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lineArg returns the argument of a grid_line call with a cell of each character
+func lineArg(grid, row, col int, text string, hl int) []interface{} {
+	var cells []interface{}
+	for i, c := range text {
+		if i == 0 {
+			cells = append(cells, []interface{}{string(c), int64(hl)})
+		} else {
+			cells = append(cells, []interface{}{string(c)})
+		}
+	}
+	return []interface{}{int64(grid), int64(row), int64(col), cells}
+}
+
+// rows returns the text of the rows of the grid, "." for the cells not drawn
+func rows(g *Grid) string {
+	cols, n := g.Size()
+	lines := make([]string, n)
+	for row := range lines {
+		var b strings.Builder
+		for col := 0; col < cols; col++ {
+			if cell := g.Cell(row, col); cell != nil {
+				b.WriteString(cell.Text)
+			} else {
+				b.WriteString(".")
+			}
+		}
+		lines[row] = b.String()
+	}
+	return strings.Join(lines, "|")
+}
+
+func TestDecodeLines(t *testing.T) {
+	args := []interface{}{
+		[]interface{}{int64(2), int64(1), int64(3), []interface{}{
+			[]interface{}{"a", int64(5)},
+			[]interface{}{"b"},
+			[]interface{}{" ", int64(0), int64(3)},
+			[]interface{}{"c", uint64(7), int64(1)},
+		}},
+		[]interface{}{int64(2), int64(1)},
+		"invalid",
+	}
+	want := []*Line{
+		{Grid: 2, Row: 1, Col: 3, Cells: []Cell{
+			{"a", 5}, {"b", 5}, {" ", 0}, {" ", 0}, {" ", 0}, {"c", 7},
+		}},
+	}
+	if got := DecodeLines(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestPut(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines [][]interface{}
+		want  string
+		ok    []bool
+	}{
+		{
+			name:  "later line overwrites",
+			lines: [][]interface{}{lineArg(1, 0, 0, "abcd", 1), lineArg(1, 0, 1, "XY", 2)},
+			want:  "aXYd|....",
+			ok:    []bool{true, true},
+		},
+		{
+			name:  "clipped at the right edge",
+			lines: [][]interface{}{lineArg(1, 1, 2, "xyz", 1)},
+			want:  "....|..xy",
+			ok:    []bool{true},
+		},
+		{
+			name:  "out of the rows",
+			lines: [][]interface{}{lineArg(1, 2, 0, "a", 1), lineArg(1, -1, 0, "a", 1)},
+			want:  "....|....",
+			ok:    []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(4, 2)
+			for i, arg := range tt.lines {
+				if ok := g.Put(DecodeLines([]interface{}{arg})[0]); ok != tt.ok[i] {
+					t.Errorf("Put #%d = %v, want %v", i, ok, tt.ok[i])
+				}
+			}
+			if got := rows(g); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tests := []struct {
+		name                         string
+		top, bot, left, right, count int
+		want                         string
+	}{
+		{"up the whole grid", 0, 4, 0, 3, 1, "bbb|ccc|ddd|..."},
+		{"down the whole grid", 0, 4, 0, 3, -2, "...|...|aaa|bbb"},
+		{"the zero region is the whole grid", 0, 0, 0, 0, 1, "bbb|ccc|ddd|..."},
+		{"up a region", 1, 3, 1, 3, 1, "aaa|bcc|c..|ddd"},
+		{"invalid region", 3, 1, 0, 3, 1, "aaa|bbb|ccc|ddd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(3, 4)
+			for row, text := range []string{"aaa", "bbb", "ccc", "ddd"} {
+				g.Put(DecodeLines([]interface{}{lineArg(1, row, 0, text, 1)})[0])
+			}
+			g.Scroll(tt.top, tt.bot, tt.left, tt.right, tt.count)
+			if got := rows(g); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakeDirty(t *testing.T) {
+	put := func(g *Grid, row, col int, text string) {
+		g.Put(DecodeLines([]interface{}{lineArg(3, row, col, text, 1)})[0])
+	}
+	tests := []struct {
+		name string
+		edit func(g *Grid) *Grid
+		want []*Line
+	}{
+		{
+			name: "merged lines of a row",
+			edit: func(g *Grid) *Grid {
+				put(g, 1, 0, "ab")
+				put(g, 1, 1, "X")
+				return g
+			},
+			want: []*Line{{Grid: 3, Row: 1, Col: 0, Cells: []Cell{{"a", 1}, {"X", 1}}}},
+		},
+		{
+			name: "split by the cells not drawn",
+			edit: func(g *Grid) *Grid {
+				put(g, 0, 0, "a")
+				put(g, 0, 2, "b")
+				return g
+			},
+			want: []*Line{
+				{Grid: 3, Row: 0, Col: 0, Cells: []Cell{{"a", 1}}},
+				{Grid: 3, Row: 0, Col: 2, Cells: []Cell{{"b", 1}}},
+			},
+		},
+		{
+			name: "cleared",
+			edit: func(g *Grid) *Grid {
+				put(g, 0, 0, "abc")
+				g.Clear()
+				return g
+			},
+		},
+		{
+			name: "dirty row moved by scroll",
+			edit: func(g *Grid) *Grid {
+				put(g, 1, 0, "abc")
+				g.Scroll(0, 2, 0, 3, 1)
+				return g
+			},
+			want: []*Line{{Grid: 3, Row: 0, Col: 0, Cells: []Cell{{"a", 1}, {"b", 1}, {"c", 1}}}},
+		},
+		{
+			name: "resized with the content",
+			edit: func(g *Grid) *Grid {
+				put(g, 0, 0, "abc")
+				put(g, 1, 0, "def")
+				return g.Resize(2, 1)
+			},
+			want: []*Line{{Grid: 3, Row: 0, Col: 0, Cells: []Cell{{"a", 1}, {"b", 1}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.edit(New(3, 2))
+			if got := g.TakeDirty(3); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got := g.TakeDirty(3); got != nil {
+				t.Errorf("taken again: %+v", got)
+			}
+		})
+	}
+}
+
+func TestResizeNil(t *testing.T) {
+	var g *Grid
+	if cols, rows := g.Resize(2, 3).Size(); cols != 2 || rows != 3 {
+		t.Errorf("got %dx%d, want 2x3", cols, rows)
+	}
+}
@@ -54,6 +54,9 @@ func IsTrue(d interface{}) bool {
 		return false
 	}
 	switch a := d.(type) {
+	case bool:
+		// The Lua boolean
+		return a
 	case int64:
 		if a == 1 {
 			return true
@@ -73,7 +76,8 @@ func SplitVimscript(s string) string {
 	listLines := "["
 	lines := strings.Split(s, "\n")
 	for i, line := range lines {
-		listLines = listLines + `'` + line + `'`
+		// Single quotes are doubled in a literal string of Vim script
+		listLines = listLines + `'` + strings.Replace(line, `'`, `''`, -1) + `'`
 		if i == len(lines)-1 {
 			listLines = listLines + "]"
 		} else {